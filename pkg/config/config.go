@@ -1,8 +1,12 @@
 package config
 
 import (
+	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 )
 
 // APIKeyLocation specifies where the API key is located for requests.
@@ -16,31 +20,350 @@ const (
 	// APIKeyLocationCookie APIKeyLocation = "cookie" // Add if needed
 )
 
+// SessionHeader is the upstream header an initialize _meta key is sent as, see
+// Config.SessionHeaders.
+type SessionHeader struct {
+	Header    string // Upstream header name, e.g. "X-Tenant-ID".
+	Sensitive bool   // Keep the value in memory only, out of the connection state file.
+}
+
+// ClientToolRule tailors tools/list for clients whose initialize clientInfo.name matches Client,
+// see Config.ClientToolRules.
+type ClientToolRule struct {
+	Client   string   // Client name pattern, case-insensitive, with path.Match wildcards, e.g. "claude-*".
+	Tags     []string // List only generated tools with one of these tags (empty lists all).
+	MaxTools int      // List at most this many tools (0 means no limit).
+}
+
 // Config holds the configuration for generating the MCP toolset.
 type Config struct {
 	SpecPath string // Path or URL to the OpenAPI specification file.
 
+	SpecEnvInterpolation bool   // Replace ${VAR} placeholders in the spec text with environment values before parsing.
+	SpecEnvStrict        bool   // Fail loading when a spec placeholder has no value and no default (implies SpecEnvInterpolation).
+	SpecFormat           string // Parse the spec as "json" or "yaml"; "auto" (or empty) detects the format.
+
+	SpecPollInterval time.Duration // Re-read the spec this often and rebuild the tools when it changes (0 disables hot reload).
+
 	// API Key details (optional, inferred from spec if possible)
 	APIKey           string         // The actual API key value.
 	APIKeyName       string         // Name of the header or query parameter for the API key (e.g., "X-API-Key", "api_key").
 	APIKeyLocation   APIKeyLocation // Where the API key should be placed (header, query, path, or cookie).
 	APIKeyFromEnvVar string         // Environment variable name to read the API key from.
 
+	// Credentials for the spec's security schemes (optional), keyed by scheme name
+	SecurityCredentials   map[string]string // Credential value per scheme: API key, bearer/OAuth token, or "user:password" for basic.
+	SecurityCredentialEnv map[string]string // Environment variable to read each scheme's credential from; takes precedence.
+	SecretCredentials     map[string]string // Credentials from the secrets file, keyed by lowercased scheme name; override SecurityCredentials.
+
+	// Session expiry detection for session-auth backends (optional)
+	LoginRedirectPattern string                 // Regular expression for redirect targets that are the login page; such redirects fail as an expired session.
+	LoginOperation       string                 // Tool that logs in again when the session expired; the session cookies it sets are sent from then on.
+	LoginArguments       map[string]interface{} // Arguments of the login operation, e.g. username and password.
+
 	// Filtering (optional)
 	IncludeTags       []string // Only include operations with these tags.
 	ExcludeTags       []string // Exclude operations with these tags.
 	IncludeOperations []string // Only include operations with these IDs.
 	ExcludeOperations []string // Exclude operations with these IDs.
+	PathPrefixes      []string // Only include operations whose path is under one of these prefixes.
+	AllowedMethods    []string // HTTP methods eligible for tool generation; nil uses DefaultAllowedMethods.
+	ReadOnly          bool     // Only generate tools for ReadOnlyMethods operations not annotated as mutating.
+	AllowEmptyToolSet bool     // Start even when the filters leave no tools, instead of refusing to.
 
 	// Overrides (optional)
-	ServerBaseURL   string // Manually override the base URL for API calls, ignoring the spec's servers field.
-	DefaultToolName string // Name for the toolset if not specified in the spec's info section.
-	DefaultToolDesc string // Description for the toolset if not specified in the spec's info section.
+	ServerBaseURL   string            // Manually override the base URL for API calls, ignoring the spec's servers field.
+	PathOverrides   map[string]string // Per-tool upstream path templates keyed by tool name, e.g. "/v2/users/{id}"; joined to the base URL as usual.
+	DefaultToolName string            // Name for the toolset if not specified in the spec's info section.
+	DefaultToolDesc string            // Description for the toolset if not specified in the spec's info section.
+	ToolNamePrefix  string            // Prepended to every generated tool name, e.g. "petstore_". Per-tool options use the final name.
+	ToolNameSuffix  string            // Appended to every generated tool name.
+
+	// Trailing slash handling of upstream request paths: "always", "never" or "preserve" (the
+	// default). The per-tool mode wins over the one for the base URL, which wins over the global one.
+	TrailingSlash          string
+	ServerTrailingSlash    map[string]string // Keyed by base URL, e.g. "https://api.example.com/v1"
+	OperationTrailingSlash map[string]string // Keyed by tool name
+
+	MaxToolDescriptionLength int // Longest generated tool description in characters; longer ones are cut at a sentence or word boundary with "…" (0 disables).
+
+	// DuplicateOperationIDs is what to do when operations share an operationId: "error", "warn"
+	// (the default; keep all under disambiguated names), "first" or "last" (keep one).
+	DuplicateOperationIDs string
 
 	// Server-side request modification
 	CustomHeaders string // Comma-separated list of headers (e.g., "Header1:Value1,Header2:Value2") to add to outgoing requests.
 
+	// SessionHeaders maps initialize _meta keys to headers sent on every upstream call of the
+	// connection, e.g. "tenant" to X-Tenant-ID. Keys the client does not send are not sent.
+	SessionHeaders map[string]SessionHeader
+
+	// ClientToolRules tailor tools/list per connection by client name; the first matching rule
+	// applies. Routing tools/call is unaffected.
+	ClientToolRules []ClientToolRule
+
+	RequestCompressionMinBytes int // Gzip outgoing request bodies of at least this many bytes (0 disables compression).
+
+	// RequestBodyFormat is how request bodies are sent for operations accepting both JSON and form
+	// bodies: "json" (the default) or "form" for application/x-www-form-urlencoded.
+	RequestBodyFormat string
+
+	// Accept header sent upstream (optional); defaults to the operation's JSON media type
+	AcceptHeader    string            // Accept header for every tool.
+	OperationAccept map[string]string // Per-tool Accept overrides keyed by tool name; take precedence over AcceptHeader.
+
+	// Accept-Language sent upstream (optional)
+	AcceptLanguage string // Default Accept-Language for every tool.
+	LocaleFromMeta bool   // Send the locale in a tools/call _meta instead, when the client gives one.
+
+	// Response projection (optional)
+	RateLimitHeaders    []string            // Upstream response headers surfaced in tool result _meta; nil uses the common rate-limit headers.
+	ResponseProjections map[string][]string // Per-tool response field allow-lists (field names or JSON pointers) keyed by tool name.
+	ResponseTransforms  map[string]string   // Per-tool response transform expressions (a JMESPath subset) keyed by tool name.
+
+	// Base64 response fields returned as image/audio content blocks next to the JSON text (optional)
+	ResponseBinaryFields   map[string][]string // Per-tool base64 fields (field names or JSON pointers) keyed by tool name.
+	BinaryFieldsFromSchema bool                // Also split out string fields the response schema declares with format byte or binary.
+
+	// Structured results (optional)
+	StructuredResults       bool     // Return {status, headers, body} as structuredContent, with its JSON as the text fallback.
+	StructuredResultHeaders []string // Upstream headers included in structured results; nil uses Content-Type, Location, ETag and Last-Modified.
+
+	// Upstream timeouts
+	UpstreamTimeout   time.Duration            // Default timeout for upstream API calls (0 uses the built-in default).
+	OperationTimeouts map[string]time.Duration // Per-tool timeout overrides keyed by tool name; take precedence over x-mcp-timeout.
+
+	// Per-phase upstream timeouts within the overall one (0 leaves the phase bounded only by it)
+	ConnectTimeout        time.Duration // Time allowed to open a TCP connection to the upstream.
+	TLSHandshakeTimeout   time.Duration // Time allowed for the TLS handshake (0 keeps Go's default of 10s).
+	ResponseHeaderTimeout time.Duration // Time allowed between sending the request and receiving response headers.
+
+	// Upstream proxy (optional); when set it replaces the HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment
+	UpstreamProxyURL string   // http, https or socks5 proxy URL; credentials may be given as user info.
+	UpstreamNoProxy  []string // Hosts reached directly: domains (with subdomains), IPs, CIDR ranges, optional :port, or "*".
+
+	// Upstream URL length. Large array query parameters can push URLs past what servers accept.
+	MaxURLLength       int               // Longest request URL sent upstream, in bytes; longer calls fail with a clear error (0 disables the check).
+	LongQueryFallbacks map[string]string // Tool to call instead, with the same arguments, when a tool's URL is too long, keyed by tool name.
+
+	// OperationMaxConcurrency caps how many calls to a tool run at once across all connections,
+	// keyed by tool name; takes precedence over x-mcp-max-concurrency. Further calls wait.
+	OperationMaxConcurrency map[string]int
+
+	// Server-wide call rates per HTTP method class, across all tools and connections (0 leaves a
+	// class unlimited). Calls over the rate are refused with a rate-limit error.
+	ReadRateLimit  float64 // Calls per second to GET, HEAD and OPTIONS operations.
+	ReadRateBurst  int     // Read calls allowed in a burst above the rate (0 uses the rate, at least 1).
+	WriteRateLimit float64 // Calls per second to all other operations: POST, PUT, PATCH and DELETE.
+	WriteRateBurst int     // Write calls allowed in a burst above the rate (0 uses the rate, at least 1).
+
+	// Retries of failed upstream calls (optional): transport errors, timeouts and retryable statuses
+	// such as 503. Calls are only repeated when their method is idempotent or they carry an
+	// idempotency key.
+	UpstreamRetries         int           // Extra attempts after a retryable failure (0 disables retries).
+	UpstreamRetryBackoff    time.Duration // Wait before the first retry, doubled for each later one (0 uses the default).
+	UpstreamRetryMaxBackoff time.Duration // Longest wait between attempts (0 uses the default).
+	UpstreamRetryJitter     string        // Randomizing of the wait: "full" (the default), "equal" or "none".
+
+	// Circuit breaker per upstream host (optional)
+	CircuitBreakerThreshold int           // Consecutive failures (transport errors or 5xx) that open a host's circuit (0 disables).
+	CircuitBreakerWindow    time.Duration // Failures must fall within this window to count as consecutive (0 means no limit).
+	CircuitBreakerCooldown  time.Duration // How long an open circuit fails calls fast before probing (0 uses the default).
+
+	// Streaming responses (optional)
+	StreamingOperations []string // Tool names whose responses are forwarded incrementally as progress notifications.
+	StreamMaxBytes      int64    // Largest streamed response accepted (0 uses the default).
+
+	// PaginateMaxPages is how many pages a GET tool call fetches in total by following Link
+	// rel="next" response headers (0 or 1 disables pagination).
+	PaginateMaxPages int
+
+	// Upstream text/event-stream responses are forwarded event by event and end early at either cap.
+	StreamMaxEvents   int           // Most events read from one upstream event stream (0 uses the default).
+	StreamMaxDuration time.Duration // Longest an upstream event stream is read (0 disables; the upstream timeout still applies).
+
+	// Idempotency / deduplication (optional)
+	IdempotencyKeyHeader string        // Header carrying the idempotency key (defaults to "Idempotency-Key").
+	IdempotencyWindow    time.Duration // How long identical calls to idempotency-key operations are deduplicated (0 disables).
+	IdempotentOperations []string      // Additional tool names to treat as idempotency-key operations.
+
+	// Mock mode (optional)
+	MockMode   bool   // Answer tool calls from spec examples/schemas instead of calling the upstream API.
+	MockStatus string // Preferred response status to mock (e.g., "201"); defaults to the first 2xx.
+
+	OrderedResponses bool // Deliver each connection's responses in request arrival order.
+
+	MaxRequestBytes int64 // Largest inbound JSON-RPC message accepted on any transport (0 uses the default).
+	MaxRawBodyBytes int64 // Largest decoded binary request body sent upstream (0 uses the default).
+
+	JSONRPCStrictness string // "strict" (default) accepts only jsonrpc "2.0"; "lenient" also accepts a missing field or a 2.0 variant.
+
+	MaxResponseBytes          int64            // Largest upstream response body read for a tool call (0 uses the default).
+	OperationMaxResponseBytes map[string]int64 // Per-tool overrides keyed by tool name; take precedence over x-mcp-max-response-bytes.
+
+	// WebSocket transport
+	WebSocketMaxMessageBytes int64         // Largest inbound WebSocket message accepted (0 uses the default).
+	WebSocketReadTimeout     time.Duration // Idle time allowed between inbound frames, including pongs (0 uses the default).
+	WebSocketWriteTimeout    time.Duration // Deadline for writing a single outbound frame (0 uses the default).
+
+	// Input schema strictness (optional)
+	StrictInputProperties bool     // Set additionalProperties: false on all generated object input schemas.
+	StrictInputOperations []string // Tool names to make strict when StrictInputProperties is off.
+
+	// MaxInputSchemaDepth truncates object schemas nested this many levels deep in generated input
+	// schemas to free-form objects, pointing to a resource with the full schema. 0 means unlimited.
+	MaxInputSchemaDepth int
+
+	// UnwrapBodyOperations are tools whose single-property request body (e.g. {"data": {...}}) is
+	// collapsed to the inner object in the input schema and re-wrapped upstream.
+	UnwrapBodyOperations []string
+
+	// PinnedArguments are server-side argument values keyed by tool name, then argument name. They
+	// override client values and supply parameters hidden with x-mcp-hidden.
+	PinnedArguments map[string]map[string]interface{}
+
+	// TagPinnedArguments are server-side argument values keyed by OpenAPI tag, then argument name.
+	// They apply to every tool generated from an operation with the tag and are removed from its
+	// input schema. PinnedArguments for the tool override them.
+	TagPinnedArguments map[string]map[string]interface{}
+
+	// BooleanQueryStyles choose how boolean query parameters are sent, keyed by tool name, then
+	// parameter name ("*" for all of them): "value" (?flag=true, the default) or "presence" (?flag
+	// for true, nothing for false). They override a parameter's x-mcp-boolean-style.
+	BooleanQueryStyles map[string]map[string]string
+
+	// ParameterAliases rename parameters in a tool's input schema, keyed by tool name, then the
+	// alias, with the name the API uses as value. Calls are translated back before the request is
+	// built. Pinned and hidden arguments keep their API names.
+	ParameterAliases map[string]map[string]string
+
+	// MissingRequired is what happens to a call to a generated tool that leaves out a required
+	// argument, keyed by tool name, then argument name ("*" for all of them): "reject" (the
+	// default) or "passthrough", which calls the upstream without it. MissingRequiredDefaults,
+	// keyed the same way, substitute a value instead, and take precedence.
+	MissingRequired         map[string]map[string]string
+	MissingRequiredDefaults map[string]map[string]interface{}
+
+	DisableInputValidation bool // Forward tool calls without checking arguments against the input schema.
+	ValidateFormats        bool // Also check string arguments with well-known formats (date-time, email, uuid, ...).
+	CoerceStringArguments  bool // Convert well-formed strings like "true" or "42" to the boolean, integer or number type the schema declares.
+
+	// ToolOrder is the order of tools in tools/list: "spec" (as written in the spec, the default),
+	// "name" or "tag" (first tag, then name). Built-in tools come last either way.
+	ToolOrder string
+
+	// Built-in tools
+	DisableDescribeTool bool // Hide the built-in __describe_operation introspection tool.
+	UpstreamHealthTool  bool // Serve the built-in __upstream_health tool, which probes the upstream.
+
+	PromptsFile string // Path to a YAML file of prompt templates served via prompts/list and prompts/get.
+
+	// HTTP listener. TLS is enabled when TLSCertFile and TLSKeyFile are set; every endpoint shares it.
+	ListenAddress   string // Host or IP to bind; empty binds all interfaces.
+	TLSCertFile     string // PEM certificate (chain) presented by the server.
+	TLSKeyFile      string // PEM private key for TLSCertFile.
+	TLSMinVersion   string // Lowest TLS version accepted: "1.2" (default) or "1.3".
+	TLSClientCAFile string // PEM CA bundle; when set, clients must present a certificate it signed (mTLS).
+
 	StateFilePath string // Configuration state file path
+
+	StatePersistRetries      int           // Extra attempts for a failed state file write (0 writes once).
+	StatePersistRetryBackoff time.Duration // Wait before the first retry; later retries wait proportionally longer.
+
+	CaseSensitiveSessionIDs bool   // Match connection/session IDs exactly instead of lowercasing them.
+	SessionIDHeader         string // Header carrying the connection/session ID (defaults to "Mcp-Session-Id").
+	ConnectionIDFormat      string // Format of server-minted connection IDs: "random" (default) or "uuidv7".
+	ConnectionIDMaxLength   int    // Longest accepted connection ID in bytes (0 uses the default of 128).
+	ConnectionIDPattern     string // Regular expression connection IDs must match in full; empty allows printable ASCII except space, '/' and '\'.
+
+	// Connection reaping. The handshake timeout is kept shorter than the idle one, so clients that
+	// stall before ready are cleaned up quickly without cutting off quiet ready sessions.
+	ConnectionInitTimeout time.Duration // Remove connections not ready this long after connecting (0 disables).
+	ConnectionIdleTimeout time.Duration // Remove ready connections without activity for this long (0 disables).
+	// ConnectionReapWarnFraction logs a warning once a connection has used this fraction of its
+	// timeout, e.g. 0.8, once per connection. 0 disables the warning.
+	ConnectionReapWarnFraction float64
+
+	// HandshakeDeadline shuts down each connection that is not ready this long after connecting,
+	// using a timer per connection rather than the reaper's scan (0 disables).
+	HandshakeDeadline time.Duration
+
+	// CallHistorySize is how many recent tool calls each connection keeps for
+	// GET /admin/connections/{id}/history (0 disables the history).
+	CallHistorySize int
+}
+
+// DefaultAllowedMethods are the HTTP methods turned into tools when AllowedMethods is not set.
+// TRACE and CONNECT are left out: they echo or tunnel requests and are rarely meant for clients.
+var DefaultAllowedMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+
+// ReadOnlyMethods are the safe HTTP methods still turned into tools in read-only mode.
+var ReadOnlyMethods = []string{"GET", "HEAD", "OPTIONS"}
+
+// IsMethodAllowed reports whether operations with the given HTTP method become tools. In
+// read-only mode the method must also be one of ReadOnlyMethods.
+func (c *Config) IsMethodAllowed(method string) bool {
+	if c.ReadOnly && !IsReadOnlyMethod(method) {
+		return false
+	}
+	allowed := c.AllowedMethods
+	if allowed == nil {
+		allowed = DefaultAllowedMethods
+	}
+	return containsMethod(allowed, method)
+}
+
+// IsReadOnlyMethod reports whether method is one of ReadOnlyMethods.
+func IsReadOnlyMethod(method string) bool {
+	return containsMethod(ReadOnlyMethods, method)
+}
+
+// containsMethod reports whether methods contains method, ignoring case.
+func containsMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultIdempotencyKeyHeader is used when IdempotencyKeyHeader is not set.
+const DefaultIdempotencyKeyHeader = "Idempotency-Key"
+
+// DefaultSessionIDHeader is the MCP spec's session header, used when SessionIDHeader is not set.
+const DefaultSessionIDHeader = "Mcp-Session-Id"
+
+// GetSessionIDHeader returns the configured session ID header, or the default.
+func (c *Config) GetSessionIDHeader() string {
+	if c.SessionIDHeader != "" {
+		return c.SessionIDHeader
+	}
+	return DefaultSessionIDHeader
+}
+
+// GetIdempotencyKeyHeader returns the configured idempotency key header, or the default.
+func (c *Config) GetIdempotencyKeyHeader() string {
+	if c.IdempotencyKeyHeader != "" {
+		return c.IdempotencyKeyHeader
+	}
+	return DefaultIdempotencyKeyHeader
+}
+
+// GetSecurityCredential resolves the credential for a security scheme, prioritizing the
+// environment variable over the direct value, like GetAPIKey. A secrets file entry replaces the
+// direct value.
+func (c *Config) GetSecurityCredential(scheme string) string {
+	if envVar := c.SecurityCredentialEnv[scheme]; envVar != "" {
+		if val := os.Getenv(envVar); val != "" {
+			return val
+		}
+		log.Printf("GetSecurityCredential: Environment variable %s for scheme '%s' not found or empty.", envVar, scheme)
+	}
+	if val, ok := c.SecretCredentials[strings.ToLower(scheme)]; ok {
+		return val
+	}
+	return c.SecurityCredentials[scheme]
 }
 
 // GetAPIKey resolves the API key value, prioritizing the environment variable over the direct flag.
@@ -70,3 +393,84 @@ func (c *Config) GetAPIKey() string {
 	log.Println("GetAPIKey: No API key found from config (env var or direct flag).")
 	return ""
 }
+
+// redactedValue replaces secret values in the formatted config.
+const redactedValue = "[REDACTED]"
+
+// printableConfig has Config's fields but not its methods, so formatting it doesn't recurse.
+type printableConfig Config
+
+// String formats the config like %+v with the API key, credentials, login, pinned and default
+// argument values, custom header values and the proxy password replaced, so it is safe to log.
+// It has a value receiver so that a Config value is redacted as well as a pointer to one.
+func (c Config) String() string {
+	return fmt.Sprintf("%+v", c.redacted())
+}
+
+// GoString is the %#v form of String, with the same values redacted.
+func (c Config) GoString() string {
+	return "config.Config" + strings.TrimPrefix(fmt.Sprintf("%#v", c.redacted()), "config.printableConfig")
+}
+
+func (c Config) redacted() printableConfig {
+	p := printableConfig(c)
+	if p.APIKey != "" {
+		p.APIKey = redactedValue
+	}
+	p.SecurityCredentials = redactStringValues(p.SecurityCredentials)
+	p.SecretCredentials = redactStringValues(p.SecretCredentials)
+	p.LoginArguments = redactValues(p.LoginArguments)
+	p.PinnedArguments = redactNestedValues(p.PinnedArguments)
+	p.TagPinnedArguments = redactNestedValues(p.TagPinnedArguments)
+	p.MissingRequiredDefaults = redactNestedValues(p.MissingRequiredDefaults)
+	if p.CustomHeaders != "" {
+		headers := strings.Split(p.CustomHeaders, ",")
+		for i, header := range headers {
+			if name, _, found := strings.Cut(header, ":"); found {
+				headers[i] = name + ":" + redactedValue
+			}
+		}
+		p.CustomHeaders = strings.Join(headers, ",")
+	}
+	if p.UpstreamProxyURL != "" {
+		if u, err := url.Parse(p.UpstreamProxyURL); err == nil {
+			p.UpstreamProxyURL = u.Redacted()
+		} else {
+			p.UpstreamProxyURL = redactedValue
+		}
+	}
+	return p
+}
+
+func redactStringValues(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	redacted := make(map[string]string, len(values))
+	for key := range values {
+		redacted[key] = redactedValue
+	}
+	return redacted
+}
+
+func redactValues(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+	redacted := make(map[string]interface{}, len(values))
+	for key := range values {
+		redacted[key] = redactedValue
+	}
+	return redacted
+}
+
+func redactNestedValues(values map[string]map[string]interface{}) map[string]map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+	redacted := make(map[string]map[string]interface{}, len(values))
+	for key, inner := range values {
+		redacted[key] = redactValues(inner)
+	}
+	return redacted
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// LoadSecretsFile reads security scheme credentials from a secrets file kept apart from the rest
+// of the configuration, so it can be rotated and gitignored on its own. The file (YAML, JSON or
+// TOML, by extension) holds a credentials map keyed by scheme name:
+//
+//	credentials:
+//	  petstoreAuth: tok-123
+//
+// Viper lowercases keys, so the returned map is keyed by lowercased scheme name. A file readable
+// by other users is loaded with a warning.
+func LoadSecretsFile(path string) (map[string]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file '%s': %w", path, err)
+	}
+	if info.Mode().Perm()&0o004 != 0 {
+		log.Printf("Warning: secrets file '%s' is world-readable (mode %s); restrict it with chmod 600", path, info.Mode().Perm())
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file '%s': %w", path, err)
+	}
+	credentials := make(map[string]string)
+	for scheme, value := range v.GetStringMapString("credentials") {
+		if value == "" {
+			return nil, fmt.Errorf("secrets file '%s' has an empty credential for scheme '%s'", path, scheme)
+		}
+		credentials[strings.ToLower(scheme)] = value
+	}
+	return credentials, nil
+}
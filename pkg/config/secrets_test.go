@@ -0,0 +1,87 @@
+package config
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSecretsFile(t *testing.T, content string, mode os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "auth.yaml")
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		t.Fatalf("writing secrets file: %v", err)
+	}
+	if err := os.Chmod(path, mode); err != nil { // WriteFile's mode is subject to the umask
+		t.Fatalf("chmod secrets file: %v", err)
+	}
+	return path
+}
+
+func TestLoadSecretsFile(t *testing.T) {
+	path := writeSecretsFile(t, "credentials:\n  petstoreAuth: tok-secret\n  basicAuth: \"user:pass\"\n", 0o600)
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	credentials, err := LoadSecretsFile(path)
+	if err != nil {
+		t.Fatalf("LoadSecretsFile() error = %v", err)
+	}
+	if got := credentials["petstoreauth"]; got != "tok-secret" {
+		t.Errorf("credentials[petstoreauth] = %q, want %q", got, "tok-secret")
+	}
+	if got := credentials["basicauth"]; got != "user:pass" {
+		t.Errorf("credentials[basicauth] = %q, want %q", got, "user:pass")
+	}
+	if strings.Contains(logged.String(), "world-readable") {
+		t.Errorf("unexpected world-readable warning for a 0600 file: %s", logged.String())
+	}
+}
+
+func TestLoadSecretsFile_WorldReadable(t *testing.T) {
+	path := writeSecretsFile(t, "credentials:\n  petstoreAuth: tok-secret\n", 0o644)
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	if _, err := LoadSecretsFile(path); err != nil {
+		t.Fatalf("LoadSecretsFile() error = %v", err)
+	}
+	if !strings.Contains(logged.String(), "is world-readable") {
+		t.Errorf("expected a world-readable warning, got: %s", logged.String())
+	}
+}
+
+func TestLoadSecretsFile_Errors(t *testing.T) {
+	if _, err := LoadSecretsFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+	if _, err := LoadSecretsFile(writeSecretsFile(t, "credentials:\n  petstoreAuth: \"\"\n", 0o600)); err == nil {
+		t.Error("expected an error for an empty credential")
+	}
+}
+
+func TestConfig_GetSecurityCredentialSecrets(t *testing.T) {
+	cfg := Config{
+		SecurityCredentials: map[string]string{"petstoreAuth": "placeholder", "other": "direct"},
+		SecretCredentials:   map[string]string{"petstoreauth": "tok-secret"},
+	}
+	if got := cfg.GetSecurityCredential("petstoreAuth"); got != "tok-secret" {
+		t.Errorf("GetSecurityCredential(petstoreAuth) = %q, want the secrets file value", got)
+	}
+	if got := cfg.GetSecurityCredential("other"); got != "direct" {
+		t.Errorf("GetSecurityCredential(other) = %q, want %q", got, "direct")
+	}
+
+	t.Setenv("TEST_PETSTORE_TOKEN", "tok-env")
+	cfg.SecurityCredentialEnv = map[string]string{"petstoreAuth": "TEST_PETSTORE_TOKEN"}
+	if got := cfg.GetSecurityCredential("petstoreAuth"); got != "tok-env" {
+		t.Errorf("GetSecurityCredential(petstoreAuth) = %q, want the environment value", got)
+	}
+}
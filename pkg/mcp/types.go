@@ -1,23 +1,76 @@
 package mcp
 
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+)
+
 // Based on the MCP specification: https://modelcontextprotocol.io/spec/
 
 // ParameterDetail describes a single parameter for an operation.
 type ParameterDetail struct {
-	Name string `json:"name"`
-	In   string `json:"in"` // Location (query, header, path, cookie)
+	Name         string `json:"name"`
+	In           string `json:"in"`                     // Location (query, header, path, cookie)
+	Style        string `json:"style,omitempty"`        // Serialization style as declared in the spec; empty means the default for In
+	Explode      *bool  `json:"explode,omitempty"`      // Declared explode flag; nil means the default for Style
+	BooleanStyle string `json:"booleanStyle,omitempty"` // How a boolean query parameter is sent, from x-mcp-boolean-style; empty means BooleanStyleValue
 	// Add other details if needed, e.g., required, type
 }
 
+// Ways a boolean query parameter is sent, see ParameterDetail.BooleanStyle.
+const (
+	BooleanStyleValue    = "value"    // ?flag=true and ?flag=false (the default)
+	BooleanStylePresence = "presence" // ?flag for true; false leaves the parameter out
+)
+
 // OperationDetail holds the necessary information to execute a specific API operation.
 type OperationDetail struct {
 	Method     string            `json:"method"`
 	Path       string            `json:"path"` // Path template (e.g., /users/{id})
 	BaseURL    string            `json:"baseUrl"`
 	Parameters []ParameterDetail `json:"parameters,omitempty"`
-	// Add RequestBody schema if needed
+
+	// Descriptive details from the spec, used for runtime introspection (not for execution).
+	RequestBody *Schema                `json:"requestBody,omitempty"` // Request body schema, if the operation takes one
+	Responses   map[string]Schema      `json:"responses,omitempty"`   // Response schemas keyed by status code (or "default")
+	Security    []map[string][]string  `json:"security,omitempty"`    // Security requirement alternatives (OR of ANDs)
+	Examples    map[string]interface{} `json:"examples,omitempty"`    // Declared response examples keyed by status code
+	Timeout     time.Duration          `json:"-"`                     // Per-operation upstream timeout from x-mcp-timeout (0 = server default)
+	MaxResponse int64                  `json:"-"`                     // Per-operation response body cap in bytes from x-mcp-max-response-bytes (0 = server default)
+	Concurrency int                    `json:"-"`                     // Most calls to the tool running at once, from x-mcp-max-concurrency (0 = unlimited)
+	Streaming   bool                   `json:"streaming,omitempty"`   // Forward the response incrementally (x-mcp-streaming or a streaming media type)
+	Idempotency bool                   `json:"idempotency,omitempty"` // Send an idempotency key and deduplicate retries (x-mcp-idempotency-key or a key header param)
+	Accept      string                 `json:"accept,omitempty"`      // Default Accept header: the declared JSON media type, else all declared success media types
+	BodyWrapper string                 `json:"bodyWrapper,omitempty"` // Request body property that tool arguments are wrapped in upstream (x-mcp-unwrap-body)
+	Deprecated  bool                   `json:"deprecated,omitempty"`  // Operation is marked deprecated in the spec
+	Sunset      string                 `json:"sunset,omitempty"`      // Planned removal date from x-sunset, as written in the spec
+	Tags        []string               `json:"tags,omitempty"`        // Tags the operation is grouped under in the spec
+	Confirm     bool                   `json:"confirm,omitempty"`     // Calls need human confirmation (x-mcp-confirm)
+	HealthCheck bool                   `json:"healthCheck,omitempty"` // Probed by the __upstream_health tool (x-mcp-health-check)
+
+	// RawBodyMediaType is the media type of a binary request body, which the tool takes base64
+	// encoded in its RawBodyArgument and sends as raw bytes. Empty for JSON bodies.
+	RawBodyMediaType string `json:"rawBodyMediaType,omitempty"`
+
+	// BodyMediaTypes are the request body media types the operation declares, sorted: its
+	// requestBody content in OpenAPI 3, or what it consumes in Swagger 2.
+	BodyMediaTypes []string `json:"bodyMediaTypes,omitempty"`
+
+	// TagArguments are the server-side argument values pinned for the operation's tags.
+	TagArguments map[string]interface{} `json:"-"`
+
+	// ArgumentAliases map the names the tool's input schema gives parameters, keyed by alias, to
+	// the names the API uses for them.
+	ArgumentAliases map[string]string `json:"argumentAliases,omitempty"`
 }
 
+// RawBodyArgument is the tool argument holding a base64-encoded binary request body, see
+// OperationDetail.RawBodyMediaType.
+const RawBodyArgument = "requestBody"
+
 // ToolSet represents the collection of tools provided by an MCP server.
 type ToolSet struct {
 	MCPVersion  string `json:"mcp_version"`
@@ -26,15 +79,80 @@ type ToolSet struct {
 	// Auth        *AuthInfo `json:"auth,omitempty"` // Removed authentication info
 	Tools []Tool `json:"tools"`
 
+	// Prompts are curated prompt templates served via prompts/list and prompts/get.
+	Prompts []Prompt `json:"prompts,omitempty"`
+
+	// Resources are read-only documents served via resources/list and resources/read, such as the
+	// events the API sends (callbacks and webhooks), which cannot be called as tools.
+	Resources []Resource `json:"-"`
+
 	// Operations maps Tool.Name (operationId) to its execution details.
 	// This is internal to the server and not part of the standard MCP JSON response.
 	Operations map[string]OperationDetail `json:"-"` // Use json:"-" to exclude from JSON
 
+	// SecuritySchemes maps the scheme names used in OperationDetail.Security to how each is sent.
+	SecuritySchemes map[string]SecurityScheme `json:"-"`
+
+	// CustomHandlers maps the names of hand-written tools in Tools to the Go code serving them.
+	// These tools have no entry in Operations and never call the upstream API.
+	CustomHandlers map[string]ToolHandler `json:"-"`
+
+	// Middleware wraps every tool call, outermost first. See ToolMiddleware.
+	Middleware []ToolMiddleware `json:"-"`
+
+	// ContentHandlers format upstream success bodies by media type, in registration order. They are
+	// consulted before the built-in JSON/text/image/audio/binary formatting.
+	ContentHandlers []ContentHandlerEntry `json:"-"`
+
+	// The spec the tools were generated from: its declared version (e.g. "OpenAPI 3.0.3") and how
+	// many operations it declares before filtering. Reported in the startup summary.
+	SpecVersion    string `json:"-"`
+	SpecOperations int    `json:"-"`
+
 	// Internal fields for server-side auth handling (not exposed in JSON)
 	apiKeyName string // e.g., "key", "X-API-Key"
 	apiKeyIn   string // e.g., "query", "header"
 }
 
+// ToolHandler serves a hand-written tool. It receives the call's arguments and returns the result:
+// a string is sent as text, any other value as JSON. A returned error is reported to the client as
+// a tool error.
+type ToolHandler func(ctx context.Context, arguments map[string]interface{}) (interface{}, error)
+
+// ToolCall is a tool invocation as seen by middleware.
+type ToolCall struct {
+	Name         string                 // Tool being called
+	Arguments    map[string]interface{} // Validated client arguments; middleware may replace them
+	ConnectionID string                 // MCP connection the call arrived on
+}
+
+// ToolCallHandler serves a tool call. Its result is rendered like a ToolHandler's.
+type ToolCallHandler func(ctx context.Context, call *ToolCall) (interface{}, error)
+
+// ToolMiddleware wraps the handler for the rest of the chain. It may change the call before
+// passing it on, inspect or replace the result, or answer without calling next at all.
+type ToolMiddleware func(next ToolCallHandler) ToolCallHandler
+
+// ContentHandler formats an upstream success body of a registered media type as tool result
+// content. mediaType is the response's Content-Type without parameters, lowercased. A returned
+// error falls back to the built-in formatting.
+type ContentHandler func(mediaType string, body []byte) (interface{}, error)
+
+// ContentHandlerEntry registers a ContentHandler for the media types matching Pattern, an exact
+// type such as "application/x-ndjson" or a path.Match pattern such as "application/vnd.*+json".
+type ContentHandlerEntry struct {
+	Pattern string
+	Handler ContentHandler
+}
+
+// SecurityScheme describes how a security scheme declared in the spec is sent upstream.
+type SecurityScheme struct {
+	Type   string // "apiKey", "http", "oauth2" or "openIdConnect"
+	Name   string // Header, query or cookie name, for apiKey schemes
+	In     string // "header", "query" or "cookie", for apiKey schemes
+	Scheme string // Authorization scheme for http schemes, e.g. "bearer" or "basic"
+}
+
 // SetAPIKeyDetails allows the parser to set internal API key info.
 func (ts *ToolSet) SetAPIKeyDetails(name, in string) {
 	ts.apiKeyName = name
@@ -47,17 +165,85 @@ func (ts *ToolSet) GetAPIKeyDetails() (name, in string) {
 	return ts.apiKeyName, ts.apiKeyIn
 }
 
+// ToolAnnotations holds MCP behavioral hints for a tool. Nil fields are omitted, leaving the client default.
+type ToolAnnotations struct {
+	ReadOnlyHint    *bool `json:"readOnlyHint,omitempty"`    // Tool does not modify its environment
+	DestructiveHint *bool `json:"destructiveHint,omitempty"` // Tool may perform destructive updates
+	IdempotentHint  *bool `json:"idempotentHint,omitempty"`  // Repeated calls with the same arguments have no additional effect
+	OpenWorldHint   *bool `json:"openWorldHint,omitempty"`   // Tool interacts with external entities
+}
+
 // Tool represents a single function or capability exposed via MCP.
 type Tool struct {
-	Name        string `json:"name"` // Corresponds to OpenAPI operationId or generated name
-	Description string `json:"description,omitempty"`
-	InputSchema Schema `json:"inputSchema"` // Renamed from Parameters, consolidate parameters/body here
+	Name        string                 `json:"name"` // Corresponds to OpenAPI operationId or generated name
+	Description string                 `json:"description,omitempty"`
+	InputSchema Schema                 `json:"inputSchema"`           // Renamed from Parameters, consolidate parameters/body here
+	Annotations *ToolAnnotations       `json:"annotations,omitempty"` // Behavioral hints for clients (e.g., auto-approval)
+	Meta        map[string]interface{} `json:"_meta,omitempty"`       // Server-attached metadata (e.g., effective timeout)
 	// Entrypoint  string      `json:"entrypoint"`             // Removed for simplicity, schema should contain enough info?
 	// RequestBody RequestBody `json:"request_body,omitempty"` // Removed, info should be part of InputSchema
 	// HTTPMethod  string      `json:"http_method"`            // Removed for simplicity
 	// TODO: Add Response handling if needed by spec/client
 }
 
+// Resource is a read-only document listed by resources/list and returned by resources/read.
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+	Text        string `json:"-"` // Content returned by resources/read
+}
+
+// PromptArgument describes one argument a prompt template accepts.
+type PromptArgument struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description"`
+	Required    bool   `json:"required,omitempty" yaml:"required"`
+}
+
+// Prompt is a parameterized prompt template. Template placeholders take the form {{argument}}.
+type Prompt struct {
+	Name        string           `json:"name" yaml:"name"`
+	Description string           `json:"description,omitempty" yaml:"description"`
+	Arguments   []PromptArgument `json:"arguments,omitempty" yaml:"arguments"`
+	Template    string           `json:"-" yaml:"template"` // Rendered by prompts/get, never listed
+}
+
+// promptPlaceholderPattern matches {{argument}} placeholders, allowing whitespace inside the braces.
+var promptPlaceholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_-]*)\s*\}\}`)
+
+// Placeholders returns the distinct argument names used in the template, in order of first use.
+func (p Prompt) Placeholders() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range promptPlaceholderPattern.FindAllStringSubmatch(p.Template, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// Render substitutes arguments into the template. Missing required arguments are an error;
+// missing optional ones render as empty text.
+func (p Prompt) Render(args map[string]string) (string, error) {
+	var missing []string
+	for _, arg := range p.Arguments {
+		if _, ok := args[arg.Name]; arg.Required && !ok {
+			missing = append(missing, arg.Name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return "", fmt.Errorf("missing required arguments: %v", missing)
+	}
+	return promptPlaceholderPattern.ReplaceAllStringFunc(p.Template, func(placeholder string) string {
+		return args[promptPlaceholderPattern.FindStringSubmatch(placeholder)[1]]
+	}), nil
+}
+
 // RequestBody describes the expected request body for a tool.
 // This might become redundant if all info is in InputSchema.
 // Keeping it for now as the parser might still use it internally.
@@ -77,5 +263,13 @@ type Schema struct {
 	Items       *Schema           `json:"items,omitempty"`      // For type "array"
 	Format      string            `json:"format,omitempty"`     // e.g., "int32", "date-time"
 	Enum        []interface{}     `json:"enum,omitempty"`
+
+	// AdditionalProperties is nil when unspecified (allowed by default); a schema-valued
+	// additionalProperties in the spec is represented as true.
+	AdditionalProperties *bool `json:"additionalProperties,omitempty"` // For type "object"
+
+	// Hidden marks a property from x-mcp-hidden; hidden top-level properties are removed from
+	// generated input schemas and supplied server-side instead.
+	Hidden bool `json:"-"`
 	// Add other relevant JSON Schema fields as needed (e.g., minimum, maximum, pattern)
 }
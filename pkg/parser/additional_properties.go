@@ -0,0 +1,49 @@
+package parser
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-openapi/spec"
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// additionalPropertiesV3 maps a v3 additionalProperties keyword to the MCP schema form.
+func additionalPropertiesV3(addProps openapi3.AdditionalProperties) *bool {
+	if addProps.Schema != nil {
+		allowed := true
+		return &allowed
+	}
+	return addProps.Has
+}
+
+// additionalPropertiesV2 maps a v2 additionalProperties keyword to the MCP schema form.
+func additionalPropertiesV2(addProps *spec.SchemaOrBool) *bool {
+	if addProps == nil {
+		return nil
+	}
+	allowed := addProps.Allows || addProps.Schema != nil
+	return &allowed
+}
+
+// strictInputProperties reports whether a tool's input schema should reject unknown properties.
+func strictInputProperties(toolName string, cfg *config.Config) bool {
+	return cfg.StrictInputProperties || sliceContains(cfg.StrictInputOperations, toolName)
+}
+
+// disallowAdditionalProperties sets additionalProperties: false on the schema and every nested
+// object schema that doesn't declare it. Objects the spec explicitly opens up are left alone.
+func disallowAdditionalProperties(schema *mcp.Schema) {
+	if schema.Type == "object" && schema.AdditionalProperties == nil {
+		disallowed := false
+		schema.AdditionalProperties = &disallowed
+	}
+	for name, propSchema := range schema.Properties {
+		disallowAdditionalProperties(&propSchema)
+		schema.Properties[name] = propSchema
+	}
+	if schema.Items != nil {
+		items := *schema.Items
+		disallowAdditionalProperties(&items)
+		schema.Items = &items
+	}
+}
@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const additionalPropertiesV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Additional Properties API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {
+    "/notes": {
+      "post": {
+        "operationId": "createNote",
+        "parameters": [{"name": "notify", "in": "query", "schema": {"type": "boolean"}}],
+        "requestBody": {"content": {"application/json": {"schema": {
+          "type": "object",
+          "properties": {
+            "text": {"type": "string"},
+            "author": {"type": "object", "properties": {"name": {"type": "string"}}},
+            "labels": {"type": "object", "additionalProperties": {"type": "string"}}
+          }
+        }}}},
+        "responses": {"201": {"description": "Created"}}
+      }
+    },
+    "/events": {
+      "post": {
+        "operationId": "trackEvent",
+        "requestBody": {"content": {"application/json": {"schema": {
+          "type": "object",
+          "additionalProperties": true,
+          "properties": {"name": {"type": "string"}}
+        }}}},
+        "responses": {"202": {"description": "Accepted"}}
+      }
+    }
+  }
+}`
+
+const additionalPropertiesV2SpecJSON = `{
+  "swagger": "2.0",
+  "info": {"title": "Additional Properties API", "version": "1.0.0"},
+  "host": "api.example.com",
+  "paths": {
+    "/events": {
+      "post": {
+        "operationId": "trackEvent",
+        "parameters": [{"name": "body", "in": "body", "schema": {
+          "type": "object",
+          "additionalProperties": true,
+          "properties": {"name": {"type": "string"}, "context": {"type": "object", "properties": {"ip": {"type": "string"}}}}
+        }}],
+        "responses": {"202": {"description": "Accepted"}}
+      }
+    }
+  }
+}`
+
+// inputSchemas returns each generated tool's input schema keyed by tool name.
+func inputSchemas(t *testing.T, fileName, content string, cfg *config.Config) map[string]mcp.Schema {
+	t.Helper()
+	doc, version := loadSpecFixture(t, fileName, content)
+	toolSet, err := GenerateToolSet(doc, version, cfg)
+	require.NoError(t, err)
+	schemas := make(map[string]mcp.Schema)
+	for _, tool := range toolSet.Tools {
+		schemas[tool.Name] = tool.InputSchema
+	}
+	return schemas
+}
+
+func TestGenerateToolSet_AdditionalProperties(t *testing.T) {
+	t.Run("Lenient by default", func(t *testing.T) {
+		schemas := inputSchemas(t, "additional_properties_v3.json", additionalPropertiesV3SpecJSON, &config.Config{})
+		assert.Nil(t, schemas["createNote"].AdditionalProperties)
+		assert.Nil(t, schemas["createNote"].Properties["author"].AdditionalProperties)
+		require.NotNil(t, schemas["createNote"].Properties["labels"].AdditionalProperties, "schema-valued additionalProperties allows extras")
+		assert.True(t, *schemas["createNote"].Properties["labels"].AdditionalProperties)
+	})
+
+	t.Run("Strict globally", func(t *testing.T) {
+		schemas := inputSchemas(t, "additional_properties_v3.json", additionalPropertiesV3SpecJSON, &config.Config{StrictInputProperties: true})
+		createNote := schemas["createNote"]
+		require.NotNil(t, createNote.AdditionalProperties)
+		assert.False(t, *createNote.AdditionalProperties)
+		require.NotNil(t, createNote.Properties["author"].AdditionalProperties)
+		assert.False(t, *createNote.Properties["author"].AdditionalProperties, "nested objects are strict too")
+		assert.True(t, *createNote.Properties["labels"].AdditionalProperties, "explicitly open objects are honored")
+		assert.True(t, *schemas["trackEvent"].AdditionalProperties, "explicitly open request bodies are honored")
+	})
+
+	t.Run("Strict per tool", func(t *testing.T) {
+		schemas := inputSchemas(t, "additional_properties_v3.json", additionalPropertiesV3SpecJSON, &config.Config{StrictInputOperations: []string{"createNote"}})
+		assert.False(t, *schemas["createNote"].AdditionalProperties)
+		assert.True(t, *schemas["trackEvent"].AdditionalProperties)
+	})
+
+	t.Run("v2", func(t *testing.T) {
+		schemas := inputSchemas(t, "additional_properties_v2.json", additionalPropertiesV2SpecJSON, &config.Config{StrictInputProperties: true})
+		trackEvent := schemas["trackEvent"]
+		assert.True(t, *trackEvent.AdditionalProperties)
+		assert.False(t, *trackEvent.Properties["context"].AdditionalProperties)
+	})
+}
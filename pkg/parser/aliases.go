@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// aliasParameters renames top-level properties of a tool's input schema to their configured
+// aliases, keeping their schema and description, and returns the aliases found keyed by alias
+// with the wire name as value. An alias for a property the schema doesn't have, or one that
+// collides with another property or alias, is an error.
+func aliasParameters(toolName string, schema *mcp.Schema, cfg *config.Config) (map[string]string, error) {
+	configured := cfg.ParameterAliases[toolName]
+	if len(configured) == 0 {
+		return nil, nil
+	}
+	aliases := make([]string, 0, len(configured))
+	for alias := range configured {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	for _, alias := range aliases {
+		wireName := configured[alias]
+		if _, ok := schema.Properties[wireName]; !ok {
+			return nil, fmt.Errorf("parameter alias '%s' of tool '%s': the tool has no parameter '%s'", alias, toolName, wireName)
+		}
+		if _, ok := schema.Properties[alias]; ok && alias != wireName {
+			return nil, fmt.Errorf("parameter alias '%s' of tool '%s' collides with an existing parameter", alias, toolName)
+		}
+	}
+	seen := make(map[string]string, len(aliases))
+	for _, alias := range aliases {
+		wireName := configured[alias]
+		if other, ok := seen[wireName]; ok {
+			return nil, fmt.Errorf("parameter '%s' of tool '%s' has two aliases, '%s' and '%s'", wireName, toolName, other, alias)
+		}
+		seen[wireName] = alias
+	}
+
+	properties := make(map[string]mcp.Schema, len(schema.Properties))
+	for name, property := range schema.Properties {
+		if alias, ok := seen[name]; ok {
+			name = alias
+		}
+		properties[name] = property
+	}
+	schema.Properties = properties
+	required := make([]string, 0, len(schema.Required))
+	for _, name := range schema.Required {
+		if alias, ok := seen[name]; ok {
+			name = alias
+		}
+		required = append(required, name)
+	}
+	if schema.Required != nil {
+		schema.Required = required
+	}
+
+	result := make(map[string]string, len(aliases))
+	for _, alias := range aliases {
+		if configured[alias] != alias {
+			result[alias] = configured[alias]
+		}
+	}
+	return result, nil
+}
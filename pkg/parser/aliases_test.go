@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const aliasesV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Aliases API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {
+    "/orders": {
+      "get": {
+        "operationId": "listOrders",
+        "parameters": [
+          {"name": "$top", "in": "query", "required": true, "description": "Most orders to return", "schema": {"type": "integer"}},
+          {"name": "q", "in": "query", "schema": {"type": "string"}}
+        ],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+
+const aliasesV2SpecJSON = `{
+  "swagger": "2.0",
+  "info": {"title": "Aliases API", "version": "1.0.0"},
+  "host": "api.example.com",
+  "paths": {
+    "/orders": {
+      "get": {
+        "operationId": "listOrders",
+        "parameters": [{"name": "$top", "in": "query", "type": "integer", "description": "Most orders to return"}],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+
+func TestGenerateToolSet_ParameterAliases(t *testing.T) {
+	cfg := &config.Config{ParameterAliases: map[string]map[string]string{"listOrders": {"page_size": "$top", "search": "q"}}}
+
+	t.Run("The schema shows the alias", func(t *testing.T) {
+		doc, version := loadSpecFixture(t, "aliases_v3.json", aliasesV3SpecJSON)
+		toolSet, err := GenerateToolSet(doc, version, cfg)
+		require.NoError(t, err)
+		schema := toolSet.Tools[0].InputSchema
+
+		assert.NotContains(t, schema.Properties, "$top")
+		assert.NotContains(t, schema.Properties, "q")
+		require.Contains(t, schema.Properties, "page_size")
+		assert.Equal(t, "Most orders to return", schema.Properties["page_size"].Description)
+		assert.Equal(t, "integer", schema.Properties["page_size"].Type)
+		assert.Contains(t, schema.Properties, "search")
+		assert.Equal(t, []string{"page_size"}, schema.Required)
+		assert.Equal(t, map[string]string{"page_size": "$top", "search": "q"}, toolSet.Operations["listOrders"].ArgumentAliases)
+	})
+
+	t.Run("Swagger 2 parameters are aliased too", func(t *testing.T) {
+		v2cfg := &config.Config{ParameterAliases: map[string]map[string]string{"listOrders": {"page_size": "$top"}}}
+		schema := inputSchemas(t, "aliases_v2.json", aliasesV2SpecJSON, v2cfg)["listOrders"]
+		assert.Contains(t, schema.Properties, "page_size")
+		assert.NotContains(t, schema.Properties, "$top")
+	})
+
+	t.Run("Bad aliases fail generation", func(t *testing.T) {
+		doc, version := loadSpecFixture(t, "aliases_v3.json", aliasesV3SpecJSON)
+		for name, aliases := range map[string]map[string]string{
+			"collides with a parameter": {"q": "$top"},
+			"unknown parameter":         {"page_size": "$skip"},
+			"two aliases":               {"page_size": "$top", "limit": "$top"},
+		} {
+			_, err := GenerateToolSet(doc, version, &config.Config{ParameterAliases: map[string]map[string]string{"listOrders": aliases}})
+			assert.Error(t, err, name)
+		}
+		_, err := GenerateToolSet(doc, version, &config.Config{ParameterAliases: map[string]map[string]string{"listOrders": {"q": "$top"}}})
+		assert.EqualError(t, err, "parameter alias 'q' of tool 'listOrders' collides with an existing parameter")
+	})
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"log"
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// annotationsExtension is the vendor extension used to override generated tool annotations, e.g.
+//
+//	x-mcp-annotations: {"destructiveHint": false, "idempotentHint": true}
+const annotationsExtension = "x-mcp-annotations"
+
+// generateToolAnnotations derives behavioral hints from the HTTP method, then applies any
+// per-operation overrides from the x-mcp-annotations extension.
+func generateToolAnnotations(method string, extensions map[string]interface{}) *mcp.ToolAnnotations {
+	var readOnly, destructive, idempotent bool
+	switch strings.ToUpper(method) {
+	case "GET", "HEAD", "OPTIONS":
+		readOnly, destructive, idempotent = true, false, true
+	case "PUT", "DELETE":
+		readOnly, destructive, idempotent = false, true, true
+	case "PATCH":
+		readOnly, destructive, idempotent = false, true, false
+	default: // POST and anything else: additive, not safe to repeat
+		readOnly, destructive, idempotent = false, false, false
+	}
+
+	annotations := &mcp.ToolAnnotations{
+		ReadOnlyHint:    &readOnly,
+		DestructiveHint: &destructive,
+		IdempotentHint:  &idempotent,
+	}
+
+	overrides, ok := lookupExtension(extensions, annotationsExtension)
+	if !ok {
+		return annotations
+	}
+	overrideMap, ok := overrides.(map[string]interface{})
+	if !ok {
+		log.Printf("Warning: ignoring %s with unexpected type %T (expected an object)", annotationsExtension, overrides)
+		return annotations
+	}
+	for key, value := range overrideMap {
+		hint, ok := value.(bool)
+		if !ok {
+			log.Printf("Warning: ignoring %s.%s with non-boolean value %v", annotationsExtension, key, value)
+			continue
+		}
+		switch key {
+		case "readOnlyHint":
+			annotations.ReadOnlyHint = &hint
+		case "destructiveHint":
+			annotations.DestructiveHint = &hint
+		case "idempotentHint":
+			annotations.IdempotentHint = &hint
+		case "openWorldHint":
+			annotations.OpenWorldHint = &hint
+		default:
+			log.Printf("Warning: ignoring unknown %s key '%s'", annotationsExtension, key)
+		}
+	}
+	return annotations
+}
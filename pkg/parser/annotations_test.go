@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const annotationsV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Annotations V3 API", "version": "1.0.0"},
+  "paths": {
+    "/items": {
+      "get": {"operationId": "listItems", "responses": {"200": {"description": "OK"}}},
+      "post": {"operationId": "createItem", "responses": {"201": {"description": "Created"}}}
+    },
+    "/items/{id}": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+      "delete": {"operationId": "deleteItem", "responses": {"204": {"description": "Deleted"}}},
+      "put": {
+        "operationId": "upsertItem",
+        "x-mcp-annotations": {"destructiveHint": false, "openWorldHint": true},
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+
+const annotationsV2SpecJSON = `{
+  "swagger": "2.0",
+  "info": {"title": "Annotations V2 API", "version": "1.0.0"},
+  "paths": {
+    "/items": {
+      "get": {"operationId": "listItems", "responses": {"200": {"description": "OK"}}}
+    },
+    "/items/{id}": {
+      "delete": {
+        "operationId": "deleteItem",
+        "parameters": [{"name": "id", "in": "path", "required": true, "type": "string"}],
+        "responses": {"204": {"description": "Deleted"}}
+      },
+      "patch": {
+        "operationId": "archiveItem",
+        "parameters": [{"name": "id", "in": "path", "required": true, "type": "string"}],
+        "x-mcp-annotations": {"destructiveHint": false, "idempotentHint": true},
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+
+// annotationsByTool indexes a ToolSet's annotations by tool name.
+func annotationsByTool(t *testing.T, toolSet *mcp.ToolSet) map[string]*mcp.ToolAnnotations {
+	t.Helper()
+	result := make(map[string]*mcp.ToolAnnotations, len(toolSet.Tools))
+	for _, tool := range toolSet.Tools {
+		require.NotNil(t, tool.Annotations, "tool %s has no annotations", tool.Name)
+		result[tool.Name] = tool.Annotations
+	}
+	return result
+}
+
+func assertHints(t *testing.T, annotations *mcp.ToolAnnotations, readOnly, destructive, idempotent bool) {
+	t.Helper()
+	require.NotNil(t, annotations)
+	assert.Equal(t, readOnly, *annotations.ReadOnlyHint, "readOnlyHint")
+	assert.Equal(t, destructive, *annotations.DestructiveHint, "destructiveHint")
+	assert.Equal(t, idempotent, *annotations.IdempotentHint, "idempotentHint")
+}
+
+func TestGenerateToolSet_AnnotationsV3(t *testing.T) {
+	doc, version := loadSpecFixture(t, "annotations_v3.json", annotationsV3SpecJSON)
+	toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+	require.NoError(t, err)
+	annotations := annotationsByTool(t, toolSet)
+
+	assertHints(t, annotations["listItems"], true, false, true)
+	assertHints(t, annotations["createItem"], false, false, false)
+	assertHints(t, annotations["deleteItem"], false, true, true)
+
+	// Overridden via x-mcp-annotations; unspecified hints keep their method defaults
+	assertHints(t, annotations["upsertItem"], false, false, true)
+	require.NotNil(t, annotations["upsertItem"].OpenWorldHint)
+	assert.True(t, *annotations["upsertItem"].OpenWorldHint)
+	assert.Nil(t, annotations["listItems"].OpenWorldHint)
+}
+
+func TestGenerateToolSet_AnnotationsV2(t *testing.T) {
+	doc, version := loadSpecFixture(t, "annotations_v2.json", annotationsV2SpecJSON)
+	toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+	require.NoError(t, err)
+	annotations := annotationsByTool(t, toolSet)
+
+	assertHints(t, annotations["listItems"], true, false, true)
+	assertHints(t, annotations["deleteItem"], false, true, true)
+	assertHints(t, annotations["archiveItem"], false, false, true)
+}
+
+func TestGenerateToolAnnotations_InvalidOverrides(t *testing.T) {
+	annotations := generateToolAnnotations("DELETE", map[string]interface{}{
+		"x-mcp-annotations": map[string]interface{}{"destructiveHint": "no", "bogusHint": true},
+	})
+	assertHints(t, annotations, false, true, true)
+
+	annotations = generateToolAnnotations("GET", map[string]interface{}{"x-mcp-annotations": true})
+	assertHints(t, annotations, true, false, true)
+}
@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-openapi/spec"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// isBinaryMediaType reports whether a request body of this media type is raw bytes rather than a
+// structured document.
+func isBinaryMediaType(mediaType string) bool {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	switch mediaType {
+	case "application/octet-stream", "application/pdf", "application/zip", "application/gzip":
+		return true
+	}
+	for _, prefix := range []string{"image/", "audio/", "video/"} {
+		if strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// rawBodyMediaTypeV3 returns the media type of an operation's binary request body, or "" when the
+// body is absent or can be sent as JSON. Among several binary media types the first in sorted
+// order is used.
+func rawBodyMediaTypeV3(rbRef *openapi3.RequestBodyRef) string {
+	if rbRef == nil || rbRef.Value == nil {
+		return ""
+	}
+	if _, ok := rbRef.Value.Content["application/json"]; ok {
+		return ""
+	}
+	mediaTypes := make([]string, 0, len(rbRef.Value.Content))
+	for mediaType := range rbRef.Value.Content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+	for _, mediaType := range mediaTypes {
+		if isBinaryMediaType(mediaType) {
+			return mediaType
+		}
+	}
+	return ""
+}
+
+// rawBodyMediaTypeV2 returns the media type of a binary body parameter: one whose schema is a
+// binary string, sent with the first binary media type the operation consumes (falling back to
+// application/octet-stream). It returns "" for JSON bodies.
+func rawBodyMediaTypeV2(op *spec.Operation, doc *spec.Swagger, bodySchema mcp.Schema) string {
+	consumes := op.Consumes
+	if len(consumes) == 0 {
+		consumes = doc.Consumes
+	}
+	binaryConsumed := ""
+	for _, mediaType := range consumes {
+		if strings.Contains(strings.ToLower(mediaType), "json") {
+			return ""
+		}
+		if binaryConsumed == "" && isBinaryMediaType(mediaType) {
+			binaryConsumed = mediaType
+		}
+	}
+	if bodySchema.Type != "string" || bodySchema.Format != "binary" {
+		return ""
+	}
+	if binaryConsumed == "" {
+		return "application/octet-stream"
+	}
+	return binaryConsumed
+}
+
+// addRawBodyProperty adds the base64 requestBody argument carrying a binary request body.
+func addRawBodyProperty(schema *mcp.Schema, mediaType string, required bool) {
+	if schema.Properties == nil {
+		schema.Properties = make(map[string]mcp.Schema)
+	}
+	schema.Properties[mcp.RawBodyArgument] = mcp.Schema{
+		Type:        "string",
+		Format:      "byte",
+		Description: fmt.Sprintf("Base64-encoded raw request body, sent as %s", mediaType),
+	}
+	if required && !sliceContains(schema.Required, mcp.RawBodyArgument) {
+		schema.Required = append(schema.Required, mcp.RawBodyArgument)
+		sort.Strings(schema.Required)
+	}
+}
+
+// bodyParameterV2 returns the operation's body parameter, or nil if it has none.
+func bodyParameterV2(params []spec.Parameter) *spec.Parameter {
+	for i := range params {
+		if params[i].In == "body" {
+			return &params[i]
+		}
+	}
+	return nil
+}
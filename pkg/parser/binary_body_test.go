@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const binaryBodyV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Upload API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {
+    "/files/{name}": {
+      "put": {
+        "operationId": "uploadFile",
+        "parameters": [{"name": "name", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "requestBody": {
+          "required": true,
+          "content": {"application/octet-stream": {"schema": {"type": "string", "format": "binary"}}}
+        },
+        "responses": {"201": {"description": "Created"}}
+      }
+    },
+    "/avatars": {
+      "post": {
+        "operationId": "uploadAvatar",
+        "requestBody": {"content": {"image/png": {}, "image/jpeg": {}}},
+        "responses": {"201": {"description": "Created"}}
+      }
+    },
+    "/notes": {
+      "post": {
+        "operationId": "createNote",
+        "requestBody": {"content": {"application/json": {"schema": {
+          "type": "object", "properties": {"text": {"type": "string"}}
+        }}}},
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  }
+}`
+
+const binaryBodyV2SpecJSON = `{
+  "swagger": "2.0",
+  "info": {"title": "Upload API", "version": "1.0.0"},
+  "host": "api.example.com",
+  "paths": {
+    "/files": {
+      "post": {
+        "operationId": "uploadFile",
+        "consumes": ["application/octet-stream"],
+        "parameters": [{"name": "body", "in": "body", "required": true, "schema": {"type": "string", "format": "binary"}}],
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  }
+}`
+
+func TestGenerateToolSet_BinaryRequestBody(t *testing.T) {
+	doc, version := loadSpecFixture(t, "binary_body_v3.json", binaryBodyV3SpecJSON)
+	toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+	require.NoError(t, err)
+	schemas := inputSchemas(t, "binary_body_v3.json", binaryBodyV3SpecJSON, &config.Config{})
+
+	upload := schemas["uploadFile"]
+	require.Contains(t, upload.Properties, mcp.RawBodyArgument)
+	assert.Equal(t, "string", upload.Properties[mcp.RawBodyArgument].Type)
+	assert.Equal(t, "byte", upload.Properties[mcp.RawBodyArgument].Format)
+	assert.Equal(t, []string{"name", mcp.RawBodyArgument}, upload.Required)
+	assert.Equal(t, "application/octet-stream", toolSet.Operations["uploadFile"].RawBodyMediaType)
+
+	assert.Equal(t, "image/jpeg", toolSet.Operations["uploadAvatar"].RawBodyMediaType, "the first binary media type in sorted order")
+	assert.NotContains(t, schemas["uploadAvatar"].Required, mcp.RawBodyArgument, "an optional body stays optional")
+
+	assert.Empty(t, toolSet.Operations["createNote"].RawBodyMediaType, "JSON bodies are unchanged")
+	assert.Contains(t, schemas["createNote"].Properties, "text")
+}
+
+func TestGenerateToolSet_BinaryRequestBodyV2(t *testing.T) {
+	doc, version := loadSpecFixture(t, "binary_body_v2.json", binaryBodyV2SpecJSON)
+	toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "application/octet-stream", toolSet.Operations["uploadFile"].RawBodyMediaType)
+	schema := inputSchemas(t, "binary_body_v2.json", binaryBodyV2SpecJSON, &config.Config{})["uploadFile"]
+	assert.Equal(t, []string{mcp.RawBodyArgument}, schema.Required)
+	assert.Equal(t, "byte", schema.Properties[mcp.RawBodyArgument].Format)
+}
+
+func TestIsBinaryMediaType(t *testing.T) {
+	for _, mediaType := range []string{"application/octet-stream", "image/png", "video/mp4", "application/pdf", "Application/Octet-Stream; charset=binary"} {
+		assert.True(t, isBinaryMediaType(mediaType), mediaType)
+	}
+	for _, mediaType := range []string{"application/json", "text/plain", "application/x-www-form-urlencoded", "multipart/form-data"} {
+		assert.False(t, isBinaryMediaType(mediaType), mediaType)
+	}
+}
@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-openapi/spec"
+)
+
+// requestBodyMediaTypesV3 returns the media types of an operation's request body, sorted.
+func requestBodyMediaTypesV3(rbRef *openapi3.RequestBodyRef) []string {
+	if rbRef == nil || rbRef.Value == nil || len(rbRef.Value.Content) == 0 {
+		return nil
+	}
+	mediaTypes := make([]string, 0, len(rbRef.Value.Content))
+	for mediaType := range rbRef.Value.Content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+	return mediaTypes
+}
+
+// requestBodyMediaTypesV2 returns the media types an operation with a body or form parameter
+// consumes (falling back to the document's), sorted. params are the operation's resolved
+// parameters.
+func requestBodyMediaTypesV2(op *spec.Operation, doc *spec.Swagger, params []spec.Parameter) []string {
+	hasBody := false
+	for _, param := range params {
+		if param.In == "body" || param.In == "formData" {
+			hasBody = true
+			break
+		}
+	}
+	consumes := op.Consumes
+	if len(consumes) == 0 {
+		consumes = doc.Consumes
+	}
+	if !hasBody || len(consumes) == 0 {
+		return nil
+	}
+	mediaTypes := append([]string(nil), consumes...)
+	sort.Strings(mediaTypes)
+	return mediaTypes
+}
@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bodyMediaTypesV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Accounts API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {
+    "/users": {
+      "post": {
+        "operationId": "createUser",
+        "requestBody": {"content": {
+          "application/x-www-form-urlencoded": {"schema": {"type": "object", "properties": {"name": {"type": "string"}}}},
+          "application/json": {"schema": {"type": "object", "properties": {"name": {"type": "string"}}}}
+        }},
+        "responses": {"201": {"description": "Created"}}
+      },
+      "get": {
+        "operationId": "listUsers",
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+
+const bodyMediaTypesV2SpecJSON = `{
+  "swagger": "2.0",
+  "info": {"title": "Accounts API", "version": "1.0.0"},
+  "host": "api.example.com",
+  "consumes": ["application/json"],
+  "paths": {
+    "/login": {
+      "post": {
+        "operationId": "login",
+        "consumes": ["application/x-www-form-urlencoded"],
+        "parameters": [{"name": "user", "in": "formData", "type": "string"}],
+        "responses": {"200": {"description": "OK"}}
+      }
+    },
+    "/notes": {
+      "post": {
+        "operationId": "createNote",
+        "parameters": [{"name": "body", "in": "body", "schema": {"type": "object"}}],
+        "responses": {"201": {"description": "Created"}}
+      },
+      "get": {
+        "operationId": "listNotes",
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+
+func TestGenerateToolSet_BodyMediaTypes(t *testing.T) {
+	doc, version := loadSpecFixture(t, "body_media_types_v3.json", bodyMediaTypesV3SpecJSON)
+	toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"application/json", "application/x-www-form-urlencoded"}, toolSet.Operations["createUser"].BodyMediaTypes)
+	assert.Nil(t, toolSet.Operations["listUsers"].BodyMediaTypes)
+
+	doc, version = loadSpecFixture(t, "body_media_types_v2.json", bodyMediaTypesV2SpecJSON)
+	toolSet, err = GenerateToolSet(doc, version, &config.Config{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"application/x-www-form-urlencoded"}, toolSet.Operations["login"].BodyMediaTypes)
+	assert.Equal(t, []string{"application/json"}, toolSet.Operations["createNote"].BodyMediaTypes, "falls back to the document's consumes")
+	assert.Nil(t, toolSet.Operations["listNotes"].BodyMediaTypes, "operations without a body declare none")
+}
@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+)
+
+// descriptionEllipsis marks a truncated tool description.
+const descriptionEllipsis = "…"
+
+// capToolDescription shortens a finished tool description to cfg.MaxToolDescriptionLength
+// characters (0 means no cap), see truncateDescription.
+func capToolDescription(description string, cfg *config.Config) string {
+	if cfg == nil {
+		return description
+	}
+	return truncateDescription(description, cfg.MaxToolDescriptionLength)
+}
+
+// truncateDescription shortens description to at most limit characters including the trailing
+// ellipsis. It cuts after the last whole sentence that fits, so the leading sentences (the
+// summary) survive, and falls back to the last word boundary, or a hard cut for a single long
+// word. Descriptions within the limit, and any limit of zero or less, leave it unchanged.
+func truncateDescription(description string, limit int) string {
+	runes := []rune(description)
+	if limit <= 0 || len(runes) <= limit {
+		return description
+	}
+	budget := limit - len([]rune(descriptionEllipsis))
+	if budget <= 0 {
+		return string(runes[:limit])
+	}
+
+	sentenceEnd, wordEnd := 0, 0
+	for i := 1; i <= budget; i++ {
+		if i < len(runes) && !unicode.IsSpace(runes[i]) {
+			continue
+		}
+		// runes[:i] ends at a word boundary
+		wordEnd = i
+		if strings.ContainsRune(".!?", runes[i-1]) || runes[i] == '\n' {
+			sentenceEnd = i
+		}
+	}
+
+	cut := budget
+	switch {
+	case sentenceEnd > 0:
+		cut = sentenceEnd
+	case wordEnd > 0:
+		cut = wordEnd
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace) + descriptionEllipsis
+}
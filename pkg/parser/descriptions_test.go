@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncateDescription(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		limit       int
+		expected    string
+	}{
+		{name: "Short description untouched", description: "List users.", limit: 50, expected: "List users."},
+		{name: "No cap", description: strings.Repeat("word ", 100), limit: 0, expected: strings.Repeat("word ", 100)},
+		{name: "Cut after the last whole sentence", description: "List users. Supports filters. Results are paged by cursor.", limit: 35, expected: "List users. Supports filters.…"},
+		{name: "First sentence too long cuts at a word", description: "List all users of the organization with their roles", limit: 20, expected: "List all users of…"},
+		{name: "Single long word is cut hard", description: "Supercalifragilistic", limit: 8, expected: "Superca…"},
+		{name: "Multibyte characters count once", description: "Liste der Benutzer. Größe ändern.", limit: 25, expected: "Liste der Benutzer.…"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			truncated := truncateDescription(tc.description, tc.limit)
+			assert.Equal(t, tc.expected, truncated)
+			if tc.limit > 0 {
+				assert.LessOrEqual(t, utf8.RuneCountInString(truncated), tc.limit)
+			}
+		})
+	}
+}
+
+const longDescriptionV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Reports API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {
+    "/reports": {
+      "get": {
+        "operationId": "listReports",
+        "description": "Lists reports. Each report carries its owner, schedule and the full history of every run, which makes this response large. Filter by owner to keep it small.",
+        "responses": {"200": {"description": "OK"}}
+      },
+      "post": {
+        "operationId": "createReport",
+        "summary": "Create a report.",
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  }
+}`
+
+func TestGenerateToolSet_MaxToolDescriptionLength(t *testing.T) {
+	doc, version := loadSpecFixture(t, "long_description_v3.json", longDescriptionV3SpecJSON)
+	toolSet, err := GenerateToolSet(doc, version, &config.Config{MaxToolDescriptionLength: 100})
+	require.NoError(t, err)
+
+	descriptions := make(map[string]string)
+	for _, tool := range toolSet.Tools {
+		descriptions[tool.Name] = tool.Description
+	}
+	assert.Equal(t, "Note: The API key is supplied by the server, no need to provide it. Lists reports.…", descriptions["listReports"])
+	assert.Equal(t, "Note: The API key is supplied by the server, no need to provide it. Create a report.", descriptions["createReport"], "short descriptions are untouched")
+}
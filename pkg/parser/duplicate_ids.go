@@ -0,0 +1,144 @@
+package parser
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-openapi/spec"
+)
+
+// Policies for operationIds used by more than one operation, see config.DuplicateOperationIDs.
+const (
+	DuplicateOperationIDsError = "error" // Fail to load the spec
+	DuplicateOperationIDsWarn  = "warn"  // Keep every operation; later ones get a _2, _3, ... tool name
+	DuplicateOperationIDsFirst = "first" // Keep the first operation in path and method order
+	DuplicateOperationIDsLast  = "last"  // Keep the last operation in path and method order
+)
+
+// operationRef identifies an operation by method and path template.
+type operationRef struct {
+	method string
+	path   string
+}
+
+func (r operationRef) String() string {
+	return strings.ToUpper(r.method) + " " + r.path
+}
+
+// operationIDUsesV3 lists the operations using each explicit operationId, in path then method order.
+func operationIDUsesV3(doc *openapi3.T) map[string][]operationRef {
+	uses := make(map[string][]operationRef)
+	for _, path := range getSortedPathsV3(doc.Paths) {
+		operations := doc.Paths.Value(path).Operations()
+		methods := make([]string, 0, len(operations))
+		for method := range operations {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			if op := operations[method]; op != nil && op.OperationID != "" {
+				uses[op.OperationID] = append(uses[op.OperationID], operationRef{method: method, path: path})
+			}
+		}
+	}
+	return uses
+}
+
+// suspendDuplicateOperationIDsV3 clears the operationId of every operation repeating an earlier
+// one, so that validation, which rejects duplicates outright, checks the rest of the spec. The
+// returned function puts the IDs back.
+func suspendDuplicateOperationIDsV3(doc *openapi3.T) (restore func()) {
+	if doc.Paths == nil {
+		return func() {}
+	}
+	var cleared []*openapi3.Operation
+	var ids []string
+	for id, refs := range operationIDUsesV3(doc) {
+		for _, ref := range refs[1:] {
+			op := doc.Paths.Value(ref.path).GetOperation(ref.method)
+			op.OperationID = ""
+			cleared = append(cleared, op)
+			ids = append(ids, id)
+		}
+	}
+	return func() {
+		for i, op := range cleared {
+			op.OperationID = ids[i]
+		}
+	}
+}
+
+// operationIDUsesV2 lists the operations using each explicit operationId, in path then method order.
+func operationIDUsesV2(doc *spec.Swagger) map[string][]operationRef {
+	uses := make(map[string][]operationRef)
+	if doc.Paths == nil {
+		return uses
+	}
+	for _, path := range getSortedPathsV2(doc.Paths) {
+		pathItem := doc.Paths.Paths[path]
+		operations := []struct {
+			method string
+			op     *spec.Operation
+		}{
+			{"DELETE", pathItem.Delete}, {"GET", pathItem.Get}, {"HEAD", pathItem.Head}, {"OPTIONS", pathItem.Options},
+			{"PATCH", pathItem.Patch}, {"POST", pathItem.Post}, {"PUT", pathItem.Put},
+		}
+		for _, operation := range operations {
+			if operation.op != nil && operation.op.ID != "" {
+				uses[operation.op.ID] = append(uses[operation.op.ID], operationRef{method: operation.method, path: path})
+			}
+		}
+	}
+	return uses
+}
+
+// duplicateOperationSkips applies the duplicate operationId policy to uses, logging every
+// duplicate, and returns the operations to leave out. The policy applies to the spec as written,
+// before operation filters. An empty policy means DuplicateOperationIDsWarn.
+func duplicateOperationSkips(uses map[string][]operationRef, policy string) (map[operationRef]bool, error) {
+	ids := make([]string, 0, len(uses))
+	for id, refs := range uses {
+		if len(refs) > 1 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	skipped := make(map[operationRef]bool)
+	var duplicates []string
+	for _, id := range ids {
+		refs := uses[id]
+		names := make([]string, len(refs))
+		for i, ref := range refs {
+			names[i] = ref.String()
+		}
+		used := strings.Join(names, ", ")
+
+		switch policy {
+		case DuplicateOperationIDsError:
+			duplicates = append(duplicates, fmt.Sprintf("'%s' (%s)", id, used))
+		case "", DuplicateOperationIDsWarn:
+			log.Printf("Warning: operationId '%s' is used by %s; keeping all of them under disambiguated tool names", id, used)
+		case DuplicateOperationIDsFirst, DuplicateOperationIDsLast:
+			keep := 0
+			if policy == DuplicateOperationIDsLast {
+				keep = len(refs) - 1
+			}
+			log.Printf("Warning: operationId '%s' is used by %s; keeping only %s (duplicate policy '%s')", id, used, names[keep], policy)
+			for i, ref := range refs {
+				if i != keep {
+					skipped[ref] = true
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unknown duplicate operationId policy %q (expected error, warn, first or last)", policy)
+		}
+	}
+	if len(duplicates) > 0 {
+		return nil, fmt.Errorf("duplicate operationIds in spec: %s", strings.Join(duplicates, "; "))
+	}
+	return skipped, nil
+}
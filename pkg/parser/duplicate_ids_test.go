@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const duplicateIDsV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Duplicate IDs API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {
+    "/users": {
+      "get": {"operationId": "listUsers", "responses": {"200": {"description": "OK"}}}
+    },
+    "/v1/users": {
+      "get": {"operationId": "getUsers", "responses": {"200": {"description": "OK"}}}
+    },
+    "/v2/users": {
+      "get": {"operationId": "getUsers", "responses": {"200": {"description": "OK"}}}
+    }
+  }
+}`
+
+const duplicateIDsV2SpecJSON = `{
+  "swagger": "2.0",
+  "info": {"title": "Duplicate IDs API", "version": "1.0.0"},
+  "host": "api.example.com",
+  "paths": {
+    "/users": {
+      "get": {"operationId": "users", "responses": {"200": {"description": "OK"}}},
+      "post": {"operationId": "users", "responses": {"201": {"description": "Created"}}}
+    }
+  }
+}`
+
+func TestGenerateToolSet_DuplicateOperationIDs(t *testing.T) {
+	generate := func(t *testing.T, fileName, content, policy string) (map[string]string, string, error) {
+		doc, version := loadSpecFixture(t, fileName, content)
+		var paths map[string]string
+		var err error
+		logged := captureLog(t, func() {
+			toolSet, genErr := GenerateToolSet(doc, version, &config.Config{DuplicateOperationIDs: policy})
+			err = genErr
+			if toolSet != nil {
+				paths = make(map[string]string)
+				for name, operation := range toolSet.Operations {
+					paths[name] = operation.Method + " " + operation.Path
+				}
+			}
+		})
+		return paths, logged, err
+	}
+
+	t.Run("warn keeps both under disambiguated names", func(t *testing.T) {
+		for _, policy := range []string{"", DuplicateOperationIDsWarn} {
+			paths, logged, err := generate(t, "duplicate_ids_v3.json", duplicateIDsV3SpecJSON, policy)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{
+				"listUsers":  "GET /users",
+				"getUsers":   "GET /v1/users",
+				"getUsers_2": "GET /v2/users",
+			}, paths)
+			assert.Contains(t, logged, "operationId 'getUsers' is used by GET /v1/users, GET /v2/users; keeping all of them")
+		}
+	})
+
+	t.Run("error fails naming the operations", func(t *testing.T) {
+		_, _, err := generate(t, "duplicate_ids_v3.json", duplicateIDsV3SpecJSON, DuplicateOperationIDsError)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "'getUsers' (GET /v1/users, GET /v2/users)")
+	})
+
+	t.Run("first wins", func(t *testing.T) {
+		paths, logged, err := generate(t, "duplicate_ids_v3.json", duplicateIDsV3SpecJSON, DuplicateOperationIDsFirst)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"listUsers": "GET /users", "getUsers": "GET /v1/users"}, paths)
+		assert.Contains(t, logged, "keeping only GET /v1/users (duplicate policy 'first')")
+	})
+
+	t.Run("last wins", func(t *testing.T) {
+		paths, logged, err := generate(t, "duplicate_ids_v3.json", duplicateIDsV3SpecJSON, DuplicateOperationIDsLast)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"listUsers": "GET /users", "getUsers": "GET /v2/users"}, paths)
+		assert.Contains(t, logged, "keeping only GET /v2/users (duplicate policy 'last')")
+	})
+
+	t.Run("v2 duplicates within one path are ordered by method", func(t *testing.T) {
+		paths, _, err := generate(t, "duplicate_ids_v2.json", duplicateIDsV2SpecJSON, DuplicateOperationIDsLast)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"users": "POST /users"}, paths)
+
+		_, _, err = generate(t, "duplicate_ids_v2.json", duplicateIDsV2SpecJSON, DuplicateOperationIDsError)
+		assert.ErrorContains(t, err, "'users' (GET /users, POST /users)")
+	})
+
+	t.Run("unknown policy", func(t *testing.T) {
+		_, _, err := generate(t, "duplicate_ids_v3.json", duplicateIDsV3SpecJSON, "newest")
+		assert.ErrorContains(t, err, "unknown duplicate operationId policy")
+	})
+}
@@ -0,0 +1,261 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// URI prefixes of the resources describing the events an API sends.
+const (
+	webhookResourcePrefix  = "openapi://webhooks/"
+	callbackResourcePrefix = "openapi://callbacks/"
+)
+
+// webhooksField is the OpenAPI 3.1 top-level field declaring webhooks. The loader does not model
+// it, so it is read from the document's extra fields.
+const webhooksField = "webhooks"
+
+// eventRequest is one request of an event: the method, the URL it is sent to (a runtime
+// expression, for callbacks) and its payload.
+type eventRequest struct {
+	Method        string      `json:"method"`
+	URL           string      `json:"url,omitempty"`
+	Summary       string      `json:"summary,omitempty"`
+	Description   string      `json:"description,omitempty"`
+	MediaType     string      `json:"mediaType,omitempty"`
+	PayloadSchema *mcp.Schema `json:"payloadSchema,omitempty"`
+}
+
+// eventDocument is the content of an event resource.
+type eventDocument struct {
+	Kind     string         `json:"kind"` // "webhook" or "callback"
+	Name     string         `json:"name"`
+	Trigger  string         `json:"trigger"`
+	Note     string         `json:"note"`
+	Requests []eventRequest `json:"requests"`
+}
+
+// eventNote tells the model the resource describes inbound traffic only.
+const eventNote = "This describes a request the API sends to its clients. It is informational and cannot be called as a tool."
+
+// callbackResourcesV3 describes the callbacks of the operation behind toolName, one resource per
+// callback, sorted by name.
+func callbackResourcesV3(toolName, method, path string, op *openapi3.Operation) []mcp.Resource {
+	names := make([]string, 0, len(op.Callbacks))
+	for name, callbackRef := range op.Callbacks {
+		if callbackRef != nil && callbackRef.Value != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var resources []mcp.Resource
+	for _, name := range names {
+		var requests []eventRequest
+		callback := op.Callbacks[name].Value
+		expressions := make([]string, 0, callback.Len())
+		for expression := range callback.Map() {
+			expressions = append(expressions, expression)
+		}
+		sort.Strings(expressions)
+		for _, expression := range expressions {
+			requests = append(requests, eventRequestsV3(callback.Value(expression), expression)...)
+		}
+		resource, err := eventResource(eventDocument{
+			Kind:     "callback",
+			Name:     name,
+			Trigger:  fmt.Sprintf("Sent by the API after a call to tool '%s' (%s %s), to the URL given by the expression in each request.", toolName, method, path),
+			Requests: requests,
+		}, callbackResourcePrefix+toolName+"/"+name, fmt.Sprintf("callback %s of %s", name, toolName))
+		if err != nil {
+			log.Printf("Warning: skipping callback '%s' of %s %s: %v", name, method, path, err)
+			continue
+		}
+		resources = append(resources, resource)
+	}
+	return resources
+}
+
+// webhookResourcesV3 describes the webhooks of an OpenAPI 3.1 document, sorted by name. Schema
+// references into components are resolved; path item references are not supported.
+func webhookResourcesV3(doc *openapi3.T) []mcp.Resource {
+	raw, ok := doc.Extensions[webhooksField]
+	if !ok {
+		return nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		log.Printf("Warning: ignoring webhooks: %v", err)
+		return nil
+	}
+	var webhooks map[string]*openapi3.PathItem
+	if err := json.Unmarshal(data, &webhooks); err != nil {
+		log.Printf("Warning: ignoring webhooks that are not path items: %v", err)
+		return nil
+	}
+
+	names := make([]string, 0, len(webhooks))
+	for name := range webhooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var resources []mcp.Resource
+	for _, name := range names {
+		pathItem := webhooks[name]
+		if pathItem == nil || pathItem.Ref != "" {
+			log.Printf("Warning: skipping webhook '%s': path item references are not supported", name)
+			continue
+		}
+		resolveComponentRefsV3(pathItem, doc.Components)
+		resource, err := eventResource(eventDocument{
+			Kind:     "webhook",
+			Name:     name,
+			Trigger:  fmt.Sprintf("Sent by the API to the webhook URL registered for '%s' when the event occurs.", name),
+			Requests: eventRequestsV3(pathItem, ""),
+		}, webhookResourcePrefix+name, "webhook "+name)
+		if err != nil {
+			log.Printf("Warning: skipping webhook '%s': %v", name, err)
+			continue
+		}
+		resources = append(resources, resource)
+	}
+	return resources
+}
+
+// eventRequestsV3 describes the operations of an event's path item, in method order.
+func eventRequestsV3(pathItem *openapi3.PathItem, url string) []eventRequest {
+	if pathItem == nil {
+		return nil
+	}
+	operations := pathItem.Operations()
+	methods := make([]string, 0, len(operations))
+	for method := range operations {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	var requests []eventRequest
+	for _, method := range methods {
+		op := operations[method]
+		request := eventRequest{Method: method, URL: url, Summary: op.Summary, Description: op.Description}
+		if op.RequestBody != nil && op.RequestBody.Value != nil {
+			mediaType, content := eventPayloadV3(op.RequestBody.Value.Content)
+			if content != nil && content.Schema != nil {
+				schema, err := openapiSchemaToMCPSchemaV3(content.Schema)
+				if err != nil {
+					log.Printf("Warning: omitting the payload schema of a %s event request: %v", method, err)
+				} else {
+					request.PayloadSchema = &schema
+				}
+			}
+			request.MediaType = mediaType
+		}
+		requests = append(requests, request)
+	}
+	return requests
+}
+
+// eventPayloadV3 picks the payload media type: JSON if declared, else the first by name.
+func eventPayloadV3(content openapi3.Content) (string, *openapi3.MediaType) {
+	if mediaType, ok := content["application/json"]; ok {
+		return "application/json", mediaType
+	}
+	keys := make([]string, 0, len(content))
+	for key := range content {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if len(keys) == 0 {
+		return "", nil
+	}
+	return keys[0], content[keys[0]]
+}
+
+// eventResource renders an event document as a JSON resource.
+func eventResource(document eventDocument, uri, name string) (mcp.Resource, error) {
+	if len(document.Requests) == 0 {
+		return mcp.Resource{}, fmt.Errorf("no operations")
+	}
+	document.Note = eventNote
+	text, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return mcp.Resource{}, err
+	}
+	description := document.Requests[0].Summary
+	if description == "" {
+		description = document.Requests[0].Description
+	}
+	if description == "" {
+		description = document.Trigger
+	}
+	return mcp.Resource{
+		URI:         uri,
+		Name:        name,
+		Description: fmt.Sprintf("Event sent by the API (%s, not callable): %s", document.Kind, description),
+		MimeType:    "application/json",
+		Text:        string(text),
+	}, nil
+}
+
+// resolveComponentRefsV3 fills in the request body and schema references of a path item that was
+// decoded outside the loader, from the document's components.
+func resolveComponentRefsV3(pathItem *openapi3.PathItem, components *openapi3.Components) {
+	if components == nil {
+		return
+	}
+	for _, op := range pathItem.Operations() {
+		body := op.RequestBody
+		if body == nil {
+			continue
+		}
+		if body.Value == nil {
+			if resolved, ok := components.RequestBodies[strings.TrimPrefix(body.Ref, "#/components/requestBodies/")]; ok && resolved != nil {
+				body.Value = resolved.Value
+			}
+		}
+		if body.Value == nil {
+			continue
+		}
+		for _, mediaType := range body.Value.Content {
+			if mediaType != nil {
+				resolveSchemaRefsV3(mediaType.Schema, components, 0)
+			}
+		}
+	}
+}
+
+// maxEventSchemaDepth bounds reference resolution in event payload schemas.
+const maxEventSchemaDepth = 32
+
+// resolveSchemaRefsV3 fills in unresolved component schema references in ref and its
+// subschemas. Component schemas themselves were resolved by the loader.
+func resolveSchemaRefsV3(ref *openapi3.SchemaRef, components *openapi3.Components, depth int) {
+	if ref == nil || depth > maxEventSchemaDepth {
+		return
+	}
+	if ref.Value == nil {
+		if resolved, ok := components.Schemas[strings.TrimPrefix(ref.Ref, "#/components/schemas/")]; ok && resolved != nil {
+			ref.Value = resolved.Value
+		}
+		return
+	}
+	schema := ref.Value
+	for _, property := range schema.Properties {
+		resolveSchemaRefsV3(property, components, depth+1)
+	}
+	resolveSchemaRefsV3(schema.Items, components, depth+1)
+	resolveSchemaRefsV3(schema.AdditionalProperties.Schema, components, depth+1)
+	resolveSchemaRefsV3(schema.Not, components, depth+1)
+	for _, group := range []openapi3.SchemaRefs{schema.AllOf, schema.AnyOf, schema.OneOf} {
+		for _, member := range group {
+			resolveSchemaRefsV3(member, components, depth+1)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const eventsV31SpecJSON = `{
+  "openapi": "3.1.0",
+  "info": {"title": "Shop API", "version": "1.0.0"},
+  "paths": {
+    "/orders": {
+      "post": {
+        "operationId": "createOrder",
+        "requestBody": {"content": {"application/json": {"schema": {"type": "object", "properties": {"callbackUrl": {"type": "string"}}}}}},
+        "callbacks": {
+          "orderShipped": {
+            "{$request.body#/callbackUrl}": {
+              "post": {
+                "summary": "The order left the warehouse",
+                "requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Shipment"}}}},
+                "responses": {"200": {"description": "OK"}}
+              }
+            }
+          }
+        },
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  },
+  "webhooks": {
+    "orderPaid": {
+      "post": {
+        "summary": "An order was paid",
+        "requestBody": {
+          "content": {
+            "application/json": {
+              "schema": {
+                "type": "object",
+                "required": ["orderId"],
+                "properties": {"orderId": {"type": "string"}, "amount": {"type": "number"}, "shipment": {"$ref": "#/components/schemas/Shipment"}}
+              }
+            }
+          }
+        },
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Shipment": {"type": "object", "properties": {"trackingNumber": {"type": "string"}}}
+    }
+  }
+}`
+
+// resourceDocument decodes the JSON content of an event resource.
+func resourceDocument(t *testing.T, resource mcp.Resource) map[string]interface{} {
+	t.Helper()
+	var document map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(resource.Text), &document))
+	return document
+}
+
+func TestGenerateToolSet_WebhooksAndCallbacksAsResources(t *testing.T) {
+	doc, version := loadSpecFixture(t, "events_v31.json", eventsV31SpecJSON)
+
+	toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+	require.NoError(t, err)
+
+	// Only the regular operation is a tool
+	require.Len(t, toolSet.Tools, 1)
+	assert.Equal(t, "createOrder", toolSet.Tools[0].Name)
+	assert.Len(t, toolSet.Operations, 1)
+
+	require.Len(t, toolSet.Resources, 2)
+	callback, webhook := toolSet.Resources[0], toolSet.Resources[1]
+
+	assert.Equal(t, "openapi://webhooks/orderPaid", webhook.URI)
+	assert.Equal(t, "webhook orderPaid", webhook.Name)
+	assert.Equal(t, "application/json", webhook.MimeType)
+	assert.Contains(t, webhook.Description, "An order was paid")
+	assert.Contains(t, webhook.Description, "not callable")
+	document := resourceDocument(t, webhook)
+	assert.Equal(t, "webhook", document["kind"])
+	assert.Equal(t, "orderPaid", document["name"])
+	assert.Contains(t, document["trigger"], "orderPaid")
+	requests := document["requests"].([]interface{})
+	require.Len(t, requests, 1)
+	request := requests[0].(map[string]interface{})
+	assert.Equal(t, "POST", request["method"])
+	assert.Equal(t, "application/json", request["mediaType"])
+	schema := request["payloadSchema"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"orderId"}, schema["required"])
+	properties := schema["properties"].(map[string]interface{})
+	assert.Equal(t, "number", properties["amount"].(map[string]interface{})["type"])
+	shipment := properties["shipment"].(map[string]interface{})
+	assert.Contains(t, shipment["properties"], "trackingNumber", "component references are resolved")
+
+	assert.Equal(t, "openapi://callbacks/createOrder/orderShipped", callback.URI)
+	assert.Equal(t, "callback orderShipped of createOrder", callback.Name)
+	document = resourceDocument(t, callback)
+	assert.Equal(t, "callback", document["kind"])
+	assert.Contains(t, document["trigger"], "createOrder")
+	request = document["requests"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "{$request.body#/callbackUrl}", request["url"])
+	assert.Equal(t, "The order left the warehouse", request["summary"])
+	assert.Contains(t, request["payloadSchema"].(map[string]interface{})["properties"], "trackingNumber")
+}
+
+func TestGenerateToolSet_CallbacksFollowFilters(t *testing.T) {
+	doc, version := loadSpecFixture(t, "events_v31.json", eventsV31SpecJSON)
+
+	toolSet, err := GenerateToolSet(doc, version, &config.Config{ExcludeOperations: []string{"createOrder"}})
+	require.NoError(t, err)
+
+	// The callback belongs to an excluded operation; webhooks stand alone
+	assert.Empty(t, toolSet.Tools)
+	require.Len(t, toolSet.Resources, 1)
+	assert.Equal(t, "openapi://webhooks/orderPaid", toolSet.Resources[0].URI)
+}
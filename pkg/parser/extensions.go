@@ -0,0 +1,210 @@
+package parser
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// streamingExtension marks an operation whose response should be forwarded incrementally.
+const streamingExtension = "x-mcp-streaming"
+
+// streamingMediaTypes are response media types that imply a streaming operation.
+var streamingMediaTypes = []string{"application/x-ndjson", "application/jsonl", "application/json-seq", "text/event-stream"}
+
+// idempotencyExtension marks an operation that should carry an idempotency key and be deduplicated.
+const idempotencyExtension = "x-mcp-idempotency-key"
+
+// timeoutExtension is the vendor extension used to set a per-operation upstream timeout, either as
+// a Go duration string ("30s", "2m") or a number of seconds.
+const timeoutExtension = "x-mcp-timeout"
+
+// maxResponseBytesExtension caps the size of an operation's upstream response body, in bytes.
+const maxResponseBytesExtension = "x-mcp-max-response-bytes"
+
+// maxConcurrencyExtension caps how many calls to an operation's tool run at once.
+const maxConcurrencyExtension = "x-mcp-max-concurrency"
+
+// confirmExtension marks a destructive operation whose calls a human should always confirm.
+const confirmExtension = "x-mcp-confirm"
+
+// healthCheckExtension marks a cheap operation that the __upstream_health tool probes.
+const healthCheckExtension = "x-mcp-health-check"
+
+// booleanStyleExtension chooses how a boolean query parameter is sent: "value" (the default) or
+// "presence", see mcp.BooleanStylePresence.
+const booleanStyleExtension = "x-mcp-boolean-style"
+
+// sunsetExtension gives the date an operation is planned to be removed, e.g. "2026-12-31".
+const sunsetExtension = "x-sunset"
+
+// lookupExtension finds a vendor extension by name, case-insensitively (go-openapi lowercases keys).
+func lookupExtension(extensions map[string]interface{}, name string) (interface{}, bool) {
+	if value, ok := extensions[name]; ok {
+		return value, true
+	}
+	for key, value := range extensions {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// operationTimeout reads the x-mcp-timeout extension. Zero means no per-operation timeout.
+func operationTimeout(extensions map[string]interface{}) time.Duration {
+	value, ok := lookupExtension(extensions, timeoutExtension)
+	if !ok {
+		return 0
+	}
+	switch v := value.(type) {
+	case string:
+		timeout, err := time.ParseDuration(v)
+		if err != nil || timeout <= 0 {
+			log.Printf("Warning: ignoring invalid %s value %q", timeoutExtension, v)
+			return 0
+		}
+		return timeout
+	case float64:
+		if v <= 0 {
+			log.Printf("Warning: ignoring non-positive %s value %v", timeoutExtension, v)
+			return 0
+		}
+		return time.Duration(v * float64(time.Second))
+	default:
+		log.Printf("Warning: ignoring %s with unexpected type %T", timeoutExtension, value)
+		return 0
+	}
+}
+
+// operationMaxResponseBytes reads the x-mcp-max-response-bytes extension. Zero means no
+// per-operation limit.
+func operationMaxResponseBytes(extensions map[string]interface{}) int64 {
+	return positiveIntegerExtension(extensions, maxResponseBytesExtension)
+}
+
+// operationMaxConcurrency reads the x-mcp-max-concurrency extension. Zero means unlimited.
+func operationMaxConcurrency(extensions map[string]interface{}) int {
+	return int(positiveIntegerExtension(extensions, maxConcurrencyExtension))
+}
+
+// positiveIntegerExtension reads an extension holding a positive whole number, logging and
+// returning zero for anything else.
+func positiveIntegerExtension(extensions map[string]interface{}, name string) int64 {
+	value, ok := lookupExtension(extensions, name)
+	if !ok {
+		return 0
+	}
+	var number float64
+	switch v := value.(type) {
+	case float64:
+		number = v
+	case int:
+		number = float64(v)
+	default:
+		log.Printf("Warning: ignoring %s with unexpected type %T", name, value)
+		return 0
+	}
+	if number < 1 || number != float64(int64(number)) {
+		log.Printf("Warning: ignoring %s value %v, must be a positive whole number", name, value)
+		return 0
+	}
+	return int64(number)
+}
+
+// operationSunset reads the x-sunset extension as a date. Timestamps are cut to their date;
+// other strings are kept as written.
+func operationSunset(extensions map[string]interface{}) string {
+	value, ok := lookupExtension(extensions, sunsetExtension)
+	if !ok {
+		return ""
+	}
+	switch v := value.(type) {
+	case string:
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t.Format(time.DateOnly)
+		}
+		return v
+	case time.Time:
+		return v.Format(time.DateOnly)
+	default:
+		log.Printf("Warning: ignoring %s with unexpected type %T", sunsetExtension, value)
+		return ""
+	}
+}
+
+// requiresConfirmation reads the x-mcp-confirm extension.
+func requiresConfirmation(extensions map[string]interface{}) bool {
+	value, ok := lookupExtension(extensions, confirmExtension)
+	if !ok {
+		return false
+	}
+	confirm, ok := value.(bool)
+	if !ok {
+		log.Printf("Warning: ignoring %s with non-boolean value %v", confirmExtension, value)
+	}
+	return confirm
+}
+
+// booleanStyle reads the x-mcp-boolean-style extension of a parameter.
+func booleanStyle(extensions map[string]interface{}) string {
+	value, ok := lookupExtension(extensions, booleanStyleExtension)
+	if !ok {
+		return ""
+	}
+	style, _ := value.(string)
+	if style != mcp.BooleanStyleValue && style != mcp.BooleanStylePresence {
+		log.Printf("Warning: ignoring %s with value %v; must be %q or %q", booleanStyleExtension, value, mcp.BooleanStyleValue, mcp.BooleanStylePresence)
+		return ""
+	}
+	return style
+}
+
+// isHealthCheck reads the x-mcp-health-check extension.
+func isHealthCheck(extensions map[string]interface{}) bool {
+	value, ok := lookupExtension(extensions, healthCheckExtension)
+	if !ok {
+		return false
+	}
+	healthCheck, ok := value.(bool)
+	if !ok {
+		log.Printf("Warning: ignoring %s with non-boolean value %v", healthCheckExtension, value)
+	}
+	return healthCheck
+}
+
+// isStreamingOperation decides whether an operation streams, from x-mcp-streaming when present,
+// otherwise from whether any of its success responses declares a streaming media type.
+func isStreamingOperation(extensions map[string]interface{}, successMediaTypes []string) bool {
+	if value, ok := lookupExtension(extensions, streamingExtension); ok {
+		if streaming, ok := value.(bool); ok {
+			return streaming
+		}
+		log.Printf("Warning: ignoring %s with non-boolean value %v", streamingExtension, value)
+	}
+	for _, mediaType := range successMediaTypes {
+		if sliceContains(streamingMediaTypes, strings.ToLower(mediaType)) {
+			return true
+		}
+	}
+	return false
+}
+
+// usesIdempotencyKey decides whether an operation takes an idempotency key, from
+// x-mcp-idempotency-key when present, otherwise from a declared header parameter named keyHeader.
+func usesIdempotencyKey(extensions map[string]interface{}, params []mcp.ParameterDetail, keyHeader string) bool {
+	if value, ok := lookupExtension(extensions, idempotencyExtension); ok {
+		if enabled, ok := value.(bool); ok {
+			return enabled
+		}
+		log.Printf("Warning: ignoring %s with non-boolean value %v", idempotencyExtension, value)
+	}
+	for _, param := range params {
+		if param.In == "header" && strings.EqualFold(param.Name, keyHeader) {
+			return true
+		}
+	}
+	return false
+}
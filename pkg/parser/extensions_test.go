@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationTimeout(t *testing.T) {
+	tests := []struct {
+		name       string
+		extensions map[string]interface{}
+		expected   time.Duration
+	}{
+		{name: "No extension", extensions: nil, expected: 0},
+		{name: "Duration string", extensions: map[string]interface{}{"x-mcp-timeout": "30s"}, expected: 30 * time.Second},
+		{name: "Seconds as number", extensions: map[string]interface{}{"x-mcp-timeout": float64(90)}, expected: 90 * time.Second},
+		{name: "Invalid string", extensions: map[string]interface{}{"x-mcp-timeout": "soon"}, expected: 0},
+		{name: "Negative number", extensions: map[string]interface{}{"x-mcp-timeout": float64(-1)}, expected: 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, operationTimeout(tc.extensions))
+		})
+	}
+}
+
+func TestIsStreamingOperation(t *testing.T) {
+	assert.False(t, isStreamingOperation(nil, []string{"application/json"}))
+	assert.True(t, isStreamingOperation(nil, []string{"application/json", "application/x-ndjson"}))
+	assert.True(t, isStreamingOperation(nil, []string{"text/event-stream"}))
+	assert.True(t, isStreamingOperation(map[string]interface{}{"x-mcp-streaming": true}, nil))
+	assert.False(t, isStreamingOperation(map[string]interface{}{"x-mcp-streaming": false}, []string{"text/event-stream"}), "extension overrides media type")
+}
+
+func TestUsesIdempotencyKey(t *testing.T) {
+	keyParam := []mcp.ParameterDetail{{Name: "idempotency-key", In: "header"}}
+	assert.False(t, usesIdempotencyKey(nil, nil, "Idempotency-Key"))
+	assert.True(t, usesIdempotencyKey(nil, keyParam, "Idempotency-Key"), "header name match is case-insensitive")
+	assert.False(t, usesIdempotencyKey(nil, []mcp.ParameterDetail{{Name: "Idempotency-Key", In: "query"}}, "Idempotency-Key"))
+	assert.True(t, usesIdempotencyKey(map[string]interface{}{"x-mcp-idempotency-key": true}, nil, "Idempotency-Key"))
+	assert.False(t, usesIdempotencyKey(map[string]interface{}{"x-mcp-idempotency-key": false}, keyParam, "Idempotency-Key"))
+}
+
+func TestRequiresConfirmation(t *testing.T) {
+	assert.False(t, requiresConfirmation(nil))
+	assert.True(t, requiresConfirmation(map[string]interface{}{"x-mcp-confirm": true}))
+	assert.False(t, requiresConfirmation(map[string]interface{}{"x-mcp-confirm": false}))
+	assert.False(t, requiresConfirmation(map[string]interface{}{"x-mcp-confirm": "yes"}), "non-boolean values are ignored")
+}
+
+func TestIsHealthCheck(t *testing.T) {
+	assert.False(t, isHealthCheck(nil))
+	assert.True(t, isHealthCheck(map[string]interface{}{"x-mcp-health-check": true}))
+	assert.False(t, isHealthCheck(map[string]interface{}{"x-mcp-health-check": "yes"}), "non-boolean values are ignored")
+}
+
+func TestBooleanStyle(t *testing.T) {
+	assert.Equal(t, "", booleanStyle(nil))
+	assert.Equal(t, mcp.BooleanStylePresence, booleanStyle(map[string]interface{}{"x-mcp-boolean-style": "presence"}))
+	assert.Equal(t, mcp.BooleanStyleValue, booleanStyle(map[string]interface{}{"x-mcp-boolean-style": "value"}))
+	assert.Equal(t, "", booleanStyle(map[string]interface{}{"x-mcp-boolean-style": "bare"}), "unknown styles are ignored")
+}
+
+func TestOperationSunset(t *testing.T) {
+	tests := []struct {
+		name       string
+		extensions map[string]interface{}
+		expected   string
+	}{
+		{name: "No extension", extensions: nil, expected: ""},
+		{name: "Date", extensions: map[string]interface{}{"x-sunset": "2026-12-31"}, expected: "2026-12-31"},
+		{name: "Timestamp is cut to its date", extensions: map[string]interface{}{"x-sunset": "2026-12-31T23:59:59Z"}, expected: "2026-12-31"},
+		{name: "Decoded YAML date", extensions: map[string]interface{}{"x-sunset": time.Date(2027, 1, 15, 0, 0, 0, 0, time.UTC)}, expected: "2027-01-15"},
+		{name: "Unexpected type", extensions: map[string]interface{}{"x-sunset": float64(2026)}, expected: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, operationSunset(tc.extensions))
+		})
+	}
+}
+
+func TestOperationMaxResponseBytes(t *testing.T) {
+	tests := []struct {
+		name       string
+		extensions map[string]interface{}
+		expected   int64
+	}{
+		{name: "No extension", extensions: nil, expected: 0},
+		{name: "Bytes as number", extensions: map[string]interface{}{"x-mcp-max-response-bytes": float64(1048576)}, expected: 1048576},
+		{name: "Decoded YAML integer", extensions: map[string]interface{}{"x-mcp-max-response-bytes": 2048}, expected: 2048},
+		{name: "Other extension", extensions: map[string]interface{}{"x-mcp-max-concurrency": float64(2)}, expected: 0},
+		{name: "Fractional number", extensions: map[string]interface{}{"x-mcp-max-response-bytes": float64(1.5)}, expected: 0},
+		{name: "Zero", extensions: map[string]interface{}{"x-mcp-max-response-bytes": float64(0)}, expected: 0},
+		{name: "String", extensions: map[string]interface{}{"x-mcp-max-response-bytes": "1MB"}, expected: 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, operationMaxResponseBytes(tc.extensions))
+		})
+	}
+}
+
+func TestOperationMaxConcurrency(t *testing.T) {
+	assert.Equal(t, 0, operationMaxConcurrency(nil))
+	assert.Equal(t, 2, operationMaxConcurrency(map[string]interface{}{"x-mcp-max-concurrency": float64(2)}))
+	assert.Equal(t, 0, operationMaxConcurrency(map[string]interface{}{"x-mcp-max-concurrency": float64(-1)}))
+	assert.Equal(t, 0, operationMaxConcurrency(map[string]interface{}{"x-mcp-max-concurrency": "2"}))
+}
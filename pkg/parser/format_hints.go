@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// formatHints are the notes added to the descriptions of string properties with a well-known
+// JSON Schema format. Models follow the description more reliably than the format keyword.
+var formatHints = map[string]string{
+	"date-time": "RFC 3339 date-time, e.g. 2024-01-31T13:45:00Z",
+	"date":      "RFC 3339 full-date, e.g. 2024-01-31",
+	"time":      "RFC 3339 time, e.g. 13:45:00Z",
+	"email":     "email address",
+	"uuid":      "UUID, e.g. 123e4567-e89b-12d3-a456-426614174000",
+	"uri":       "absolute URI",
+	"hostname":  "hostname",
+	"ipv4":      "IPv4 address",
+	"ipv6":      "IPv6 address",
+}
+
+// addFormatHints appends a "format: ..." note to the description of every string property in
+// schema, at any depth, whose format is in formatHints. The format keyword itself is kept.
+func addFormatHints(schema *mcp.Schema) {
+	if schema.Type == "string" {
+		if hint, ok := formatHints[strings.ToLower(schema.Format)]; ok {
+			note := "format: " + hint
+			if schema.Description == "" {
+				schema.Description = note
+			} else if !strings.Contains(schema.Description, note) {
+				schema.Description += " (" + note + ")"
+			}
+		}
+	}
+	for name, property := range schema.Properties {
+		addFormatHints(&property)
+		schema.Properties[name] = property
+	}
+	if schema.Items != nil {
+		items := *schema.Items
+		addFormatHints(&items)
+		schema.Items = &items
+	}
+}
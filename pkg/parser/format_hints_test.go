@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const formatHintsV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Events API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {
+    "/events": {
+      "post": {
+        "operationId": "createEvent",
+        "parameters": [
+          {"name": "since", "in": "query", "description": "Only events after this time", "schema": {"type": "string", "format": "date-time"}},
+          {"name": "day", "in": "query", "schema": {"type": "string", "format": "date"}},
+          {"name": "count", "in": "query", "schema": {"type": "integer", "format": "int32"}}
+        ],
+        "requestBody": {"content": {"application/json": {"schema": {
+          "type": "object",
+          "properties": {
+            "organizer": {"type": "object", "properties": {"email": {"type": "string", "format": "email"}}},
+            "attendees": {"type": "array", "items": {"type": "string", "format": "uuid"}},
+            "color": {"type": "string", "format": "hex-color"}
+          }
+        }}}},
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  }
+}`
+
+func TestGenerateToolSet_FormatHints(t *testing.T) {
+	schema := inputSchemas(t, "format_hints_v3.json", formatHintsV3SpecJSON, &config.Config{})["createEvent"]
+
+	since := schema.Properties["since"]
+	assert.Equal(t, "date-time", since.Format, "the format keyword is kept")
+	assert.Equal(t, "Only events after this time (format: RFC 3339 date-time, e.g. 2024-01-31T13:45:00Z)", since.Description)
+	assert.Equal(t, "format: RFC 3339 full-date, e.g. 2024-01-31", schema.Properties["day"].Description, "a missing description becomes the note")
+
+	assert.Equal(t, "format: email address", schema.Properties["organizer"].Properties["email"].Description, "nested properties get notes")
+	require.NotNil(t, schema.Properties["attendees"].Items)
+	assert.Contains(t, schema.Properties["attendees"].Items.Description, "format: UUID")
+
+	assert.Empty(t, schema.Properties["count"].Description, "non-string formats get no note")
+	assert.Empty(t, schema.Properties["color"].Description, "unknown formats get no note")
+	assert.Equal(t, "hex-color", schema.Properties["color"].Format)
+}
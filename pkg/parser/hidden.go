@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"log"
+	"sort"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// hiddenExtension marks a parameter or body property that the client must not set. It is left out
+// of the input schema; a value can be supplied server-side with a pinned argument.
+const hiddenExtension = "x-mcp-hidden"
+
+// isHidden reads the x-mcp-hidden extension of a parameter or schema.
+func isHidden(extensions map[string]interface{}) bool {
+	value, ok := lookupExtension(extensions, hiddenExtension)
+	if !ok {
+		return false
+	}
+	hidden, ok := value.(bool)
+	if !ok {
+		log.Printf("Warning: ignoring %s with non-boolean value %v", hiddenExtension, value)
+	}
+	return hidden
+}
+
+// hideParameters removes hidden top-level properties from a tool's input schema. A hidden property
+// that is required but has no pinned value can never be satisfied, which is logged as a warning.
+func hideParameters(toolName string, schema *mcp.Schema, cfg *config.Config) {
+	var hidden []string
+	for name, propSchema := range schema.Properties {
+		if propSchema.Hidden {
+			hidden = append(hidden, name)
+		}
+	}
+	sort.Strings(hidden)
+
+	for _, name := range hidden {
+		delete(schema.Properties, name)
+		if !sliceContains(schema.Required, name) {
+			continue
+		}
+		schema.Required = removeString(schema.Required, name)
+		if _, pinned := cfg.PinnedArguments[toolName][name]; !pinned {
+			log.Printf("Warning: tool '%s' hides required parameter '%s' (%s) but no value is pinned for it; calls will be missing it. Pin one with --pin-arg %s:%s=value",
+				toolName, name, hiddenExtension, toolName, name)
+		}
+	}
+}
+
+// removeString returns list without any occurrence of value.
+func removeString(list []string, value string) []string {
+	kept := make([]string, 0, len(list))
+	for _, item := range list {
+		if item != value {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// hideTagPinnedParameters removes the arguments pinned by the operation's tags from its input
+// schema, since the server always supplies them.
+func hideTagPinnedParameters(schema *mcp.Schema, tagArguments map[string]interface{}) {
+	for name := range tagArguments {
+		delete(schema.Properties, name)
+		schema.Required = removeString(schema.Required, name)
+	}
+}
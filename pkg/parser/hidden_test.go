@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+const hiddenV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Hidden API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {
+    "/orders": {
+      "post": {
+        "operationId": "createOrder",
+        "parameters": [
+          {"name": "dryRun", "in": "query", "schema": {"type": "boolean"}},
+          {"name": "X-Signature", "in": "header", "required": true, "x-mcp-hidden": true, "schema": {"type": "string"}}
+        ],
+        "requestBody": {"content": {"application/json": {"schema": {
+          "type": "object",
+          "required": ["item"],
+          "properties": {
+            "item": {"type": "string"},
+            "pageToken": {"type": "string", "x-mcp-hidden": true}
+          }
+        }}}},
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  }
+}`
+
+const hiddenV2SpecJSON = `{
+  "swagger": "2.0",
+  "info": {"title": "Hidden API", "version": "1.0.0"},
+  "host": "api.example.com",
+  "paths": {
+    "/orders": {
+      "get": {
+        "operationId": "listOrders",
+        "parameters": [
+          {"name": "limit", "in": "query", "type": "integer"},
+          {"name": "cursor", "in": "query", "type": "string", "required": true, "x-mcp-hidden": true}
+        ],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+
+// captureLog collects log output written while fn runs.
+func captureLog(t *testing.T, fn func()) string {
+	t.Helper()
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	fn()
+	return buf.String()
+}
+
+func TestGenerateToolSet_HiddenParameters(t *testing.T) {
+	t.Run("Hidden parameters and properties are left out of the schema", func(t *testing.T) {
+		cfg := &config.Config{PinnedArguments: map[string]map[string]interface{}{"createOrder": {"X-Signature": "sig"}}}
+		createOrder := inputSchemas(t, "hidden_v3.json", hiddenV3SpecJSON, cfg)["createOrder"]
+
+		assert.Contains(t, createOrder.Properties, "dryRun")
+		assert.Contains(t, createOrder.Properties, "item")
+		assert.NotContains(t, createOrder.Properties, "X-Signature")
+		assert.NotContains(t, createOrder.Properties, "pageToken")
+		assert.Equal(t, []string{"item"}, createOrder.Required)
+	})
+
+	t.Run("Hidden parameters are still sent", func(t *testing.T) {
+		doc, version := loadSpecFixture(t, "hidden_v3.json", hiddenV3SpecJSON)
+		toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+		assert.NoError(t, err)
+		assert.Contains(t, toolSet.Operations["createOrder"].Parameters, mcp.ParameterDetail{Name: "X-Signature", In: "header"})
+	})
+
+	t.Run("Pinned required parameter does not warn", func(t *testing.T) {
+		cfg := &config.Config{PinnedArguments: map[string]map[string]interface{}{"createOrder": {"X-Signature": "sig"}}}
+		output := captureLog(t, func() { inputSchemas(t, "hidden_v3.json", hiddenV3SpecJSON, cfg) })
+		assert.NotContains(t, output, "hides required parameter")
+	})
+
+	t.Run("Unpinned required parameter warns", func(t *testing.T) {
+		output := captureLog(t, func() { inputSchemas(t, "hidden_v3.json", hiddenV3SpecJSON, &config.Config{}) })
+		assert.Contains(t, output, "tool 'createOrder' hides required parameter 'X-Signature'")
+		assert.NotContains(t, output, "'pageToken'", "optional hidden properties need no pin")
+	})
+
+	t.Run("v2", func(t *testing.T) {
+		listOrders := inputSchemas(t, "hidden_v2.json", hiddenV2SpecJSON, &config.Config{})["listOrders"]
+		assert.Contains(t, listOrders.Properties, "limit")
+		assert.NotContains(t, listOrders.Properties, "cursor")
+		assert.Empty(t, listOrders.Required)
+
+		output := captureLog(t, func() { inputSchemas(t, "hidden_v2.json", hiddenV2SpecJSON, &config.Config{}) })
+		assert.Contains(t, output, "tool 'listOrders' hides required parameter 'cursor'")
+	})
+}
+
+func TestIsHidden(t *testing.T) {
+	assert.True(t, isHidden(map[string]interface{}{"x-mcp-hidden": true}))
+	assert.False(t, isHidden(map[string]interface{}{"x-mcp-hidden": false}))
+	assert.False(t, isHidden(map[string]interface{}{"x-mcp-hidden": "yes"}), "non-boolean values are ignored")
+	assert.False(t, isHidden(nil))
+}
@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// LoadOptions controls optional preprocessing applied to the spec while it is loaded.
+type LoadOptions struct {
+	// InterpolateEnv replaces ${VAR} and ${VAR:-default} placeholders in the spec's strings with
+	// environment values.
+	// Write $${ for a literal "${".
+	InterpolateEnv bool
+	// StrictEnv fails loading when a placeholder has no value and no default.
+	// Unresolved placeholders are otherwise left as-is.
+	StrictEnv bool
+	// LookupEnv resolves variables; defaults to os.LookupEnv.
+	LookupEnv func(key string) (string, bool)
+	// SpecFormat forces parsing the spec as SpecFormatJSON or SpecFormatYAML. Empty or
+	// SpecFormatAuto detects the format from the extension, Content-Type, or content.
+	SpecFormat string
+	// Transforms run in order on the loaded spec before it is returned, see WithSpecTransform.
+	Transforms []SpecTransform
+}
+
+// envPlaceholderPattern matches $${ (escape), ${NAME} and ${NAME:-default}.
+var envPlaceholderPattern = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// envInterpolator substitutes environment placeholders in strings, collecting the names of
+// the placeholders it could not resolve.
+type envInterpolator struct {
+	lookup     func(key string) (string, bool)
+	unresolved map[string]bool
+}
+
+func newEnvInterpolator(opts LoadOptions) *envInterpolator {
+	lookup := opts.LookupEnv
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	return &envInterpolator{lookup: lookup, unresolved: make(map[string]bool)}
+}
+
+// interpolate substitutes the placeholders in one string.
+func (e *envInterpolator) interpolate(s string) string {
+	return envPlaceholderPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if match == "$${" {
+			return "${"
+		}
+		groups := envPlaceholderPattern.FindStringSubmatch(match)
+		name := groups[1]
+		value, ok := e.lookup(name)
+		if !ok {
+			if !strings.Contains(match, ":-") { // No default given
+				e.unresolved[name] = true
+				return match
+			}
+			value = groups[2]
+		}
+		return value
+	})
+}
+
+// value substitutes the placeholders in the strings of a decoded JSON value, object keys
+// included.
+func (e *envInterpolator) value(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return e.interpolate(v)
+	case map[string]interface{}:
+		interpolated := make(map[string]interface{}, len(v))
+		for key, element := range v {
+			interpolated[e.interpolate(key)] = e.value(element)
+		}
+		return interpolated
+	case []interface{}:
+		for i, element := range v {
+			v[i] = e.value(element)
+		}
+		return v
+	}
+	return value
+}
+
+// interpolateEnv substitutes environment placeholders in the strings of a JSON spec. Only string
+// values and keys are touched, so a value cannot add keys or break the document, and it is
+// encoded the way JSON needs.
+func interpolateEnv(data []byte, opts LoadOptions) ([]byte, error) {
+	var document interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber() // Keeps large integers exact
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	interpolator := newEnvInterpolator(opts)
+	result, err := json.Marshal(interpolator.value(document))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(interpolator.unresolved) > 0 {
+		names := make([]string, 0, len(interpolator.unresolved))
+		for name := range interpolator.unresolved {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if opts.StrictEnv {
+			return nil, fmt.Errorf("unresolved environment placeholders in spec: %s", strings.Join(names, ", "))
+		}
+		log.Printf("Warning: leaving unresolved environment placeholders in spec: %s", strings.Join(names, ", "))
+	}
+	return result, nil
+}
+
+// interpolateOperationOrder substitutes environment placeholders in the paths of an operation
+// order read from the source text, so they match the interpolated spec's paths.
+func interpolateOperationOrder(order []operationRef, opts LoadOptions) {
+	interpolator := newEnvInterpolator(opts)
+	for i := range order {
+		order[i].path = interpolator.interpolate(order[i].path)
+	}
+}
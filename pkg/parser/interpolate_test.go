@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEnv returns a LookupEnv func backed by a map.
+func fakeEnv(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := vars[key]
+		return value, ok
+	}
+}
+
+func TestInterpolateEnv(t *testing.T) {
+	env := fakeEnv(map[string]string{
+		"API_HOST": "api.example.com",
+		"QUOTED":   `say "hi"`,
+		"EMPTY":    "",
+	})
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "Resolved placeholder", input: `"https://${API_HOST}/v1"`, expected: `"https://api.example.com/v1"`},
+		{name: "Default used when unset", input: `"${API_PORT:-8443}"`, expected: `"8443"`},
+		{name: "Value wins over default", input: `"${API_HOST:-localhost}"`, expected: `"api.example.com"`},
+		{name: "Empty value is resolved", input: `"[${EMPTY}]"`, expected: `"[]"`},
+		{name: "Empty default", input: `"[${MISSING:-}]"`, expected: `"[]"`},
+		{name: "Value is JSON-escaped", input: `"${QUOTED}"`, expected: `"say \"hi\""`},
+		{name: "Escaped placeholder", input: `"$${API_HOST}"`, expected: `"${API_HOST}"`},
+		{name: "Unresolved left as-is", input: `"${MISSING}"`, expected: `"${MISSING}"`},
+		{name: "Bare dollar untouched", input: `"^[a-z]+$"`, expected: `"^[a-z]+$"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := interpolateEnv([]byte(tc.input), LoadOptions{InterpolateEnv: true, LookupEnv: env})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(result))
+		})
+	}
+}
+
+func TestInterpolateEnv_StrictFailsOnUnresolved(t *testing.T) {
+	env := fakeEnv(map[string]string{"API_HOST": "api.example.com"})
+	input := `{"a": "${API_HOST}", "b": "${TOKEN_URL}", "c": "${AUDIENCE}", "d": "${TOKEN_URL}", "e": "${PORT:-80}"}`
+
+	_, err := interpolateEnv([]byte(input), LoadOptions{InterpolateEnv: true, StrictEnv: true, LookupEnv: env})
+	require.Error(t, err)
+	assert.Equal(t, "unresolved environment placeholders in spec: AUDIENCE, TOKEN_URL", err.Error())
+
+	// Everything resolvable: strict mode succeeds
+	result, err := interpolateEnv([]byte(`"${API_HOST}:${PORT:-80}"`), LoadOptions{InterpolateEnv: true, StrictEnv: true, LookupEnv: env})
+	require.NoError(t, err)
+	assert.Equal(t, `"api.example.com:80"`, string(result))
+}
+
+const interpolatedV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Interpolated API", "version": "1.0.0"},
+  "servers": [{"url": "https://${API_HOST}/v1"}],
+  "paths": {}
+}`
+
+func TestLoadSwaggerWithOptions_InterpolatesEnv(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "interpolated_v3.json")
+	require.NoError(t, os.WriteFile(filePath, []byte(interpolatedV3SpecJSON), 0644))
+	env := fakeEnv(map[string]string{"API_HOST": "api.example.com"})
+
+	doc, version, err := LoadSwaggerWithOptions(filePath, LoadOptions{InterpolateEnv: true, LookupEnv: env})
+	require.NoError(t, err)
+	assert.Equal(t, VersionV3, version)
+	assert.Equal(t, "https://api.example.com/v1", doc.(*openapi3.T).Servers[0].URL)
+
+	// Without interpolation the placeholder reads as an undeclared server variable
+	_, _, err = LoadSwagger(filePath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "undeclared variables")
+
+	// Strict mode refuses to load with the variable unset
+	_, _, err = LoadSwaggerWithOptions(filePath, LoadOptions{InterpolateEnv: true, StrictEnv: true, LookupEnv: fakeEnv(nil)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "API_HOST")
+}
+
+const interpolatedV3SpecYAML = `openapi: 3.0.0
+info:
+  title: Interpolated API
+  version: 1.0.0
+  description: ${DESCRIPTION}
+servers:
+  - url: https://${API_HOST}/v1
+paths:
+  /${PREFIX}/items:
+    get:
+      operationId: listItems
+      responses:
+        "200":
+          description: OK
+`
+
+func TestLoadSwaggerWithOptions_InterpolatesYAMLStringsOnly(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "interpolated_v3.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(interpolatedV3SpecYAML), 0644))
+	description := "\"Quoted\": keys like\nx-injected: true # are just text"
+	env := fakeEnv(map[string]string{"API_HOST": "api.example.com", "PREFIX": "v2", "DESCRIPTION": description})
+
+	doc, version, err := LoadSwaggerWithOptions(filePath, LoadOptions{InterpolateEnv: true, LookupEnv: env})
+	require.NoError(t, err)
+	assert.Equal(t, VersionV3, version)
+	v3 := doc.(*openapi3.T)
+	assert.Equal(t, description, v3.Info.Description, "the value stays one string")
+	assert.Empty(t, v3.Info.Extensions)
+	assert.Equal(t, "https://api.example.com/v1", v3.Servers[0].URL)
+	assert.NotNil(t, v3.Paths.Find("/v2/items"), "keys are interpolated too")
+}
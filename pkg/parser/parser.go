@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -26,16 +27,29 @@ const (
 )
 
 // LoadSwagger detects the version and loads an OpenAPI/Swagger specification
-// from a local file path or a remote URL.
+// from a local file path, a remote URL, or standard input (StdinSpecLocation).
 // It returns the loaded spec document (as interface{}), the detected version (string), and an error.
 func LoadSwagger(location string) (interface{}, string, error) {
+	return LoadSwaggerWithOptions(location, LoadOptions{})
+}
+
+// LoadSwaggerWithOptions is LoadSwagger with optional preprocessing of the spec text.
+func LoadSwaggerWithOptions(location string, opts LoadOptions) (interface{}, string, error) {
+	if location == StdinSpecLocation {
+		data, err := readSpecStdin()
+		if err != nil {
+			return nil, "", err
+		}
+		return loadSpecData(location, data, "", opts, true) // Relative external refs resolve against the working directory
+	}
+
 	// Determine if location is URL or file path
-	locationURL, urlErr := url.ParseRequestURI(location)
-	isURL := urlErr == nil && locationURL != nil && (locationURL.Scheme == "http" || locationURL.Scheme == "https")
+	_, isURL := specURL(location)
 
 	var data []byte
 	var err error
-	var absPath string // Store absolute path if it's a file
+	var absPath string     // Store absolute path if it's a file
+	var contentType string // Content-Type of a fetched spec, for format detection
 
 	if !isURL {
 		log.Printf("Detected file path location: %s", location)
@@ -64,7 +78,52 @@ func LoadSwagger(location string) (interface{}, string, error) {
 		if err != nil {
 			return nil, "", fmt.Errorf("failed to read response body from URL '%s': %w", location, err)
 		}
+		contentType = resp.Header.Get("Content-Type")
+	}
+
+	return loadSpecData(location, data, contentType, opts, false)
+}
+
+// specURL parses location as an http(s) URL, reporting false for file paths.
+func specURL(location string) (*url.URL, bool) {
+	locationURL, err := url.ParseRequestURI(location)
+	return locationURL, err == nil && locationURL != nil && (locationURL.Scheme == "http" || locationURL.Scheme == "https")
+}
+
+// loadSpecData parses and validates spec text read from location. With fromData set, the text is
+// always loaded as given rather than re-read from the location (which only anchors relative
+// external refs).
+func loadSpecData(location string, data []byte, contentType string, opts LoadOptions, fromData bool) (interface{}, string, error) {
+	locationURL, isURL := specURL(location)
+	absPath := location
+	if !isURL {
+		var err error
+		if absPath, err = filepath.Abs(location); err != nil {
+			return nil, "", fmt.Errorf("failed to get absolute path for '%s': %w", location, err)
+		}
+	}
+
+	order := documentOperationOrder(data) // Lost once converted to JSON and loaded
+
+	// Parse as JSON or YAML; everything below works on the JSON form
+	format, chosen, err := resolveSpecFormat(opts.SpecFormat, location, contentType, data)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err = specToJSON(data, format)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse %s from '%s' (%s): %w", strings.ToUpper(format), location, chosen, err)
+	}
+	// Interpolating the parsed strings rather than the source text keeps values from changing
+	// the document's structure, whatever its format
+	if opts.InterpolateEnv {
+		data, err = interpolateEnv(data, opts)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to interpolate environment into spec from '%s': %w", location, err)
+		}
+		interpolateOperationOrder(order, opts)
 	}
+	loadFromData := fromData || opts.InterpolateEnv || format == SpecFormatYAML // The source text is not what was parsed
 
 	// Detect version from data
 	var detector map[string]interface{}
@@ -79,7 +138,15 @@ func LoadSwagger(location string) (interface{}, string, error) {
 		var doc *openapi3.T
 		var loadErr error
 
-		if !isURL {
+		if loadFromData {
+			// Load the preprocessed data; the location still anchors relative external refs
+			refBase := locationURL
+			if !isURL {
+				refBase = &url.URL{Path: absPath}
+			}
+			log.Printf("Loading V3 spec using LoadFromDataWithPath: %s", location)
+			doc, loadErr = loader.LoadFromDataWithPath(data, refBase)
+		} else if !isURL {
 			// Use LoadFromFile for local files
 			log.Printf("Loading V3 spec using LoadFromFile: %s", absPath)
 			doc, loadErr = loader.LoadFromFile(absPath)
@@ -93,9 +160,17 @@ func LoadSwagger(location string) (interface{}, string, error) {
 			return nil, "", fmt.Errorf("failed to load OpenAPI v3 spec from '%s': %w", location, loadErr)
 		}
 
-		if err := doc.Validate(context.Background()); err != nil {
+		// Duplicate operationIds are left to the DuplicateOperationIDs policy of GenerateToolSet
+		restoreIDs := suspendDuplicateOperationIDsV3(doc)
+		err := doc.Validate(context.Background(), openapi3.AllowExtraSiblingFields(webhooksField)) // OpenAPI 3.1 webhooks, see webhookResourcesV3
+		restoreIDs()
+		if err != nil {
 			return nil, "", fmt.Errorf("OpenAPI v3 spec validation failed for '%s': %w", location, err)
 		}
+		recordOperationOrder(doc, order)
+		if err := applySpecTransforms(doc, opts.Transforms, location); err != nil {
+			return nil, "", err
+		}
 		return doc, VersionV3, nil
 	} else if _, ok := detector["swagger"]; ok {
 		// Swagger 2.0 - Still load from data as loads.Analyzed expects bytes
@@ -104,6 +179,10 @@ func LoadSwagger(location string) (interface{}, string, error) {
 		if err != nil {
 			return nil, "", fmt.Errorf("failed to load or validate Swagger v2 spec from '%s': %w", location, err)
 		}
+		recordOperationOrder(doc.Spec(), order)
+		if err := applySpecTransforms(doc.Spec(), opts.Transforms, location); err != nil {
+			return nil, "", err
+		}
 		return doc.Spec(), VersionV2, nil
 	} else {
 		return nil, "", fmt.Errorf("failed to detect OpenAPI/Swagger version in '%s': missing 'openapi' or 'swagger' key", location)
@@ -112,22 +191,38 @@ func LoadSwagger(location string) (interface{}, string, error) {
 
 // GenerateToolSet converts a loaded spec (v2 or v3) into an MCP ToolSet.
 func GenerateToolSet(specDoc interface{}, version string, cfg *config.Config) (*mcp.ToolSet, error) {
+	var toolSet *mcp.ToolSet
+	var err error
 	switch version {
 	case VersionV3:
 		docV3, ok := specDoc.(*openapi3.T)
 		if !ok {
 			return nil, fmt.Errorf("internal error: expected *openapi3.T for v3 spec, got %T", specDoc)
 		}
-		return generateToolSetV3(docV3, cfg)
+		toolSet, err = generateToolSetV3(docV3, cfg)
+		if err == nil {
+			toolSet.SpecVersion, toolSet.SpecOperations = "OpenAPI "+docV3.OpenAPI, specOperationCountV3(docV3)
+		}
 	case VersionV2:
 		docV2, ok := specDoc.(*spec.Swagger)
 		if !ok {
 			return nil, fmt.Errorf("internal error: expected *spec.Swagger for v2 spec, got %T", specDoc)
 		}
-		return generateToolSetV2(docV2, cfg)
+		toolSet, err = generateToolSetV2(docV2, cfg)
+		if err == nil {
+			toolSet.SpecVersion, toolSet.SpecOperations = "Swagger "+docV2.Swagger, specOperationCountV2(docV2)
+		}
 	default:
 		return nil, fmt.Errorf("unsupported specification version: %s", version)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyPathOverrides(toolSet, cfg); err != nil {
+		return nil, err
+	}
+	return toolSet, nil
 }
 
 // --- V3 Specific Implementation ---
@@ -143,19 +238,30 @@ func generateToolSetV3(doc *openapi3.T, cfg *config.Config) (*mcp.ToolSet, error
 		baseURL = "" // Allow proceeding if override is set
 	}
 
+	toolSet.SecuritySchemes = securitySchemesV3(doc)
+
 	// // V3 Handles security differently (Components.SecuritySchemes). Rely on config flags for server-side injection.
 	// apiKeyName := cfg.APIKeyName
 	// apiKeyIn := string(cfg.APIKeyLocation)
 	// // Store detected/configured key details internally - Let config handle this
 	// toolSet.SetAPIKeyDetails(apiKeyName, apiKeyIn)
 
+	skipped, err := duplicateOperationSkips(operationIDUsesV3(doc), cfg.DuplicateOperationIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	toolOperations := make(map[string]operationRef) // Where each tool is in the spec, see sortToolsInSpecOrder
 	paths := getSortedPathsV3(doc.Paths)
 	for _, rawPath := range paths { // Rename loop var to rawPath
 		pathItem := doc.Paths.Value(rawPath)
-		for method, op := range pathItem.Operations() {
-			if op == nil || !shouldIncludeOperationV3(op, cfg) {
+		operations := pathItem.Operations()
+		for _, method := range sortedMethodsV3(operations) {
+			op := operations[method]
+			if op == nil || skipped[operationRef{method: method, path: rawPath}] || !shouldIncludeOperationV3(op, method, rawPath, cfg) {
 				continue
 			}
+			opBaseURL := operationBaseURLV3(op, pathItem, method, rawPath, baseURL, cfg)
 
 			// Clean the path
 			cleanPath := rawPath
@@ -164,6 +270,7 @@ func generateToolSetV3(doc *openapi3.T, cfg *config.Config) (*mcp.ToolSet, error
 			}
 
 			toolName := generateToolNameV3(op, method, rawPath) // Still generate name from raw path
+			toolName = finalizeToolName(toolName, cfg, toolSet.Operations)
 			toolDesc := getOperationDescriptionV3(op)
 
 			// Convert parameters (query, header, path, cookie)
@@ -173,37 +280,41 @@ func generateToolSetV3(doc *openapi3.T, cfg *config.Config) (*mcp.ToolSet, error
 			}
 
 			// Handle request body
+			var requestBodySchema *mcp.Schema
+			bodyWrapper := ""
+			rawBodyMediaType := rawBodyMediaTypeV3(op.RequestBody)
 			requestBody, err := requestBodyToMCPV3(op.RequestBody)
 			if err != nil {
 				log.Printf("Warning: skipping request body for %s %s due to error: %v", method, rawPath, err)
+			} else if rawBodyMediaType != "" {
+				// Raw bytes can't travel in JSON arguments, so the tool takes them base64 encoded
+				addRawBodyProperty(&parametersSchema, rawBodyMediaType, requestBody.Required)
 			} else {
-				// Merge request body schema into the main parameter schema
-				if requestBody.Content != nil {
+				// Merge request body schema into the main parameter schema. requestBodyToMCPV3 keeps
+				// the schema of one media type, which is unwrapped once when asked
+				if mediaTypeSchema, ok := requestBody.Content["application/json"]; ok {
+					bodySchema := mediaTypeSchema
+					requestBodySchema = &bodySchema
+					if shouldUnwrapBody(toolName, op.Extensions, cfg) {
+						mediaTypeSchema, bodyWrapper = unwrapBodySchema(toolName, mediaTypeSchema)
+					}
 					if parametersSchema.Properties == nil {
 						parametersSchema.Properties = make(map[string]mcp.Schema)
 					}
-					for _, mediaTypeSchema := range requestBody.Content {
-						if mediaTypeSchema.Type == "object" && mediaTypeSchema.Properties != nil {
-							for propName, propSchema := range mediaTypeSchema.Properties {
-								parametersSchema.Properties[propName] = propSchema
-							}
-						} else {
-							// If body is not an object, represent as 'requestBody'
-							log.Printf("Warning: V3 request body for %s %s is not an object schema. Representing as 'requestBody' field.", method, rawPath)
-							parametersSchema.Properties["requestBody"] = mediaTypeSchema
+					if mediaTypeSchema.Type == "object" && mediaTypeSchema.Properties != nil {
+						for propName, propSchema := range mediaTypeSchema.Properties {
+							parametersSchema.Properties[propName] = propSchema
 						}
-						break // Only process the first content type
+						// Body fields are merged flat, so the body's policy on extra fields applies
+						parametersSchema.AdditionalProperties = mediaTypeSchema.AdditionalProperties
+					} else {
+						// If body is not an object, represent as 'requestBody'
+						log.Printf("Warning: V3 request body for %s %s is not an object schema. Representing as 'requestBody' field.", method, rawPath)
+						parametersSchema.Properties["requestBody"] = mediaTypeSchema
 					}
 
 					// Merge required fields from the body *schema* (not the requestBody boolean)
-					var bodySchemaRequired []string
-					for _, mediaTypeSchema := range requestBody.Content {
-						if len(mediaTypeSchema.Required) > 0 {
-							bodySchemaRequired = mediaTypeSchema.Required
-							break // Use required from the first content type with a schema
-						}
-					}
-
+					bodySchemaRequired := mediaTypeSchema.Required
 					if len(bodySchemaRequired) > 0 {
 						if parametersSchema.Required == nil {
 							parametersSchema.Required = make([]string, 0)
@@ -228,22 +339,65 @@ func generateToolSetV3(doc *openapi3.T, cfg *config.Config) (*mcp.ToolSet, error
 			// Prepend note about API key handling
 			finalToolDesc := "Note: The API key is supplied by the server, no need to provide it. " + toolDesc
 
+			tagArguments := tagPinnedArguments(toolName, op.Tags, cfg)
+			hideTagPinnedParameters(&parametersSchema, tagArguments)
+			hideParameters(toolName, &parametersSchema, cfg)
+			argumentAliases, err := aliasParameters(toolName, &parametersSchema, cfg)
+			if err != nil {
+				return nil, err
+			}
+			addFormatHints(&parametersSchema)
+			if strictInputProperties(toolName, cfg) {
+				disallowAdditionalProperties(&parametersSchema)
+			}
+			if resource, ok := limitInputSchemaDepth(toolName, &parametersSchema, cfg); ok {
+				toolSet.Resources = append(toolSet.Resources, resource)
+			}
+
 			tool := mcp.Tool{
 				Name:        toolName,
-				Description: finalToolDesc,    // Use potentially modified description
-				InputSchema: parametersSchema, // Use InputSchema, assuming it contains combined params/body
+				Description: capToolDescription(finalToolDesc, cfg), // Capped once every note is added
+				InputSchema: parametersSchema,                       // Use InputSchema, assuming it contains combined params/body
+				Annotations: generateToolAnnotations(method, op.Extensions),
 			}
 			toolSet.Tools = append(toolSet.Tools, tool)
+			toolOperations[toolName] = operationRef{method: method, path: rawPath}
 
 			// Store operation details for execution
 			toolSet.Operations[toolName] = mcp.OperationDetail{
-				Method:     method,
-				Path:       cleanPath, // Use the cleaned path here
-				BaseURL:    baseURL,
-				Parameters: opParams,
+				Method:       method,
+				Path:         cleanPath, // Use the cleaned path here
+				BaseURL:      opBaseURL,
+				Parameters:   opParams,
+				RequestBody:  requestBodySchema,
+				Responses:    responsesToMCPV3(op.Responses),
+				Security:     securityRequirementsV3(op, doc),
+				Examples:     responseExamplesV3(op.Responses),
+				Timeout:      operationTimeout(op.Extensions),
+				MaxResponse:  operationMaxResponseBytes(op.Extensions),
+				Concurrency:  operationMaxConcurrency(op.Extensions),
+				Streaming:    isStreamingOperation(op.Extensions, successMediaTypesV3(op.Responses)),
+				Accept:       defaultAcceptHeader(successMediaTypesV3(op.Responses)),
+				Idempotency:  usesIdempotencyKey(op.Extensions, opParams, cfg.GetIdempotencyKeyHeader()),
+				BodyWrapper:  bodyWrapper,
+				Deprecated:   op.Deprecated,
+				Sunset:       operationSunset(op.Extensions),
+				Confirm:      requiresConfirmation(op.Extensions),
+				HealthCheck:  isHealthCheck(op.Extensions),
+				Tags:         op.Tags,
+				TagArguments: tagArguments,
+
+				ArgumentAliases:  argumentAliases,
+				RawBodyMediaType: rawBodyMediaType,
+				BodyMediaTypes:   requestBodyMediaTypesV3(op.RequestBody),
 			}
+
+			// Callbacks are requests the API sends, so they are described rather than callable
+			toolSet.Resources = append(toolSet.Resources, callbackResourcesV3(toolName, method, rawPath, op)...)
 		}
 	}
+	toolSet.Resources = append(toolSet.Resources, webhookResourcesV3(doc)...)
+	sortToolsInSpecOrder(toolSet, toolOperations, recordedOperationOrder(doc.Extensions))
 	return toolSet, nil
 }
 
@@ -252,23 +406,11 @@ func determineBaseURLV3(doc *openapi3.T, cfg *config.Config) (string, error) {
 		return strings.TrimSuffix(cfg.ServerBaseURL, "/"), nil
 	}
 	if len(doc.Servers) > 0 {
-		baseURL := ""
-		for _, server := range doc.Servers {
-			if baseURL == "" {
-				baseURL = server.URL
-			}
-			if strings.HasPrefix(strings.ToLower(server.URL), "https://") {
-				baseURL = server.URL
-				break
-			}
-			if strings.HasPrefix(strings.ToLower(server.URL), "http://") {
-				baseURL = server.URL
-			}
-		}
+		baseURL := chooseServerURLV3(doc.Servers)
 		if baseURL == "" {
 			return "", fmt.Errorf("v3: could not determine a suitable base URL from servers list")
 		}
-		return strings.TrimSuffix(baseURL, "/"), nil
+		return baseURL, nil
 	}
 	return "", fmt.Errorf("v3: no server base URL specified in config or OpenAPI spec servers list")
 }
@@ -289,7 +431,7 @@ func generateToolNameV3(op *openapi3.Operation, method, path string) string {
 	if op.OperationID != "" {
 		return op.OperationID
 	}
-	return generateDefaultToolName(method, path)
+	return syntheticOperationID(method, path)
 }
 
 func getOperationDescriptionV3(op *openapi3.Operation) string {
@@ -299,8 +441,11 @@ func getOperationDescriptionV3(op *openapi3.Operation) string {
 	return op.Description
 }
 
-func shouldIncludeOperationV3(op *openapi3.Operation, cfg *config.Config) bool {
-	return shouldInclude(op.OperationID, op.Tags, cfg)
+// shouldIncludeOperationV3 applies the filters; operation filters match the synthetic ID when the
+// operation has no operationId.
+func shouldIncludeOperationV3(op *openapi3.Operation, method, path string, cfg *config.Config) bool {
+	return methodAllowed(method, path, cfg) && readOnlyAllowed(method, path, op.Extensions, cfg) &&
+		matchesPathPrefix(path, cfg.PathPrefixes) && shouldInclude(generateToolNameV3(op, method, path), op.Tags, cfg)
 }
 
 // parametersToMCPSchemaAndDetailsV3 converts parameters and also returns the parameter details.
@@ -328,8 +473,11 @@ func parametersToMCPSchemaAndDetailsV3(params openapi3.Parameters, cfg *config.C
 		// Decision: Keep storing *all* params in opParams for potential server-side use,
 		//           but skip adding the API key to the mcpSchema exposed to the client.
 		opParams = append(opParams, mcp.ParameterDetail{
-			Name: param.Name,
-			In:   param.In,
+			Name:         param.Name,
+			In:           param.In,
+			Style:        param.Style,
+			Explode:      param.Explode,
+			BooleanStyle: booleanStyle(param.Extensions),
 		})
 
 		propSchema, err := openapiSchemaToMCPSchemaV3(param.Schema)
@@ -337,6 +485,7 @@ func parametersToMCPSchemaAndDetailsV3(params openapi3.Parameters, cfg *config.C
 			return mcp.Schema{}, nil, fmt.Errorf("v3 param '%s': %w", param.Name, err)
 		}
 		propSchema.Description = param.Description
+		propSchema.Hidden = propSchema.Hidden || isHidden(param.Extensions)
 		mcpSchema.Properties[param.Name] = propSchema
 		if param.Required {
 			mcpSchema.Required = append(mcpSchema.Required, param.Name)
@@ -361,11 +510,9 @@ func requestBodyToMCPV3(rbRef *openapi3.RequestBodyRef) (mcp.RequestBody, error)
 	var chosenMediaTypeKey string
 	if mt, ok := rb.Content["application/json"]; ok {
 		mediaType, chosenMediaTypeKey = mt, "application/json"
-	} else {
-		for key, mt := range rb.Content {
-			mediaType, chosenMediaTypeKey = mt, key
-			break
-		}
+	} else if mediaTypes := requestBodyMediaTypesV3(rbRef); len(mediaTypes) > 0 {
+		// The first in name order, so the tool's arguments don't change from one load to the next
+		mediaType, chosenMediaTypeKey = rb.Content[mediaTypes[0]], mediaTypes[0]
 	}
 
 	if mediaType != nil && mediaType.Schema != nil {
@@ -380,6 +527,184 @@ func requestBodyToMCPV3(rbRef *openapi3.RequestBodyRef) (mcp.RequestBody, error)
 	return mcpRB, nil
 }
 
+// responsesToMCPV3 converts the operation's responses into schemas keyed by status code.
+// Responses without content are kept with just their description.
+func responsesToMCPV3(responses *openapi3.Responses) map[string]mcp.Schema {
+	if responses == nil || responses.Len() == 0 {
+		return nil
+	}
+	result := make(map[string]mcp.Schema, responses.Len())
+	for status, respRef := range responses.Map() {
+		if respRef == nil || respRef.Value == nil {
+			continue
+		}
+		resp := respRef.Value
+		respSchema := mcp.Schema{}
+		mediaType := preferredMediaTypeV3(resp.Content)
+		if mediaType != nil && mediaType.Schema != nil {
+			converted, err := openapiSchemaToMCPSchemaV3(mediaType.Schema)
+			if err != nil {
+				log.Printf("Warning: skipping schema for response %s: %v", status, err)
+			} else {
+				respSchema = converted
+			}
+		}
+		if respSchema.Description == "" && resp.Description != nil {
+			respSchema.Description = *resp.Description
+		}
+		result[status] = respSchema
+	}
+	return result
+}
+
+// preferredMediaTypeV3 picks application/json from a content map, falling back to the first
+// media type in name order.
+func preferredMediaTypeV3(content openapi3.Content) *openapi3.MediaType {
+	if mt, ok := content["application/json"]; ok {
+		return mt
+	}
+	names := make([]string, 0, len(content))
+	for name := range content {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return nil
+	}
+	return content[names[0]]
+}
+
+// responseExamplesV3 collects the declared example for each response, keyed by status code.
+// Media-type examples take precedence over schema-level examples.
+func responseExamplesV3(responses *openapi3.Responses) map[string]interface{} {
+	if responses == nil {
+		return nil
+	}
+	result := make(map[string]interface{})
+	for status, respRef := range responses.Map() {
+		if respRef == nil || respRef.Value == nil {
+			continue
+		}
+		mediaType := preferredMediaTypeV3(respRef.Value.Content)
+		if mediaType == nil {
+			continue
+		}
+		if mediaType.Example != nil {
+			result[status] = mediaType.Example
+			continue
+		}
+		if len(mediaType.Examples) > 0 {
+			names := make([]string, 0, len(mediaType.Examples))
+			for name := range mediaType.Examples {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			if exampleRef := mediaType.Examples[names[0]]; exampleRef != nil && exampleRef.Value != nil && exampleRef.Value.Value != nil {
+				result[status] = exampleRef.Value.Value
+				continue
+			}
+		}
+		if mediaType.Schema != nil && mediaType.Schema.Value != nil && mediaType.Schema.Value.Example != nil {
+			result[status] = mediaType.Schema.Value.Example
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// successMediaTypesV3 lists the media types declared by the operation's 2xx responses.
+func successMediaTypesV3(responses *openapi3.Responses) []string {
+	if responses == nil {
+		return nil
+	}
+	var mediaTypes []string
+	for status, respRef := range responses.Map() {
+		if !strings.HasPrefix(status, "2") || respRef == nil || respRef.Value == nil {
+			continue
+		}
+		for mediaType := range respRef.Value.Content {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+	return mediaTypes
+}
+
+// defaultAcceptHeader builds the Accept header for an operation from its success media types:
+// the preferred JSON media type if one is declared, otherwise every declared type.
+func defaultAcceptHeader(mediaTypes []string) string {
+	if jsonType := preferredJSONMediaType(mediaTypes); jsonType != "" {
+		return jsonType
+	}
+	unique := make([]string, 0, len(mediaTypes))
+	for _, mediaType := range mediaTypes {
+		if !sliceContains(unique, mediaType) {
+			unique = append(unique, mediaType)
+		}
+	}
+	sort.Strings(unique)
+	return strings.Join(unique, ", ")
+}
+
+// preferredJSONMediaType picks the JSON media type to request from an operation's success media
+// types: application/json if declared, otherwise the first JSON-suffixed type in name order.
+// It returns "" when the operation declares no JSON representation.
+func preferredJSONMediaType(mediaTypes []string) string {
+	var candidates []string
+	for _, mediaType := range mediaTypes {
+		base := strings.ToLower(strings.TrimSpace(strings.Split(mediaType, ";")[0]))
+		if base == "application/json" {
+			return mediaType
+		}
+		if strings.HasSuffix(base, "+json") || strings.HasSuffix(base, "/json") {
+			candidates = append(candidates, mediaType)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.Strings(candidates)
+	return candidates[0]
+}
+
+// securityRequirementsV3 returns the operation's security requirements, falling back to the
+// document-level requirements when the operation doesn't declare its own.
+func securityRequirementsV3(op *openapi3.Operation, doc *openapi3.T) []map[string][]string {
+	requirements := doc.Security
+	if op.Security != nil {
+		requirements = *op.Security
+	}
+	if len(requirements) == 0 {
+		return nil
+	}
+	result := make([]map[string][]string, 0, len(requirements))
+	for _, req := range requirements {
+		result = append(result, map[string][]string(req))
+	}
+	return result
+}
+
+// securitySchemesV3 collects the document's security schemes, keyed by name.
+func securitySchemesV3(doc *openapi3.T) map[string]mcp.SecurityScheme {
+	schemes := make(map[string]mcp.SecurityScheme)
+	if doc.Components == nil {
+		return schemes
+	}
+	for name, ref := range doc.Components.SecuritySchemes {
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		schemes[name] = mcp.SecurityScheme{
+			Type:   ref.Value.Type,
+			Name:   ref.Value.Name,
+			In:     ref.Value.In,
+			Scheme: strings.ToLower(ref.Value.Scheme),
+		}
+	}
+	return schemes
+}
+
 func openapiSchemaToMCPSchemaV3(oapiSchemaRef *openapi3.SchemaRef) (mcp.Schema, error) {
 	if oapiSchemaRef == nil {
 		return mcp.Schema{Type: "string", Description: "Schema reference was nil"}, nil
@@ -399,12 +724,14 @@ func openapiSchemaToMCPSchemaV3(oapiSchemaRef *openapi3.SchemaRef) (mcp.Schema,
 		Description: oapiSchema.Description,
 		Format:      oapiSchema.Format,
 		Enum:        oapiSchema.Enum,
+		Hidden:      isHidden(oapiSchema.Extensions),
 	}
 
 	switch mcpSchema.Type {
 	case "object":
 		mcpSchema.Properties = make(map[string]mcp.Schema)
 		mcpSchema.Required = oapiSchema.Required
+		mcpSchema.AdditionalProperties = additionalPropertiesV3(oapiSchema.AdditionalProperties)
 		for name, propRef := range oapiSchema.Properties {
 			propSchema, err := openapiSchemaToMCPSchemaV3(propRef)
 			if err != nil {
@@ -462,8 +789,15 @@ func generateToolSetV2(doc *spec.Swagger, cfg *config.Config) (*mcp.ToolSet, err
 	}
 	// Store detected/configured key details internally
 	toolSet.SetAPIKeyDetails(apiKeyName, apiKeyIn)
+	toolSet.SecuritySchemes = securitySchemesV2(doc)
+
+	skipped, err := duplicateOperationSkips(operationIDUsesV2(doc), cfg.DuplicateOperationIDs)
+	if err != nil {
+		return nil, err
+	}
 
 	// --- Iterate through Paths ---
+	toolOperations := make(map[string]operationRef) // Where each tool is in the spec, see sortToolsInSpecOrder
 	paths := getSortedPathsV2(doc.Paths)
 	for _, rawPath := range paths { // Rename loop var to rawPath
 		pathItem := doc.Paths.Paths[rawPath]
@@ -477,8 +811,9 @@ func generateToolSetV2(doc *spec.Swagger, cfg *config.Config) (*mcp.ToolSet, err
 			"PATCH":   pathItem.Patch,
 		}
 
-		for method, op := range ops {
-			if op == nil || !shouldIncludeOperationV2(op, cfg) {
+		for _, method := range sortedMethodsV2(ops) {
+			op := ops[method]
+			if op == nil || skipped[operationRef{method: method, path: rawPath}] || !shouldIncludeOperationV2(op, method, rawPath, cfg) {
 				continue
 			}
 
@@ -489,28 +824,52 @@ func generateToolSetV2(doc *spec.Swagger, cfg *config.Config) (*mcp.ToolSet, err
 			}
 
 			toolName := generateToolNameV2(op, method, rawPath) // Still generate name from raw path
+			toolName = finalizeToolName(toolName, cfg, toolSet.Operations)
 			toolDesc := getOperationDescriptionV2(op)
 
+			// Resolve shared parameters/responses so they are handled exactly like inline ones
+			opParameters, err := resolveParameterRefsV2(op.Parameters, doc.Parameters)
+			if err != nil {
+				return nil, fmt.Errorf("error processing v2 parameters for %s %s: %w", method, rawPath, err)
+			}
+			opResponses := resolveResponseRefsV2(op.Responses, doc.Responses)
+
 			// Convert parameters and potential body schema
-			parametersSchema, bodySchema, opParams, err := parametersToMCPSchemaAndDetailsV2(op.Parameters, doc.Definitions, apiKeyName)
+			parametersSchema, bodySchema, opParams, err := parametersToMCPSchemaAndDetailsV2(opParameters, doc.Definitions, apiKeyName)
 			if err != nil {
 				return nil, fmt.Errorf("error processing v2 parameters for %s %s: %w", method, rawPath, err)
 			}
 
+			// A binary body is taken base64 encoded instead of being merged as JSON
+			rawBodyMediaType := rawBodyMediaTypeV2(op, doc, bodySchema)
+			if bodyParam := bodyParameterV2(opParameters); rawBodyMediaType != "" && bodyParam != nil {
+				// Replaces the property named after the body parameter
+				delete(parametersSchema.Properties, bodyParam.Name)
+				parametersSchema.Required = removeString(parametersSchema.Required, bodyParam.Name)
+				addRawBodyProperty(&parametersSchema, rawBodyMediaType, bodyParam.Required)
+				bodySchema = mcp.Schema{}
+			}
+
 			// Combine request body into parameters schema if it exists
-			if bodySchema.Type != "" { // Check if bodySchema was actually populated
-				if bodySchema.Type == "object" && bodySchema.Properties != nil {
+			mergedBody, bodyWrapper := bodySchema, ""
+			if bodySchema.Type != "" && shouldUnwrapBody(toolName, op.Extensions, cfg) {
+				mergedBody, bodyWrapper = unwrapBodySchema(toolName, bodySchema)
+			}
+			if mergedBody.Type != "" { // Check if bodySchema was actually populated
+				if mergedBody.Type == "object" && mergedBody.Properties != nil {
 					if parametersSchema.Properties == nil {
 						parametersSchema.Properties = make(map[string]mcp.Schema)
 					}
-					for propName, propSchema := range bodySchema.Properties {
+					for propName, propSchema := range mergedBody.Properties {
 						parametersSchema.Properties[propName] = propSchema
 					}
-					if len(bodySchema.Required) > 0 {
+					// Body fields are merged flat, so the body's policy on extra fields applies
+					parametersSchema.AdditionalProperties = mergedBody.AdditionalProperties
+					if len(mergedBody.Required) > 0 {
 						if parametersSchema.Required == nil {
 							parametersSchema.Required = make([]string, 0)
 						}
-						for _, r := range bodySchema.Required {
+						for _, r := range mergedBody.Required {
 							if !sliceContains(parametersSchema.Required, r) {
 								parametersSchema.Required = append(parametersSchema.Required, r)
 							}
@@ -523,30 +882,74 @@ func generateToolSetV2(doc *spec.Swagger, cfg *config.Config) (*mcp.ToolSet, err
 					if parametersSchema.Properties == nil {
 						parametersSchema.Properties = make(map[string]mcp.Schema)
 					}
-					parametersSchema.Properties["requestBody"] = bodySchema
+					parametersSchema.Properties["requestBody"] = mergedBody
 				}
 			}
 
 			// Prepend note about API key handling
 			finalToolDesc := "Note: The API key is supplied by the server, no need to provide it. " + toolDesc
 
+			tagArguments := tagPinnedArguments(toolName, op.Tags, cfg)
+			hideTagPinnedParameters(&parametersSchema, tagArguments)
+			hideParameters(toolName, &parametersSchema, cfg)
+			argumentAliases, err := aliasParameters(toolName, &parametersSchema, cfg)
+			if err != nil {
+				return nil, err
+			}
+			addFormatHints(&parametersSchema)
+			if strictInputProperties(toolName, cfg) {
+				disallowAdditionalProperties(&parametersSchema)
+			}
+			if resource, ok := limitInputSchemaDepth(toolName, &parametersSchema, cfg); ok {
+				toolSet.Resources = append(toolSet.Resources, resource)
+			}
+
 			tool := mcp.Tool{
 				Name:        toolName,
-				Description: finalToolDesc,    // Use potentially modified description
-				InputSchema: parametersSchema, // Use InputSchema, assuming it contains combined params/body
+				Description: capToolDescription(finalToolDesc, cfg), // Capped once every note is added
+				InputSchema: parametersSchema,                       // Use InputSchema, assuming it contains combined params/body
+				Annotations: generateToolAnnotations(method, op.Extensions),
 			}
 			toolSet.Tools = append(toolSet.Tools, tool)
+			toolOperations[toolName] = operationRef{method: method, path: rawPath}
+
+			var requestBodySchema *mcp.Schema
+			if bodySchema.Type != "" {
+				requestBodySchema = &bodySchema
+			}
 
 			// Store operation details for execution
 			toolSet.Operations[toolName] = mcp.OperationDetail{
-				Method:     method,
-				Path:       cleanPath, // Use the cleaned path here
-				BaseURL:    baseURL,
-				Parameters: opParams,
+				Method:       method,
+				Path:         cleanPath, // Use the cleaned path here
+				BaseURL:      baseURL,
+				Parameters:   opParams,
+				RequestBody:  requestBodySchema,
+				Responses:    responsesToMCPV2(opResponses, doc.Definitions),
+				Security:     securityRequirementsV2(op, doc),
+				Examples:     responseExamplesV2(opResponses),
+				Timeout:      operationTimeout(op.Extensions),
+				MaxResponse:  operationMaxResponseBytes(op.Extensions),
+				Concurrency:  operationMaxConcurrency(op.Extensions),
+				Streaming:    isStreamingOperation(op.Extensions, successMediaTypesV2(op, doc)),
+				Accept:       defaultAcceptHeader(successMediaTypesV2(op, doc)),
+				Idempotency:  usesIdempotencyKey(op.Extensions, opParams, cfg.GetIdempotencyKeyHeader()),
+				BodyWrapper:  bodyWrapper,
+				Deprecated:   op.Deprecated,
+				Sunset:       operationSunset(op.Extensions),
+				Confirm:      requiresConfirmation(op.Extensions),
+				HealthCheck:  isHealthCheck(op.Extensions),
+				Tags:         op.Tags,
+				TagArguments: tagArguments,
+
+				ArgumentAliases:  argumentAliases,
+				RawBodyMediaType: rawBodyMediaType,
+				BodyMediaTypes:   requestBodyMediaTypesV2(op, doc, opParameters),
 			}
 		}
 	}
 
+	sortToolsInSpecOrder(toolSet, toolOperations, recordedOperationOrder(doc.Extensions))
 	return toolSet, nil
 }
 
@@ -603,7 +1006,7 @@ func generateToolNameV2(op *spec.Operation, method, path string) string {
 	if op.ID != "" {
 		return op.ID
 	}
-	return generateDefaultToolName(method, path)
+	return syntheticOperationID(method, path)
 }
 
 func getOperationDescriptionV2(op *spec.Operation) string {
@@ -613,8 +1016,11 @@ func getOperationDescriptionV2(op *spec.Operation) string {
 	return op.Description
 }
 
-func shouldIncludeOperationV2(op *spec.Operation, cfg *config.Config) bool {
-	return shouldInclude(op.ID, op.Tags, cfg)
+// shouldIncludeOperationV2 applies the filters; operation filters match the synthetic ID when the
+// operation has no operationId.
+func shouldIncludeOperationV2(op *spec.Operation, method, path string, cfg *config.Config) bool {
+	return methodAllowed(method, path, cfg) && readOnlyAllowed(method, path, op.Extensions, cfg) &&
+		matchesPathPrefix(path, cfg.PathPrefixes) && shouldInclude(generateToolNameV2(op, method, path), op.Tags, cfg)
 }
 
 // parametersToMCPSchemaAndDetailsV2 converts V2 parameters and also returns details and request body.
@@ -649,8 +1055,9 @@ func parametersToMCPSchemaAndDetailsV2(params []spec.Parameter, definitions spec
 
 		// Add non-body param detail
 		opParams = append(opParams, mcp.ParameterDetail{
-			Name: param.Name,
-			In:   param.In, // query, header, path, formData
+			Name:         param.Name,
+			In:           param.In, // query, header, path, formData
+			BooleanStyle: booleanStyle(param.Extensions),
 		})
 
 		// Convert non-body param schema and add to mcpSchema
@@ -681,6 +1088,7 @@ func parametersToMCPSchemaAndDetailsV2(params []spec.Parameter, definitions spec
 			bodySchema.Format = bodySchemaFields.Format
 			bodySchema.Enum = bodySchemaFields.Enum
 			bodySchema.Required = bodySchemaFields.Required // Required fields from the *schema* itself
+			bodySchema.AdditionalProperties = bodySchemaFields.AdditionalProperties
 
 			// Merge bodySchema properties into the main mcpSchema
 			if bodySchema.Type == "object" && bodySchema.Properties != nil {
@@ -744,6 +1152,7 @@ func swaggerParamToMCPSchema(param *spec.Parameter, definitions spec.Definitions
 		Description: param.Description,
 		Format:      param.Format,
 		Enum:        param.Enum,
+		Hidden:      isHidden(param.Extensions),
 		// TODO: Map items for array type, map constraints (maximum, etc.)
 	}
 	if param.Type == "array" && param.Items != nil {
@@ -806,6 +1215,7 @@ func swaggerSchemaToMCPSchemaV2(oapiSchema *spec.Schema, definitions spec.Defini
 		Description: oapiSchema.Description,
 		Format:      oapiSchema.Format,
 		Enum:        oapiSchema.Enum,
+		Hidden:      isHidden(oapiSchema.Extensions),
 		// TODO: Map V2 constraints (Maximum, Minimum, etc.)
 	}
 
@@ -813,6 +1223,7 @@ func swaggerSchemaToMCPSchemaV2(oapiSchema *spec.Schema, definitions spec.Defini
 	case "object":
 		mcpSchema.Properties = make(map[string]mcp.Schema)
 		mcpSchema.Required = oapiSchema.Required
+		mcpSchema.AdditionalProperties = additionalPropertiesV2(oapiSchema.AdditionalProperties)
 		for name, propSchema := range oapiSchema.Properties {
 			// propSchema here is spec.Schema, need recursive call
 			propMCPSchema, err := swaggerSchemaToMCPSchemaV2(&propSchema, definitions)
@@ -852,6 +1263,117 @@ func swaggerSchemaToMCPSchemaV2(oapiSchema *spec.Schema, definitions spec.Defini
 	return mcpSchema, nil
 }
 
+// responsesToMCPV2 converts the operation's responses into schemas keyed by status code.
+func responsesToMCPV2(responses *spec.Responses, definitions spec.Definitions) map[string]mcp.Schema {
+	if responses == nil {
+		return nil
+	}
+	result := make(map[string]mcp.Schema)
+	convert := func(status string, resp spec.Response) {
+		respSchema := mcp.Schema{}
+		if resp.Schema != nil {
+			converted, err := swaggerSchemaToMCPSchemaV2(resp.Schema, definitions)
+			if err != nil {
+				log.Printf("Warning: skipping schema for response %s: %v", status, err)
+			} else {
+				respSchema = converted
+			}
+		}
+		if respSchema.Description == "" {
+			respSchema.Description = resp.Description
+		}
+		result[status] = respSchema
+	}
+	for code, resp := range responses.StatusCodeResponses {
+		convert(fmt.Sprintf("%d", code), resp)
+	}
+	if responses.Default != nil {
+		convert("default", *responses.Default)
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// responseExamplesV2 collects the declared example for each response, keyed by status code.
+// Response-level examples (by mime type) take precedence over schema-level examples.
+func responseExamplesV2(responses *spec.Responses) map[string]interface{} {
+	if responses == nil {
+		return nil
+	}
+	result := make(map[string]interface{})
+	collect := func(status string, resp spec.Response) {
+		if example, ok := resp.Examples["application/json"]; ok {
+			result[status] = example
+			return
+		}
+		mimeTypes := make([]string, 0, len(resp.Examples))
+		for mimeType := range resp.Examples {
+			mimeTypes = append(mimeTypes, mimeType)
+		}
+		sort.Strings(mimeTypes)
+		if len(mimeTypes) > 0 {
+			result[status] = resp.Examples[mimeTypes[0]]
+			return
+		}
+		if resp.Schema != nil && resp.Schema.Example != nil {
+			result[status] = resp.Schema.Example
+		}
+	}
+	for code, resp := range responses.StatusCodeResponses {
+		collect(fmt.Sprintf("%d", code), resp)
+	}
+	if responses.Default != nil {
+		collect("default", *responses.Default)
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// successMediaTypesV2 lists the media types the operation produces (Swagger 2 has no
+// per-response media types, so the operation or document "produces" list applies).
+func successMediaTypesV2(op *spec.Operation, doc *spec.Swagger) []string {
+	if len(op.Produces) > 0 {
+		return op.Produces
+	}
+	return doc.Produces
+}
+
+// securityRequirementsV2 returns the operation's security requirements, falling back to the
+// document-level requirements when the operation doesn't declare its own.
+func securityRequirementsV2(op *spec.Operation, doc *spec.Swagger) []map[string][]string {
+	if op.Security != nil {
+		if len(op.Security) == 0 {
+			return nil
+		}
+		return op.Security
+	}
+	if len(doc.Security) == 0 {
+		return nil
+	}
+	return doc.Security
+}
+
+// securitySchemesV2 collects the document's security definitions, keyed by name. Swagger's
+// "basic" type is mapped to the OpenAPI 3 equivalent, an http scheme named "basic".
+func securitySchemesV2(doc *spec.Swagger) map[string]mcp.SecurityScheme {
+	schemes := make(map[string]mcp.SecurityScheme)
+	for name, def := range doc.SecurityDefinitions {
+		if def == nil {
+			continue
+		}
+		scheme := mcp.SecurityScheme{Type: def.Type, Name: def.Name, In: def.In}
+		if def.Type == "basic" {
+			scheme = mcp.SecurityScheme{Type: "http", Scheme: "basic"}
+		}
+		schemes[name] = scheme
+	}
+	return schemes
+}
+
 func resolveRefV2(ref spec.Ref, definitions spec.Definitions) (*spec.Schema, error) {
 	// Simple local definition resolution
 	refStr := ref.String()
@@ -866,6 +1388,59 @@ func resolveRefV2(ref spec.Ref, definitions spec.Definitions) (*spec.Schema, err
 	return &schema, nil
 }
 
+// resolveParameterRefsV2 replaces "#/parameters/..." references with the shared parameter they name.
+func resolveParameterRefsV2(params []spec.Parameter, shared map[string]spec.Parameter) ([]spec.Parameter, error) {
+	resolved := make([]spec.Parameter, 0, len(params))
+	for _, param := range params {
+		refStr := param.Ref.String()
+		if refStr == "" {
+			resolved = append(resolved, param)
+			continue
+		}
+		if !strings.HasPrefix(refStr, "#/parameters/") {
+			return nil, fmt.Errorf("unsupported parameter $ref format: %s", refStr)
+		}
+		sharedParam, ok := shared[strings.TrimPrefix(refStr, "#/parameters/")]
+		if !ok {
+			return nil, fmt.Errorf("parameter $ref '%s' not found in parameters", refStr)
+		}
+		resolved = append(resolved, sharedParam)
+	}
+	return resolved, nil
+}
+
+// resolveResponseRefsV2 returns a copy of responses with "#/responses/..." references replaced by the
+// shared response they name. Unresolvable references are logged and left as-is.
+func resolveResponseRefsV2(responses *spec.Responses, shared map[string]spec.Response) *spec.Responses {
+	if responses == nil {
+		return nil
+	}
+	resolve := func(status string, resp spec.Response) spec.Response {
+		refStr := resp.Ref.String()
+		if refStr == "" {
+			return resp
+		}
+		if sharedResp, ok := shared[strings.TrimPrefix(refStr, "#/responses/")]; ok && strings.HasPrefix(refStr, "#/responses/") {
+			return sharedResp
+		}
+		log.Printf("Warning: could not resolve $ref '%s' for response %s", refStr, status)
+		return resp
+	}
+
+	resolved := &spec.Responses{}
+	if responses.Default != nil {
+		defaultResp := resolve("default", *responses.Default)
+		resolved.Default = &defaultResp
+	}
+	if responses.StatusCodeResponses != nil {
+		resolved.StatusCodeResponses = make(map[int]spec.Response, len(responses.StatusCodeResponses))
+		for code, resp := range responses.StatusCodeResponses {
+			resolved.StatusCodeResponses[code] = resolve(fmt.Sprintf("%d", code), resp)
+		}
+	}
+	return resolved
+}
+
 // --- Common Helper Functions ---
 
 func createBaseToolSet(title, desc string, cfg *config.Config) *mcp.ToolSet {
@@ -898,27 +1473,28 @@ func createBaseToolSet(title, desc string, cfg *config.Config) *mcp.ToolSet {
 	return toolSet
 }
 
-// generateDefaultToolName creates a name if operationId is missing.
-func generateDefaultToolName(method, path string) string {
-	pathParts := strings.Split(strings.Trim(path, "/"), "/")
-	var nameParts []string
-	nameParts = append(nameParts, strings.ToUpper(method[:1])+strings.ToLower(method[1:]))
-	for _, part := range pathParts {
-		if part == "" {
-			continue
-		}
-		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
-			paramName := strings.Trim(part, "{}")
-			nameParts = append(nameParts, "By"+strings.ToUpper(paramName[:1])+paramName[1:])
-		} else {
-			sanitizedPart := strings.ReplaceAll(part, "-", "_")
-			sanitizedPart = strings.Title(sanitizedPart) // Basic capitalization
-			nameParts = append(nameParts, sanitizedPart)
+// syntheticOperationID derives a stable operation ID for an operation without one, from the
+// lowercased method and the path's segments with parameter braces dropped, e.g. GET /users/{userId}
+// becomes get_users_userId. Characters outside [A-Za-z0-9_] become underscores. Only the method and
+// path feed in, so the ID survives edits elsewhere in the spec.
+func syntheticOperationID(method, path string) string {
+	if queryIndex := strings.Index(path, "?"); queryIndex != -1 {
+		path = path[:queryIndex]
+	}
+	parts := []string{strings.ToLower(method)}
+	for _, segment := range strings.Split(path, "/") {
+		segment = strings.Trim(segment, "{}")
+		segment = strings.Trim(nonIdentifierChars.ReplaceAllString(segment, "_"), "_")
+		if segment != "" {
+			parts = append(parts, segment)
 		}
 	}
-	return strings.Join(nameParts, "")
+	return strings.Join(parts, "_")
 }
 
+// nonIdentifierChars matches runs of characters not allowed in synthetic operation IDs.
+var nonIdentifierChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
 // shouldInclude determines if an operation should be included based on config filters.
 func shouldInclude(opID string, opTags []string, cfg *config.Config) bool {
 	// Exclusion rules take precedence
@@ -953,6 +1529,51 @@ func shouldInclude(opID string, opTags []string, cfg *config.Config) bool {
 	return false // Did not match any inclusion rule
 }
 
+// methodAllowed reports whether the operation's HTTP method is eligible for tool generation, logging
+// operations skipped because of it.
+func methodAllowed(method, path string, cfg *config.Config) bool {
+	if cfg.IsMethodAllowed(method) {
+		return true
+	}
+	if cfg.ReadOnly && !config.IsReadOnlyMethod(method) {
+		log.Printf("Skipping %s %s: read-only mode", strings.ToUpper(method), path)
+	} else {
+		log.Printf("Skipping %s %s: method not in the allowed methods list", strings.ToUpper(method), path)
+	}
+	return false
+}
+
+// readOnlyAllowed reports whether the operation may be exposed in read-only mode. Beyond the method
+// check, an operation whose x-mcp-annotations mark it as mutating (readOnlyHint false or
+// destructiveHint true) is left out, e.g. a GET that triggers a job.
+func readOnlyAllowed(method, path string, extensions map[string]interface{}, cfg *config.Config) bool {
+	if !cfg.ReadOnly {
+		return true
+	}
+	annotations := generateToolAnnotations(method, extensions)
+	if *annotations.ReadOnlyHint && !*annotations.DestructiveHint {
+		return true
+	}
+	log.Printf("Skipping %s %s: annotated as mutating (read-only mode)", strings.ToUpper(method), path)
+	return false
+}
+
+// matchesPathPrefix reports whether path falls under any of the given prefixes (OR semantics).
+// Matching is done on whole path segments, so "/public" matches "/public" and "/public/items"
+// but not "/publicity". An empty prefix list matches every path.
+func matchesPathPrefix(path string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 // mapJSONSchemaType ensures the type is one recognized by JSON Schema / MCP.
 func mapJSONSchemaType(oapiType string) string {
 	switch strings.ToLower(oapiType) { // Normalize type
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -885,7 +886,7 @@ func TestGenerateToolSet(t *testing.T) {
 								"query_flag":   {Type: "boolean"},
 								"X-Request-ID": {Type: "string"},
 								// Body param ($ref to Item) merged
-								"id":   {Type: "string", Format: "uuid"},
+								"id":   {Type: "string", Format: "uuid", Description: "format: UUID, e.g. 123e4567-e89b-12d3-a456-426614174000"},
 								"name": {Type: "string"},
 							},
 							Required: []string{"path_id", "query_flag", "id"}, // Required params + required definition props
@@ -1084,3 +1085,654 @@ func TestGenerateToolSet(t *testing.T) {
 		})
 	}
 }
+
+// V3 Spec with operations under mixed path prefixes
+const pathPrefixV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {
+    "title": "Path Prefix V3 API",
+    "version": "1.0.0"
+  },
+  "paths": {
+    "/public/items": {
+      "get": {
+        "operationId": "listPublicItems",
+        "tags": ["items"],
+        "responses": {"200": {"description": "OK"}}
+      },
+      "post": {
+        "operationId": "createPublicItem",
+        "tags": ["items", "write"],
+        "responses": {"201": {"description": "Created"}}
+      }
+    },
+    "/public": {
+      "get": {
+        "operationId": "getPublicRoot",
+        "responses": {"200": {"description": "OK"}}
+      }
+    },
+    "/publicity": {
+      "get": {
+        "operationId": "getPublicity",
+        "responses": {"200": {"description": "OK"}}
+      }
+    },
+    "/internal/admin": {
+      "get": {
+        "operationId": "getInternalAdmin",
+        "tags": ["items"],
+        "responses": {"200": {"description": "OK"}}
+      }
+    },
+    "/partners/feed": {
+      "get": {
+        "operationId": "getPartnerFeed",
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+
+// loadSpecFixture writes a spec fixture to a temp dir and loads it via LoadSwagger.
+func loadSpecFixture(t *testing.T, fileName, content string) (interface{}, string) {
+	t.Helper()
+	filePath := filepath.Join(t.TempDir(), fileName)
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+	doc, version, err := LoadSwagger(filePath)
+	require.NoError(t, err)
+	return doc, version
+}
+
+// toolNames returns the sorted names of the tools in a ToolSet.
+func toolNames(toolSet *mcp.ToolSet) []string {
+	names := make([]string, 0, len(toolSet.Tools))
+	for _, tool := range toolSet.Tools {
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestGenerateToolSet_PathPrefix(t *testing.T) {
+	doc, version := loadSpecFixture(t, "path_prefix_v3.json", pathPrefixV3SpecJSON)
+
+	tests := []struct {
+		name     string
+		cfg      *config.Config
+		expected []string
+	}{
+		{
+			name:     "No prefix includes everything",
+			cfg:      &config.Config{},
+			expected: []string{"createPublicItem", "getInternalAdmin", "getPartnerFeed", "getPublicRoot", "getPublicity", "listPublicItems"},
+		},
+		{
+			name:     "Single prefix matches whole segments only",
+			cfg:      &config.Config{PathPrefixes: []string{"/public"}},
+			expected: []string{"createPublicItem", "getPublicRoot", "listPublicItems"},
+		},
+		{
+			name:     "Trailing slash on prefix is ignored",
+			cfg:      &config.Config{PathPrefixes: []string{"/public/"}},
+			expected: []string{"createPublicItem", "getPublicRoot", "listPublicItems"},
+		},
+		{
+			name:     "Multiple prefixes use OR semantics",
+			cfg:      &config.Config{PathPrefixes: []string{"/public", "/partners"}},
+			expected: []string{"createPublicItem", "getPartnerFeed", "getPublicRoot", "listPublicItems"},
+		},
+		{
+			name:     "Prefix composes with tag and operation filters",
+			cfg:      &config.Config{PathPrefixes: []string{"/public"}, IncludeTags: []string{"items"}, ExcludeTags: []string{"write"}},
+			expected: []string{"listPublicItems"},
+		},
+		{
+			name:     "Prefix matching nothing yields no tools",
+			cfg:      &config.Config{PathPrefixes: []string{"/v2"}},
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			toolSet, err := GenerateToolSet(doc, version, tc.cfg)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, toolNames(toolSet))
+			assert.Len(t, toolSet.Operations, len(tc.expected))
+		})
+	}
+}
+
+const allowedMethodsV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Allowed Methods V3 API", "version": "1.0.0"},
+  "paths": {
+    "/items": {
+      "get": {"operationId": "listItems", "responses": {"200": {"description": "OK"}}},
+      "post": {"operationId": "createItem", "responses": {"201": {"description": "Created"}}},
+      "head": {"operationId": "headItems", "responses": {"200": {"description": "OK"}}},
+      "trace": {"operationId": "traceItems", "responses": {"200": {"description": "OK"}}}
+    }
+  }
+}`
+
+func TestGenerateToolSet_AllowedMethods(t *testing.T) {
+	doc, version := loadSpecFixture(t, "allowed_methods_v3.json", allowedMethodsV3SpecJSON)
+
+	tests := []struct {
+		name     string
+		cfg      *config.Config
+		expected []string
+	}{
+		{
+			name:     "TRACE is excluded by default",
+			cfg:      &config.Config{},
+			expected: []string{"createItem", "headItems", "listItems"},
+		},
+		{
+			name:     "TRACE is included when allowed",
+			cfg:      &config.Config{AllowedMethods: []string{"GET", "POST", "HEAD", "TRACE"}},
+			expected: []string{"createItem", "headItems", "listItems", "traceItems"},
+		},
+		{
+			name:     "Read-only allow-list",
+			cfg:      &config.Config{AllowedMethods: []string{"get", "head"}},
+			expected: []string{"headItems", "listItems"},
+		},
+		{
+			name:     "Allow-list composes with operation filters",
+			cfg:      &config.Config{AllowedMethods: []string{"GET", "TRACE"}, ExcludeOperations: []string{"listItems"}},
+			expected: []string{"traceItems"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			toolSet, err := GenerateToolSet(doc, version, tc.cfg)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, toolNames(toolSet))
+			assert.Len(t, toolSet.Operations, len(tc.expected))
+		})
+	}
+}
+
+const readOnlyV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Read-only V3 API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {
+    "/items": {
+      "get": {"operationId": "listItems", "responses": {"200": {"description": "OK"}}},
+      "post": {"operationId": "createItem", "responses": {"201": {"description": "Created"}}},
+      "options": {"operationId": "itemOptions", "responses": {"200": {"description": "OK"}}}
+    },
+    "/items/{id}": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+      "get": {"operationId": "getItem", "responses": {"200": {"description": "OK"}}},
+      "put": {"operationId": "replaceItem", "responses": {"200": {"description": "OK"}}},
+      "patch": {"operationId": "updateItem", "responses": {"200": {"description": "OK"}}},
+      "delete": {"operationId": "deleteItem", "responses": {"204": {"description": "Deleted"}}}
+    },
+    "/items/{id}/refresh": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+      "get": {
+        "operationId": "refreshItem",
+        "x-mcp-annotations": {"readOnlyHint": false},
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+
+func TestGenerateToolSet_ReadOnly(t *testing.T) {
+	doc, version := loadSpecFixture(t, "read_only_v3.json", readOnlyV3SpecJSON)
+
+	toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+	require.NoError(t, err)
+	assert.Len(t, toolSet.Tools, 8, "everything is generated without --read-only")
+
+	toolSet, err = GenerateToolSet(doc, version, &config.Config{ReadOnly: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"getItem", "itemOptions", "listItems"}, toolNames(toolSet),
+		"POST, PUT, PATCH, DELETE and GETs annotated as mutating are absent")
+	for _, name := range []string{"createItem", "replaceItem", "updateItem", "deleteItem", "refreshItem"} {
+		assert.NotContains(t, toolSet.Operations, name)
+	}
+
+	toolSet, err = GenerateToolSet(doc, version, &config.Config{ReadOnly: true, AllowedMethods: []string{"GET", "POST"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"getItem", "listItems"}, toolNames(toolSet), "a method must pass both the allow-list and read-only mode")
+}
+
+const operationDetailsV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Operation Details V3 API", "version": "1.0.0"},
+  "servers": [{"url": "http://localhost:3000"}],
+  "security": [{"apiKey": []}],
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "createPet",
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"type": "object", "properties": {"name": {"type": "string"}}}}}
+        },
+        "responses": {
+          "201": {"description": "Created", "content": {"application/json": {"schema": {"type": "object", "properties": {"id": {"type": "integer"}}}}}},
+          "default": {"description": "Unexpected error"}
+        },
+        "security": [{"oauth": ["pets:write"]}]
+      },
+      "get": {
+        "operationId": "listPets",
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+
+const operationDetailsV2SpecJSON = `{
+  "swagger": "2.0",
+  "info": {"title": "Operation Details V2 API", "version": "1.0.0"},
+  "host": "localhost:3000",
+  "security": [{"apiKey": []}],
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "createPet",
+        "parameters": [
+          {"name": "body", "in": "body", "required": true, "schema": {"type": "object", "properties": {"name": {"type": "string"}}}}
+        ],
+        "responses": {
+          "201": {"description": "Created", "schema": {"type": "object", "properties": {"id": {"type": "integer"}}}},
+          "default": {"description": "Unexpected error"}
+        },
+        "security": [{"oauth": ["pets:write"]}]
+      },
+      "get": {
+        "operationId": "listPets",
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+
+func TestGenerateToolSet_OperationDetails(t *testing.T) {
+	for _, fixture := range []struct{ fileName, content string }{
+		{"operation_details_v3.json", operationDetailsV3SpecJSON},
+		{"operation_details_v2.json", operationDetailsV2SpecJSON},
+	} {
+		t.Run(fixture.fileName, func(t *testing.T) {
+			doc, version := loadSpecFixture(t, fixture.fileName, fixture.content)
+			toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+			require.NoError(t, err)
+
+			create := toolSet.Operations["createPet"]
+			require.NotNil(t, create.RequestBody)
+			assert.Equal(t, "object", create.RequestBody.Type)
+			assert.Contains(t, create.RequestBody.Properties, "name")
+			require.Contains(t, create.Responses, "201")
+			assert.Contains(t, create.Responses["201"].Properties, "id")
+			assert.Equal(t, "Unexpected error", create.Responses["default"].Description)
+			assert.Equal(t, []map[string][]string{{"oauth": {"pets:write"}}}, create.Security)
+
+			list := toolSet.Operations["listPets"]
+			assert.Nil(t, list.RequestBody)
+			assert.Equal(t, "OK", list.Responses["200"].Description)
+			assert.Equal(t, []map[string][]string{{"apiKey": {}}}, list.Security, "document-level security applies when the operation declares none")
+		})
+	}
+}
+
+const responseExamplesV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Response Examples V3 API", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "responses": {
+          "200": {"description": "OK", "content": {"application/json": {"example": [{"name": "Rex"}]}}},
+          "404": {"description": "Missing", "content": {"application/json": {"examples": {"b": {"value": {"error": "second"}}, "a": {"value": {"error": "first"}}}}}}
+        }
+      },
+      "post": {
+        "operationId": "createPet",
+        "responses": {
+          "201": {"description": "Created", "content": {"application/json": {"schema": {"type": "object", "example": {"id": 7}}}}},
+          "204": {"description": "No content"}
+        }
+      }
+    }
+  }
+}`
+
+const responseExamplesV2SpecJSON = `{
+  "swagger": "2.0",
+  "info": {"title": "Response Examples V2 API", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "responses": {
+          "200": {"description": "OK", "examples": {"application/json": [{"name": "Rex"}]}},
+          "404": {"description": "Missing", "schema": {"type": "object", "example": {"error": "first"}}}
+        }
+      }
+    }
+  }
+}`
+
+func TestGenerateToolSet_ResponseExamples(t *testing.T) {
+	t.Run("v3", func(t *testing.T) {
+		doc, version := loadSpecFixture(t, "response_examples_v3.json", responseExamplesV3SpecJSON)
+		toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+		require.NoError(t, err)
+
+		list := toolSet.Operations["listPets"]
+		assert.Equal(t, []interface{}{map[string]interface{}{"name": "Rex"}}, list.Examples["200"])
+		assert.Equal(t, map[string]interface{}{"error": "first"}, list.Examples["404"], "named examples are picked in name order")
+
+		create := toolSet.Operations["createPet"]
+		assert.Equal(t, map[string]interface{}{"id": float64(7)}, create.Examples["201"], "schema-level example is used as a fallback")
+		assert.NotContains(t, create.Examples, "204")
+	})
+
+	t.Run("v2", func(t *testing.T) {
+		doc, version := loadSpecFixture(t, "response_examples_v2.json", responseExamplesV2SpecJSON)
+		toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+		require.NoError(t, err)
+
+		list := toolSet.Operations["listPets"]
+		assert.Equal(t, []interface{}{map[string]interface{}{"name": "Rex"}}, list.Examples["200"])
+		assert.Equal(t, map[string]interface{}{"error": "first"}, list.Examples["404"])
+	})
+}
+
+// Each fixture defines the same operation twice: once via shared component references, once inline.
+const componentRefsV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Component Refs API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "components": {
+    "parameters": {
+      "Limit": {"name": "limit", "in": "query", "required": true, "description": "Page size", "schema": {"type": "integer"}},
+      "TraceID": {"name": "X-Trace-Id", "in": "header", "description": "Trace header", "schema": {"type": "string"}}
+    },
+    "requestBodies": {
+      "Note": {"content": {"application/json": {"schema": {"type": "object", "required": ["text"], "properties": {"text": {"type": "string"}}}}}}
+    },
+    "responses": {
+      "NoteCreated": {"description": "Created", "content": {"application/json": {"schema": {"type": "object", "properties": {"id": {"type": "string"}}}, "example": {"id": "n1"}}}}
+    }
+  },
+  "paths": {
+    "/ref/notes": {
+      "post": {
+        "operationId": "createNoteRef",
+        "parameters": [{"$ref": "#/components/parameters/Limit"}, {"$ref": "#/components/parameters/TraceID"}],
+        "requestBody": {"$ref": "#/components/requestBodies/Note"},
+        "responses": {"201": {"$ref": "#/components/responses/NoteCreated"}}
+      }
+    },
+    "/inline/notes": {
+      "post": {
+        "operationId": "createNoteInline",
+        "parameters": [
+          {"name": "limit", "in": "query", "required": true, "description": "Page size", "schema": {"type": "integer"}},
+          {"name": "X-Trace-Id", "in": "header", "description": "Trace header", "schema": {"type": "string"}}
+        ],
+        "requestBody": {"content": {"application/json": {"schema": {"type": "object", "required": ["text"], "properties": {"text": {"type": "string"}}}}}},
+        "responses": {"201": {"description": "Created", "content": {"application/json": {"schema": {"type": "object", "properties": {"id": {"type": "string"}}}, "example": {"id": "n1"}}}}}
+      }
+    }
+  }
+}`
+
+const componentRefsV2SpecJSON = `{
+  "swagger": "2.0",
+  "info": {"title": "Component Refs API", "version": "1.0.0"},
+  "host": "api.example.com",
+  "parameters": {
+    "Limit": {"name": "limit", "in": "query", "required": true, "description": "Page size", "type": "integer"},
+    "TraceID": {"name": "X-Trace-Id", "in": "header", "description": "Trace header", "type": "string"},
+    "Note": {"name": "body", "in": "body", "required": true, "schema": {"type": "object", "required": ["text"], "properties": {"text": {"type": "string"}}}}
+  },
+  "responses": {
+    "NoteCreated": {"description": "Created", "schema": {"type": "object", "properties": {"id": {"type": "string"}}}, "examples": {"application/json": {"id": "n1"}}}
+  },
+  "paths": {
+    "/ref/notes": {
+      "post": {
+        "operationId": "createNoteRef",
+        "parameters": [{"$ref": "#/parameters/Limit"}, {"$ref": "#/parameters/TraceID"}, {"$ref": "#/parameters/Note"}],
+        "responses": {"201": {"$ref": "#/responses/NoteCreated"}}
+      }
+    },
+    "/inline/notes": {
+      "post": {
+        "operationId": "createNoteInline",
+        "parameters": [
+          {"name": "limit", "in": "query", "required": true, "description": "Page size", "type": "integer"},
+          {"name": "X-Trace-Id", "in": "header", "description": "Trace header", "type": "string"},
+          {"name": "body", "in": "body", "required": true, "schema": {"type": "object", "required": ["text"], "properties": {"text": {"type": "string"}}}}
+        ],
+        "responses": {"201": {"description": "Created", "schema": {"type": "object", "properties": {"id": {"type": "string"}}}, "examples": {"application/json": {"id": "n1"}}}}
+      }
+    }
+  }
+}`
+
+func TestGenerateToolSet_ComponentRefs(t *testing.T) {
+	fixtures := []struct {
+		name     string
+		fileName string
+		content  string
+	}{
+		{name: "v3", fileName: "component_refs_v3.json", content: componentRefsV3SpecJSON},
+		{name: "v2", fileName: "component_refs_v2.json", content: componentRefsV2SpecJSON},
+	}
+	for _, fixture := range fixtures {
+		t.Run(fixture.name, func(t *testing.T) {
+			doc, version := loadSpecFixture(t, fixture.fileName, fixture.content)
+			toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+			require.NoError(t, err)
+
+			tools := make(map[string]mcp.Tool)
+			for _, tool := range toolSet.Tools {
+				tools[tool.Name] = tool
+			}
+			refTool, inlineTool := tools["createNoteRef"], tools["createNoteInline"]
+			assert.Equal(t, inlineTool.InputSchema, refTool.InputSchema)
+			assert.Contains(t, refTool.InputSchema.Properties, "limit")
+			assert.Contains(t, refTool.InputSchema.Properties, "X-Trace-Id")
+			assert.Contains(t, refTool.InputSchema.Properties, "text")
+			assert.Contains(t, refTool.InputSchema.Required, "limit")
+
+			refOp, inlineOp := toolSet.Operations["createNoteRef"], toolSet.Operations["createNoteInline"]
+			assert.Equal(t, inlineOp.Parameters, refOp.Parameters)
+			assert.Equal(t, inlineOp.RequestBody, refOp.RequestBody)
+			assert.Equal(t, inlineOp.Responses, refOp.Responses)
+			assert.Equal(t, inlineOp.Examples, refOp.Examples)
+			assert.Equal(t, map[string]interface{}{"id": "n1"}, refOp.Examples["201"])
+		})
+	}
+}
+
+func TestResolveParameterRefsV2_Errors(t *testing.T) {
+	_, err := resolveParameterRefsV2([]spec.Parameter{*spec.ParamRef("#/parameters/Missing")}, nil)
+	assert.ErrorContains(t, err, "not found")
+
+	_, err = resolveParameterRefsV2([]spec.Parameter{*spec.ParamRef("other.json#/Limit")}, nil)
+	assert.ErrorContains(t, err, "unsupported parameter $ref format")
+}
+
+func TestPreferredJSONMediaType(t *testing.T) {
+	assert.Equal(t, "application/json", preferredJSONMediaType([]string{"application/xml", "application/json"}))
+	assert.Equal(t, "application/hal+json", preferredJSONMediaType([]string{"application/xml", "application/vnd.api+json", "application/hal+json"}))
+	assert.Equal(t, "", preferredJSONMediaType([]string{"application/xml", "text/csv"}))
+	assert.Equal(t, "", preferredJSONMediaType(nil))
+
+	assert.Equal(t, "application/json", defaultAcceptHeader([]string{"application/xml", "application/json"}))
+	assert.Equal(t, "image/jpeg, image/png", defaultAcceptHeader([]string{"image/png", "image/jpeg", "image/png"}))
+	assert.Equal(t, "", defaultAcceptHeader(nil))
+}
+
+func TestGenerateToolSet_ParameterStyles(t *testing.T) {
+	const specJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Styles API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {
+    "/users/{id}/{ids}": {
+      "get": {
+        "operationId": "getUsers",
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "style": "label", "schema": {"type": "integer"}},
+          {"name": "ids", "in": "path", "required": true, "style": "matrix", "explode": true, "schema": {"type": "array", "items": {"type": "integer"}}}
+        ],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+	doc, version := loadSpecFixture(t, "styles_v3.json", specJSON)
+	toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+	require.NoError(t, err)
+
+	explode := true
+	assert.Equal(t, []mcp.ParameterDetail{
+		{Name: "id", In: "path", Style: "label"},
+		{Name: "ids", In: "path", Style: "matrix", Explode: &explode},
+	}, toolSet.Operations["getUsers"].Parameters)
+}
+
+func TestSyntheticOperationID(t *testing.T) {
+	tests := []struct {
+		method, path, want string
+	}{
+		{"GET", "/users/{userId}", "get_users_userId"},
+		{"post", "/users", "post_users"},
+		{"DELETE", "/v1/user-profiles/{id}.json", "delete_v1_user_profiles_id_json"},
+		{"GET", "/search?q", "get_search"},
+		{"GET", "/", "get"},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, syntheticOperationID(tc.method, tc.path), "%s %s", tc.method, tc.path)
+	}
+}
+
+func TestGenerateToolSet_SyntheticOperationIDs(t *testing.T) {
+	const specTemplate = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Synthetic IDs API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {
+    "/users/{userId}": {
+      "get": {%s"summary": "%s", "parameters": [{"name": "userId", "in": "path", "required": true, "schema": {"type": "string"}}], "responses": {"200": {"description": "OK"}}}
+    }%s
+  }
+}`
+	toolNames := func(specJSON string, cfg *config.Config) []string {
+		doc, version := loadSpecFixture(t, "synthetic_ids_v3.json", specJSON)
+		toolSet, err := GenerateToolSet(doc, version, cfg)
+		require.NoError(t, err)
+		var names []string
+		for _, tool := range toolSet.Tools {
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+
+	original := fmt.Sprintf(specTemplate, "", "Get a user", "")
+	assert.Equal(t, []string{"get_users_userId"}, toolNames(original, &config.Config{}))
+
+	// Edits elsewhere in the spec leave the synthetic ID alone
+	edited := fmt.Sprintf(specTemplate, "", "Fetch one user", `,
+    "/groups": {"get": {"responses": {"200": {"description": "OK"}}}}`)
+	assert.ElementsMatch(t, []string{"get_users_userId", "get_groups"}, toolNames(edited, &config.Config{}))
+
+	// Operation filters match the synthetic ID
+	assert.Equal(t, []string{"get_users_userId"}, toolNames(edited, &config.Config{IncludeOperations: []string{"get_users_userId"}}))
+	assert.Equal(t, []string{"get_groups"}, toolNames(edited, &config.Config{ExcludeOperations: []string{"get_users_userId"}}))
+
+	// A real operationId takes precedence once added
+	withID := fmt.Sprintf(specTemplate, `"operationId": "getUser", `, "Get a user", "")
+	assert.Equal(t, []string{"getUser"}, toolNames(withID, &config.Config{}))
+}
+
+const securitySchemesV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Security Schemes V3 API", "version": "1.0.0"},
+  "servers": [{"url": "http://localhost:3000"}],
+  "components": {
+    "securitySchemes": {
+      "apiKey": {"type": "apiKey", "name": "X-API-Key", "in": "header"},
+      "bearer": {"type": "http", "scheme": "bearer"},
+      "oauth": {"type": "oauth2", "flows": {"clientCredentials": {"tokenUrl": "https://auth.example.com/token", "scopes": {}}}}
+    }
+  },
+  "paths": {
+    "/pets": {"get": {"operationId": "listPets", "security": [{"apiKey": [], "oauth": []}, {"bearer": []}], "responses": {"200": {"description": "OK"}}}}
+  }
+}`
+
+const securitySchemesV2SpecJSON = `{
+  "swagger": "2.0",
+  "info": {"title": "Security Schemes V2 API", "version": "1.0.0"},
+  "host": "localhost:3000",
+  "securityDefinitions": {
+    "apiKey": {"type": "apiKey", "name": "key", "in": "query"},
+    "basic": {"type": "basic"}
+  },
+  "paths": {
+    "/pets": {"get": {"operationId": "listPets", "security": [{"apiKey": [], "basic": []}], "responses": {"200": {"description": "OK"}}}}
+  }
+}`
+
+func TestGenerateToolSet_SecuritySchemes(t *testing.T) {
+	t.Run("V3", func(t *testing.T) {
+		doc, version := loadSpecFixture(t, "security_schemes_v3.json", securitySchemesV3SpecJSON)
+		toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]mcp.SecurityScheme{
+			"apiKey": {Type: "apiKey", Name: "X-API-Key", In: "header"},
+			"bearer": {Type: "http", Scheme: "bearer"},
+			"oauth":  {Type: "oauth2"},
+		}, toolSet.SecuritySchemes)
+		assert.Len(t, toolSet.Operations["listPets"].Security, 2)
+	})
+
+	t.Run("V2", func(t *testing.T) {
+		doc, version := loadSpecFixture(t, "security_schemes_v2.json", securitySchemesV2SpecJSON)
+		toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]mcp.SecurityScheme{
+			"apiKey": {Type: "apiKey", Name: "key", In: "query"},
+			"basic":  {Type: "http", Scheme: "basic"},
+		}, toolSet.SecuritySchemes)
+	})
+}
+
+func TestGenerateToolSet_Deprecation(t *testing.T) {
+	doc, version := loadSpecFixture(t, "deprecation_v3.json", `{
+  "openapi": "3.0.0",
+  "info": {"title": "Deprecation API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {
+    "/v1/orders": {"get": {"operationId": "listLegacyOrders", "deprecated": true, "x-sunset": "2026-12-31", "responses": {"200": {"description": "OK"}}}},
+    "/v2/orders": {"get": {"operationId": "listOrders", "responses": {"200": {"description": "OK"}}}}
+  }
+}`)
+	toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+	require.NoError(t, err)
+
+	legacy := toolSet.Operations["listLegacyOrders"]
+	assert.True(t, legacy.Deprecated)
+	assert.Equal(t, "2026-12-31", legacy.Sunset)
+	assert.False(t, toolSet.Operations["listOrders"].Deprecated)
+	assert.Empty(t, toolSet.Operations["listOrders"].Sunset)
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// pathPlaceholderPattern matches {param} placeholders in a path template.
+var pathPlaceholderPattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// applyPathOverrides replaces the upstream path of tools named in cfg.PathOverrides. An override
+// may only use placeholders for the operation's own path parameters (or a path API key), so a typo
+// fails at startup instead of sending a literal "{param}" upstream.
+func applyPathOverrides(toolSet *mcp.ToolSet, cfg *config.Config) error {
+	toolNames := make([]string, 0, len(cfg.PathOverrides))
+	for toolName := range cfg.PathOverrides {
+		toolNames = append(toolNames, toolName)
+	}
+	sort.Strings(toolNames)
+
+	for _, toolName := range toolNames {
+		template := cfg.PathOverrides[toolName]
+		operation, ok := toolSet.Operations[toolName]
+		if !ok {
+			return fmt.Errorf("path override for unknown tool '%s'", toolName)
+		}
+		if !strings.HasPrefix(template, "/") {
+			return fmt.Errorf("path override for tool '%s' must start with '/': %s", toolName, template)
+		}
+
+		pathParams := make(map[string]bool)
+		for _, param := range operation.Parameters {
+			if param.In == "path" {
+				pathParams[param.Name] = true
+			}
+		}
+		if cfg.APIKeyLocation == config.APIKeyLocationPath && cfg.APIKeyName != "" {
+			pathParams[cfg.APIKeyName] = true // Filled in server-side
+		}
+
+		used := make(map[string]bool)
+		for _, match := range pathPlaceholderPattern.FindAllStringSubmatch(template, -1) {
+			if !pathParams[match[1]] {
+				return fmt.Errorf("path override for tool '%s' uses {%s}, which is not a path parameter of the operation", toolName, match[1])
+			}
+			used[match[1]] = true
+		}
+		for _, param := range operation.Parameters {
+			if param.In == "path" && !used[param.Name] {
+				log.Printf("Warning: path override for tool '%s' does not use path parameter '%s'", toolName, param.Name)
+			}
+		}
+
+		log.Printf("Rewriting upstream path of tool '%s' from %s to %s", toolName, operation.Path, template)
+		operation.Path = template
+		toolSet.Operations[toolName] = operation
+	}
+	return nil
+}
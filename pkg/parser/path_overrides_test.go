@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pathOverridesV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Path Overrides API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {
+    "/api/v1/users/{userId}/orders/{orderId}": {
+      "get": {
+        "operationId": "getOrder",
+        "parameters": [
+          {"name": "userId", "in": "path", "required": true, "schema": {"type": "string"}},
+          {"name": "orderId", "in": "path", "required": true, "schema": {"type": "string"}},
+          {"name": "expand", "in": "query", "schema": {"type": "boolean"}}
+        ],
+        "responses": {"200": {"description": "OK"}}
+      }
+    },
+    "/ping": {
+      "get": {"operationId": "getPing", "responses": {"200": {"description": "OK"}}}
+    }
+  }
+}`
+
+func TestGenerateToolSet_PathOverrides(t *testing.T) {
+	doc, version := loadSpecFixture(t, "path_overrides_v3.json", pathOverridesV3SpecJSON)
+
+	t.Run("Override replaces the upstream path", func(t *testing.T) {
+		cfg := &config.Config{PathOverrides: map[string]string{"getOrder": "/users/{userId}/orders/{orderId}"}}
+		toolSet, err := GenerateToolSet(doc, version, cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "/users/{userId}/orders/{orderId}", toolSet.Operations["getOrder"].Path)
+		assert.Equal(t, "/ping", toolSet.Operations["getPing"].Path, "other tools keep their path")
+	})
+
+	tests := []struct {
+		name      string
+		overrides map[string]string
+		wantErr   string
+	}{
+		{name: "Unknown tool", overrides: map[string]string{"getOrders": "/orders"}, wantErr: "path override for unknown tool 'getOrders'"},
+		{name: "Unknown placeholder", overrides: map[string]string{"getOrder": "/users/{user}/orders/{orderId}"}, wantErr: "uses {user}, which is not a path parameter"},
+		{name: "Query parameter placeholder", overrides: map[string]string{"getOrder": "/orders/{orderId}/{expand}"}, wantErr: "uses {expand}"},
+		{name: "Relative path", overrides: map[string]string{"getPing": "ping"}, wantErr: "must start with '/'"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := GenerateToolSet(doc, version, &config.Config{PathOverrides: tc.overrides})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
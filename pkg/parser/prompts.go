@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"gopkg.in/yaml.v3"
+)
+
+// promptsFile is the layout of a prompts sidecar YAML file.
+type promptsFile struct {
+	Prompts []mcp.Prompt `yaml:"prompts"`
+}
+
+// LoadPrompts reads prompt template definitions from a sidecar YAML file. Names must be unique,
+// and every template placeholder must refer to a declared argument.
+func LoadPrompts(path string) ([]mcp.Prompt, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading prompts file '%s': %w", path, err)
+	}
+	prompts, err := parsePrompts(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompts file '%s': %w", path, err)
+	}
+	return prompts, nil
+}
+
+// parsePrompts decodes and validates prompt definitions.
+func parsePrompts(data []byte) ([]mcp.Prompt, error) {
+	var file promptsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for i, prompt := range file.Prompts {
+		if prompt.Name == "" {
+			return nil, fmt.Errorf("prompt #%d has no name", i+1)
+		}
+		if seen[prompt.Name] {
+			return nil, fmt.Errorf("duplicate prompt name '%s'", prompt.Name)
+		}
+		seen[prompt.Name] = true
+		if prompt.Template == "" {
+			return nil, fmt.Errorf("prompt '%s' has no template", prompt.Name)
+		}
+
+		declared := make(map[string]bool)
+		for _, arg := range prompt.Arguments {
+			if arg.Name == "" {
+				return nil, fmt.Errorf("prompt '%s' has an argument with no name", prompt.Name)
+			}
+			declared[arg.Name] = true
+		}
+		for _, name := range prompt.Placeholders() {
+			if !declared[name] {
+				return nil, fmt.Errorf("prompt '%s' template uses undeclared argument '%s'", prompt.Name, name)
+			}
+		}
+	}
+	return file.Prompts, nil
+}
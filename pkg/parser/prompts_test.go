@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const promptsYAML = `prompts:
+  - name: summarize_invoices
+    description: Summarize invoices for a customer
+    arguments:
+      - name: customer
+        description: Customer ID
+        required: true
+      - name: period
+    template: |
+      Summarize the invoices for customer {{customer}} during {{ period }}.
+      Highlight anything overdue for {{customer}}.
+`
+
+func TestLoadPrompts(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "prompts.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(promptsYAML), 0644))
+
+	prompts, err := LoadPrompts(filePath)
+	require.NoError(t, err)
+	require.Len(t, prompts, 1)
+	prompt := prompts[0]
+	assert.Equal(t, "summarize_invoices", prompt.Name)
+	assert.Equal(t, "Summarize invoices for a customer", prompt.Description)
+	require.Len(t, prompt.Arguments, 2)
+	assert.True(t, prompt.Arguments[0].Required)
+	assert.False(t, prompt.Arguments[1].Required)
+	assert.Equal(t, []string{"customer", "period"}, prompt.Placeholders())
+
+	text, err := prompt.Render(map[string]string{"customer": "ACME-42", "period": "Q3"})
+	require.NoError(t, err)
+	assert.Equal(t, "Summarize the invoices for customer ACME-42 during Q3.\nHighlight anything overdue for ACME-42.\n", text)
+
+	_, err = prompt.Render(map[string]string{"period": "Q3"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "customer")
+}
+
+func TestParsePrompts_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		expected string
+	}{
+		{name: "Missing name", yaml: "prompts:\n  - template: hi\n", expected: "has no name"},
+		{name: "Missing template", yaml: "prompts:\n  - name: a\n", expected: "has no template"},
+		{name: "Duplicate name", yaml: "prompts:\n  - name: a\n    template: x\n  - name: a\n    template: y\n", expected: "duplicate prompt name"},
+		{name: "Undeclared placeholder", yaml: "prompts:\n  - name: a\n    template: 'hi {{who}}'\n", expected: "undeclared argument 'who'"},
+		{name: "Malformed YAML", yaml: "prompts: [", expected: "failed to parse YAML"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parsePrompts([]byte(tc.yaml))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// inputSchemaResourcePrefix is the URI prefix of the resources holding the full input schema of a
+// tool whose generated schema was truncated.
+const inputSchemaResourcePrefix = "openapi://schemas/"
+
+// inputSchemaResourceURI is the URI of the full input schema resource for toolName.
+func inputSchemaResourceURI(toolName string) string {
+	return inputSchemaResourcePrefix + toolName + "/input"
+}
+
+// limitInputSchemaDepth replaces object schemas nested cfg.MaxInputSchemaDepth or more levels deep
+// with free-form objects, so huge request bodies stay usable. The tool's arguments are level 1;
+// array items are at the level of their array. When anything was truncated, the full schema is
+// returned as a resource that the truncation notes point to.
+func limitInputSchemaDepth(toolName string, schema *mcp.Schema, cfg *config.Config) (mcp.Resource, bool) {
+	if cfg.MaxInputSchemaDepth <= 0 {
+		return mcp.Resource{}, false
+	}
+	full, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		log.Printf("Warning: not limiting the input schema depth of tool '%s': %v", toolName, err)
+		return mcp.Resource{}, false
+	}
+
+	uri := inputSchemaResourceURI(toolName)
+	truncated := 0
+	*schema = truncateSchema(*schema, 0, cfg.MaxInputSchemaDepth, "", uri, &truncated)
+	if truncated == 0 {
+		return mcp.Resource{}, false
+	}
+	log.Printf("Note: truncated %d nested object schema(s) of tool '%s' at depth %d", truncated, toolName, cfg.MaxInputSchemaDepth)
+	return mcp.Resource{
+		URI:         uri,
+		Name:        "input schema of " + toolName,
+		Description: fmt.Sprintf("Full input schema of tool '%s', whose objects nested %d or more levels deep are listed as free-form objects.", toolName, cfg.MaxInputSchemaDepth),
+		MimeType:    "application/schema+json",
+		Text:        string(full),
+	}, true
+}
+
+// truncateSchema returns schema with object schemas at level maxDepth or deeper replaced. Maps are
+// copied rather than changed, since nested schemas may be shared with the operation details.
+func truncateSchema(schema mcp.Schema, level, maxDepth int, pointer, uri string, truncated *int) mcp.Schema {
+	if level >= maxDepth && hasNestedProperties(schema) {
+		*truncated++
+		open := true
+		note := fmt.Sprintf("Nested object truncated at depth %d; pass it as a JSON object. Its full schema is at %s in resource %s.", maxDepth, pointer, uri)
+		if schema.Type == "array" {
+			// Keep the array, with free-form items
+			items := mcp.Schema{Type: "object", AdditionalProperties: &open}
+			schema.Items = &items
+			schema.Description = strings.TrimSpace(schema.Description + " " + note)
+			return schema
+		}
+		return mcp.Schema{
+			Type:                 "object",
+			Description:          strings.TrimSpace(schema.Description + " " + note),
+			AdditionalProperties: &open,
+		}
+	}
+
+	if len(schema.Properties) > 0 {
+		properties := make(map[string]mcp.Schema, len(schema.Properties))
+		for name, property := range schema.Properties {
+			properties[name] = truncateSchema(property, level+1, maxDepth, pointer+"/properties/"+escapePointerToken(name), uri, truncated)
+		}
+		schema.Properties = properties
+	}
+	if schema.Items != nil {
+		items := truncateSchema(*schema.Items, level, maxDepth, pointer+"/items", uri, truncated)
+		schema.Items = &items
+	}
+	return schema
+}
+
+// hasNestedProperties reports whether schema is an object with properties, or an array whose
+// items are.
+func hasNestedProperties(schema mcp.Schema) bool {
+	for schema.Items != nil {
+		schema = *schema.Items
+	}
+	return len(schema.Properties) > 0
+}
+
+// escapePointerToken escapes a property name for use in a JSON pointer.
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deepBodySpecJSON has a request body nested six objects deep (l1 to l6).
+const deepBodySpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Deep API", "version": "1.0.0"},
+  "paths": {
+    "/documents": {
+      "post": {
+        "operationId": "createDocument",
+        "requestBody": {"content": {"application/json": {"schema": {
+          "type": "object",
+          "properties": {"l1": {"type": "object", "required": ["name"], "properties": {
+            "name": {"type": "string"},
+            "l2": {"type": "object", "properties": {
+              "l3": {"type": "object", "description": "Third level", "required": ["l4"], "properties": {
+                "l4": {"type": "object", "properties": {
+                  "l5": {"type": "object", "properties": {
+                    "l6": {"type": "object", "properties": {"leaf": {"type": "string"}}}
+                  }}
+                }}
+              }}
+            }}
+          }}}
+        }}}},
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  }
+}`
+
+// schemaAt follows property names from schema.
+func schemaAt(t *testing.T, schema mcp.Schema, names ...string) mcp.Schema {
+	t.Helper()
+	for _, name := range names {
+		property, ok := schema.Properties[name]
+		require.True(t, ok, "missing property %s", name)
+		schema = property
+	}
+	return schema
+}
+
+func TestGenerateToolSet_MaxInputSchemaDepth(t *testing.T) {
+	doc, version := loadSpecFixture(t, "deep.json", deepBodySpecJSON)
+
+	// Body fields are merged into the arguments, so l1 is level 1
+	toolSet, err := GenerateToolSet(doc, version, &config.Config{MaxInputSchemaDepth: 3})
+	require.NoError(t, err)
+	require.Len(t, toolSet.Tools, 1)
+	input := toolSet.Tools[0].InputSchema
+
+	l1 := schemaAt(t, input, "l1")
+	assert.Equal(t, []string{"name"}, l1.Required)
+	assert.Contains(t, l1.Properties, "name")
+	l2 := schemaAt(t, l1, "l2")
+	require.Contains(t, l2.Properties, "l3")
+
+	l3 := l2.Properties["l3"]
+	assert.Equal(t, "object", l3.Type)
+	assert.Empty(t, l3.Properties)
+	assert.Empty(t, l3.Required)
+	require.NotNil(t, l3.AdditionalProperties)
+	assert.True(t, *l3.AdditionalProperties)
+	assert.Contains(t, l3.Description, "Third level")
+	assert.Contains(t, l3.Description, "/properties/l1/properties/l2/properties/l3")
+	assert.Contains(t, l3.Description, "openapi://schemas/createDocument/input")
+
+	require.Len(t, toolSet.Resources, 1)
+	resource := toolSet.Resources[0]
+	assert.Equal(t, "openapi://schemas/createDocument/input", resource.URI)
+	assert.Equal(t, "application/schema+json", resource.MimeType)
+	var full mcp.Schema
+	require.NoError(t, json.Unmarshal([]byte(resource.Text), &full))
+	leaf := schemaAt(t, full, "l1", "l2", "l3", "l4", "l5", "l6", "leaf")
+	assert.Equal(t, "string", leaf.Type)
+
+	// The operation details keep the full body schema for request building
+	body := toolSet.Operations["createDocument"].RequestBody
+	require.NotNil(t, body)
+	assert.Contains(t, schemaAt(t, *body, "l1", "l2", "l3").Properties, "l4")
+}
+
+func TestGenerateToolSet_MaxInputSchemaDepthUnlimited(t *testing.T) {
+	doc, version := loadSpecFixture(t, "deep.json", deepBodySpecJSON)
+
+	toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+	require.NoError(t, err)
+
+	assert.Empty(t, toolSet.Resources)
+	leaf := schemaAt(t, toolSet.Tools[0].InputSchema, "l1", "l2", "l3", "l4", "l5", "l6", "leaf")
+	assert.Equal(t, "string", leaf.Type)
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"log"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+)
+
+// chooseServerURLV3 picks the base URL from a servers list: the first https server, else the last
+// http one, else the first entry. Server variables are replaced with their defaults. It returns ""
+// when no server has a URL.
+func chooseServerURLV3(servers openapi3.Servers) string {
+	baseURL := ""
+	for _, server := range servers {
+		if server == nil {
+			continue
+		}
+		serverURL := serverURLV3(server)
+		if baseURL == "" {
+			baseURL = serverURL
+		}
+		if strings.HasPrefix(strings.ToLower(serverURL), "https://") {
+			baseURL = serverURL
+			break
+		}
+		if strings.HasPrefix(strings.ToLower(serverURL), "http://") {
+			baseURL = serverURL
+		}
+	}
+	return strings.TrimSuffix(baseURL, "/")
+}
+
+// serverURLV3 returns a server's URL with each {variable} replaced by its default value.
+// Placeholders without a declared variable are left as written.
+func serverURLV3(server *openapi3.Server) string {
+	serverURL := server.URL
+	for name, variable := range server.Variables {
+		if variable != nil {
+			serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", variable.Default)
+		}
+	}
+	return serverURL
+}
+
+// operationBaseURLV3 resolves an operation's base URL with OpenAPI precedence: the operation's own
+// servers, then its path item's, then the root base URL. --base-url overrides all of them.
+func operationBaseURLV3(op *openapi3.Operation, pathItem *openapi3.PathItem, method, path, rootBaseURL string, cfg *config.Config) string {
+	if cfg.ServerBaseURL != "" {
+		return rootBaseURL
+	}
+	level, servers := "", openapi3.Servers(nil)
+	switch {
+	case op.Servers != nil && len(*op.Servers) > 0:
+		level, servers = "operation", *op.Servers
+	case len(pathItem.Servers) > 0:
+		level, servers = "path", pathItem.Servers
+	default:
+		return rootBaseURL
+	}
+	if baseURL := chooseServerURLV3(servers); baseURL != "" {
+		log.Printf("Using %s-level server %s for %s %s", level, baseURL, method, path)
+		return baseURL
+	}
+	return rootBaseURL
+}
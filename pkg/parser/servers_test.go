@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const serverOverridesV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Server Overrides V3 API", "version": "1.0.0"},
+  "servers": [{
+    "url": "https://{region}.api.example.com/v1",
+    "variables": {"region": {"default": "eu", "enum": ["eu", "us"]}}
+  }],
+  "paths": {
+    "/items": {
+      "get": {"operationId": "listItems", "responses": {"200": {"description": "OK"}}},
+      "post": {
+        "operationId": "registerCallback",
+        "servers": [{
+          "url": "https://hooks.example.com/{version}/",
+          "variables": {"version": {"default": "v2"}}
+        }],
+        "responses": {"201": {"description": "Created"}}
+      }
+    },
+    "/reports": {
+      "servers": [
+        {"url": "http://reports-legacy.example.com"},
+        {"url": "https://reports.example.com"}
+      ],
+      "get": {"operationId": "listReports", "responses": {"200": {"description": "OK"}}},
+      "post": {
+        "operationId": "createReport",
+        "servers": [{"url": "https://reports-write.example.com"}],
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  }
+}`
+
+func TestGenerateToolSet_ServerOverrides(t *testing.T) {
+	doc, version := loadSpecFixture(t, "server_overrides_v3.json", serverOverridesV3SpecJSON)
+
+	toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"listItems":        "https://eu.api.example.com/v1", // Root, with variable defaults
+		"registerCallback": "https://hooks.example.com/v2",  // Operation beats root
+		"listReports":      "https://reports.example.com",   // Path beats root, https preferred
+		"createReport":     "https://reports-write.example.com",
+	}
+	for toolName, baseURL := range expected {
+		assert.Equal(t, baseURL, toolSet.Operations[toolName].BaseURL, toolName)
+	}
+}
+
+func TestGenerateToolSet_ServerOverridesWithBaseURL(t *testing.T) {
+	doc, version := loadSpecFixture(t, "server_overrides_v3.json", serverOverridesV3SpecJSON)
+
+	toolSet, err := GenerateToolSet(doc, version, &config.Config{ServerBaseURL: "http://localhost:9000/"})
+	require.NoError(t, err)
+	for toolName, operation := range toolSet.Operations {
+		assert.Equal(t, "http://localhost:9000", operation.BaseURL, "--base-url overrides every level for %s", toolName)
+	}
+}
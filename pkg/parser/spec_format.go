@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec formats accepted by LoadOptions.SpecFormat.
+const (
+	SpecFormatAuto = "auto"
+	SpecFormatJSON = "json"
+	SpecFormatYAML = "yaml"
+)
+
+// resolveSpecFormat picks the format to parse the spec text as. A forced format is used as-is;
+// auto looks at the file extension, then the Content-Type of a fetched spec, then the content.
+// The second result describes how the format was chosen, for error messages.
+func resolveSpecFormat(forced, location, contentType string, data []byte) (string, string, error) {
+	switch strings.ToLower(forced) {
+	case SpecFormatJSON, SpecFormatYAML:
+		return strings.ToLower(forced), "forced spec format " + strings.ToLower(forced), nil
+	case "", SpecFormatAuto:
+	default:
+		return "", "", fmt.Errorf("unsupported spec format %q (use json, yaml or auto)", forced)
+	}
+
+	locationPath := location
+	if queryIndex := strings.IndexAny(locationPath, "?#"); queryIndex != -1 {
+		locationPath = locationPath[:queryIndex]
+	}
+	switch strings.ToLower(path.Ext(locationPath)) {
+	case ".json":
+		return SpecFormatJSON, "format detected from the file extension", nil
+	case ".yaml", ".yml":
+		return SpecFormatYAML, "format detected from the file extension", nil
+	}
+
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.Contains(contentType, "yaml"):
+		return SpecFormatYAML, "format detected from the Content-Type", nil
+	case strings.Contains(contentType, "json"):
+		return SpecFormatJSON, "format detected from the Content-Type", nil
+	}
+
+	// JSON documents start with an object; anything else is treated as YAML
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return SpecFormatJSON, "format detected from the content", nil
+	}
+	return SpecFormatYAML, "format detected from the content", nil
+}
+
+// specToJSON parses the spec text in the given format and returns it as JSON, which the rest of
+// the loader works with.
+func specToJSON(data []byte, format string) ([]byte, error) {
+	if format == SpecFormatJSON {
+		if !json.Valid(data) {
+			var probe interface{}
+			return nil, json.Unmarshal(data, &probe) // Reports where the text stops being JSON
+		}
+		return data, nil
+	}
+
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonCompatible(document))
+}
+
+// jsonCompatible converts YAML-decoded values to ones encoding/json accepts: mappings with
+// non-string keys (such as unquoted response codes) get their keys stringified.
+func jsonCompatible(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, element := range v {
+			v[key] = jsonCompatible(element)
+		}
+		return v
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, element := range v {
+			converted[fmt.Sprintf("%v", key)] = jsonCompatible(element)
+		}
+		return converted
+	case []interface{}:
+		for i, element := range v {
+			v[i] = jsonCompatible(element)
+		}
+		return v
+	default:
+		return value
+	}
+}
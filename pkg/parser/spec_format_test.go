@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-openapi/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const yamlV3Spec = `openapi: 3.0.0
+info:
+  title: YAML API
+  version: 1.0.0
+servers:
+  - url: https://api.example.com
+paths:
+  /ping:
+    get:
+      operationId: getPing
+      responses:
+        200:
+          description: OK
+`
+
+const yamlV2Spec = `swagger: "2.0"
+info:
+  title: YAML API
+  version: 1.0.0
+host: api.example.com
+paths:
+  /ping:
+    get:
+      operationId: getPing
+      responses:
+        200:
+          description: OK
+`
+
+// writeSpecFile writes a spec under a name whose extension says nothing about the format.
+func writeSpecFile(t *testing.T, name, content string) string {
+	t.Helper()
+	filePath := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+	return filePath
+}
+
+func TestLoadSwaggerWithOptions_SpecFormat(t *testing.T) {
+	t.Run("Forced YAML parses a YAML spec without a telling extension", func(t *testing.T) {
+		doc, version, err := LoadSwaggerWithOptions(writeSpecFile(t, "spec.txt", yamlV3Spec), LoadOptions{SpecFormat: SpecFormatYAML})
+		require.NoError(t, err)
+		assert.Equal(t, VersionV3, version)
+		assert.Equal(t, "YAML API", doc.(*openapi3.T).Info.Title)
+	})
+
+	t.Run("Forced JSON fails cleanly on YAML", func(t *testing.T) {
+		_, _, err := LoadSwaggerWithOptions(writeSpecFile(t, "spec.yaml", yamlV3Spec), LoadOptions{SpecFormat: SpecFormatJSON})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to parse JSON from")
+		assert.Contains(t, err.Error(), "(forced spec format json)")
+	})
+
+	t.Run("Auto detects YAML from the extension", func(t *testing.T) {
+		_, version, err := LoadSwaggerWithOptions(writeSpecFile(t, "spec.yml", yamlV3Spec), LoadOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, VersionV3, version)
+	})
+
+	t.Run("Auto detects YAML from the Content-Type of a URL", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/yaml")
+			w.Write([]byte(yamlV2Spec))
+		}))
+		defer server.Close()
+
+		doc, version, err := LoadSwaggerWithOptions(server.URL+"/spec", LoadOptions{SpecFormat: SpecFormatAuto})
+		require.NoError(t, err)
+		assert.Equal(t, VersionV2, version)
+		assert.Contains(t, doc.(*spec.Swagger).Paths.Paths, "/ping")
+	})
+
+	t.Run("Unknown format is rejected", func(t *testing.T) {
+		_, _, err := LoadSwaggerWithOptions(writeSpecFile(t, "spec.yaml", yamlV3Spec), LoadOptions{SpecFormat: "toml"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unsupported spec format "toml"`)
+	})
+}
+
+func TestResolveSpecFormat(t *testing.T) {
+	tests := []struct {
+		name, forced, location, contentType, data, want string
+	}{
+		{name: "Forced wins over extension", forced: "yaml", location: "spec.json", data: "{}", want: SpecFormatYAML},
+		{name: "JSON extension", location: "/specs/api.JSON", want: SpecFormatJSON},
+		{name: "YAML extension on URL with query", location: "https://example.com/api.yaml?v=2", want: SpecFormatYAML},
+		{name: "Content-Type", location: "https://example.com/spec", contentType: "text/yaml; charset=utf-8", want: SpecFormatYAML},
+		{name: "Content sniffing JSON", location: "spec", data: "  {\"openapi\": \"3.0.0\"}", want: SpecFormatJSON},
+		{name: "Content sniffing YAML", location: "spec", data: "openapi: 3.0.0", want: SpecFormatYAML},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			format, _, err := resolveSpecFormat(tc.forced, tc.location, tc.contentType, []byte(tc.data))
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, format)
+		})
+	}
+}
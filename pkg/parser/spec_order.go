@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-openapi/spec"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"gopkg.in/yaml.v3"
+)
+
+// operationOrderExtension is the document extension under which the loader records the order the
+// operations are written in. Neither loader keeps it: paths and operations are maps.
+const operationOrderExtension = "x-openapi-mcp-claude-operation-order"
+
+// specMethods are the operation fields of a path item, by their name in the spec.
+var specMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true, "options": true, "head": true, "patch": true, "trace": true,
+}
+
+// documentOperationOrder lists the operations of spec text (JSON or YAML) in the order written:
+// paths in document order, then methods in path item order. It returns nil if the text can't be
+// read as YAML.
+func documentOperationOrder(data []byte) []operationRef {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil || len(root.Content) == 0 {
+		return nil
+	}
+	paths := mappingValue(root.Content[0], "paths")
+	if paths == nil {
+		return nil
+	}
+	var order []operationRef
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		path, pathItem := paths.Content[i].Value, paths.Content[i+1]
+		if pathItem.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j+1 < len(pathItem.Content); j += 2 {
+			if method := strings.ToLower(pathItem.Content[j].Value); specMethods[method] {
+				order = append(order, operationRef{method: strings.ToUpper(method), path: path})
+			}
+		}
+	}
+	return order
+}
+
+// mappingValue returns the value of key in a YAML mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// recordOperationOrder attaches the written operation order to a loaded document.
+func recordOperationOrder(doc interface{}, order []operationRef) {
+	if len(order) == 0 {
+		return
+	}
+	switch d := doc.(type) {
+	case *openapi3.T:
+		if d.Extensions == nil {
+			d.Extensions = make(map[string]interface{})
+		}
+		d.Extensions[operationOrderExtension] = order
+	case *spec.Swagger:
+		if d.Extensions == nil {
+			d.Extensions = make(spec.Extensions)
+		}
+		d.Extensions[operationOrderExtension] = order
+	}
+}
+
+// recordedOperationOrder returns the order recorded by recordOperationOrder, or nil.
+func recordedOperationOrder(extensions map[string]interface{}) []operationRef {
+	order, _ := extensions[operationOrderExtension].([]operationRef)
+	return order
+}
+
+// sortToolsInSpecOrder puts the generated tools in the order their operations are written in.
+// Tools are generated in path, then method name order, which decides tool name collisions;
+// without a recorded order, or for operations it misses, that order is kept.
+func sortToolsInSpecOrder(toolSet *mcp.ToolSet, toolOperations map[string]operationRef, order []operationRef) {
+	if len(order) == 0 {
+		return
+	}
+	positions := make(map[operationRef]int, len(order))
+	for i, ref := range order {
+		positions[ref] = i
+	}
+	position := func(tool mcp.Tool) int {
+		if i, ok := positions[toolOperations[tool.Name]]; ok {
+			return i
+		}
+		return len(order)
+	}
+	sort.SliceStable(toolSet.Tools, func(i, j int) bool {
+		return position(toolSet.Tools[i]) < position(toolSet.Tools[j])
+	})
+}
+
+// sortedMethodsV3 returns the methods of a path item's operations in name order.
+func sortedMethodsV3(operations map[string]*openapi3.Operation) []string {
+	methods := make([]string, 0, len(operations))
+	for method := range operations {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// sortedMethodsV2 returns the methods of a path item's operations in name order.
+func sortedMethodsV2(operations map[string]*spec.Operation) []string {
+	methods := make([]string, 0, len(operations))
+	for method := range operations {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
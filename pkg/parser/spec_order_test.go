@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Paths and methods are deliberately out of alphabetical order
+const orderedSpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Ordered API", "version": "1.0.0"},
+  "paths": {
+    "/zoos": {
+      "post": {"operationId": "createZoo", "responses": {"201": {"description": "Created"}}},
+      "get": {"operationId": "listZoos", "responses": {"200": {"description": "OK"}}}
+    },
+    "/animals/{id}": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+      "delete": {"operationId": "deleteAnimal", "responses": {"204": {"description": "Deleted"}}},
+      "get": {"operationId": "getAnimal", "responses": {"200": {"description": "OK"}}}
+    },
+    "/animals": {
+      "get": {"operationId": "listAnimals", "responses": {"200": {"description": "OK"}}}
+    }
+  }
+}`
+
+const orderedSpecYAML = `swagger: "2.0"
+info: {title: Ordered API, version: "1.0.0"}
+host: api.example.com
+paths:
+  /zoos:
+    put:
+      operationId: replaceZoos
+      responses: {"200": {description: OK}}
+    get:
+      operationId: listZoos
+      responses: {"200": {description: OK}}
+  /animals:
+    get:
+      operationId: listAnimals
+      responses: {"200": {description: OK}}
+`
+
+func TestGenerateToolSet_SpecOrder(t *testing.T) {
+	doc, version := loadSpecFixture(t, "ordered.json", orderedSpecJSON)
+	toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+	require.NoError(t, err)
+
+	var names []string
+	for _, tool := range toolSet.Tools {
+		names = append(names, tool.Name)
+	}
+	assert.Equal(t, []string{"createZoo", "listZoos", "deleteAnimal", "getAnimal", "listAnimals"}, names)
+
+	// Regenerating gives the same order
+	again, err := GenerateToolSet(doc, version, &config.Config{})
+	require.NoError(t, err)
+	assert.Equal(t, toolSet.Tools, again.Tools)
+}
+
+func TestGenerateToolSet_SpecOrderV2YAML(t *testing.T) {
+	doc, version := loadSpecFixture(t, "ordered.yaml", orderedSpecYAML)
+	toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+	require.NoError(t, err)
+
+	var names []string
+	for _, tool := range toolSet.Tools {
+		names = append(names, tool.Name)
+	}
+	assert.Equal(t, []string{"replaceZoos", "listZoos", "listAnimals"}, names)
+}
+
+func TestDocumentOperationOrder(t *testing.T) {
+	order := documentOperationOrder([]byte(orderedSpecJSON))
+	assert.Equal(t, []operationRef{
+		{method: "POST", path: "/zoos"}, {method: "GET", path: "/zoos"},
+		{method: "DELETE", path: "/animals/{id}"}, {method: "GET", path: "/animals/{id}"},
+		{method: "GET", path: "/animals"},
+	}, order, "path-level fields such as parameters are not operations")
+
+	assert.Nil(t, documentOperationOrder([]byte(`{"openapi": "3.0.0"}`)))
+	assert.Nil(t, documentOperationOrder([]byte(`{not yaml`)))
+}
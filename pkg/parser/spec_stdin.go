@@ -0,0 +1,27 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// StdinSpecLocation is the spec location that reads the whole document from standard input, e.g.
+// for `cat spec.yaml | openapi-mcp-claude --spec -`. The format is detected from the content.
+const StdinSpecLocation = "-"
+
+// readSpecStdin reads the spec document from standard input until EOF.
+func readSpecStdin() ([]byte, error) {
+	log.Printf("Reading spec from standard input")
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading spec from standard input: %w", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, errors.New("failed reading spec from standard input: it is empty")
+	}
+	return data, nil
+}
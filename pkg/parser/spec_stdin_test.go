@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"os"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const stdinSpecYAML = `openapi: 3.0.0
+info:
+  title: Piped API
+  version: 1.0.0
+servers:
+  - url: https://api.example.com
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        "200":
+          description: OK
+`
+
+// pipeStdin makes content the process's standard input for the rest of the test.
+func pipeStdin(t *testing.T, content string) {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	_, err = writer.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	stdin := os.Stdin
+	os.Stdin = reader
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		reader.Close()
+	})
+}
+
+func TestLoadSwagger_Stdin(t *testing.T) {
+	t.Run("The spec is read from standard input", func(t *testing.T) {
+		pipeStdin(t, stdinSpecYAML)
+		doc, version, err := LoadSwagger(StdinSpecLocation)
+		require.NoError(t, err)
+		assert.Equal(t, VersionV3, version)
+
+		toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"listPets"}, toolNames(toolSet))
+	})
+
+	t.Run("Empty standard input fails", func(t *testing.T) {
+		pipeStdin(t, "  \n")
+		_, _, err := LoadSwagger(StdinSpecLocation)
+		assert.EqualError(t, err, "failed reading spec from standard input: it is empty")
+	})
+}
@@ -0,0 +1,34 @@
+package parser
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-openapi/spec"
+)
+
+// specOperationCountV3 counts the operations a V3 spec declares, before any filtering.
+func specOperationCountV3(doc *openapi3.T) int {
+	if doc.Paths == nil {
+		return 0
+	}
+	count := 0
+	for _, pathItem := range doc.Paths.Map() {
+		count += len(pathItem.Operations())
+	}
+	return count
+}
+
+// specOperationCountV2 counts the operations a V2 spec declares, before any filtering.
+func specOperationCountV2(doc *spec.Swagger) int {
+	if doc.Paths == nil {
+		return 0
+	}
+	count := 0
+	for _, pathItem := range doc.Paths.Paths {
+		for _, op := range []*spec.Operation{pathItem.Get, pathItem.Put, pathItem.Post, pathItem.Delete, pathItem.Options, pathItem.Head, pathItem.Patch} {
+			if op != nil {
+				count++
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,25 @@
+package parser
+
+import "fmt"
+
+// SpecTransform changes a loaded spec in memory before tools are generated from it, e.g. to strip
+// a vendor extension, rename tags or add a server. doc is the *openapi3.T of an OpenAPI 3 spec or
+// the *spec.Swagger of a Swagger 2.0 one. An error fails loading the spec.
+type SpecTransform func(doc interface{}) error
+
+// WithSpecTransform returns the options with transform added after the transforms already
+// registered. Transforms run after the spec is parsed and validated.
+func (o LoadOptions) WithSpecTransform(transform SpecTransform) LoadOptions {
+	o.Transforms = append(o.Transforms[:len(o.Transforms):len(o.Transforms)], transform)
+	return o
+}
+
+// applySpecTransforms runs transforms on doc in registration order, stopping at the first error.
+func applySpecTransforms(doc interface{}, transforms []SpecTransform, location string) error {
+	for i, transform := range transforms {
+		if err := transform(doc); err != nil {
+			return fmt.Errorf("spec transform %d failed for '%s': %w", i+1, location, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-openapi/spec"
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const transformSpecV3 = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Transform API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {
+    "/invoices": {"get": {"operationId": "listInvoices", "tags": ["legacy"], "responses": {"200": {"description": "OK"}}}}
+  }
+}`
+
+const transformSpecV2 = `{
+  "swagger": "2.0",
+  "info": {"title": "Transform API", "version": "1.0.0"},
+  "host": "api.example.com",
+  "paths": {
+    "/invoices": {"get": {"operationId": "listInvoices", "responses": {"200": {"description": "OK"}}}}
+  }
+}`
+
+func writeTransformSpec(t *testing.T, content string) string {
+	t.Helper()
+	filePath := filepath.Join(t.TempDir(), "spec.json")
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+	return filePath
+}
+
+func TestSpecTransform_AddsTag(t *testing.T) {
+	var ran []string
+	opts := LoadOptions{}.
+		WithSpecTransform(func(doc interface{}) error {
+			ran = append(ran, "rename")
+			for _, pathItem := range doc.(*openapi3.T).Paths.Map() {
+				for _, op := range pathItem.Operations() {
+					for i, tag := range op.Tags {
+						if tag == "legacy" {
+							op.Tags[i] = "invoices"
+						}
+					}
+				}
+			}
+			return nil
+		}).
+		WithSpecTransform(func(doc interface{}) error {
+			ran = append(ran, "add")
+			doc.(*openapi3.T).Paths.Value("/invoices").Get.Tags = append(doc.(*openapi3.T).Paths.Value("/invoices").Get.Tags, "billing")
+			return nil
+		})
+
+	doc, version, err := LoadSwaggerWithOptions(writeTransformSpec(t, transformSpecV3), opts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rename", "add"}, ran, "transforms run in registration order")
+
+	toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"invoices", "billing"}, toolSet.Operations["listInvoices"].Tags)
+
+	// The tag filter sees the transformed tags
+	toolSet, err = GenerateToolSet(doc, version, &config.Config{IncludeTags: []string{"billing"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"listInvoices"}, toolNames(toolSet))
+}
+
+func TestSpecTransform_V2(t *testing.T) {
+	opts := LoadOptions{}.WithSpecTransform(func(doc interface{}) error {
+		doc.(*spec.Swagger).Paths.Paths["/invoices"].Get.Tags = []string{"billing"}
+		return nil
+	})
+	doc, version, err := LoadSwaggerWithOptions(writeTransformSpec(t, transformSpecV2), opts)
+	require.NoError(t, err)
+
+	toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"billing"}, toolSet.Operations["listInvoices"].Tags)
+}
+
+func TestSpecTransform_ErrorFailsLoading(t *testing.T) {
+	ran := false
+	opts := LoadOptions{}.
+		WithSpecTransform(func(doc interface{}) error { return errors.New("unsupported vendor extension") }).
+		WithSpecTransform(func(doc interface{}) error { ran = true; return nil })
+
+	_, _, err := LoadSwaggerWithOptions(writeTransformSpec(t, transformSpecV3), opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "spec transform 1 failed")
+	assert.Contains(t, err.Error(), "unsupported vendor extension")
+	assert.False(t, ran, "later transforms don't run")
+}
+
+func TestWithSpecTransform_DoesNotShareTransforms(t *testing.T) {
+	base := LoadOptions{}.WithSpecTransform(func(doc interface{}) error { return nil })
+	first := base.WithSpecTransform(func(doc interface{}) error { return errors.New("first") })
+	second := base.WithSpecTransform(func(doc interface{}) error { return nil })
+	require.Len(t, first.Transforms, 2)
+	require.Len(t, second.Transforms, 2)
+	assert.Error(t, first.Transforms[1](nil))
+	assert.NoError(t, second.Transforms[1](nil))
+}
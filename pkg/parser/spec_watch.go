@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// SpecWatcher re-reads a spec location for hot reload and loads it only when it changed. URL specs
+// are fetched with conditional requests (If-None-Match / If-Modified-Since from the last response),
+// so an unchanged spec costs a 304 and no download; file specs are compared by content.
+type SpecWatcher struct {
+	location string
+	opts     LoadOptions
+	client   *http.Client
+
+	etag         string   // ETag of the last 200 response
+	lastModified string   // Last-Modified of the last 200 response
+	digest       [32]byte // SHA-256 of the last text loaded
+	loaded       bool     // Whether anything was loaded yet
+}
+
+// NewSpecWatcher returns a watcher for location, loading it with opts.
+func NewSpecWatcher(location string, opts LoadOptions) *SpecWatcher {
+	return &SpecWatcher{
+		location: location,
+		opts:     opts,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Check reads the spec and, if it changed since the last Check, loads it. changed is false on a
+// 304 or identical content, in which case doc is nil. The first successful Check always loads.
+func (w *SpecWatcher) Check() (doc interface{}, version string, changed bool, err error) {
+	data, resp, err := w.fetch()
+	if err != nil || data == nil {
+		return nil, "", false, err
+	}
+	digest := sha256.Sum256(data)
+	if w.loaded && digest == w.digest {
+		return nil, "", false, nil
+	}
+
+	contentType := ""
+	if resp != nil {
+		contentType = resp.Header.Get("Content-Type")
+	}
+	doc, version, err = loadSpecData(w.location, data, contentType, w.opts, true)
+	if err != nil {
+		return nil, "", false, err
+	}
+	// Validators are only kept once the spec they describe has loaded, so a broken spec is not
+	// answered with 304 on the next poll
+	if resp != nil {
+		w.etag = resp.Header.Get("ETag")
+		w.lastModified = resp.Header.Get("Last-Modified")
+	}
+	w.digest = digest
+	w.loaded = true
+	return doc, version, true, nil
+}
+
+// fetch reads the spec text, along with the response for URL specs. data is nil when a URL
+// answered 304 Not Modified.
+func (w *SpecWatcher) fetch() ([]byte, *http.Response, error) {
+	if _, isURL := specURL(w.location); !isURL {
+		data, err := os.ReadFile(w.location)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed reading file path '%s': %w", w.location, err)
+		}
+		return data, nil, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, w.location, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build request for URL '%s': %w", w.location, err)
+	}
+	if w.loaded {
+		if w.etag != "" {
+			req.Header.Set("If-None-Match", w.etag)
+		}
+		if w.lastModified != "" {
+			req.Header.Set("If-Modified-Since", w.lastModified)
+		}
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch URL '%s': %w", w.location, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		log.Printf("[SpecReload] %s not modified", w.location)
+		return nil, resp, nil
+	case http.StatusOK:
+	default:
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 1024)) // Attempt to read body for error context
+		return nil, nil, fmt.Errorf("failed to fetch URL '%s': status code %d, body: %s", w.location, resp.StatusCode, string(bytes.TrimSpace(bodyBytes)))
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response body from URL '%s': %w", w.location, err)
+	}
+	return data, resp, nil
+}
@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// watchSpecJSON is a minimal v3 spec with one GET operation.
+func watchSpecJSON(operationID string) string {
+	return fmt.Sprintf(`{
+  "openapi": "3.0.0",
+  "info": {"title": "Watched API", "version": "1.0.0"},
+  "paths": {"/items": {"get": {"operationId": %q, "responses": {"200": {"description": "OK"}}}}}
+}`, operationID)
+}
+
+// versionedSpecServer serves a spec with an ETag, answering 304 when the client already has it.
+type versionedSpecServer struct {
+	mutex      sync.Mutex
+	etag, body string
+	requests   []string // If-None-Match of every request
+}
+
+func (s *versionedSpecServer) set(etag, body string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.etag, s.body = etag, body
+}
+
+func (s *versionedSpecServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.requests = append(s.requests, r.Header.Get("If-None-Match"))
+	if r.Header.Get("If-None-Match") == s.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", s.etag)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(s.body))
+}
+
+func operationIDOf(t *testing.T, doc interface{}) string {
+	t.Helper()
+	docV3, ok := doc.(*openapi3.T)
+	require.True(t, ok, "expected a v3 document, got %T", doc)
+	return docV3.Paths.Value("/items").Get.OperationID
+}
+
+func TestSpecWatcher_URLConditionalRequests(t *testing.T) {
+	specServer := &versionedSpecServer{}
+	specServer.set(`"v1"`, watchSpecJSON("listItems"))
+	backend := httptest.NewServer(specServer)
+	defer backend.Close()
+
+	watcher := NewSpecWatcher(backend.URL+"/openapi.json", LoadOptions{})
+
+	doc, version, changed, err := watcher.Check()
+	require.NoError(t, err)
+	assert.True(t, changed, "the first check always loads")
+	assert.Equal(t, VersionV3, version)
+	assert.Equal(t, "listItems", operationIDOf(t, doc))
+
+	doc, _, changed, err = watcher.Check()
+	require.NoError(t, err)
+	assert.False(t, changed, "a 304 is not a change")
+	assert.Nil(t, doc)
+
+	specServer.set(`"v2"`, watchSpecJSON("listAllItems"))
+	doc, _, changed, err = watcher.Check()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "listAllItems", operationIDOf(t, doc))
+
+	assert.Equal(t, []string{"", `"v1"`, `"v1"`}, specServer.requests, "validators of the last loaded spec are sent back")
+}
+
+func TestSpecWatcher_BrokenSpecIsRefetched(t *testing.T) {
+	specServer := &versionedSpecServer{}
+	specServer.set(`"v1"`, watchSpecJSON("listItems"))
+	backend := httptest.NewServer(specServer)
+	defer backend.Close()
+
+	watcher := NewSpecWatcher(backend.URL+"/openapi.json", LoadOptions{})
+	_, _, _, err := watcher.Check()
+	require.NoError(t, err)
+
+	specServer.set(`"v2"`, `{"openapi": "3.0.0", "paths": `)
+	_, _, changed, err := watcher.Check()
+	assert.Error(t, err)
+	assert.False(t, changed)
+
+	// The broken version's ETag was not kept, so the fixed spec is fetched in full
+	specServer.set(`"v2"`, watchSpecJSON("listAllItems"))
+	doc, _, changed, err := watcher.Check()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "listAllItems", operationIDOf(t, doc))
+}
+
+func TestSpecWatcher_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "openapi.json")
+	require.NoError(t, os.WriteFile(path, []byte(watchSpecJSON("listItems")), 0644))
+	watcher := NewSpecWatcher(path, LoadOptions{})
+
+	_, _, changed, err := watcher.Check()
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	_, _, changed, err = watcher.Check()
+	require.NoError(t, err)
+	assert.False(t, changed, "identical content is not a change")
+
+	require.NoError(t, os.WriteFile(path, []byte(watchSpecJSON("listAllItems")), 0644))
+	doc, _, changed, err := watcher.Check()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "listAllItems", operationIDOf(t, doc))
+}
@@ -0,0 +1,33 @@
+package parser
+
+import (
+	"log"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+)
+
+// tagPinnedArguments collects the arguments pinned for an operation's tags. When two tags pin the
+// same argument, the tag listed first on the operation wins.
+func tagPinnedArguments(toolName string, tags []string, cfg *config.Config) map[string]interface{} {
+	if len(cfg.TagPinnedArguments) == 0 {
+		return nil
+	}
+	var pinned map[string]interface{}
+	pinnedBy := make(map[string]string)
+	for _, tag := range tags {
+		for name, value := range cfg.TagPinnedArguments[tag] {
+			if owner, seen := pinnedBy[name]; seen {
+				if owner != tag {
+					log.Printf("Warning: tool '%s' gets argument '%s' pinned by tags '%s' and '%s'; using the value for '%s'", toolName, name, owner, tag, owner)
+				}
+				continue
+			}
+			if pinned == nil {
+				pinned = make(map[string]interface{})
+			}
+			pinned[name] = value
+			pinnedBy[name] = tag
+		}
+	}
+	return pinned
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tagPinsV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Tenant API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {
+    "/projects": {
+      "get": {
+        "operationId": "listProjects",
+        "tags": ["tenant-scoped"],
+        "parameters": [
+          {"name": "X-Tenant-Id", "in": "header", "required": true, "schema": {"type": "string"}},
+          {"name": "limit", "in": "query", "schema": {"type": "integer"}}
+        ],
+        "responses": {"200": {"description": "OK"}}
+      },
+      "post": {
+        "operationId": "createProject",
+        "tags": ["tenant-scoped", "projects"],
+        "parameters": [
+          {"name": "X-Tenant-Id", "in": "header", "required": true, "schema": {"type": "string"}}
+        ],
+        "requestBody": {"content": {"application/json": {"schema": {
+          "type": "object",
+          "required": ["name"],
+          "properties": {"name": {"type": "string"}}
+        }}}},
+        "responses": {"201": {"description": "Created"}}
+      }
+    },
+    "/health": {
+      "get": {
+        "operationId": "getHealth",
+        "parameters": [
+          {"name": "X-Tenant-Id", "in": "header", "schema": {"type": "string"}}
+        ],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+
+func TestGenerateToolSet_TagPinnedArguments(t *testing.T) {
+	cfg := &config.Config{TagPinnedArguments: map[string]map[string]interface{}{
+		"tenant-scoped": {"X-Tenant-Id": "acme"},
+	}}
+	doc, version := loadSpecFixture(t, "tag_pins_v3.json", tagPinsV3SpecJSON)
+	toolSet, err := GenerateToolSet(doc, version, cfg)
+	require.NoError(t, err)
+	schemas := inputSchemas(t, "tag_pins_v3.json", tagPinsV3SpecJSON, cfg)
+
+	for _, toolName := range []string{"listProjects", "createProject"} {
+		assert.NotContains(t, schemas[toolName].Properties, "X-Tenant-Id", "%s hides the tag-pinned header", toolName)
+		assert.NotContains(t, schemas[toolName].Required, "X-Tenant-Id", toolName)
+		assert.Equal(t, map[string]interface{}{"X-Tenant-Id": "acme"}, toolSet.Operations[toolName].TagArguments, toolName)
+	}
+	assert.Contains(t, schemas["listProjects"].Properties, "limit")
+	assert.Equal(t, []string{"name"}, schemas["createProject"].Required)
+
+	assert.Contains(t, schemas["getHealth"].Properties, "X-Tenant-Id", "operations without the tag are untouched")
+	assert.Nil(t, toolSet.Operations["getHealth"].TagArguments)
+}
+
+func TestTagPinnedArguments_FirstTagWins(t *testing.T) {
+	cfg := &config.Config{TagPinnedArguments: map[string]map[string]interface{}{
+		"tenant-scoped": {"X-Tenant-Id": "acme"},
+		"projects":      {"X-Tenant-Id": "other", "region": "eu"},
+	}}
+
+	var pinned map[string]interface{}
+	output := captureLog(t, func() {
+		pinned = tagPinnedArguments("createProject", []string{"tenant-scoped", "projects"}, cfg)
+	})
+	assert.Equal(t, map[string]interface{}{"X-Tenant-Id": "acme", "region": "eu"}, pinned)
+	assert.Contains(t, output, "pinned by tags 'tenant-scoped' and 'projects'")
+
+	assert.Nil(t, tagPinnedArguments("getHealth", nil, cfg))
+	assert.Nil(t, tagPinnedArguments("listProjects", []string{"tenant-scoped"}, &config.Config{}))
+}
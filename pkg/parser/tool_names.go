@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// maxToolNameLength is the longest tool name MCP clients reliably accept.
+const maxToolNameLength = 64
+
+// toolNameAffixPattern matches the characters allowed in MCP tool names.
+var toolNameAffixPattern = regexp.MustCompile(`^[A-Za-z0-9_-]*$`)
+
+// ValidateToolNameAffixes checks that a tool name prefix and suffix keep names valid MCP
+// identifiers and leave room for the generated part of the name.
+func ValidateToolNameAffixes(prefix, suffix string) error {
+	for _, affix := range []string{prefix, suffix} {
+		if !toolNameAffixPattern.MatchString(affix) {
+			return fmt.Errorf("tool name affix %q may only contain letters, digits, '_' and '-'", affix)
+		}
+	}
+	if len(prefix)+len(suffix) >= maxToolNameLength {
+		return fmt.Errorf("tool name prefix and suffix must be shorter than %d characters together", maxToolNameLength)
+	}
+	return nil
+}
+
+// finalizeToolName applies the configured prefix and suffix to a generated tool name, then resolves
+// collisions with already-generated tools by appending _2, _3, ... The generated part is shortened
+// when needed to keep affixed names within maxToolNameLength.
+func finalizeToolName(baseName string, cfg *config.Config, taken map[string]mcp.OperationDetail) string {
+	name := affixToolName(baseName, "", cfg)
+	if _, exists := taken[name]; !exists {
+		return name
+	}
+	for n := 2; ; n++ {
+		candidate := affixToolName(baseName, fmt.Sprintf("_%d", n), cfg)
+		if _, exists := taken[candidate]; !exists {
+			log.Printf("Warning: tool name '%s' is already in use, naming this operation '%s' instead", name, candidate)
+			return candidate
+		}
+	}
+}
+
+// affixToolName builds prefix + baseName + disambiguator + suffix, truncating baseName if an affix
+// pushes the result over maxToolNameLength. Names without affixes are left as generated.
+func affixToolName(baseName, disambiguator string, cfg *config.Config) string {
+	prefix, suffix := cfg.ToolNamePrefix, cfg.ToolNameSuffix
+	if prefix == "" && suffix == "" {
+		return baseName + disambiguator
+	}
+	if room := maxToolNameLength - len(prefix) - len(suffix) - len(disambiguator); len(baseName) > room && room > 0 {
+		baseName = baseName[:room]
+	}
+	return prefix + baseName + disambiguator + suffix
+}
@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const toolNamesV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Tool Names API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {
+    "/pets": {
+      "get": {"operationId": "listPets", "responses": {"200": {"description": "OK"}}}
+    },
+    "/owners": {
+      "get": {"responses": {"200": {"description": "OK"}}}
+    },
+    "/people": {
+      "get": {"operationId": "get_owners", "responses": {"200": {"description": "OK"}}}
+    }
+  }
+}`
+
+func generatedToolNames(t *testing.T, cfg *config.Config) []string {
+	t.Helper()
+	doc, version := loadSpecFixture(t, "tool_names_v3.json", toolNamesV3SpecJSON)
+	toolSet, err := GenerateToolSet(doc, version, cfg)
+	require.NoError(t, err)
+	var names []string
+	for _, tool := range toolSet.Tools {
+		names = append(names, tool.Name)
+		assert.Contains(t, toolSet.Operations, tool.Name, "each tool has its own operation")
+	}
+	return names
+}
+
+func TestGenerateToolSet_ToolNameAffixes(t *testing.T) {
+	t.Run("Collisions are disambiguated", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"get_owners", "get_owners_2", "listPets"}, generatedToolNames(t, &config.Config{}))
+	})
+
+	t.Run("Prefix and suffix apply to every tool, before collision handling", func(t *testing.T) {
+		names := generatedToolNames(t, &config.Config{ToolNamePrefix: "petstore_", ToolNameSuffix: "-v1"})
+		assert.ElementsMatch(t, []string{"petstore_get_owners-v1", "petstore_get_owners_2-v1", "petstore_listPets-v1"}, names)
+		for _, name := range names {
+			assert.True(t, strings.HasPrefix(name, "petstore_"), name)
+		}
+	})
+}
+
+func TestFinalizeToolName_Length(t *testing.T) {
+	cfg := &config.Config{ToolNamePrefix: "petstore_"}
+	longName := strings.Repeat("a", 70)
+
+	name := finalizeToolName(longName, cfg, map[string]mcp.OperationDetail{})
+	assert.Len(t, name, maxToolNameLength)
+	assert.True(t, strings.HasPrefix(name, "petstore_aaa"))
+
+	taken := map[string]mcp.OperationDetail{name: {}}
+	second := finalizeToolName(longName, cfg, taken)
+	assert.Len(t, second, maxToolNameLength)
+	assert.True(t, strings.HasSuffix(second, "_2"), second)
+
+	assert.Equal(t, longName, finalizeToolName(longName, &config.Config{}, taken), "names without affixes are not shortened")
+}
+
+func TestValidateToolNameAffixes(t *testing.T) {
+	assert.NoError(t, ValidateToolNameAffixes("petstore_", "-v1"))
+	assert.NoError(t, ValidateToolNameAffixes("", ""))
+	assert.Error(t, ValidateToolNameAffixes("pet store.", ""))
+	assert.Error(t, ValidateToolNameAffixes("", "/v1"))
+	assert.Error(t, ValidateToolNameAffixes(strings.Repeat("p", 40), strings.Repeat("s", 24)))
+}
@@ -0,0 +1,42 @@
+package parser
+
+import (
+	"log"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// unwrapBodyExtension opts an operation into unwrapping a single-property request body.
+const unwrapBodyExtension = "x-mcp-unwrap-body"
+
+// shouldUnwrapBody reports whether a tool's request body wrapper should be collapsed, either via
+// --unwrap-body or the x-mcp-unwrap-body extension.
+func shouldUnwrapBody(toolName string, extensions map[string]interface{}, cfg *config.Config) bool {
+	if sliceContains(cfg.UnwrapBodyOperations, toolName) {
+		return true
+	}
+	if value, ok := lookupExtension(extensions, unwrapBodyExtension); ok {
+		if enabled, ok := value.(bool); ok {
+			return enabled
+		}
+		log.Printf("Warning: ignoring %s with non-boolean value %v", unwrapBodyExtension, value)
+	}
+	return false
+}
+
+// unwrapBodySchema collapses a wrapper body such as {"data": {...}} to the schema of its only
+// property, returning that property's name so the server can re-wrap arguments. Bodies that are
+// not an object with exactly one object property are returned unchanged with an empty name.
+func unwrapBodySchema(toolName string, body mcp.Schema) (mcp.Schema, string) {
+	if body.Type == "object" && len(body.Properties) == 1 {
+		for wrapper, inner := range body.Properties {
+			if inner.Type == "object" && inner.Properties != nil {
+				log.Printf("Parser: Tool '%s' takes the contents of request body property '%s' directly", toolName, wrapper)
+				return inner, wrapper
+			}
+		}
+	}
+	log.Printf("Warning: tool '%s' asks to unwrap its request body, but it is not an object with exactly one object property. Leaving it as is.", toolName)
+	return body, ""
+}
@@ -0,0 +1,140 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const unwrapBodyV3SpecJSON = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Unwrap Body API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {
+    "/orders": {
+      "post": {
+        "operationId": "createOrder",
+        "x-mcp-unwrap-body": true,
+        "requestBody": {"content": {"application/json": {"schema": {
+          "type": "object",
+          "required": ["data"],
+          "properties": {"data": {"type": "object", "required": ["sku"], "properties": {"sku": {"type": "string"}, "quantity": {"type": "integer"}}}}
+        }}}},
+        "responses": {"201": {"description": "Created"}}
+      },
+      "put": {
+        "operationId": "replaceOrder",
+        "requestBody": {"content": {"application/json": {"schema": {
+          "type": "object",
+          "properties": {"data": {"type": "object", "properties": {"sku": {"type": "string"}}}}
+        }}}},
+        "responses": {"200": {"description": "OK"}}
+      },
+      "patch": {
+        "operationId": "patchOrder",
+        "x-mcp-unwrap-body": true,
+        "requestBody": {"content": {"application/json": {"schema": {
+          "type": "object",
+          "properties": {"sku": {"type": "string"}, "quantity": {"type": "integer"}}
+        }}}},
+        "responses": {"200": {"description": "OK"}}
+      }
+    },
+    "/orders/import": {
+      "post": {
+        "operationId": "importOrder",
+        "x-mcp-unwrap-body": true,
+        "requestBody": {"content": {
+          "application/x-www-form-urlencoded": {"schema": {"type": "object", "properties": {"sku": {"type": "string"}, "source": {"type": "string"}}}},
+          "application/vnd.orders+json": {"schema": {"type": "object", "properties": {"data": {"type": "object", "properties": {"sku": {"type": "string"}}}}}},
+          "text/csv": {"schema": {"type": "object", "properties": {"rows": {"type": "object", "properties": {"line": {"type": "string"}}}}}}
+        }},
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  }
+}`
+
+const unwrapBodyV2SpecJSON = `{
+  "swagger": "2.0",
+  "info": {"title": "Unwrap Body API", "version": "1.0.0"},
+  "host": "api.example.com",
+  "paths": {
+    "/orders": {
+      "post": {
+        "operationId": "createOrder",
+        "x-mcp-unwrap-body": true,
+        "parameters": [{"name": "body", "in": "body", "schema": {
+          "type": "object",
+          "properties": {"data": {"type": "object", "required": ["sku"], "properties": {"sku": {"type": "string"}}}}
+        }}],
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  }
+}`
+
+func TestGenerateToolSet_UnwrapBody(t *testing.T) {
+	doc, version := loadSpecFixture(t, "unwrap_body_v3.json", unwrapBodyV3SpecJSON)
+
+	t.Run("Extension unwraps the wrapper property", func(t *testing.T) {
+		schemas := inputSchemas(t, "unwrap_body_v3.json", unwrapBodyV3SpecJSON, &config.Config{})
+		create := schemas["createOrder"]
+		assert.Contains(t, create.Properties, "sku")
+		assert.Contains(t, create.Properties, "quantity")
+		assert.NotContains(t, create.Properties, "data")
+		assert.Equal(t, []string{"sku"}, create.Required, "the inner object's required list applies")
+
+		toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+		require.NoError(t, err)
+		assert.Equal(t, "data", toolSet.Operations["createOrder"].BodyWrapper)
+		assert.Contains(t, toolSet.Operations["createOrder"].RequestBody.Properties, "data", "the described body keeps its real shape")
+	})
+
+	t.Run("Off by default", func(t *testing.T) {
+		schemas := inputSchemas(t, "unwrap_body_v3.json", unwrapBodyV3SpecJSON, &config.Config{})
+		assert.Contains(t, schemas["replaceOrder"].Properties, "data")
+	})
+
+	t.Run("Flag enables unwrapping per tool", func(t *testing.T) {
+		cfg := &config.Config{UnwrapBodyOperations: []string{"replaceOrder"}}
+		schemas := inputSchemas(t, "unwrap_body_v3.json", unwrapBodyV3SpecJSON, cfg)
+		assert.Contains(t, schemas["replaceOrder"].Properties, "sku")
+
+		toolSet, err := GenerateToolSet(doc, version, cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "data", toolSet.Operations["replaceOrder"].BodyWrapper)
+	})
+
+	t.Run("Bodies that are not a wrapper are left alone", func(t *testing.T) {
+		var wrapper string
+		output := captureLog(t, func() {
+			toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+			require.NoError(t, err)
+			wrapper = toolSet.Operations["patchOrder"].BodyWrapper
+		})
+		assert.Empty(t, wrapper)
+		assert.Contains(t, output, "tool 'patchOrder' asks to unwrap its request body")
+	})
+
+	t.Run("Several media types unwrap the same one every time", func(t *testing.T) {
+		for i := 0; i < 20; i++ { // Media types are a map, so a dependence on its order shows up quickly
+			toolSet, err := GenerateToolSet(doc, version, &config.Config{})
+			require.NoError(t, err)
+			require.Equal(t, "data", toolSet.Operations["importOrder"].BodyWrapper)
+		}
+		schemas := inputSchemas(t, "unwrap_body_v3.json", unwrapBodyV3SpecJSON, &config.Config{})
+		assert.Contains(t, schemas["importOrder"].Properties, "sku")
+		for _, other := range []string{"data", "source", "rows"} {
+			assert.NotContains(t, schemas["importOrder"].Properties, other, "only the first media type in name order is merged")
+		}
+	})
+
+	t.Run("V2 body parameter", func(t *testing.T) {
+		schemas := inputSchemas(t, "unwrap_body_v2.json", unwrapBodyV2SpecJSON, &config.Config{})
+		assert.Contains(t, schemas["createOrder"].Properties, "sku")
+		assert.Equal(t, []string{"sku"}, schemas["createOrder"].Required)
+	})
+}
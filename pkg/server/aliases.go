@@ -0,0 +1,34 @@
+package server
+
+import (
+	"log"
+
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// unaliasArguments returns the tool input with the operation's parameter aliases translated back
+// to the names the API uses. An argument given under both names, such as a pinned one, keeps the
+// value under the API's name. The input map is not modified.
+func unaliasArguments(toolName string, operation mcp.OperationDetail, input map[string]interface{}) map[string]interface{} {
+	if len(operation.ArgumentAliases) == 0 {
+		return input
+	}
+	translated := make(map[string]interface{}, len(input))
+	for name, value := range input {
+		if _, aliased := operation.ArgumentAliases[name]; !aliased {
+			translated[name] = value
+		}
+	}
+	for alias, apiName := range operation.ArgumentAliases {
+		value, ok := input[alias]
+		if !ok {
+			continue
+		}
+		if _, both := input[apiName]; both {
+			log.Printf("[ExecuteToolCall] Tool '%s' got both '%s' and its alias '%s'; ignoring the alias", toolName, apiName, alias)
+			continue
+		}
+		translated[apiName] = value
+	}
+	return translated
+}
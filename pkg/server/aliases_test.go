@@ -0,0 +1,62 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteToolCall_ParameterAliases(t *testing.T) {
+	var query string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	specPath := filepath.Join(t.TempDir(), "spec.json")
+	require.NoError(t, os.WriteFile(specPath, []byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "Aliases API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {
+    "/orders": {
+      "get": {
+        "operationId": "listOrders",
+        "parameters": [
+          {"name": "$top", "in": "query", "schema": {"type": "integer"}},
+          {"name": "status", "in": "query", "schema": {"type": "string"}}
+        ],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`), 0o600))
+	doc, version, err := parser.LoadSwagger(specPath)
+	require.NoError(t, err)
+	cfg := &config.Config{ServerBaseURL: backend.URL, ParameterAliases: map[string]map[string]string{"listOrders": {"page_size": "$top"}}}
+	toolSet, err := parser.GenerateToolSet(doc, version, cfg)
+	require.NoError(t, err)
+	assert.Contains(t, toolSet.Tools[0].InputSchema.Properties, "page_size")
+
+	input := map[string]interface{}{"page_size": float64(25), "status": "open"}
+	resp, err := executeToolCall(&ToolCallParams{ToolName: "listOrders", Input: input}, toolSet, cfg)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "%24top=25&status=open", query)
+	assert.Contains(t, input, "page_size", "the client's arguments are not modified")
+
+	// A pinned value under the API's name wins over the alias
+	cfg.PinnedArguments = map[string]map[string]interface{}{"listOrders": {"$top": 10}}
+	resp, err = executeToolCall(&ToolCallParams{ToolName: "listOrders", Input: input}, toolSet, cfg)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "%24top=10&status=open", query)
+}
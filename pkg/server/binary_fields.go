@@ -0,0 +1,162 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// binaryResponseFields returns the JSON pointers of the base64 fields of a tool's JSON response
+// that are returned as content blocks of their own: the configured ones, plus with
+// BinaryFieldsFromSchema the string fields the response schema declares with format byte or
+// binary.
+func binaryResponseFields(toolName string, operation mcp.OperationDetail, status int, cfg *config.Config) []string {
+	if cfg == nil {
+		return nil
+	}
+	var pointers []string
+	for _, field := range cfg.ResponseBinaryFields[toolName] {
+		if !strings.HasPrefix(field, "/") {
+			field = "/" + escapeJSONPointer(field)
+		}
+		pointers = append(pointers, field)
+	}
+	if cfg.BinaryFieldsFromSchema {
+		schema, ok := operation.Responses[strconv.Itoa(status)]
+		if !ok {
+			schema, ok = operation.Responses["default"]
+		}
+		if ok {
+			pointers = append(pointers, binarySchemaFields(schema, "")...)
+		}
+	}
+	return pointers
+}
+
+// binarySchemaFields lists the pointers of the string properties with format byte or binary in
+// an object schema and the objects nested in it.
+func binarySchemaFields(schema mcp.Schema, pointer string) []string {
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var pointers []string
+	for _, name := range names {
+		property := schema.Properties[name]
+		path := pointer + "/" + escapeJSONPointer(name)
+		if property.Type == "string" && (property.Format == "byte" || property.Format == "binary") {
+			pointers = append(pointers, path)
+		} else if len(property.Properties) > 0 {
+			pointers = append(pointers, binarySchemaFields(property, path)...)
+		}
+	}
+	return pointers
+}
+
+// withBinaryFieldContent splits the base64 image and audio fields out of a JSON response's text
+// content into image and audio blocks following it, so clients can show them instead of reading
+// them as text. Each field is replaced in the JSON by a note naming its block. Fields that are
+// missing, not base64, or of another media type are left in place.
+func withBinaryFieldContent(toolName string, httpResp *http.Response, content []ToolResultContent, operation mcp.OperationDetail, cfg *config.Config) []ToolResultContent {
+	if len(content) != 1 || content[0].Type != "text" {
+		return content
+	}
+	mediaType, _, _ := mime.ParseMediaType(httpResp.Header.Get("Content-Type"))
+	if !strings.HasSuffix(mediaType, "/json") && !strings.HasSuffix(mediaType, "+json") {
+		return content
+	}
+	pointers := binaryResponseFields(toolName, operation, httpResp.StatusCode, cfg)
+	if len(pointers) == 0 {
+		return content
+	}
+	var body interface{}
+	if err := decodeJSON([]byte(content[0].Text), &body); err != nil {
+		return content
+	}
+
+	var blocks []ToolResultContent
+	for _, pointer := range pointers {
+		parent, key, ok := pointerParent(body, pointer)
+		if !ok {
+			continue
+		}
+		encoded, _ := parent[key].(string)
+		block, ok := binaryFieldBlock(encoded)
+		if !ok {
+			continue
+		}
+		blocks = append(blocks, block)
+		parent[key] = fmt.Sprintf("(%s, %d bytes of base64: returned as content block %d)", block.MimeType, len(encoded), len(blocks)+1)
+	}
+	if len(blocks) == 0 {
+		return content
+	}
+	text, err := json.Marshal(body)
+	if err != nil {
+		log.Printf("[ExecuteToolCall] Could not encode the response of tool '%s' without its binary fields: %v", toolName, err)
+		return content
+	}
+	log.Printf("[ExecuteToolCall] Returning %d binary field(s) of tool '%s' as separate content blocks", len(blocks), toolName)
+	return append([]ToolResultContent{{Type: "text", Text: string(text)}}, blocks...)
+}
+
+// pointerParent resolves a JSON pointer into nested objects to the object holding its last
+// segment.
+func pointerParent(body interface{}, pointer string) (map[string]interface{}, string, bool) {
+	segments := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	current := body
+	for _, segment := range segments[:len(segments)-1] {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, "", false
+		}
+		current = object[unescapeJSONPointer(segment)]
+	}
+	object, ok := current.(map[string]interface{})
+	key := unescapeJSONPointer(segments[len(segments)-1])
+	if !ok {
+		return nil, "", false
+	}
+	if _, present := object[key]; !present {
+		return nil, "", false
+	}
+	return object, key, true
+}
+
+// binaryFieldBlock decodes a base64 field, given bare or as a data: URL, into an image or audio
+// block. The media type comes from the data: URL, else from the decoded bytes.
+func binaryFieldBlock(encoded string) (ToolResultContent, bool) {
+	mediaType := ""
+	if rest, ok := strings.CutPrefix(encoded, "data:"); ok {
+		header, data, found := strings.Cut(rest, ",")
+		if !found || !strings.HasSuffix(header, ";base64") {
+			return ToolResultContent{}, false
+		}
+		mediaType, encoded = strings.TrimSuffix(header, ";base64"), data
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(decoded) == 0 {
+		return ToolResultContent{}, false
+	}
+	if mediaType == "" {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(decoded))
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "image/"):
+		return ToolResultContent{Type: "image", Data: encoded, MimeType: mediaType}, true
+	case strings.HasPrefix(mediaType, "audio/"):
+		return ToolResultContent{Type: "audio", Data: encoded, MimeType: mediaType}, true
+	}
+	return ToolResultContent{}, false
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pngBytes starts like a PNG file, which is all content sniffing looks at.
+var pngBytes = append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 16)...)
+
+func binaryFieldBackend(t *testing.T, body map[string]interface{}) *httptest.Server {
+	encoded, err := json.Marshal(body)
+	require.NoError(t, err)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(encoded)
+	}))
+	t.Cleanup(backend.Close)
+	return backend
+}
+
+func TestCallToolUpstream_BinaryFieldFromSchema(t *testing.T) {
+	thumbnail := base64.StdEncoding.EncodeToString(pngBytes)
+	backend := binaryFieldBackend(t, map[string]interface{}{
+		"summary": map[string]interface{}{"pages": 3, "title": "Q3 report"},
+		"preview": map[string]interface{}{"thumbnail": thumbnail},
+	})
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"get_report": {Method: "GET", Path: "/report", BaseURL: backend.URL, Responses: map[string]mcp.Schema{
+			"200": {Type: "object", Properties: map[string]mcp.Schema{
+				"summary": {Type: "object"},
+				"preview": {Type: "object", Properties: map[string]mcp.Schema{"thumbnail": {Type: "string", Format: "byte"}}},
+			}},
+		}},
+	}}
+	params := &ToolCallParams{ToolName: "get_report", Input: map[string]interface{}{}}
+
+	result := callToolUpstream("conn", &jsonRPCRequest{ID: 1}, params, toolSet, &config.Config{BinaryFieldsFromSchema: true})
+	require.False(t, result.IsError)
+	require.Len(t, result.Content, 2)
+
+	assert.Equal(t, "text", result.Content[0].Type)
+	var summary map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &summary))
+	assert.Equal(t, map[string]interface{}{"pages": float64(3), "title": "Q3 report"}, summary["summary"])
+	assert.Contains(t, summary["preview"].(map[string]interface{})["thumbnail"], "returned as content block 2")
+	assert.NotContains(t, result.Content[0].Text, thumbnail)
+
+	assert.Equal(t, ToolResultContent{Type: "image", Data: thumbnail, MimeType: "image/png"}, result.Content[1])
+
+	// Without the option the response stays one text block
+	result = callToolUpstream("conn", &jsonRPCRequest{ID: 1}, params, toolSet, &config.Config{})
+	require.Len(t, result.Content, 1)
+	assert.Contains(t, result.Content[0].Text, thumbnail)
+}
+
+func TestCallToolUpstream_ConfiguredBinaryFields(t *testing.T) {
+	audio := base64.StdEncoding.EncodeToString([]byte("not sniffable, typed by its data: URL"))
+	backend := binaryFieldBackend(t, map[string]interface{}{
+		"id":       "rec-1",
+		"clip":     "data:audio/mpeg;base64," + audio,
+		"note":     "plain text, not base64!",
+		"document": base64.StdEncoding.EncodeToString([]byte("%PDF-1.4 a pdf")),
+	})
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"get_recording": {Method: "GET", Path: "/recording", BaseURL: backend.URL},
+	}}
+	cfg := &config.Config{ResponseBinaryFields: map[string][]string{"get_recording": {"clip", "/note", "document", "/missing/field"}}}
+	params := &ToolCallParams{ToolName: "get_recording", Input: map[string]interface{}{}}
+
+	result := callToolUpstream("conn", &jsonRPCRequest{ID: 1}, params, toolSet, cfg)
+	require.False(t, result.IsError)
+	require.Len(t, result.Content, 2)
+	assert.Equal(t, ToolResultContent{Type: "audio", Data: audio, MimeType: "audio/mpeg"}, result.Content[1])
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &body))
+	assert.Equal(t, "plain text, not base64!", body["note"], "fields that aren't base64 stay")
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("%PDF-1.4 a pdf")), body["document"], "only images and audio are split out")
+}
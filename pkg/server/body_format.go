@@ -0,0 +1,72 @@
+package server
+
+import (
+	"encoding/json"
+	"mime"
+	"net/url"
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// formMediaType is the media type of URL-encoded form bodies.
+const formMediaType = "application/x-www-form-urlencoded"
+
+// requestBodyFormatForm selects form bodies in --request-body-format.
+const requestBodyFormatForm = "form"
+
+// useFormBody reports whether an operation's request body is sent URL-encoded. An operation
+// declaring only a form body gets one and an operation declaring only JSON (or another type) keeps
+// JSON; --request-body-format decides for operations declaring both, or no media types at all.
+func useFormBody(operation mcp.OperationDetail, cfg *config.Config) bool {
+	acceptsJSON, acceptsForm := false, false
+	for _, mediaType := range operation.BodyMediaTypes {
+		base, _, err := mime.ParseMediaType(mediaType)
+		if err != nil {
+			base = strings.ToLower(strings.TrimSpace(mediaType))
+		}
+		switch {
+		case base == formMediaType:
+			acceptsForm = true
+		case base == "application/json" || strings.HasSuffix(base, "+json"):
+			acceptsJSON = true
+		}
+	}
+	configured := cfg != nil && cfg.RequestBodyFormat == requestBodyFormatForm
+	switch {
+	case acceptsForm && acceptsJSON:
+		return configured
+	case acceptsForm:
+		return true
+	case len(operation.BodyMediaTypes) == 0:
+		return configured
+	default:
+		return false
+	}
+}
+
+// encodeFormBody URL-encodes body fields. Arrays repeat their key; objects are sent as JSON text,
+// since forms have no nesting.
+func encodeFormBody(bodyData map[string]interface{}) ([]byte, error) {
+	values := make(url.Values)
+	for key, value := range bodyData {
+		switch v := value.(type) {
+		case []interface{}:
+			for _, item := range v {
+				values.Add(key, formatScalar(item))
+			}
+		case map[string]interface{}:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			values.Add(key, string(encoded))
+		case nil:
+			values.Add(key, "")
+		default:
+			values.Add(key, formatScalar(v))
+		}
+	}
+	return []byte(values.Encode()), nil
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type capturedBody struct {
+	contentType string
+	body        string
+}
+
+// bodyFormatTestCall calls toolName on a backend recording the request body it receives.
+func bodyFormatTestCall(t *testing.T, toolName string, cfg *config.Config) capturedBody {
+	t.Helper()
+	var captured capturedBody
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		captured = capturedBody{contentType: r.Header.Get("Content-Type"), body: string(body)}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer backend.Close()
+
+	both := []string{"application/json", "application/x-www-form-urlencoded"}
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"create_user":  {Method: "POST", Path: "/users", BaseURL: backend.URL, BodyMediaTypes: both},
+		"create_token": {Method: "POST", Path: "/tokens", BaseURL: backend.URL, BodyMediaTypes: both},
+		"create_note":  {Method: "POST", Path: "/notes", BaseURL: backend.URL, BodyMediaTypes: []string{"application/json"}},
+		"login":        {Method: "POST", Path: "/login", BaseURL: backend.URL, BodyMediaTypes: []string{"application/x-www-form-urlencoded"}},
+	}}
+	params := &ToolCallParams{ToolName: toolName, Input: map[string]interface{}{
+		"name":  "Ada Lovelace",
+		"tags":  []interface{}{"admin", "ops"},
+		"prefs": map[string]interface{}{"theme": "dark"},
+	}}
+	result := callToolUpstream("conn", &jsonRPCRequest{ID: 1}, params, toolSet, cfg)
+	require.False(t, result.IsError, "tool call failed: %+v", result.Content)
+	return captured
+}
+
+func TestRequestBodyFormat_Form(t *testing.T) {
+	cfg := &config.Config{RequestBodyFormat: "form"}
+	for _, toolName := range []string{"create_user", "create_token"} {
+		captured := bodyFormatTestCall(t, toolName, cfg)
+		assert.Equal(t, "application/x-www-form-urlencoded", captured.contentType, toolName)
+		assert.Equal(t, "name=Ada+Lovelace&prefs=%7B%22theme%22%3A%22dark%22%7D&tags=admin&tags=ops", captured.body, toolName)
+	}
+
+	captured := bodyFormatTestCall(t, "create_note", cfg)
+	assert.Equal(t, "application/json", captured.contentType, "JSON-only operations ignore the global setting")
+	assert.JSONEq(t, `{"name": "Ada Lovelace", "tags": ["admin", "ops"], "prefs": {"theme": "dark"}}`, captured.body)
+}
+
+func TestRequestBodyFormat_JSONDefault(t *testing.T) {
+	captured := bodyFormatTestCall(t, "create_user", &config.Config{})
+	assert.Equal(t, "application/json", captured.contentType)
+	assert.JSONEq(t, `{"name": "Ada Lovelace", "tags": ["admin", "ops"], "prefs": {"theme": "dark"}}`, captured.body)
+
+	captured = bodyFormatTestCall(t, "login", &config.Config{RequestBodyFormat: "json"})
+	assert.Equal(t, "application/x-www-form-urlencoded", captured.contentType, "form-only operations ignore the global setting")
+}
+
+func TestUseFormBody(t *testing.T) {
+	form := &config.Config{RequestBodyFormat: "form"}
+	assert.True(t, useFormBody(mcp.OperationDetail{}, form), "operations without declared media types follow the setting")
+	assert.False(t, useFormBody(mcp.OperationDetail{}, nil))
+	assert.True(t, useFormBody(mcp.OperationDetail{BodyMediaTypes: []string{"application/merge-patch+json", "application/x-www-form-urlencoded; charset=utf-8"}}, form))
+	assert.False(t, useFormBody(mcp.OperationDetail{BodyMediaTypes: []string{"application/xml"}}, form))
+}
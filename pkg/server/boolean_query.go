@@ -0,0 +1,63 @@
+package server
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// BooleanQueryAllParameters stands for every boolean query parameter of a tool in
+// config.BooleanQueryStyles.
+const BooleanQueryAllParameters = "*"
+
+// booleanQueryStyle returns how a tool's boolean query parameter is sent: the configured style
+// for the parameter, else for all of the tool's parameters, else the spec's x-mcp-boolean-style,
+// else explicit values.
+func booleanQueryStyle(toolName string, detail mcp.ParameterDetail, cfg *config.Config) string {
+	if cfg != nil {
+		if style, ok := cfg.BooleanQueryStyles[toolName][detail.Name]; ok {
+			return style
+		}
+		if style, ok := cfg.BooleanQueryStyles[toolName][BooleanQueryAllParameters]; ok {
+			return style
+		}
+	}
+	if detail.BooleanStyle != "" {
+		return detail.BooleanStyle
+	}
+	return mcp.BooleanStyleValue
+}
+
+// addPresenceFlag handles a boolean query argument sent in presence style: true adds the bare
+// key to flags, false leaves the parameter out. It reports whether the value was a boolean.
+func addPresenceFlag(flags []string, key string, value interface{}) ([]string, bool) {
+	set, ok := value.(bool)
+	if !ok {
+		return flags, false
+	}
+	if set {
+		flags = append(flags, key)
+	}
+	return flags, true
+}
+
+// encodeQuery encodes the query parameters followed by the presence-style flags, which have no
+// value and so no "=".
+func encodeQuery(queryParams url.Values, flags []string) string {
+	encoded := queryParams.Encode()
+	if len(flags) == 0 {
+		return encoded
+	}
+	sort.Strings(flags)
+	parts := make([]string, 0, len(flags)+1)
+	if encoded != "" {
+		parts = append(parts, encoded)
+	}
+	for _, flag := range flags {
+		parts = append(parts, url.QueryEscape(flag))
+	}
+	return strings.Join(parts, "&")
+}
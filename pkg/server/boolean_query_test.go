@@ -0,0 +1,60 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteToolCall_BooleanQueryStyles(t *testing.T) {
+	var query string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"listFiles": {Method: "GET", Path: "/files", BaseURL: backend.URL, Parameters: []mcp.ParameterDetail{
+			{Name: "recursive", In: "query", BooleanStyle: mcp.BooleanStylePresence},
+			{Name: "hidden", In: "query"},
+			{Name: "limit", In: "query"},
+		}},
+	}}
+	call := func(cfg *config.Config, input map[string]interface{}) string {
+		t.Helper()
+		resp, err := executeToolCall(&ToolCallParams{ToolName: "listFiles", Input: input}, toolSet, cfg)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return query
+	}
+
+	for _, tc := range []struct {
+		name  string
+		cfg   *config.Config
+		input map[string]interface{}
+		want  string
+	}{
+		{"Value style true", &config.Config{}, map[string]interface{}{"hidden": true}, "hidden=true"},
+		{"Value style false", &config.Config{}, map[string]interface{}{"hidden": false}, "hidden=false"},
+		{"Presence style true", &config.Config{}, map[string]interface{}{"recursive": true, "limit": 5}, "limit=5&recursive"},
+		{"Presence style false", &config.Config{}, map[string]interface{}{"recursive": false}, ""},
+		{"Configured presence style", &config.Config{BooleanQueryStyles: map[string]map[string]string{"listFiles": {"hidden": "presence"}}},
+			map[string]interface{}{"hidden": true}, "hidden"},
+		{"Configured for all parameters", &config.Config{BooleanQueryStyles: map[string]map[string]string{"listFiles": {"*": "presence"}}},
+			map[string]interface{}{"hidden": false, "recursive": true}, "recursive"},
+		{"Configuration overrides the extension", &config.Config{BooleanQueryStyles: map[string]map[string]string{"listFiles": {"recursive": "value"}}},
+			map[string]interface{}{"recursive": false}, "recursive=false"},
+		{"Non-boolean values are sent as given", &config.Config{BooleanQueryStyles: map[string]map[string]string{"listFiles": {"*": "presence"}}},
+			map[string]interface{}{"limit": 5}, "limit=5"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, call(tc.cfg, tc.input))
+		})
+	}
+}
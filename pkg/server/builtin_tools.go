@@ -0,0 +1,205 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// describeOperationToolName is the built-in meta-tool that reports an operation's spec details.
+const describeOperationToolName = "__describe_operation"
+
+// describeOperationTool returns the tool definition advertised for __describe_operation.
+func describeOperationTool() mcp.Tool {
+	readOnly, openWorld := true, false
+	return mcp.Tool{
+		Name:        describeOperationToolName,
+		Description: "Describe the OpenAPI operation behind a tool: its parameters, request/response schemas, and security requirements. Does not call the API.",
+		InputSchema: mcp.Schema{
+			Type: "object",
+			Properties: map[string]mcp.Schema{
+				"tool_name": {
+					Type:        "string",
+					Description: "Name of the tool to describe.",
+				},
+			},
+			Required: []string{"tool_name"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:  &readOnly,
+			OpenWorldHint: &openWorld,
+		},
+	}
+}
+
+// listTools returns the generated tools, in the configured order, followed by any enabled built-in
+// tools. Generated tools carry their effective upstream timeout in _meta so clients know the
+// budget, and whether calls need confirmation.
+func listTools(toolSet *mcp.ToolSet, cfg *config.Config) []mcp.Tool {
+	tools := make([]mcp.Tool, 0, len(toolSet.Tools)+1)
+	for _, tool := range toolSet.Tools {
+		if operation, ok := toolSet.Operations[tool.Name]; ok {
+			meta := timeoutMeta(effectiveTimeout(tool.Name, operation, cfg))
+			if operation.Confirm {
+				meta[confirmationMetaKey] = true
+			}
+			for key, value := range tool.Meta {
+				meta[key] = value
+			}
+			tool.Meta = meta
+		}
+		tools = append(tools, tool)
+	}
+	tools = orderTools(tools, toolSet, cfg)
+	if cfg == nil || !cfg.DisableDescribeTool {
+		tools = append(tools, describeOperationTool())
+	}
+	if upstreamHealthEnabled(cfg) {
+		tools = append(tools, upstreamHealthTool())
+	}
+	return tools
+}
+
+// OperationParameterDescription describes a single operation parameter in __describe_operation output.
+type OperationParameterDescription struct {
+	Name     string      `json:"name"`
+	In       string      `json:"in"`
+	Required bool        `json:"required"`
+	Schema   *mcp.Schema `json:"schema,omitempty"`
+}
+
+// OperationDescription is the structured result of __describe_operation.
+type OperationDescription struct {
+	Tool        string                          `json:"tool"`
+	Description string                          `json:"description,omitempty"`
+	Method      string                          `json:"method"`
+	Path        string                          `json:"path"`
+	BaseURL     string                          `json:"baseUrl"`
+	Parameters  []OperationParameterDescription `json:"parameters,omitempty"`
+	RequestBody *mcp.Schema                     `json:"requestBody,omitempty"`
+	Responses   map[string]mcp.Schema           `json:"responses,omitempty"`
+	Security    []map[string][]string           `json:"security,omitempty"`
+}
+
+// describeOperation builds the description for a generated tool. Returns false if the tool is unknown.
+func describeOperation(toolName string, toolSet *mcp.ToolSet) (*OperationDescription, bool) {
+	operation, ok := toolSet.Operations[toolName]
+	if !ok {
+		return nil, false
+	}
+
+	description := &OperationDescription{
+		Tool:        toolName,
+		Method:      operation.Method,
+		Path:        operation.Path,
+		BaseURL:     operation.BaseURL,
+		RequestBody: operation.RequestBody,
+		Responses:   operation.Responses,
+		Security:    operation.Security,
+	}
+
+	var inputSchema mcp.Schema
+	for _, tool := range toolSet.Tools {
+		if tool.Name == toolName {
+			description.Description = tool.Description
+			inputSchema = tool.InputSchema
+			break
+		}
+	}
+
+	for _, param := range operation.Parameters {
+		paramDesc := OperationParameterDescription{
+			Name:     param.Name,
+			In:       param.In,
+			Required: sliceContainsString(inputSchema.Required, param.Name),
+		}
+		if schema, ok := inputSchema.Properties[param.Name]; ok {
+			paramDesc.Schema = &schema
+		}
+		description.Parameters = append(description.Parameters, paramDesc)
+	}
+	sort.SliceStable(description.Parameters, func(i, j int) bool {
+		return description.Parameters[i].Name < description.Parameters[j].Name
+	})
+
+	return description, true
+}
+
+// handleBuiltinToolCall serves calls to built-in tools. It returns false if the tool isn't a
+// built-in (or is disabled), in which case the call should be forwarded upstream.
+func handleBuiltinToolCall(req *jsonRPCRequest, params *ToolCallParams, toolSet *mcp.ToolSet, cfg *config.Config) (jsonRPCResponse, bool) {
+	if params.ToolName == upstreamHealthToolName && upstreamHealthEnabled(cfg) {
+		return handleUpstreamHealthCall(req, toolSet, cfg)
+	}
+	if params.ToolName != describeOperationToolName || (cfg != nil && cfg.DisableDescribeTool) {
+		return jsonRPCResponse{}, false
+	}
+
+	toolName, _ := params.Input["tool_name"].(string)
+	log.Printf("[BuiltinTool] Describing operation for tool '%s'", toolName)
+
+	var resultPayload ToolResultPayload
+	description, found := describeOperation(toolName, toolSet)
+	if !found {
+		message := fmt.Sprintf("Unknown tool '%s'", toolName)
+		resultPayload = ToolResultPayload{
+			IsError:    true,
+			Content:    []ToolResultContent{{Type: "text", Text: message}},
+			Error:      &MCPError{Message: message},
+			ToolCallID: fmt.Sprintf("%v", req.ID),
+		}
+	} else {
+		descriptionJSON, err := json.MarshalIndent(description, "", "  ")
+		if err != nil {
+			log.Printf("[BuiltinTool] Error marshalling description for '%s': %v", toolName, err)
+			return createJSONRPCError(req.ID, -32603, "Internal error", err.Error()), true
+		}
+		resultPayload = ToolResultPayload{
+			Content:           []ToolResultContent{{Type: "text", Text: string(descriptionJSON)}},
+			StructuredContent: description,
+			ToolCallID:        fmt.Sprintf("%v", req.ID),
+		}
+	}
+
+	return jsonRPCResponse{
+		Jsonrpc: "2.0",
+		ID:      req.ID,
+		Result:  resultPayload,
+	}, true
+}
+
+// handleUpstreamHealthCall serves __upstream_health. An unhealthy upstream is a result, not a
+// failed call.
+func handleUpstreamHealthCall(req *jsonRPCRequest, toolSet *mcp.ToolSet, cfg *config.Config) (jsonRPCResponse, bool) {
+	health := probeUpstream(toolSet, cfg)
+	log.Printf("[BuiltinTool] Upstream health probe of %s: healthy=%t status=%d latency=%dms", health.Target, health.Healthy, health.Status, health.LatencyMs)
+
+	healthJSON, err := json.MarshalIndent(health, "", "  ")
+	if err != nil {
+		log.Printf("[BuiltinTool] Error marshalling upstream health: %v", err)
+		return createJSONRPCError(req.ID, -32603, "Internal error", err.Error()), true
+	}
+	return jsonRPCResponse{
+		Jsonrpc: "2.0",
+		ID:      req.ID,
+		Result: ToolResultPayload{
+			Content:           []ToolResultContent{{Type: "text", Text: string(healthJSON)}},
+			StructuredContent: health,
+			ToolCallID:        fmt.Sprintf("%v", req.ID),
+		},
+	}, true
+}
+
+// sliceContainsString reports whether s is present in slice.
+func sliceContainsString(slice []string, s string) bool {
+	for _, item := range slice {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
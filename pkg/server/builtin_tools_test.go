@@ -0,0 +1,109 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestToolSetForDescribe() *mcp.ToolSet {
+	return &mcp.ToolSet{
+		Tools: []mcp.Tool{
+			{
+				Name:        "get_user",
+				Description: "Get a user",
+				InputSchema: mcp.Schema{
+					Type: "object",
+					Properties: map[string]mcp.Schema{
+						"id":      {Type: "string", Description: "User ID"},
+						"verbose": {Type: "boolean"},
+					},
+					Required: []string{"id"},
+				},
+			},
+		},
+		Operations: map[string]mcp.OperationDetail{
+			"get_user": {
+				Method:     "GET",
+				Path:       "/users/{id}",
+				BaseURL:    "http://127.0.0.1:1", // Never contacted
+				Parameters: []mcp.ParameterDetail{{Name: "verbose", In: "query"}, {Name: "id", In: "path"}},
+				Responses: map[string]mcp.Schema{
+					"200": {Type: "object", Properties: map[string]mcp.Schema{"name": {Type: "string"}}},
+					"404": {Description: "Not found"},
+				},
+				Security: []map[string][]string{{"apiKey": {}}},
+			},
+		},
+	}
+}
+
+func TestListTools_DescribeTool(t *testing.T) {
+	toolSet := createTestToolSetForDescribe()
+
+	tools := listTools(toolSet, &config.Config{})
+	require.Len(t, tools, 2)
+	assert.Equal(t, describeOperationToolName, tools[1].Name)
+	assert.Equal(t, []string{"tool_name"}, tools[1].InputSchema.Required)
+
+	tools = listTools(toolSet, &config.Config{DisableDescribeTool: true})
+	require.Len(t, tools, 1)
+	assert.Equal(t, "get_user", tools[0].Name)
+}
+
+func TestHandleToolCall_DescribeOperation(t *testing.T) {
+	toolSet := createTestToolSetForDescribe()
+	callDescribe := func(cfg *config.Config, toolName string) jsonRPCResponse {
+		params, _ := json.Marshal(ToolCallParams{ToolName: describeOperationToolName, Input: map[string]interface{}{"tool_name": toolName}})
+		req := &jsonRPCRequest{Jsonrpc: "2.0", Method: "tools/call", Params: json.RawMessage(params), ID: "describe-1"}
+		return handleToolCallJSONRPC("test-conn", req, toolSet, cfg)
+	}
+
+	t.Run("Known tool", func(t *testing.T) {
+		resp := callDescribe(&config.Config{}, "get_user")
+		require.Nil(t, resp.Error)
+		result, ok := resp.Result.(ToolResultPayload)
+		require.True(t, ok)
+		assert.False(t, result.IsError)
+
+		description, ok := result.StructuredContent.(*OperationDescription)
+		require.True(t, ok)
+		assert.Equal(t, "GET", description.Method)
+		assert.Equal(t, "/users/{id}", description.Path)
+		assert.Equal(t, "Get a user", description.Description)
+		require.Len(t, description.Parameters, 2)
+		assert.Equal(t, "id", description.Parameters[0].Name)
+		assert.Equal(t, "path", description.Parameters[0].In)
+		assert.True(t, description.Parameters[0].Required)
+		assert.Equal(t, "User ID", description.Parameters[0].Schema.Description)
+		assert.False(t, description.Parameters[1].Required)
+		assert.Contains(t, description.Responses, "404")
+		assert.Equal(t, []map[string][]string{{"apiKey": {}}}, description.Security)
+
+		// The text content mirrors the structured content
+		require.Len(t, result.Content, 1)
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &decoded))
+		assert.Equal(t, "get_user", decoded["tool"])
+	})
+
+	t.Run("Unknown tool", func(t *testing.T) {
+		resp := callDescribe(&config.Config{}, "missing")
+		result, ok := resp.Result.(ToolResultPayload)
+		require.True(t, ok)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].Text, "Unknown tool 'missing'")
+	})
+
+	t.Run("Disabled falls through to upstream lookup", func(t *testing.T) {
+		resp := callDescribe(&config.Config{DisableDescribeTool: true}, "get_user")
+		result, ok := resp.Result.(ToolResultPayload)
+		require.True(t, ok)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].Text, "Failed to execute tool '__describe_operation'")
+	})
+}
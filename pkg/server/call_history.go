@@ -0,0 +1,131 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Outcomes of a recorded tool call.
+const (
+	CallOutcomeSuccess  = "success"  // The tool returned a result
+	CallOutcomeError    = "error"    // The tool returned an error result, e.g. an upstream failure
+	CallOutcomeRejected = "rejected" // The call was refused with a JSON-RPC error, e.g. invalid arguments
+)
+
+// ToolCallRecord is one entry of a connection's call history. Arguments are left out, as they may
+// be sensitive.
+type ToolCallRecord struct {
+	Tool      string    `json:"tool"`
+	Timestamp time.Time `json:"timestamp"`
+	Outcome   string    `json:"outcome"`
+	Category  string    `json:"category,omitempty"` // Error category of failed calls, see ErrorCategoryClientError
+	LatencyMs int64     `json:"latencyMs"`
+}
+
+// callHistory is a ring buffer of a connection's most recent tool calls.
+type callHistory struct {
+	records []ToolCallRecord
+	next    int // Index the next record is written at
+	full    bool
+}
+
+func (h *callHistory) add(record ToolCallRecord) {
+	h.records[h.next] = record
+	h.next = (h.next + 1) % len(h.records)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// newestFirst returns the recorded calls, the most recent first.
+func (h *callHistory) newestFirst() []ToolCallRecord {
+	count := h.next
+	if h.full {
+		count = len(h.records)
+	}
+	calls := make([]ToolCallRecord, 0, count)
+	for i := 1; i <= count; i++ {
+		calls = append(calls, h.records[(h.next-i+len(h.records))%len(h.records)])
+	}
+	return calls
+}
+
+// SetCallHistorySize sets how many recent tool calls are kept per connection for
+// GET /admin/connections/{id}/history. Zero or less disables the history, which is the default.
+func (cm *ConnectionManager) SetCallHistorySize(size int) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	cm.callHistorySize = size
+}
+
+// RecordToolCall adds a finished tool call to the connection's history, if the history is enabled.
+func (cm *ConnectionManager) RecordToolCall(id string, record ToolCallRecord) bool {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	conn, ok := cm.connections[cm.normalizeID(id)]
+	if !ok || cm.callHistorySize <= 0 {
+		return false
+	}
+	if conn.history == nil || len(conn.history.records) != cm.callHistorySize {
+		conn.history = &callHistory{records: make([]ToolCallRecord, cm.callHistorySize)}
+	}
+	conn.history.add(record)
+	return true
+}
+
+// CallHistory returns the connection's recent tool calls, newest first, and whether the
+// connection exists.
+func (cm *ConnectionManager) CallHistory(id string) ([]ToolCallRecord, bool) {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+
+	conn, ok := cm.connections[cm.normalizeID(id)]
+	if !ok {
+		return nil, false
+	}
+	if conn.history == nil {
+		return []ToolCallRecord{}, true
+	}
+	return conn.history.newestFirst(), true
+}
+
+// toolCallRecord describes a finished tools/call for the call history.
+func toolCallRecord(toolName string, started time.Time, resp jsonRPCResponse) ToolCallRecord {
+	record := ToolCallRecord{
+		Tool:      toolName,
+		Timestamp: started,
+		Outcome:   CallOutcomeSuccess,
+		LatencyMs: time.Since(started).Milliseconds(),
+	}
+	if resp.Error != nil {
+		record.Outcome = CallOutcomeRejected
+		return record
+	}
+	if result, ok := resp.Result.(ToolResultPayload); ok && result.IsError {
+		record.Outcome = CallOutcomeError
+		record.Category, _ = result.Meta["category"].(string)
+	}
+	return record
+}
+
+// adminConnectionHistoryHandler reports a connection's recent tool calls as JSON, newest first.
+func adminConnectionHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	calls, ok := mcpConnectionManager.CallHistory(id)
+	if !ok {
+		http.Error(w, "Connection not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":    id,
+		"calls": calls,
+		"count": len(calls),
+	}); err != nil {
+		log.Printf("Error writing admin call history for %s: %v", id, err)
+	}
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func enableCallHistory(t *testing.T, size int) {
+	mcpConnectionManager.SetCallHistorySize(size)
+	t.Cleanup(func() { mcpConnectionManager.SetCallHistorySize(0) })
+}
+
+func TestAdminConnectionHistoryEndpoint(t *testing.T) {
+	enableCallHistory(t, 10)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer backend.Close()
+
+	connID := "history-" + uuid.NewString()
+	setupTestConnection(connID)
+	defer cleanupTestConnection(connID)
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"get_report": {Method: "GET", Path: "/report", BaseURL: backend.URL},
+		"get_broken": {Method: "GET", Path: "/broken", BaseURL: backend.URL},
+	}}
+	cfg := &config.Config{DisableInputValidation: true}
+	for _, toolName := range []string{"get_report", "get_broken"} {
+		params, _ := json.Marshal(map[string]interface{}{"name": toolName, "arguments": map[string]interface{}{"secret": "s3cret"}})
+		handleToolCallJSONRPC(connID, &jsonRPCRequest{Jsonrpc: "2.0", ID: 1, Method: "tools/call", Params: json.RawMessage(params)}, toolSet, cfg)
+	}
+
+	srv := httptest.NewServer(newMCPMux(toolSet, cfg))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/admin/connections/" + connID + "/history")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var body struct {
+		ID    string           `json:"id"`
+		Calls []ToolCallRecord `json:"calls"`
+		Count int              `json:"count"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, connID, body.ID)
+	require.Len(t, body.Calls, 2)
+	assert.Equal(t, 2, body.Count)
+	assert.Equal(t, "get_broken", body.Calls[0].Tool, "newest first")
+	assert.Equal(t, CallOutcomeError, body.Calls[0].Outcome)
+	assert.Equal(t, ErrorCategoryServerError, body.Calls[0].Category)
+	assert.Equal(t, "get_report", body.Calls[1].Tool)
+	assert.Equal(t, CallOutcomeSuccess, body.Calls[1].Outcome)
+	assert.False(t, body.Calls[0].Timestamp.Before(body.Calls[1].Timestamp))
+
+	missing, err := http.Get(srv.URL + "/admin/connections/no-such-connection/history")
+	require.NoError(t, err)
+	missing.Body.Close()
+	assert.Equal(t, http.StatusNotFound, missing.StatusCode)
+}
+
+func TestCallHistory_Bounded(t *testing.T) {
+	enableCallHistory(t, 3)
+	connID := "history-bounded-" + uuid.NewString()
+	setupTestConnection(connID)
+	defer cleanupTestConnection(connID)
+
+	for _, toolName := range []string{"a", "b", "c", "d", "e"} {
+		assert.True(t, mcpConnectionManager.RecordToolCall(connID, ToolCallRecord{Tool: toolName, Timestamp: time.Now()}))
+	}
+	calls, ok := mcpConnectionManager.CallHistory(connID)
+	require.True(t, ok)
+	var names []string
+	for _, call := range calls {
+		names = append(names, call.Tool)
+	}
+	assert.Equal(t, []string{"e", "d", "c"}, names, "only the newest calls are kept")
+
+	conn := mcpConnectionManager.GetConnection(connID)
+	require.True(t, mcpConnectionManager.RemoveConnection(connID))
+	assert.Nil(t, conn.history, "cleared on removal")
+	_, ok = mcpConnectionManager.CallHistory(connID)
+	assert.False(t, ok)
+}
+
+func TestCallHistory_DisabledByDefault(t *testing.T) {
+	connID := "history-disabled-" + uuid.NewString()
+	setupTestConnection(connID)
+	defer cleanupTestConnection(connID)
+
+	assert.False(t, mcpConnectionManager.RecordToolCall(connID, ToolCallRecord{Tool: "a"}))
+	calls, ok := mcpConnectionManager.CallHistory(connID)
+	assert.True(t, ok)
+	assert.Empty(t, calls)
+}
+
+func TestToolCallRecord_Rejected(t *testing.T) {
+	record := toolCallRecord("get_report", time.Now(), createJSONRPCError(1, -32602, "Invalid arguments", nil))
+	assert.Equal(t, CallOutcomeRejected, record.Outcome)
+}
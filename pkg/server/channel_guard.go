@@ -0,0 +1,103 @@
+package server
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	// errConnectionShutdown is returned to writers that try to send after a connection shut down.
+	errConnectionShutdown = errors.New("connection is shut down")
+	// errChannelFull is returned by trySend when the connection's buffer has no room.
+	errChannelFull = errors.New("connection channel is full")
+)
+
+// channelGuard makes closing a connection's Channel safe against concurrent writers. Writers
+// hold a send lease while they write; shutdown refuses new leases and the channel is closed
+// by whoever releases the last one, so it is closed exactly once and never while being sent on.
+// The zero value is ready to use.
+type channelGuard struct {
+	mutex    sync.Mutex
+	leases   int  // Writers currently sending
+	shutdown bool // No new leases are handed out
+	closed   bool // Channel has been closed
+}
+
+// acquireSend takes a send lease on the connection's Channel, failing once it is shut down.
+// Every successful call must be paired with releaseSend.
+func (c *Connection) acquireSend() error {
+	c.guard.mutex.Lock()
+	defer c.guard.mutex.Unlock()
+
+	if c.guard.shutdown {
+		return errConnectionShutdown
+	}
+	c.guard.leases++
+	return nil
+}
+
+// releaseSend returns a send lease, closing the channel if it was the last one after shutdown.
+func (c *Connection) releaseSend() {
+	c.guard.mutex.Lock()
+	defer c.guard.mutex.Unlock()
+
+	c.guard.leases--
+	c.closeIfDrainedLocked()
+}
+
+// trySend queues resp on the connection's Channel without blocking. A message that cannot be
+// queued is recorded as undelivered.
+func (c *Connection) trySend(resp jsonRPCResponse) error {
+	if err := c.acquireSend(); err != nil {
+		recordUndelivered(c.ID, resp, err)
+		return err
+	}
+	defer c.releaseSend()
+
+	select {
+	case c.Channel <- resp:
+		return nil
+	default:
+		recordUndelivered(c.ID, resp, errChannelFull)
+		return errChannelFull
+	}
+}
+
+// sendOrDone queues resp on the connection's Channel, blocking until there is room or done is
+// closed. A writer blocked here delays the close of the channel until it returns.
+func (c *Connection) sendOrDone(resp jsonRPCResponse, done <-chan struct{}) error {
+	if err := c.acquireSend(); err != nil {
+		recordUndelivered(c.ID, resp, err)
+		return err
+	}
+	defer c.releaseSend()
+
+	select {
+	case c.Channel <- resp:
+		return nil
+	case <-done:
+		recordUndelivered(c.ID, resp, errConnectionShutdown)
+		return errConnectionShutdown
+	}
+}
+
+// shutdownChannel stops new sends on the connection's Channel and closes it as soon as the
+// writers holding a lease are done. Calling it more than once is harmless.
+func (c *Connection) shutdownChannel() {
+	c.guard.mutex.Lock()
+	defer c.guard.mutex.Unlock()
+
+	c.guard.shutdown = true
+	c.closeIfDrainedLocked()
+}
+
+// closeIfDrainedLocked closes the channel once shut down with no leases left. The guard mutex
+// must be held.
+func (c *Connection) closeIfDrainedLocked() {
+	if c.guard.shutdown && c.guard.leases == 0 && !c.guard.closed {
+		c.guard.closed = true
+		if c.Channel != nil {
+			close(c.Channel)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drain reads ch until it is closed, reporting how many responses it held.
+func drain(ch chan jsonRPCResponse) int {
+	count := 0
+	for range ch {
+		count++
+	}
+	return count
+}
+
+func TestChannelGuard_CloseWaitsForLeases(t *testing.T) {
+	conn := &Connection{Channel: make(chan jsonRPCResponse, 1)}
+
+	require.NoError(t, conn.acquireSend())
+	conn.shutdownChannel()
+	conn.shutdownChannel() // Repeated shutdown must not double-close
+
+	select {
+	case <-conn.Channel:
+		t.Fatal("channel closed while a lease was held")
+	default:
+	}
+	assert.ErrorIs(t, conn.acquireSend(), errConnectionShutdown, "no new leases after shutdown")
+
+	conn.releaseSend()
+	_, ok := <-conn.Channel
+	assert.False(t, ok, "channel should close when the last lease is released")
+	assert.ErrorIs(t, conn.trySend(jsonRPCResponse{}), errConnectionShutdown)
+}
+
+func TestChannelGuard_TrySendFull(t *testing.T) {
+	conn := &Connection{Channel: make(chan jsonRPCResponse, 1)}
+
+	require.NoError(t, conn.trySend(jsonRPCResponse{ID: 1}))
+	assert.ErrorIs(t, conn.trySend(jsonRPCResponse{ID: 2}), errChannelFull)
+}
+
+func TestChannelGuard_SendOrDoneUnblocksOnDone(t *testing.T) {
+	conn := &Connection{Channel: make(chan jsonRPCResponse)}
+	done := make(chan struct{})
+
+	result := make(chan error)
+	go func() { result <- conn.sendOrDone(jsonRPCResponse{}, done) }()
+
+	conn.shutdownChannel() // Must not close the channel under the blocked writer
+	close(done)
+	assert.ErrorIs(t, <-result, errConnectionShutdown)
+	_, ok := <-conn.Channel
+	assert.False(t, ok)
+}
+
+func TestChannelGuard_ConcurrentSendsAndRemoval(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		cm := NewConnectionManager()
+		conn, _ := cm.NewConnection("stress")
+
+		drained := make(chan int)
+		go func() { drained <- drain(conn.Channel) }()
+
+		var wg sync.WaitGroup
+		var mutex sync.Mutex
+		sent := 0
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(id int) {
+				defer wg.Done()
+				for j := 0; j < 20; j++ {
+					err := conn.trySend(jsonRPCResponse{ID: id})
+					if err == nil {
+						mutex.Lock()
+						sent++
+						mutex.Unlock()
+					} else if err != errChannelFull {
+						assert.ErrorIs(t, err, errConnectionShutdown)
+					}
+				}
+			}(i)
+		}
+		wg.Add(2)
+		go func() { defer wg.Done(); cm.RemoveConnection("stress") }()
+		go func() { defer wg.Done(); cm.RemoveConnection("stress") }()
+		wg.Wait()
+
+		assert.Equal(t, sent, <-drained, "every accepted send is delivered before the close")
+		assert.Equal(t, StateShutdown, conn.State)
+		assert.ErrorIs(t, conn.trySend(jsonRPCResponse{}), errConnectionShutdown)
+	}
+}
+
+func TestChannelGuard_Reconnect(t *testing.T) {
+	cm := NewConnectionManager()
+	old, _ := cm.NewConnection("session")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				err := old.trySend(jsonRPCResponse{})
+				if err != nil && err != errChannelFull {
+					assert.ErrorIs(t, err, errConnectionShutdown)
+				}
+			}
+		}()
+	}
+	go drain(old.Channel)
+
+	// Reconnecting under the same ID shuts the replaced connection down
+	replacement, _ := cm.NewConnection("session")
+	wg.Wait()
+
+	assert.Equal(t, StateShutdown, old.State)
+	assert.ErrorIs(t, old.trySend(jsonRPCResponse{}), errConnectionShutdown)
+	require.NoError(t, replacement.trySend(jsonRPCResponse{}))
+
+	// The old handler tearing down must leave the replacement registered
+	assert.False(t, cm.removeConnectionInstance(old))
+	assert.Same(t, replacement, cm.GetConnection("session"))
+
+	assert.True(t, cm.removeConnectionInstance(replacement))
+	assert.Nil(t, cm.GetConnection("session"))
+	assert.Equal(t, 1, drain(replacement.Channel))
+}
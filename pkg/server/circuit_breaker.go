@@ -0,0 +1,139 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+)
+
+// circuitState is the state of one upstream host's circuit.
+type circuitState string
+
+const (
+	circuitClosed   circuitState = "closed"    // Calls flow normally
+	circuitOpen     circuitState = "open"      // Calls fail fast until the cooldown ends
+	circuitHalfOpen circuitState = "half-open" // One probe call is in flight to test recovery
+)
+
+// defaultCircuitBreakerCooldown is how long a circuit stays open when not configured.
+const defaultCircuitBreakerCooldown = 30 * time.Second
+
+// circuit tracks consecutive failures for one upstream host.
+type circuit struct {
+	state        circuitState
+	failures     int       // Consecutive failures in the current streak
+	firstFailure time.Time // Start of the current streak, for the failure window
+	openedAt     time.Time
+}
+
+// circuitBreaker fails tool calls fast while an upstream host is known to be down.
+type circuitBreaker struct {
+	mutex    sync.Mutex
+	circuits map[string]*circuit
+	now      func() time.Time
+}
+
+func newCircuitBreaker() *circuitBreaker {
+	return &circuitBreaker{
+		circuits: make(map[string]*circuit),
+		now:      time.Now,
+	}
+}
+
+// Global breaker shared by all connections, since they share the upstream hosts
+var upstreamCircuitBreaker = newCircuitBreaker()
+
+// errCircuitOpen reports that a call was rejected without contacting the upstream.
+type errCircuitOpen struct {
+	host       string
+	retryAfter time.Duration
+}
+
+func (e *errCircuitOpen) Error() string {
+	if e.retryAfter <= 0 {
+		return fmt.Sprintf("upstream %s unavailable (circuit open, recovery probe in progress)", e.host)
+	}
+	return fmt.Sprintf("upstream %s unavailable (circuit open, retry in %s)", e.host, e.retryAfter.Round(time.Second))
+}
+
+// circuitBreakerCooldown resolves the open-circuit cooldown from config, applying the default.
+func circuitBreakerCooldown(cfg *config.Config) time.Duration {
+	if cfg.CircuitBreakerCooldown > 0 {
+		return cfg.CircuitBreakerCooldown
+	}
+	return defaultCircuitBreakerCooldown
+}
+
+// allow reports whether a call to host may proceed. Once the cooldown of an open circuit has
+// passed, exactly one call is let through as a half-open probe; the rest keep failing fast.
+func (b *circuitBreaker) allow(host string, cfg *config.Config) error {
+	if cfg == nil || cfg.CircuitBreakerThreshold <= 0 {
+		return nil
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c := b.circuits[host]
+	if c == nil {
+		return nil
+	}
+	switch c.state {
+	case circuitOpen:
+		remaining := c.openedAt.Add(circuitBreakerCooldown(cfg)).Sub(b.now())
+		if remaining > 0 {
+			return &errCircuitOpen{host: host, retryAfter: remaining}
+		}
+		log.Printf("[CircuitBreaker] Cooldown over for %s, letting a probe call through", host)
+		c.state = circuitHalfOpen
+		return nil
+	case circuitHalfOpen:
+		return &errCircuitOpen{host: host}
+	default:
+		return nil
+	}
+}
+
+// record updates host's circuit with the outcome of a call that allow let through.
+func (b *circuitBreaker) record(host string, success bool, cfg *config.Config) {
+	if cfg == nil || cfg.CircuitBreakerThreshold <= 0 {
+		return
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c := b.circuits[host]
+	if success {
+		if c != nil && c.state != circuitClosed {
+			log.Printf("[CircuitBreaker] Probe to %s succeeded, closing circuit", host)
+		}
+		delete(b.circuits, host)
+		return
+	}
+
+	now := b.now()
+	if c == nil {
+		c = &circuit{state: circuitClosed}
+		b.circuits[host] = c
+	}
+	if c.state == circuitHalfOpen {
+		log.Printf("[CircuitBreaker] Probe to %s failed, reopening circuit", host)
+		c.state = circuitOpen
+		c.openedAt = now
+		return
+	}
+
+	// A streak older than the window starts over
+	if c.failures == 0 || (cfg.CircuitBreakerWindow > 0 && now.Sub(c.firstFailure) > cfg.CircuitBreakerWindow) {
+		c.failures = 0
+		c.firstFailure = now
+	}
+	c.failures++
+	if c.state == circuitClosed && c.failures >= cfg.CircuitBreakerThreshold {
+		log.Printf("[CircuitBreaker] %d consecutive failures for %s, opening circuit for %s", c.failures, host, circuitBreakerCooldown(cfg))
+		c.state = circuitOpen
+		c.openedAt = now
+	}
+}
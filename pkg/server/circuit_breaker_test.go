@@ -0,0 +1,119 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useFakeCircuitClock swaps in a fresh global breaker driven by the returned clock.
+func useFakeCircuitClock(t *testing.T) *time.Time {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	breaker := newCircuitBreaker()
+	breaker.now = func() time.Time { return now }
+	previous := upstreamCircuitBreaker
+	upstreamCircuitBreaker = breaker
+	t.Cleanup(func() { upstreamCircuitBreaker = previous })
+	return &now
+}
+
+func TestToolCall_CircuitBreaker(t *testing.T) {
+	now := useFakeCircuitClock(t)
+
+	var hits int32
+	var healthy atomic.Bool
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"get_status": {Method: "GET", Path: "/status", BaseURL: backend.URL},
+	}}
+	cfg := &config.Config{CircuitBreakerThreshold: 3, CircuitBreakerWindow: time.Minute, CircuitBreakerCooldown: 30 * time.Second}
+
+	call := func() ToolResultPayload {
+		params, _ := json.Marshal(ToolCallParams{ToolName: "get_status", Input: map[string]interface{}{}})
+		req := &jsonRPCRequest{Jsonrpc: "2.0", Method: "tools/call", Params: json.RawMessage(params), ID: "cb"}
+		result, ok := handleToolCallJSONRPC("test-conn", req, toolSet, cfg).Result.(ToolResultPayload)
+		require.True(t, ok)
+		return result
+	}
+
+	// Three consecutive 5xx responses trip the breaker
+	for i := 0; i < 3; i++ {
+		assert.True(t, call().IsError)
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&hits))
+
+	// Open: fail fast without contacting the upstream
+	healthy.Store(true)
+	result := call()
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "unavailable (circuit open, retry in 30s)")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&hits))
+
+	// After the cooldown a probe goes through; success closes the circuit
+	*now = now.Add(31 * time.Second)
+	assert.False(t, call().IsError)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&hits))
+	assert.False(t, call().IsError)
+	assert.Equal(t, int32(5), atomic.LoadInt32(&hits))
+}
+
+func TestCircuitBreaker_StateTransitions(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	breaker := newCircuitBreaker()
+	breaker.now = func() time.Time { return now }
+	cfg := &config.Config{CircuitBreakerThreshold: 2, CircuitBreakerWindow: time.Minute, CircuitBreakerCooldown: 10 * time.Second}
+
+	// Disabled by default
+	disabled := &config.Config{}
+	for i := 0; i < 10; i++ {
+		breaker.record("api.example.com", false, disabled)
+	}
+	assert.NoError(t, breaker.allow("api.example.com", disabled))
+
+	// Failures outside the window don't count as consecutive
+	breaker.record("api.example.com", false, cfg)
+	now = now.Add(2 * time.Minute)
+	breaker.record("api.example.com", false, cfg)
+	assert.NoError(t, breaker.allow("api.example.com", cfg))
+
+	// A success resets the streak
+	breaker.record("api.example.com", true, cfg)
+	breaker.record("api.example.com", false, cfg)
+	assert.NoError(t, breaker.allow("api.example.com", cfg))
+
+	// Second consecutive failure opens; other hosts are unaffected
+	breaker.record("api.example.com", false, cfg)
+	assert.Error(t, breaker.allow("api.example.com", cfg))
+	assert.NoError(t, breaker.allow("other.example.com", cfg))
+
+	// Half-open lets exactly one probe through; a failed probe reopens
+	now = now.Add(11 * time.Second)
+	require.NoError(t, breaker.allow("api.example.com", cfg))
+	assert.ErrorContains(t, breaker.allow("api.example.com", cfg), "recovery probe in progress")
+	breaker.record("api.example.com", false, cfg)
+	assert.ErrorContains(t, breaker.allow("api.example.com", cfg), "retry in 10s")
+
+	// A successful probe closes the circuit
+	now = now.Add(11 * time.Second)
+	require.NoError(t, breaker.allow("api.example.com", cfg))
+	breaker.record("api.example.com", true, cfg)
+	assert.NoError(t, breaker.allow("api.example.com", cfg))
+	assert.NoError(t, breaker.allow("api.example.com", cfg))
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"log"
+	"path"
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// clientToolRule returns the first configured rule matching the client name a connection
+// initialized with. Connections that sent no clientInfo match no rule.
+func clientToolRule(connID string, cfg *config.Config) (config.ClientToolRule, bool) {
+	if cfg == nil || len(cfg.ClientToolRules) == 0 {
+		return config.ClientToolRule{}, false
+	}
+	name := strings.ToLower(mcpConnectionManager.ClientName(connID))
+	if name == "" {
+		return config.ClientToolRule{}, false
+	}
+	for _, rule := range cfg.ClientToolRules {
+		if matched, _ := path.Match(strings.ToLower(rule.Client), name); matched {
+			return rule, true
+		}
+	}
+	return config.ClientToolRule{}, false
+}
+
+// filterToolsForClient narrows a connection's tools/list to what its client's rule allows: only
+// generated tools with one of the rule's tags, then at most MaxTools. Registered and built-in
+// tools have no tags and are kept by the tag filter.
+func filterToolsForClient(connID string, tools []mcp.Tool, toolSet *mcp.ToolSet, cfg *config.Config) []mcp.Tool {
+	rule, ok := clientToolRule(connID, cfg)
+	if !ok {
+		return tools
+	}
+	filtered := tools
+	if len(rule.Tags) > 0 {
+		filtered = make([]mcp.Tool, 0, len(tools))
+		for _, tool := range tools {
+			operation, generated := toolSet.Operations[tool.Name]
+			if !generated || hasAnyTag(operation.Tags, rule.Tags) {
+				filtered = append(filtered, tool)
+			}
+		}
+	}
+	if rule.MaxTools > 0 && len(filtered) > rule.MaxTools {
+		filtered = filtered[:rule.MaxTools]
+	}
+	log.Printf("Listing %d of %d tools for %s under the client rule for '%s'", len(filtered), len(tools), connID, rule.Client)
+	return filtered
+}
+
+// hasAnyTag reports whether tags contains any of wanted.
+func hasAnyTag(tags, wanted []string) bool {
+	for _, tag := range wanted {
+		if sliceContainsString(tags, tag) {
+			return true
+		}
+	}
+	return false
+}
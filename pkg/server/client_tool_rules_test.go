@@ -0,0 +1,72 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listToolsAs initializes a connection with the given client name and returns the names its
+// tools/list shows.
+func listToolsAs(t *testing.T, connID, clientName string, toolSet *mcp.ToolSet, cfg *config.Config) []string {
+	t.Helper()
+	conn, _ := setupTestConnection(connID)
+	t.Cleanup(func() { cleanupTestConnection(connID) })
+
+	params := map[string]interface{}{"protocolVersion": defaultProtocolVersion}
+	if clientName != "" {
+		params["clientInfo"] = map[string]interface{}{"name": clientName, "version": "1.0"}
+	}
+	initialize := &jsonRPCRequest{Jsonrpc: "2.0", ID: 1, Method: "initialize", Params: params}
+	resp, _ := dispatchJSONRPC(conn, connID, initialize, toolSet, cfg)
+	require.Nil(t, resp.Error)
+	mcpConnectionManager.UpdateState(connID, StateReady)
+
+	resp, _ = dispatchJSONRPC(conn, connID, &jsonRPCRequest{Jsonrpc: "2.0", ID: 2, Method: "tools/list"}, toolSet, cfg)
+	require.Nil(t, resp.Error)
+	var names []string
+	for _, tool := range resp.Result.(map[string]interface{})["tools"].([]mcp.Tool) {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+func TestToolsList_ClientToolRules(t *testing.T) {
+	toolSet := &mcp.ToolSet{
+		Tools: []mcp.Tool{{Name: "list_pets"}, {Name: "get_pet"}, {Name: "delete_pet"}, {Name: "get_invoice"}},
+		Operations: map[string]mcp.OperationDetail{
+			"list_pets":   {Method: "GET", Path: "/pets", Tags: []string{"pets", "public"}},
+			"get_pet":     {Method: "GET", Path: "/pets/{id}", Tags: []string{"pets", "public"}},
+			"delete_pet":  {Method: "DELETE", Path: "/pets/{id}", Tags: []string{"pets", "admin"}},
+			"get_invoice": {Method: "GET", Path: "/invoices/{id}", Tags: []string{"billing"}},
+		},
+	}
+	cfg := &config.Config{
+		DisableDescribeTool: true,
+		ClientToolRules: []config.ClientToolRule{
+			{Client: "Mobile-*", Tags: []string{"public"}},
+			{Client: "tiny-client", MaxTools: 2},
+		},
+	}
+
+	assert.Equal(t, []string{"list_pets", "get_pet"}, listToolsAs(t, "client-rules-mobile", "mobile-assistant", toolSet, cfg),
+		"the tag rule applies, matching the name case-insensitively")
+	assert.Equal(t, []string{"list_pets", "get_pet"}, listToolsAs(t, "client-rules-tiny", "tiny-client", toolSet, cfg),
+		"the tool cap applies")
+	assert.Equal(t, []string{"list_pets", "get_pet", "delete_pet", "get_invoice"}, listToolsAs(t, "client-rules-desktop", "desktop", toolSet, cfg),
+		"clients matching no rule see every tool")
+	assert.Len(t, listToolsAs(t, "client-rules-anonymous", "", toolSet, cfg), 4, "clients without clientInfo see every tool")
+}
+
+func TestToolsList_ClientTagRuleKeepsBuiltins(t *testing.T) {
+	toolSet := &mcp.ToolSet{
+		Tools:      []mcp.Tool{{Name: "get_invoice"}},
+		Operations: map[string]mcp.OperationDetail{"get_invoice": {Method: "GET", Path: "/invoices/{id}", Tags: []string{"billing"}}},
+	}
+	cfg := &config.Config{ClientToolRules: []config.ClientToolRule{{Client: "*", Tags: []string{"public"}}}}
+
+	assert.Equal(t, []string{describeOperationToolName}, listToolsAs(t, "client-rules-builtin", "any", toolSet, cfg))
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// acceptEncoding is advertised on every upstream request. Setting it explicitly
+// disables the transport's implicit gzip handling, so decodeResponseBody owns decompression.
+const acceptEncoding = "gzip, deflate"
+
+// decompressedBody closes both the decompressing reader and the underlying response body.
+type decompressedBody struct {
+	io.Reader
+	decoder io.Closer
+	body    io.Closer
+}
+
+func (d *decompressedBody) Close() error {
+	decErr := d.decoder.Close()
+	bodyErr := d.body.Close()
+	if decErr != nil {
+		return decErr
+	}
+	return bodyErr
+}
+
+// decodeResponseBody replaces resp.Body with a decompressing reader according to the
+// response's Content-Encoding. Bodies the transport already decompressed are left alone.
+func decodeResponseBody(resp *http.Response) error {
+	if resp.Uncompressed {
+		return nil
+	}
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if encoding == "" || encoding == "identity" {
+		return nil
+	}
+	// Empty bodies (HEAD, 204, 304) carry the header but nothing to decode
+	isHead := resp.Request != nil && resp.Request.Method == http.MethodHead
+	if isHead || resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified || resp.ContentLength == 0 {
+		return nil
+	}
+	// So may bodies of unknown length
+	br := bufio.NewReader(resp.Body)
+	if _, err := br.Peek(1); err == io.EOF {
+		return nil
+	}
+
+	var decoder io.ReadCloser
+	switch encoding {
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("invalid gzip response body: %w", err)
+		}
+		decoder = gz
+	case "deflate":
+		// "deflate" is meant to be zlib-wrapped, but some servers send raw DEFLATE.
+		header, _ := br.Peek(2)
+		if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			zr, err := zlib.NewReader(br)
+			if err != nil {
+				return fmt.Errorf("invalid deflate response body: %w", err)
+			}
+			decoder = zr
+		} else {
+			decoder = flate.NewReader(br)
+		}
+	default:
+		log.Printf("[ExecuteToolCall] Warning: Unsupported response Content-Encoding '%s', passing body through undecoded.", encoding)
+		return nil
+	}
+
+	resp.Body = &decompressedBody{Reader: decoder, decoder: decoder, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// gzipBytes compresses data with gzip.
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// rejectsRequestEncoding reports whether a response to a gzip-encoded request body says the
+// backend can't read that encoding: 415 Unsupported Media Type, or a 400 listing the encodings it
+// does accept in Accept-Encoding without gzip (RFC 7694). Other 400s are left alone.
+func rejectsRequestEncoding(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusUnsupportedMediaType:
+		return true
+	case http.StatusBadRequest:
+		accepted := resp.Header.Values("Accept-Encoding")
+		if len(accepted) == 0 {
+			return false
+		}
+		for _, value := range accepted {
+			for _, coding := range strings.Split(value, ",") {
+				name, _, _ := strings.Cut(coding, ";")
+				name = strings.ToLower(strings.TrimSpace(name))
+				if name == "gzip" || name == "x-gzip" || name == "*" {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// uncompressedRequest copies req with body sent as is instead of gzipped.
+func uncompressedRequest(req *http.Request, body []byte) (*http.Request, error) {
+	retry, err := http.NewRequest(req.Method, req.URL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	retry.Header = req.Header.Clone()
+	retry.Header.Del("Content-Encoding")
+	return retry, nil
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteToolCall_GzipResponse(t *testing.T) {
+	const payload = `{"items":[1,2,3]}`
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.Header.Get("Accept-Encoding"), "gzip")
+		compressed, err := gzipBytes([]byte(payload))
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusOK)
+		w.Write(compressed)
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"list_items": {Method: "GET", Path: "/items", BaseURL: backend.URL},
+	}}
+	resp, err := executeToolCall(&ToolCallParams{ToolName: "list_items", Input: map[string]interface{}{}}, toolSet, &config.Config{})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, payload, string(body))
+	assert.Empty(t, resp.Header.Get("Content-Encoding"))
+}
+
+func TestExecuteToolCall_RequestCompression(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           string
+		minBytes       int
+		expectEncoding string
+	}{
+		{name: "Body above threshold is gzipped", data: strings.Repeat("x", 256), minBytes: 64, expectEncoding: "gzip"},
+		{name: "Tiny body is sent as-is", data: "x", minBytes: 64, expectEncoding: ""},
+		{name: "Compression disabled", data: strings.Repeat("x", 256), minBytes: 0, expectEncoding: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var receivedEncoding string
+			var receivedBody []byte
+			backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				receivedEncoding = r.Header.Get("Content-Encoding")
+				var reader io.Reader = r.Body
+				if receivedEncoding == "gzip" {
+					gz, err := gzip.NewReader(r.Body)
+					require.NoError(t, err)
+					reader = gz
+				}
+				receivedBody, _ = io.ReadAll(reader)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer backend.Close()
+
+			toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+				"post_data": {Method: "POST", Path: "/data", BaseURL: backend.URL},
+			}}
+			cfg := &config.Config{RequestCompressionMinBytes: tc.minBytes}
+			resp, err := executeToolCall(&ToolCallParams{ToolName: "post_data", Input: map[string]interface{}{"data": tc.data}}, toolSet, cfg)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tc.expectEncoding, receivedEncoding)
+			assert.JSONEq(t, `{"data":"`+tc.data+`"}`, string(receivedBody))
+		})
+	}
+}
+
+func TestExecuteToolCall_RequestCompressionFallback(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		acceptEncoding   string
+		expectedAttempts []string
+		expectedStatus   int
+	}{
+		{name: "415 is retried uncompressed", status: http.StatusUnsupportedMediaType, expectedAttempts: []string{"gzip", ""}, expectedStatus: http.StatusOK},
+		{name: "400 listing accepted encodings is retried", status: http.StatusBadRequest, acceptEncoding: "identity", expectedAttempts: []string{"gzip", ""}, expectedStatus: http.StatusOK},
+		{name: "Generic 400 is not retried", status: http.StatusBadRequest, expectedAttempts: []string{"gzip"}, expectedStatus: http.StatusBadRequest},
+		{name: "400 accepting gzip is not retried", status: http.StatusBadRequest, acceptEncoding: "gzip, identity", expectedAttempts: []string{"gzip"}, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var attempts []string
+			var lastBody []byte
+			backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				encoding := r.Header.Get("Content-Encoding")
+				attempts = append(attempts, encoding)
+				if encoding == "gzip" {
+					if tc.acceptEncoding != "" {
+						w.Header().Set("Accept-Encoding", tc.acceptEncoding)
+					}
+					w.WriteHeader(tc.status)
+					return
+				}
+				lastBody, _ = io.ReadAll(r.Body)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer backend.Close()
+
+			data := strings.Repeat("x", 256)
+			toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+				"post_data": {Method: "POST", Path: "/data", BaseURL: backend.URL},
+			}}
+			cfg := &config.Config{RequestCompressionMinBytes: 64}
+			resp, err := executeToolCall(&ToolCallParams{ToolName: "post_data", Input: map[string]interface{}{"data": data}}, toolSet, cfg)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tc.expectedAttempts, attempts)
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			if len(tc.expectedAttempts) == 2 {
+				assert.JSONEq(t, `{"data":"`+data+`"}`, string(lastBody), "the retry sends the same body uncompressed")
+			}
+		})
+	}
+}
+
+func TestDecodeResponseBody(t *testing.T) {
+	const payload = "hello, upstream"
+
+	var zlibBuf, flateBuf bytes.Buffer
+	zw := zlib.NewWriter(&zlibBuf)
+	zw.Write([]byte(payload))
+	zw.Close()
+	fw, _ := flate.NewWriter(&flateBuf, flate.DefaultCompression)
+	fw.Write([]byte(payload))
+	fw.Close()
+	gzipped, err := gzipBytes([]byte(payload))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		encoding     string
+		body         []byte
+		uncompressed bool
+		expected     string
+	}{
+		{name: "gzip", encoding: "gzip", body: gzipped, expected: payload},
+		{name: "zlib-wrapped deflate", encoding: "deflate", body: zlibBuf.Bytes(), expected: payload},
+		{name: "raw deflate", encoding: "deflate", body: flateBuf.Bytes(), expected: payload},
+		{name: "identity", encoding: "", body: []byte(payload), expected: payload},
+		{name: "already decompressed by transport", encoding: "gzip", body: []byte(payload), uncompressed: true, expected: payload},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode:    http.StatusOK,
+				Header:        http.Header{},
+				Body:          io.NopCloser(bytes.NewReader(tc.body)),
+				ContentLength: int64(len(tc.body)),
+				Uncompressed:  tc.uncompressed,
+			}
+			if tc.encoding != "" {
+				resp.Header.Set("Content-Encoding", tc.encoding)
+			}
+
+			require.NoError(t, decodeResponseBody(resp))
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(body))
+			assert.NoError(t, resp.Body.Close())
+		})
+	}
+
+	t.Run("empty gzip bodies", func(t *testing.T) {
+		head := httptest.NewRequest(http.MethodHead, "/report", nil)
+		get := httptest.NewRequest(http.MethodGet, "/report", nil)
+		for name, resp := range map[string]*http.Response{
+			"HEAD":           {StatusCode: http.StatusOK, Request: head, ContentLength: 512},
+			"204":            {StatusCode: http.StatusNoContent, Request: get, ContentLength: -1},
+			"304":            {StatusCode: http.StatusNotModified, Request: get, ContentLength: -1},
+			"zero length":    {StatusCode: http.StatusOK, Request: get, ContentLength: 0},
+			"unknown length": {StatusCode: http.StatusOK, Request: get, ContentLength: -1},
+		} {
+			resp.Header = http.Header{"Content-Encoding": []string{"gzip"}}
+			resp.Body = io.NopCloser(strings.NewReader(""))
+			require.NoError(t, decodeResponseBody(resp), name)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err, name)
+			assert.Empty(t, body, name)
+		}
+	})
+
+	t.Run("corrupt gzip", func(t *testing.T) {
+		resp := &http.Response{
+			StatusCode:    http.StatusOK,
+			Header:        http.Header{"Content-Encoding": []string{"gzip"}},
+			Body:          io.NopCloser(strings.NewReader("not gzip")),
+			ContentLength: 8,
+		}
+		assert.Error(t, decodeResponseBody(resp))
+	})
+}
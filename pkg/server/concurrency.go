@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// toolConcurrencyLimiter holds one semaphore per limited tool, shared by every connection.
+type toolConcurrencyLimiter struct {
+	mutex sync.Mutex
+	slots map[string]chan struct{} // Buffered to the tool's limit; one element per running call
+}
+
+var toolConcurrency = &toolConcurrencyLimiter{slots: make(map[string]chan struct{})}
+
+// effectiveMaxConcurrency is the most calls to a tool that may run at once: the
+// --operation-max-concurrency override, else the operation's x-mcp-max-concurrency. Zero means
+// unlimited.
+func effectiveMaxConcurrency(toolName string, operation mcp.OperationDetail, cfg *config.Config) int {
+	if cfg != nil {
+		if limit, ok := cfg.OperationMaxConcurrency[toolName]; ok && limit > 0 {
+			return limit
+		}
+	}
+	if operation.Concurrency > 0 {
+		return operation.Concurrency
+	}
+	return 0
+}
+
+// semaphore returns the tool's semaphore, replacing it when the limit changed (after a spec
+// reload). Calls holding a slot of the old one release it there.
+func (l *toolConcurrencyLimiter) semaphore(toolName string, limit int) chan struct{} {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	slots, ok := l.slots[toolName]
+	if !ok || cap(slots) != limit {
+		slots = make(chan struct{}, limit)
+		l.slots[toolName] = slots
+	}
+	return slots
+}
+
+// acquire waits for a free slot of the tool until ctx is done, returning the function that frees
+// it. A limit of zero or less never waits.
+func (l *toolConcurrencyLimiter) acquire(ctx context.Context, toolName string, limit int) (func(), error) {
+	if limit <= 0 {
+		return func() {}, nil
+	}
+	slots := l.semaphore(toolName, limit)
+	release := func() { <-slots }
+	select {
+	case slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	log.Printf("[ExecuteToolCall] Tool '%s' is at its limit of %d concurrent calls; waiting", toolName, limit)
+	select {
+	case slots <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out waiting for one of the tool's %d concurrent call slots: %w", limit, ctx.Err())
+	}
+}
+
+// acquireToolSlot waits for the tool's concurrency limit to allow another call, for at most the
+// tool's timeout.
+func acquireToolSlot(ctx context.Context, toolName string, toolSet *mcp.ToolSet, cfg *config.Config) (func(), error) {
+	operation := toolSet.Operations[toolName]
+	limit := effectiveMaxConcurrency(toolName, operation, cfg)
+	if limit <= 0 {
+		return func() {}, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, effectiveTimeout(toolName, operation, cfg))
+	defer cancel()
+	return toolConcurrency.acquire(ctx, toolName, limit)
+}
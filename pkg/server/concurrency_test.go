@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveMaxConcurrency(t *testing.T) {
+	operation := mcp.OperationDetail{Concurrency: 3}
+	assert.Equal(t, 0, effectiveMaxConcurrency("export", mcp.OperationDetail{}, &config.Config{}), "unlimited by default")
+	assert.Equal(t, 3, effectiveMaxConcurrency("export", operation, nil))
+	cfg := &config.Config{OperationMaxConcurrency: map[string]int{"export": 1}}
+	assert.Equal(t, 1, effectiveMaxConcurrency("export", operation, cfg), "configured limit wins over the extension")
+	assert.Equal(t, 3, effectiveMaxConcurrency("other", operation, cfg))
+}
+
+func TestInvokeTool_MaxConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			previous := peak.Load()
+			if current <= previous || peak.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"export_report": {Method: "GET", Path: "/export", BaseURL: backend.URL, Concurrency: 2},
+	}}
+	var wg sync.WaitGroup
+	results := make([]ToolResultPayload, 6)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			params := &ToolCallParams{ToolName: "export_report", Input: map[string]interface{}{}}
+			result, err := invokeTool(context.Background(), "conn", &jsonRPCRequest{ID: i}, params, toolSet, &config.Config{})
+			require.NoError(t, err)
+			results[i] = result.(ToolResultPayload)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), peak.Load(), "no more than the limit run at once")
+	for _, result := range results {
+		assert.False(t, result.IsError, "calls over the limit wait instead of failing")
+	}
+}
+
+func TestInvokeTool_MaxConcurrencyWaitTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer backend.Close()
+	defer close(release)
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"slow_report": {Method: "GET", Path: "/slow", BaseURL: backend.URL},
+	}}
+	cfg := &config.Config{
+		OperationMaxConcurrency: map[string]int{"slow_report": 1},
+		OperationTimeouts:       map[string]time.Duration{"slow_report": 100 * time.Millisecond},
+	}
+	params := &ToolCallParams{ToolName: "slow_report", Input: map[string]interface{}{}}
+
+	// Hold the only slot directly so the call has to wait for it
+	held, err := toolConcurrency.acquire(context.Background(), "slow_report", 1)
+	require.NoError(t, err)
+	defer held()
+
+	_, err = invokeTool(context.Background(), "conn", &jsonRPCRequest{ID: 1}, params, toolSet, cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 concurrent call slots")
+}
+
+func TestToolConcurrencyLimiter_Unlimited(t *testing.T) {
+	limiter := &toolConcurrencyLimiter{slots: make(map[string]chan struct{})}
+	for i := 0; i < 3; i++ {
+		release, err := limiter.acquire(context.Background(), "any", 0)
+		require.NoError(t, err)
+		defer release()
+	}
+	assert.Empty(t, limiter.slots, "unlimited tools get no semaphore")
+}
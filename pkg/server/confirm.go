@@ -0,0 +1,18 @@
+package server
+
+import (
+	"log"
+
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// confirmationMetaKey is the tools/list _meta entry marking a tool whose calls a human should
+// always confirm, set from x-mcp-confirm. It goes beyond destructiveHint, which clients may treat
+// as advisory.
+const confirmationMetaKey = "requiresConfirmation"
+
+// auditConfirmedCall logs a prominent audit entry for a call to a tool marked x-mcp-confirm. The
+// arguments are left out; the regular execution log carries them.
+func auditConfirmedCall(connID, toolName string, operation mcp.OperationDetail) {
+	log.Printf("[Audit] *** Confirmation-required tool '%s' (%s %s) invoked by %s ***", toolName, operation.Method, operation.Path, connID)
+}
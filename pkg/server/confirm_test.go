@@ -0,0 +1,55 @@
+package server
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_ConfirmationRequired(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{
+		Tools: []mcp.Tool{
+			{Name: "purge_account", InputSchema: mcp.Schema{Type: "object"}},
+			{Name: "get_account", InputSchema: mcp.Schema{Type: "object"}},
+		},
+		Operations: map[string]mcp.OperationDetail{
+			"purge_account": {Method: "DELETE", Path: "/account", BaseURL: backend.URL, Confirm: true},
+			"get_account":   {Method: "GET", Path: "/account", BaseURL: backend.URL},
+		},
+	}
+	s := NewServer(toolSet, &config.Config{})
+
+	resp := dispatchToReadyConnection(t, s, "tools/list", map[string]interface{}{})
+	require.Nil(t, resp.Error)
+	meta := map[string]map[string]interface{}{}
+	for _, tool := range resp.Result.(map[string]interface{})["tools"].([]mcp.Tool) {
+		meta[tool.Name] = tool.Meta
+	}
+	assert.Equal(t, true, meta["purge_account"][confirmationMetaKey])
+	assert.NotContains(t, meta["get_account"], confirmationMetaKey)
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	resp = dispatchToReadyConnection(t, s, "tools/call", map[string]interface{}{"name": "purge_account"})
+	require.Nil(t, resp.Error)
+	assert.Contains(t, logged.String(), "[Audit] *** Confirmation-required tool 'purge_account' (DELETE /account) invoked by test-custom-tool-conn ***")
+
+	logged.Reset()
+	dispatchToReadyConnection(t, s, "tools/call", map[string]interface{}{"name": "get_account"})
+	assert.NotContains(t, logged.String(), "[Audit]", "unmarked tools are not audited")
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
+// ErrInvalidConnectionID is wrapped by the errors for IDs rejected by the connection ID policy.
+var ErrInvalidConnectionID = errors.New("invalid connection ID")
+
+// defaultMaxConnectionIDLength is the longest connection ID accepted unless the policy says
+// otherwise. Generated IDs are 32 (random) or 36 (UUIDv7) characters.
+const defaultMaxConnectionIDLength = 128
+
+// ConnectionIDPolicy decides which connection IDs are accepted. Client-supplied IDs end up in
+// logs, response headers and as keys of the state file, so overly long ones, control characters
+// and path-like strings are refused. Whatever the policy, empty IDs, control characters and the
+// IDs "." and ".." are always rejected.
+type ConnectionIDPolicy struct {
+	MaxLength int            // Longest accepted ID in bytes; zero or less uses defaultMaxConnectionIDLength
+	Allowed   *regexp.Regexp // Pattern the whole ID must match; nil allows printable ASCII except space, '/' and '\'
+}
+
+// Validate reports why id is not acceptable, or nil.
+func (p ConnectionIDPolicy) Validate(id string) error {
+	maxLength := p.MaxLength
+	if maxLength <= 0 {
+		maxLength = defaultMaxConnectionIDLength
+	}
+	switch {
+	case id == "":
+		return fmt.Errorf("%w: empty", ErrInvalidConnectionID)
+	case len(id) > maxLength:
+		return fmt.Errorf("%w: %d bytes long, the limit is %d", ErrInvalidConnectionID, len(id), maxLength)
+	case !utf8.ValidString(id):
+		return fmt.Errorf("%w: not valid UTF-8", ErrInvalidConnectionID)
+	case id == "." || id == "..":
+		return fmt.Errorf("%w: %q is a path segment", ErrInvalidConnectionID, id)
+	}
+	for _, r := range id {
+		if r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0) {
+			return fmt.Errorf("%w: contains control character %U", ErrInvalidConnectionID, r)
+		}
+	}
+
+	if p.Allowed != nil {
+		if loc := p.Allowed.FindStringIndex(id); loc == nil || loc[0] != 0 || loc[1] != len(id) {
+			return fmt.Errorf("%w: does not match %s", ErrInvalidConnectionID, p.Allowed)
+		}
+		return nil
+	}
+	for _, r := range id {
+		if r <= ' ' || r > '~' || r == '/' || r == '\\' {
+			return fmt.Errorf("%w: contains %q; only printable ASCII other than space, '/' and '\\' is allowed", ErrInvalidConnectionID, r)
+		}
+	}
+	return nil
+}
+
+// SetIDPolicy replaces the policy connection IDs are checked against when connections are created,
+// reattached or imported. The zero policy is the default.
+func (cm *ConnectionManager) SetIDPolicy(policy ConnectionIDPolicy) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	cm.idPolicy = policy
+}
+
+// validateID validates id against the current policy, for transports that must reject an ID
+// before they can create the connection.
+func (cm *ConnectionManager) validateID(id string) error {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+
+	return cm.idPolicy.Validate(id)
+}
+
+// ConnectionIDPolicyFromConfig builds the policy for --connection-id-max-length and
+// --connection-id-pattern. An empty pattern keeps the default character rule.
+func ConnectionIDPolicyFromConfig(maxLength int, pattern string) (ConnectionIDPolicy, error) {
+	policy := ConnectionIDPolicy{MaxLength: maxLength}
+	if pattern != "" {
+		allowed, err := regexp.Compile(pattern)
+		if err != nil {
+			return ConnectionIDPolicy{}, fmt.Errorf("invalid connection ID pattern %q: %w", pattern, err)
+		}
+		policy.Allowed = allowed
+	}
+	return policy, nil
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionIDPolicy_Default(t *testing.T) {
+	accepted := []string{
+		"abc",
+		"9f86d081884c7d659a2feaa0c55ad015",
+		"0190d6a4-6f3c-7cc2-a7d4-1e2f3a4b5c6d",
+		"QmFzZTY0-Id_with.dots~and:colons",
+		"a..b",
+		strings.Repeat("x", 128),
+	}
+	for _, id := range accepted {
+		assert.NoError(t, ConnectionIDPolicy{}.Validate(id), id)
+	}
+
+	rejected := map[string]string{
+		"empty":         "",
+		"too long":      strings.Repeat("x", 129),
+		"slash":         "../../etc/passwd",
+		"backslash":     `..\state`,
+		"dot":           ".",
+		"dot dot":       "..",
+		"space":         "two words",
+		"newline":       "id\nX-Injected: 1",
+		"tab":           "id\t1",
+		"delete":        "id\x7f",
+		"non-ASCII":     "séance",
+		"invalid UTF-8": "id\xff",
+		"C1 control":    "id\u0085",
+		"NUL":           "id\x00",
+		"tilde slash":   "~/session",
+	}
+	for name, id := range rejected {
+		err := ConnectionIDPolicy{}.Validate(id)
+		assert.ErrorIs(t, err, ErrInvalidConnectionID, name)
+	}
+}
+
+func TestConnectionIDPolicy_Custom(t *testing.T) {
+	policy := ConnectionIDPolicy{MaxLength: 8, Allowed: regexp.MustCompile(`[a-z0-9-]+`)}
+	assert.NoError(t, policy.Validate("abc-123"))
+	assert.ErrorContains(t, policy.Validate("abcdefghi"), "the limit is 8")
+	assert.ErrorContains(t, policy.Validate("ABC"), "does not match")
+	assert.ErrorContains(t, policy.Validate("abc_1"), "does not match", "the whole ID must match")
+
+	// Control characters are refused even if the pattern allows them
+	permissive := ConnectionIDPolicy{Allowed: regexp.MustCompile(`.+`)}
+	assert.NoError(t, permissive.Validate("a/b c"))
+	assert.ErrorIs(t, permissive.Validate("a\rb"), ErrInvalidConnectionID)
+	assert.ErrorIs(t, permissive.Validate(".."), ErrInvalidConnectionID)
+
+	_, err := ConnectionIDPolicyFromConfig(64, "[a-z")
+	assert.Error(t, err)
+	policy, err = ConnectionIDPolicyFromConfig(64, "[a-z]+")
+	require.NoError(t, err)
+	assert.Equal(t, 64, policy.MaxLength)
+	assert.Error(t, policy.Validate("a1"))
+}
+
+func TestConnectionManager_RejectsInvalidIDs(t *testing.T) {
+	cm := NewConnectionManager()
+	persisted := 0
+	cm.writeState = func() error {
+		persisted++
+		return nil
+	}
+
+	conn, err := cm.NewConnection("../escape")
+	assert.ErrorIs(t, err, ErrInvalidConnectionID)
+	assert.Nil(t, conn)
+	assert.Zero(t, cm.GetConnectionCount())
+	assert.Zero(t, persisted, "a rejected ID is never persisted")
+
+	conn, err = cm.NewConnection("fine-id")
+	require.NoError(t, err)
+	assert.Same(t, conn, cm.GetConnection("fine-id"))
+
+	_, ok, err := cm.ReattachConnection(strings.Repeat("x", 200))
+	assert.ErrorIs(t, err, ErrInvalidConnectionID)
+	assert.False(t, ok)
+
+	// A tightened policy applies to reattachment and imports as well
+	cm.SetIDPolicy(ConnectionIDPolicy{MaxLength: 4})
+	_, _, err = cm.ReattachConnection("fine-id")
+	assert.ErrorIs(t, err, ErrInvalidConnectionID)
+	err = cm.ImportState([]byte("connection:\n  much-too-long:\n    state: 3\n"))
+	assert.ErrorIs(t, err, ErrInvalidConnectionID)
+	assert.Nil(t, cm.GetConnection("much-too-long"))
+
+	calls := 0
+	cm.SetIDGenerator(sequenceGenerator([]string{"minted-too-long"}, &calls))
+	_, err = cm.NewGeneratedConnection(httptest.NewRequest(http.MethodGet, "/ws", nil))
+	assert.ErrorIs(t, err, ErrInvalidConnectionID)
+}
+
+func TestStreamablePost_RejectsInvalidSessionID(t *testing.T) {
+	srv := httptest.NewServer(newMCPMux(createTestToolSetForCall(), &config.Config{}))
+	defer srv.Close()
+
+	id := strings.Repeat("a", 200)
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/messages", strings.NewReader(`{"jsonrpc": "2.0", "id": 1, "method": "ping"}`))
+	require.NoError(t, err)
+	req.Header.Set("Mcp-Session-Id", id)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Nil(t, mcpConnectionManager.GetConnection(id))
+}
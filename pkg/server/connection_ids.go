@@ -0,0 +1,108 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// ConnectionIDGenerator mints the ID of a connection whose client did not supply one. r is the
+// request opening the connection, for generators that derive IDs from client details.
+type ConnectionIDGenerator func(r *http.Request) (string, error)
+
+// maxConnectionIDAttempts bounds how often a colliding generated ID is regenerated.
+const maxConnectionIDAttempts = 8
+
+// randomConnectionID is the default generator: 128 random bits as lowercase hex, which is URL-safe
+// and unaffected by the lowercasing of case-insensitive IDs.
+func randomConnectionID(*http.Request) (string, error) {
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf[:]), nil
+}
+
+// UUIDv7ConnectionID generates time-ordered UUIDv7 connection IDs, so IDs sort by creation time.
+func UUIDv7ConnectionID(*http.Request) (string, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+// connectionIDGenerators are the generators selectable by name with --connection-id-format.
+var connectionIDGenerators = map[string]ConnectionIDGenerator{
+	"random": randomConnectionID,
+	"uuidv7": UUIDv7ConnectionID,
+}
+
+// ConnectionIDGeneratorByName returns the built-in generator for a --connection-id-format value;
+// an empty name is the default random format.
+func ConnectionIDGeneratorByName(name string) (ConnectionIDGenerator, error) {
+	if name == "" {
+		return randomConnectionID, nil
+	}
+	if generator, ok := connectionIDGenerators[name]; ok {
+		return generator, nil
+	}
+	return nil, fmt.Errorf("unknown connection ID format %q (supported: random, uuidv7)", name)
+}
+
+// SetConnectionIDGenerator replaces the generator for server-minted connection IDs. nil restores
+// the default random IDs. Set it before serving.
+func (s *Server) SetConnectionIDGenerator(generator ConnectionIDGenerator) {
+	mcpConnectionManager.SetIDGenerator(generator)
+}
+
+// SetIDGenerator replaces the generator used by NewGeneratedConnection; nil restores the default.
+func (cm *ConnectionManager) SetIDGenerator(generator ConnectionIDGenerator) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	cm.idGenerator = generator
+}
+
+// NewGeneratedConnection creates a connection under a freshly generated ID. An ID that is already
+// in use is never reused: the generator is called again, up to maxConnectionIDAttempts times.
+func (cm *ConnectionManager) NewGeneratedConnection(r *http.Request) (*Connection, error) {
+	cm.mutex.RLock()
+	generator := cm.idGenerator
+	cm.mutex.RUnlock()
+	if generator == nil {
+		generator = randomConnectionID
+	}
+
+	for attempt := 0; attempt < maxConnectionIDAttempts; attempt++ {
+		// Generators may be slow or call out, so they run without the lock
+		id, err := generator(r)
+		if err != nil {
+			return nil, fmt.Errorf("generating connection ID: %w", err)
+		}
+		if id == "" {
+			return nil, errors.New("generating connection ID: generator returned an empty ID")
+		}
+
+		cm.mutex.Lock()
+		if err := cm.idPolicy.Validate(id); err != nil {
+			cm.mutex.Unlock()
+			return nil, fmt.Errorf("generating connection ID: %w", err)
+		}
+		if _, taken := cm.connections[cm.normalizeID(id)]; taken {
+			cm.mutex.Unlock()
+			log.Printf("[ConnectionManager] Generated connection ID %s is already in use, regenerating", id)
+			continue
+		}
+		conn := cm.newConnectionLocked(id)
+		cm.mutex.Unlock()
+		cm.flushState()
+		return conn, nil
+	}
+	return nil, fmt.Errorf("generating connection ID: every one of %d attempts collided with an existing connection", maxConnectionIDAttempts)
+}
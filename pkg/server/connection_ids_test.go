@@ -0,0 +1,81 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sequenceGenerator returns ids in order, counting how often it was called.
+func sequenceGenerator(ids []string, calls *int) ConnectionIDGenerator {
+	return func(*http.Request) (string, error) {
+		id := ids[*calls%len(ids)]
+		*calls++
+		return id, nil
+	}
+}
+
+func TestNewGeneratedConnection_CustomGenerator(t *testing.T) {
+	cm := NewConnectionManager()
+	calls := 0
+	cm.SetIDGenerator(sequenceGenerator([]string{"minted-1"}, &calls))
+
+	conn, err := cm.NewGeneratedConnection(httptest.NewRequest(http.MethodGet, "/ws", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "minted-1", conn.ID)
+	assert.Same(t, conn, cm.GetConnection("minted-1"))
+	assert.Equal(t, 1, calls)
+}
+
+func TestNewGeneratedConnection_RegeneratesOnCollision(t *testing.T) {
+	cm := NewConnectionManager()
+	existing, _ := cm.NewConnection("taken")
+	calls := 0
+	cm.SetIDGenerator(sequenceGenerator([]string{"TAKEN", "fresh"}, &calls))
+
+	conn, err := cm.NewGeneratedConnection(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "fresh", conn.ID)
+	assert.Equal(t, 2, calls, "the colliding ID is regenerated")
+	assert.Same(t, existing, cm.GetConnection("taken"), "the existing connection is untouched")
+}
+
+func TestNewGeneratedConnection_Failures(t *testing.T) {
+	cm := NewConnectionManager()
+	cm.NewConnection("taken")
+	calls := 0
+	cm.SetIDGenerator(sequenceGenerator([]string{"taken"}, &calls))
+
+	_, err := cm.NewGeneratedConnection(nil)
+	assert.ErrorContains(t, err, "collided")
+	assert.Equal(t, maxConnectionIDAttempts, calls)
+
+	cm.SetIDGenerator(func(*http.Request) (string, error) { return "", errors.New("entropy exhausted") })
+	_, err = cm.NewGeneratedConnection(nil)
+	assert.ErrorContains(t, err, "entropy exhausted")
+
+	cm.SetIDGenerator(func(*http.Request) (string, error) { return "", nil })
+	_, err = cm.NewGeneratedConnection(nil)
+	assert.ErrorContains(t, err, "empty ID")
+}
+
+func TestConnectionIDGenerators(t *testing.T) {
+	cm := NewConnectionManager()
+	conn, err := cm.NewGeneratedConnection(nil)
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{32}$`), conn.ID, "the default is a random hex token")
+
+	generator, err := ConnectionIDGeneratorByName("uuidv7")
+	require.NoError(t, err)
+	id, err := generator(nil)
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), id)
+
+	_, err = ConnectionIDGeneratorByName("sequential")
+	assert.Error(t, err)
+}
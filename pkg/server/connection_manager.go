@@ -2,6 +2,8 @@ package server
 
 import (
 	"log"
+	"net/http"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -38,24 +40,87 @@ func (s ConnectionState) String() string {
 
 // Connection represents an MCP connection
 type Connection struct {
-	ID            string               `yaml:"id"`
-	State         ConnectionState      `yaml:"state"`
-	Channel       chan jsonRPCResponse `yaml:"-"`
-	InitializedAt *time.Time           `yaml:"initializedAt"`
-	CreatedAt     time.Time            `yaml:"createdAt"`
+	ID              string               `yaml:"id"`
+	State           ConnectionState      `yaml:"state"`
+	Channel         chan jsonRPCResponse `yaml:"-"`
+	InitializedAt   *time.Time           `yaml:"initializedAt"`
+	CreatedAt       time.Time            `yaml:"createdAt"`
+	ProtocolVersion string               `yaml:"protocolVersion,omitempty"` // Negotiated during initialize
+	LastActivity    time.Time            `yaml:"lastActivity"`              // Last inbound message or keepalive
+
+	// ClientCapabilities is the capabilities object the client sent in initialize
+	ClientCapabilities map[string]interface{} `yaml:"clientCapabilities,omitempty"`
+
+	// ClientInfo is the clientInfo object (name, version) the client sent in initialize
+	ClientInfo map[string]interface{} `yaml:"clientInfo,omitempty"`
+
+	// SessionHeaders are the upstream headers taken from initialize _meta, see
+	// config.SessionHeaders. Sensitive ones are kept in sensitiveHeaders, which is not persisted.
+	SessionHeaders   map[string]string `yaml:"sessionHeaders,omitempty"`
+	sensitiveHeaders map[string]string
+
+	sequencer *responseSequencer // Orders responses when OrderedResponses is enabled
+	guard     channelGuard       // Closes Channel safely under concurrent writers, see acquireSend
+
+	deprecationWarned sync.Map // Tool names whose deprecation warning was sent on this connection
+
+	detached   bool // Restored or imported, and not yet claimed by a transport, see ReattachConnection
+	reapWarned bool // The reaper has warned that the connection is about to expire
+
+	handshakeTimer *time.Timer // Shuts the connection down unless it is ready in time, see SetHandshakeDeadline
+
+	history *callHistory // Recent tool calls, see SetCallHistorySize; nil until the first one
 }
 
 // ConnectionManager manages MCP connections and their states
 type ConnectionManager struct {
 	connections map[string]*Connection `yaml:"connection"`
 	mutex       sync.RWMutex
+
+	// byState indexes connections by state, keyed like connections. It is kept in step with
+	// connections under the write lock, so state counts are O(1). See setStateLocked.
+	byState map[ConnectionState]map[string]*Connection
+
+	// caseSensitiveIDs disables lowercasing of connection IDs. Off by default, so
+	// "ABC" and "abc" refer to the same connection.
+	caseSensitiveIDs bool
+
+	// idGenerator mints IDs for connections the client did not name; nil uses randomConnectionID.
+	idGenerator ConnectionIDGenerator
+
+	// idPolicy decides which connection IDs are accepted, see SetIDPolicy.
+	idPolicy ConnectionIDPolicy
+
+	// reapWarnFraction is the share of a connection's timeout after which Reap warns, see
+	// SetReapWarnFraction.
+	reapWarnFraction float64
+
+	// handshakeDeadline is how long a new connection has to become ready, see SetHandshakeDeadline.
+	handshakeDeadline time.Duration
+
+	// callHistorySize is how many recent tool calls each connection keeps, see SetCallHistorySize.
+	callHistorySize int
+
+	// State file persistence, see persist and flushState. writeState is replaceable for tests.
+	writeState   func() error
+	persistMutex sync.Mutex // Guards the fields below; taken briefly, also under mutex
+
+	pendingState   map[string]*Connection // Latest snapshot not yet written, nil if none
+	persistRetries int
+	persistBackoff time.Duration
+	lastPersistErr error
 }
 
+// stateFileMutex serializes use of the global viper state file config: loading it and writing
+// snapshots, see flushState. It is never taken while holding a manager's mutex.
+var stateFileMutex sync.Mutex
+
 // NewConnectionManager creates a new connection manager
 func NewConnectionManager() *ConnectionManager {
 
 	connections := make(map[string]*Connection)
 
+	stateFileMutex.Lock()
 	if !viper.IsSet("connection") {
 		viper.Set("connection", connections)
 		viper.WriteConfig()
@@ -64,36 +129,217 @@ func NewConnectionManager() *ConnectionManager {
 		for m, c := range tempCm {
 			connBytes, _ := yaml.Marshal(c)
 			log.Println(string(connBytes))
-			tCmc := &Connection{}
-			err := yaml.Unmarshal(connBytes, tCmc)
+			tCmc, err := decodeStateEntry(c)
 			if err != nil {
 				log.Panic(err)
 			}
-			connections[m] = tCmc
-			connections[m].Channel = make(chan jsonRPCResponse, messageChannelBufferSize)
+			// viper lowercases map keys, so the decoded ID keeps the case the connection had
+			if tCmc.ID == "" {
+				tCmc.ID = m
+			}
+			tCmc.Channel = make(chan jsonRPCResponse, messageChannelBufferSize)
+			tCmc.sequencer = newResponseSequencer()
+			tCmc.detached = true
+			connections[tCmc.ID] = tCmc
+		}
+	}
+	stateFileMutex.Unlock()
+
+	cm := &ConnectionManager{
+		connections: make(map[string]*Connection, len(connections)),
+		writeState:  writeViperState,
+	}
+	for _, conn := range connections {
+		cm.addLocked(cm.normalizeID(conn.ID), conn)
+	}
+	return cm
+}
+
+// addLocked registers conn under id and indexes it by state. Callers must hold the write lock.
+func (cm *ConnectionManager) addLocked(id string, conn *Connection) {
+	cm.connections[id] = conn
+	if cm.byState == nil {
+		cm.byState = make(map[ConnectionState]map[string]*Connection)
+	}
+	if cm.byState[conn.State] == nil {
+		cm.byState[conn.State] = make(map[string]*Connection)
+	}
+	cm.byState[conn.State][id] = conn
+}
+
+// deleteLocked unregisters the connection under id. Callers must hold the write lock.
+func (cm *ConnectionManager) deleteLocked(id string) {
+	if conn, ok := cm.connections[id]; ok {
+		conn.stopHandshakeTimer()
+		delete(cm.byState[conn.State], id)
+		delete(cm.connections, id)
+	}
+}
+
+// setStateLocked changes the state of the connection registered under id, moving it in the
+// state index. Callers must hold the write lock.
+func (cm *ConnectionManager) setStateLocked(id string, conn *Connection, state ConnectionState) {
+	delete(cm.byState[conn.State], id)
+	conn.State = state
+	if cm.byState[state] == nil {
+		cm.byState[state] = make(map[string]*Connection)
+	}
+	cm.byState[state][id] = conn
+}
+
+// writeViperState writes the state file. Without a configured state file persistence is disabled
+// and this is a no-op.
+func writeViperState() error {
+	if viper.ConfigFileUsed() == "" {
+		return nil
+	}
+	return viper.WriteConfig()
+}
+
+// SetPersistenceRetries controls how often a failed state file write is retried, waiting
+// backoff times the attempt number between tries. Zero retries (the default) writes once.
+func (cm *ConnectionManager) SetPersistenceRetries(retries int, backoff time.Duration) {
+	cm.persistMutex.Lock()
+	defer cm.persistMutex.Unlock()
+
+	cm.persistRetries = retries
+	cm.persistBackoff = backoff
+}
+
+// LastPersistenceError returns the error of the most recent state file write, or nil if it
+// succeeded. A non-nil error means the in-memory state is ahead of the file.
+func (cm *ConnectionManager) LastPersistenceError() error {
+	cm.persistMutex.Lock()
+	defer cm.persistMutex.Unlock()
+
+	return cm.lastPersistErr
+}
+
+// persist snapshots the connections for the state file. Callers must hold the mutex, and write
+// the snapshot with flushState once they have released it, so that slow writes and retry waits
+// never block other callers; deferring flushState before deferring the unlock does that.
+func (cm *ConnectionManager) persist() {
+	data, err := yaml.Marshal(cm.connections)
+	if err == nil {
+		var state map[string]*Connection
+		if err = yaml.Unmarshal(data, &state); err == nil {
+			cm.persistMutex.Lock()
+			cm.pendingState = state
+			cm.persistMutex.Unlock()
+			return
+		}
+	}
+	log.Printf("[ConnectionManager] Warning: could not snapshot connection state: %v", err)
+}
+
+// flushState writes the latest snapshot taken by persist, if it has not been written yet.
+// Callers must not hold the mutex. The in-memory change has already happened, so a write that
+// still fails after the retries is logged and recorded rather than undone: the server keeps
+// working, but a restart would lose the change.
+func (cm *ConnectionManager) flushState() {
+	stateFileMutex.Lock()
+	defer stateFileMutex.Unlock()
+
+	cm.persistMutex.Lock()
+	state, retries, backoff := cm.pendingState, cm.persistRetries, cm.persistBackoff
+	cm.pendingState = nil
+	cm.persistMutex.Unlock()
+	if state == nil {
+		return
+	}
+
+	viper.Set("connection", state)
+	if cm.writeState == nil {
+		return
+	}
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * backoff)
+		}
+		if err = cm.writeState(); err == nil {
+			break
 		}
 	}
+	if err != nil {
+		log.Printf("[ConnectionManager] Warning: could not persist connection state after %d attempt(s): %v. The change is kept in memory but will be lost on restart.", retries+1, err)
+	}
+
+	cm.persistMutex.Lock()
+	cm.lastPersistErr = err
+	cm.persistMutex.Unlock()
+}
+
+// decodeStateEntry converts one entry of the state file's connection map into a Connection.
+func decodeStateEntry(entry interface{}) (*Connection, error) {
+	connBytes, _ := yaml.Marshal(entry)
+	conn := &Connection{}
+	if err := yaml.Unmarshal(connBytes, conn); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// SetCaseSensitiveIDs controls whether connection IDs are matched exactly (true) or
+// lowercased first (false, the default). Set it before any connections are created; connections
+// restored from the state file are rekeyed by their stored ID.
+func (cm *ConnectionManager) SetCaseSensitiveIDs(enabled bool) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	cm.caseSensitiveIDs = enabled
+
+	connections := cm.connections
+	cm.connections = make(map[string]*Connection, len(connections))
+	cm.byState = nil
+	for _, conn := range connections {
+		cm.addLocked(cm.normalizeID(conn.ID), conn)
+	}
+}
 
-	return &ConnectionManager{
-		connections: connections,
+// normalizeID applies the manager's ID normalization. Callers must hold the mutex.
+func (cm *ConnectionManager) normalizeID(id string) string {
+	if cm.caseSensitiveIDs {
+		return id
 	}
+	return strings.ToLower(id)
 }
 
-// NewConnection creates a new connection with the given ID
-func (cm *ConnectionManager) NewConnection(id string) *Connection {
+// NewConnection creates a new connection with the given ID. An ID the connection ID policy
+// rejects is an error wrapping ErrInvalidConnectionID, and nothing is created or persisted.
+func (cm *ConnectionManager) NewConnection(id string) (*Connection, error) {
+	defer cm.flushState()
 	cm.mutex.Lock()
 	defer cm.mutex.Unlock()
 
+	if err := cm.idPolicy.Validate(id); err != nil {
+		return nil, err
+	}
+	return cm.newConnectionLocked(id), nil
+}
+
+// newConnectionLocked is NewConnection for callers holding the write lock, who flush the state
+// (see flushState) after releasing it.
+func (cm *ConnectionManager) newConnectionLocked(id string) *Connection {
 	conn := &Connection{
-		ID:        strings.ToLower(id),
+		ID:        cm.normalizeID(id),
 		State:     StateConnected,
 		Channel:   make(chan jsonRPCResponse, messageChannelBufferSize),
 		CreatedAt: time.Now(),
+		sequencer: newResponseSequencer(),
 	}
+	conn.LastActivity = conn.CreatedAt
 
-	cm.connections[strings.ToLower(id)] = conn
-	viper.Set("connection", cm.connections)
-	viper.WriteConfig()
+	// A reconnect under the same ID replaces the old connection; its writers get a clean error
+	if old, ok := cm.connections[conn.ID]; ok {
+		cm.deleteLocked(conn.ID)
+		old.State = StateShutdown
+		old.shutdownChannel()
+	}
+
+	cm.addLocked(conn.ID, conn)
+	cm.startHandshakeTimerLocked(conn)
+	cm.persist()
 	return conn
 }
 
@@ -102,58 +348,202 @@ func (cm *ConnectionManager) GetConnection(id string) *Connection {
 	cm.mutex.RLock()
 	defer cm.mutex.RUnlock()
 
-	return cm.connections[strings.ToLower(id)]
+	return cm.connections[cm.normalizeID(id)]
 }
 
 // UpdateState updates the state of a connection
 func (cm *ConnectionManager) UpdateState(id string, state ConnectionState) bool {
+	defer cm.flushState()
 	cm.mutex.Lock()
 	defer cm.mutex.Unlock()
 
-	conn, ok := cm.connections[strings.ToLower(id)]
+	conn, ok := cm.connections[cm.normalizeID(id)]
 	if !ok {
 		return false
 	}
 
 	oldState := conn.State
-	conn.State = state
+	cm.setStateLocked(cm.normalizeID(id), conn, state)
 
 	// Set initialized timestamp when moving to Ready state
 	if state == StateReady && oldState != StateReady {
 		now := time.Now()
 		conn.InitializedAt = &now
+		conn.stopHandshakeTimer()
 	}
 
-	viper.Set("connection", cm.connections)
-	viper.WriteConfig()
+	cm.persist()
 
 	return true
 }
 
+// SetProtocolVersion records the protocol version negotiated during initialize
+func (cm *ConnectionManager) SetProtocolVersion(id string, protocolVersion string) bool {
+	defer cm.flushState()
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	conn, ok := cm.connections[cm.normalizeID(id)]
+	if !ok {
+		return false
+	}
+
+	conn.ProtocolVersion = protocolVersion
+
+	cm.persist()
+
+	return true
+}
+
+// SetClientCapabilities records the capabilities the client advertised during initialize
+func (cm *ConnectionManager) SetClientCapabilities(id string, capabilities map[string]interface{}) bool {
+	defer cm.flushState()
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	conn, ok := cm.connections[cm.normalizeID(id)]
+	if !ok {
+		return false
+	}
+
+	conn.ClientCapabilities = capabilities
+
+	cm.persist()
+
+	return true
+}
+
+// SetClientInfo records the clientInfo the client sent during initialize
+func (cm *ConnectionManager) SetClientInfo(id string, info map[string]interface{}) bool {
+	defer cm.flushState()
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	conn, ok := cm.connections[cm.normalizeID(id)]
+	if !ok {
+		return false
+	}
+
+	conn.ClientInfo = info
+
+	cm.persist()
+
+	return true
+}
+
+// ClientName returns the clientInfo name a connection initialized with, or "" if unknown.
+func (cm *ConnectionManager) ClientName(id string) string {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+
+	conn, ok := cm.connections[cm.normalizeID(id)]
+	if !ok {
+		return ""
+	}
+	name, _ := conn.ClientInfo["name"].(string)
+	return name
+}
+
+// SetSessionHeaders records the upstream headers a connection's initialize _meta mapped to.
+// Only headers are persisted; sensitive ones live in memory for the life of the process.
+func (cm *ConnectionManager) SetSessionHeaders(id string, headers, sensitive map[string]string) bool {
+	defer cm.flushState()
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	conn, ok := cm.connections[cm.normalizeID(id)]
+	if !ok {
+		return false
+	}
+
+	conn.SessionHeaders = headers
+	conn.sensitiveHeaders = sensitive
+
+	cm.persist()
+
+	return true
+}
+
+// SessionHeaders returns a copy of the upstream headers recorded for a connection, sensitive
+// ones included. It is nil for unknown connections or connections without any.
+func (cm *ConnectionManager) SessionHeaders(id string) http.Header {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+
+	conn, ok := cm.connections[cm.normalizeID(id)]
+	if !ok || len(conn.SessionHeaders)+len(conn.sensitiveHeaders) == 0 {
+		return nil
+	}
+	headers := make(http.Header)
+	for name, value := range conn.SessionHeaders {
+		headers.Set(name, value)
+	}
+	for name, value := range conn.sensitiveHeaders {
+		headers.Set(name, value)
+	}
+	return headers
+}
+
+// Touch records activity on a connection. It is called for every inbound message and
+// keepalive, so unlike the other mutators it does not persist the state file.
+func (cm *ConnectionManager) Touch(id string) bool {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	conn, ok := cm.connections[cm.normalizeID(id)]
+	if !ok {
+		return false
+	}
+
+	conn.LastActivity = time.Now()
+	return true
+}
+
 // RemoveConnection removes a connection from the manager
 func (cm *ConnectionManager) RemoveConnection(id string) bool {
+	defer cm.flushState()
 	cm.mutex.Lock()
 	defer cm.mutex.Unlock()
 
-	conn, ok := cm.connections[strings.ToLower(id)]
+	conn, ok := cm.connections[cm.normalizeID(id)]
 	if !ok {
 		return false
 	}
 
-	// Close the channel if it's not already closed
-	select {
-	case <-conn.Channel:
-		// Channel is already closed
-	default:
-		close(conn.Channel)
-	}
+	// The channel closes once in-flight writers are done
+	cm.deleteLocked(cm.normalizeID(id))
+	conn.State = StateShutdown
+	conn.history = nil
+	conn.shutdownChannel()
 
-	viper.Set("connection", cm.connections)
-	viper.WriteConfig()
+	cm.persist()
 
 	return true
 }
 
+// removeConnectionInstance removes conn if it is still the connection registered under its ID,
+// so that a handler tearing down after a reconnect does not remove its replacement. The channel
+// of conn is shut down either way.
+func (cm *ConnectionManager) removeConnectionInstance(conn *Connection) bool {
+	defer cm.flushState()
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	registered := cm.connections[conn.ID] == conn
+	if registered {
+		cm.deleteLocked(conn.ID)
+	}
+	conn.State = StateShutdown
+	conn.history = nil
+	conn.shutdownChannel()
+
+	if !registered {
+		return false
+	}
+	cm.persist()
+	return true
+}
+
 // GetConnectionCount returns the total number of active connections
 func (cm *ConnectionManager) GetConnectionCount() int {
 	cm.mutex.RLock()
@@ -168,10 +558,85 @@ func (cm *ConnectionManager) GetConnectionsByState(state ConnectionState) []*Con
 	defer cm.mutex.RUnlock()
 
 	var connections []*Connection
+	for _, conn := range cm.byState[state] {
+		connections = append(connections, conn)
+	}
+	return connections
+}
+
+// Broadcast queues msg on every connection for which filter returns true, without blocking.
+// The filter runs under the manager's read lock and must not call back into the manager.
+// Connections that closed or whose buffer is full miss the message, which is recorded as
+// undelivered; the others still get it.
+func (cm *ConnectionManager) Broadcast(filter func(*Connection) bool, msg jsonRPCResponse) {
+	cm.mutex.RLock()
+	var recipients []*Connection
 	for _, conn := range cm.connections {
-		if conn.State == state {
-			connections = append(connections, conn)
+		if filter(conn) {
+			recipients = append(recipients, conn)
 		}
 	}
-	return connections
+	cm.mutex.RUnlock()
+
+	for _, conn := range recipients {
+		conn.trySend(msg) // Failures are logged and counted by trySend
+	}
+}
+
+// isReady is a Broadcast filter matching connections that finished the initialize handshake,
+// the only ones that have negotiated capabilities and may receive notifications.
+func isReady(conn *Connection) bool {
+	return conn.State == StateReady
+}
+
+// CountByState returns the number of connections in the given state without scanning them.
+func (cm *ConnectionManager) CountByState(state ConnectionState) int {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+
+	return len(cm.byState[state])
+}
+
+// ConnectionSnapshot is a point-in-time view of a connection for the admin endpoint.
+// Uptime and TimeToReady are derived when the snapshot is taken, never stored.
+type ConnectionSnapshot struct {
+	ID                 string     `json:"id"`
+	State              string     `json:"state"`
+	ProtocolVersion    string     `json:"protocolVersion,omitempty"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	InitializedAt      *time.Time `json:"initializedAt"`
+	LastActivity       time.Time  `json:"lastActivity"`
+	UptimeSeconds      float64    `json:"uptimeSeconds"`      // now - CreatedAt
+	TimeToReadySeconds *float64   `json:"timeToReadySeconds"` // InitializedAt - CreatedAt; null if never ready
+}
+
+// Snapshot returns a view of every connection, sorted by ID.
+func (cm *ConnectionManager) Snapshot() []ConnectionSnapshot {
+	return cm.snapshotAt(time.Now())
+}
+
+// snapshotAt builds the snapshot relative to the given time.
+func (cm *ConnectionManager) snapshotAt(now time.Time) []ConnectionSnapshot {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+
+	snapshots := make([]ConnectionSnapshot, 0, len(cm.connections))
+	for _, conn := range cm.connections {
+		snapshot := ConnectionSnapshot{
+			ID:              conn.ID,
+			State:           conn.State.String(),
+			ProtocolVersion: conn.ProtocolVersion,
+			CreatedAt:       conn.CreatedAt,
+			InitializedAt:   conn.InitializedAt,
+			LastActivity:    conn.LastActivity,
+			UptimeSeconds:   now.Sub(conn.CreatedAt).Seconds(),
+		}
+		if conn.InitializedAt != nil {
+			timeToReady := conn.InitializedAt.Sub(conn.CreatedAt).Seconds()
+			snapshot.TimeToReadySeconds = &timeToReady
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].ID < snapshots[j].ID })
+	return snapshots
 }
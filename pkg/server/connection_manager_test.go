@@ -1,12 +1,21 @@
 package server
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewConnectionManager(t *testing.T) {
@@ -20,7 +29,7 @@ func TestConnectionManager_NewConnection(t *testing.T) {
 	cm := NewConnectionManager()
 	connID := "test-conn-1"
 
-	conn := cm.NewConnection(connID)
+	conn, _ := cm.NewConnection(connID)
 
 	assert.NotNil(t, conn)
 	assert.Equal(t, connID, conn.ID)
@@ -44,7 +53,7 @@ func TestConnectionManager_GetConnection(t *testing.T) {
 	assert.Nil(t, conn)
 
 	// Create and retrieve
-	created := cm.NewConnection(connID)
+	created, _ := cm.NewConnection(connID)
 	retrieved := cm.GetConnection(connID)
 	assert.Equal(t, created, retrieved)
 }
@@ -58,7 +67,7 @@ func TestConnectionManager_UpdateState(t *testing.T) {
 	assert.False(t, updated)
 
 	// Create connection and update state
-	conn := cm.NewConnection(connID)
+	conn, _ := cm.NewConnection(connID)
 	assert.Equal(t, StateConnected, conn.State)
 	assert.Nil(t, conn.InitializedAt)
 
@@ -98,7 +107,7 @@ func TestConnectionManager_RemoveConnection(t *testing.T) {
 	assert.False(t, removed)
 
 	// Create connection and remove
-	conn := cm.NewConnection(connID)
+	conn, _ := cm.NewConnection(connID)
 	assert.Equal(t, 1, cm.GetConnectionCount())
 
 	removed = cm.RemoveConnection(connID)
@@ -131,13 +140,26 @@ func TestConnectionManager_RemoveConnection(t *testing.T) {
 	}
 }
 
+func TestConnectionManager_RemoveConnectionFreesID(t *testing.T) {
+	cm := NewConnectionManager()
+	cm.NewConnection("reused-conn")
+
+	assert.True(t, cm.RemoveConnection("reused-conn"))
+	assert.False(t, cm.RemoveConnection("reused-conn"), "a removed connection is not found again")
+
+	// The ID can be used again by a new connection
+	cm.NewConnection("reused-conn")
+	assert.Equal(t, 1, cm.GetConnectionCount())
+	assert.Equal(t, StateConnected, cm.GetConnection("reused-conn").State)
+}
+
 func TestConnectionManager_GetConnectionsByState(t *testing.T) {
 	cm := NewConnectionManager()
 
 	// Create connections in different states
-	conn1 := cm.NewConnection("conn1")
-	conn2 := cm.NewConnection("conn2")
-	conn3 := cm.NewConnection("conn3")
+	conn1, _ := cm.NewConnection("conn1")
+	conn2, _ := cm.NewConnection("conn2")
+	conn3, _ := cm.NewConnection("conn3")
 
 	cm.UpdateState("conn2", StateInitializing)
 	cm.UpdateState("conn3", StateReady)
@@ -173,7 +195,7 @@ func TestConnectionManager_ConcurrentAccess(t *testing.T) {
 			defer wg.Done()
 			for j := 0; j < connectionsPerGoroutine; j++ {
 				connID := fmt.Sprintf("conn-%d-%d", routineID, j)
-				conn := cm.NewConnection(connID)
+				conn, _ := cm.NewConnection(connID)
 				assert.NotNil(t, conn)
 				assert.Equal(t, connID, conn.ID)
 			}
@@ -226,4 +248,313 @@ func TestConnectionState_String(t *testing.T) {
 	for _, tc := range tests {
 		assert.Equal(t, tc.expected, tc.state.String())
 	}
-}
\ No newline at end of file
+}
+func TestConnectionManager_Snapshot(t *testing.T) {
+	cm := NewConnectionManager()
+	created := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	ready := created.Add(1500 * time.Millisecond)
+	now := created.Add(10 * time.Minute)
+
+	readyConn, _ := cm.NewConnection("snapshot-ready")
+	readyConn.CreatedAt = created
+	readyConn.State = StateReady
+	readyConn.InitializedAt = &ready
+
+	pendingConn, _ := cm.NewConnection("snapshot-pending")
+	pendingConn.CreatedAt = created.Add(time.Minute)
+	pendingConn.State = StateInitializing
+
+	snapshot := cm.snapshotAt(now)
+	assert.Len(t, snapshot, 2)
+
+	// Sorted by ID
+	assert.Equal(t, "snapshot-pending", snapshot[0].ID)
+	assert.Equal(t, "Initializing", snapshot[0].State)
+	assert.Equal(t, 540.0, snapshot[0].UptimeSeconds)
+	assert.Nil(t, snapshot[0].TimeToReadySeconds)
+
+	assert.Equal(t, "snapshot-ready", snapshot[1].ID)
+	assert.Equal(t, "Ready", snapshot[1].State)
+	assert.Equal(t, 600.0, snapshot[1].UptimeSeconds)
+	if assert.NotNil(t, snapshot[1].TimeToReadySeconds) {
+		assert.Equal(t, 1.5, *snapshot[1].TimeToReadySeconds)
+	}
+
+	// Never-ready connections serialize time-to-ready as null
+	encoded, err := json.Marshal(snapshot[0])
+	assert.NoError(t, err)
+	assert.Contains(t, string(encoded), `"timeToReadySeconds":null`)
+
+	cm.RemoveConnection("snapshot-ready")
+	cm.RemoveConnection("snapshot-pending")
+}
+
+func TestConnectionManager_IDNormalization(t *testing.T) {
+	t.Run("Normalized by default", func(t *testing.T) {
+		cm := NewConnectionManager()
+		created, _ := cm.NewConnection("abc")
+		defer cm.RemoveConnection("abc")
+
+		assert.Equal(t, created, cm.GetConnection("ABC"))
+		assert.True(t, cm.UpdateState("AbC", StateReady))
+		assert.Equal(t, StateReady, created.State)
+
+		// A differently-cased ID refers to the same connection
+		cm.NewConnection("ABC")
+		assert.Equal(t, 1, cm.GetConnectionCount())
+		assert.Equal(t, "abc", cm.GetConnection("abc").ID)
+
+		assert.True(t, cm.RemoveConnection("ABC"))
+		assert.Nil(t, cm.GetConnection("abc"))
+	})
+
+	t.Run("Case-sensitive", func(t *testing.T) {
+		cm := NewConnectionManager()
+		cm.SetCaseSensitiveIDs(true)
+		upper, _ := cm.NewConnection("QmFzZTY0-Id")
+		lower, _ := cm.NewConnection("qmfzzty0-id")
+		defer cm.RemoveConnection("qmfzzty0-id")
+
+		assert.Equal(t, 2, cm.GetConnectionCount())
+		assert.Equal(t, "QmFzZTY0-Id", upper.ID)
+		assert.Equal(t, upper, cm.GetConnection("QmFzZTY0-Id"))
+		assert.Equal(t, lower, cm.GetConnection("qmfzzty0-id"))
+		assert.Nil(t, cm.GetConnection("QMFZZTY0-ID"))
+
+		assert.True(t, cm.UpdateState("QmFzZTY0-Id", StateReady))
+		assert.Equal(t, StateReady, upper.State)
+		assert.Equal(t, StateConnected, lower.State)
+		assert.False(t, cm.UpdateState("QMFZZTY0-ID", StateReady))
+
+		assert.False(t, cm.RemoveConnection("QMFZZTY0-ID"))
+		assert.True(t, cm.RemoveConnection("QmFzZTY0-Id"))
+		assert.Equal(t, 1, cm.GetConnectionCount())
+	})
+}
+
+func TestConnectionManager_RestoredIDsKeepTheirCase(t *testing.T) {
+	saved := viper.Get("connection")
+	defer viper.Set("connection", saved)
+	viper.Set("connection", map[string]interface{}{
+		"abc": map[string]interface{}{"id": "AbC", "state": int(StateReady)},
+	})
+
+	t.Run("Case-sensitive", func(t *testing.T) {
+		cm := NewConnectionManager()
+		cm.SetCaseSensitiveIDs(true)
+
+		restored := cm.GetConnection("AbC")
+		require.NotNil(t, restored, "found under the ID it had before the restart")
+		assert.Equal(t, "AbC", restored.ID)
+		assert.Nil(t, cm.GetConnection("abc"))
+		assert.Equal(t, 1, cm.CountByState(StateReady))
+	})
+
+	t.Run("Normalized by default", func(t *testing.T) {
+		cm := NewConnectionManager()
+		assert.NotNil(t, cm.GetConnection("abc"))
+		assert.NotNil(t, cm.GetConnection("ABC"))
+	})
+}
+
+func TestConnectionManager_PersistenceFailure(t *testing.T) {
+	cm := NewConnectionManager()
+	conn, _ := cm.NewConnection("persist-conn")
+
+	writeErr := errors.New("read-only file system")
+	attempts := 0
+	cm.writeState = func() error {
+		attempts++
+		return writeErr
+	}
+	cm.SetPersistenceRetries(2, time.Millisecond)
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	// The transition still happens in memory, but the failed write is surfaced
+	assert.True(t, cm.UpdateState("persist-conn", StateReady))
+	assert.Equal(t, StateReady, conn.State)
+	assert.Equal(t, 3, attempts, "one write plus two retries")
+	assert.ErrorIs(t, cm.LastPersistenceError(), writeErr)
+	assert.Contains(t, logged.String(), "could not persist connection state after 3 attempt(s): read-only file system")
+
+	// A retry that succeeds clears the error
+	attempts = 0
+	cm.writeState = func() error {
+		attempts++
+		if attempts == 1 {
+			return writeErr
+		}
+		return nil
+	}
+	assert.True(t, cm.UpdateState("persist-conn", StateShutdown))
+	assert.Equal(t, 2, attempts)
+	assert.NoError(t, cm.LastPersistenceError())
+}
+
+func TestConnectionManager_SlowPersistenceDoesNotHoldLock(t *testing.T) {
+	cm := NewConnectionManager()
+	cm.NewConnection("slow-conn")
+
+	writing := make(chan struct{})
+	release := make(chan struct{})
+	cm.writeState = func() error {
+		close(writing)
+		<-release
+		return nil
+	}
+
+	updated := make(chan bool)
+	go func() { updated <- cm.UpdateState("slow-conn", StateReady) }()
+	<-writing
+
+	// While the write is pending, the manager stays usable
+	lookups := make(chan struct{})
+	go func() {
+		defer close(lookups)
+		assert.Equal(t, StateReady, cm.GetConnection("slow-conn").State)
+		assert.True(t, cm.Touch("slow-conn"))
+		assert.Equal(t, 1, cm.CountByState(StateReady))
+	}()
+	select {
+	case <-lookups:
+	case <-time.After(2 * time.Second):
+		t.Fatal("lookups blocked behind a state file write")
+	}
+
+	close(release)
+	assert.True(t, <-updated)
+	assert.NoError(t, cm.LastPersistenceError())
+}
+
+func TestWriteViperState_NoStateFile(t *testing.T) {
+	// Tests run without a state file, so persistence is disabled and writes are no-ops
+	assert.NoError(t, writeViperState())
+}
+
+// countByScan counts connections in a state the slow way, as a reference for CountByState.
+func countByScan(cm *ConnectionManager, state ConnectionState) int {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+
+	count := 0
+	for _, conn := range cm.connections {
+		if conn.State == state {
+			count++
+		}
+	}
+	return count
+}
+
+func TestConnectionManager_CountByStateUnderChurn(t *testing.T) {
+	cm := NewConnectionManager()
+	states := []ConnectionState{StateConnected, StateInitializing, StateReady, StateShutdown}
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(seed))
+			for i := 0; i < 500; i++ {
+				id := fmt.Sprintf("churn-%d", rng.Intn(40)) // Shared IDs, so workers collide
+				switch rng.Intn(4) {
+				case 0:
+					cm.NewConnection(id) // Also replaces an existing connection
+				case 1:
+					cm.RemoveConnection(id)
+				default:
+					cm.UpdateState(id, states[rng.Intn(len(states))])
+				}
+			}
+		}(int64(worker))
+	}
+	wg.Wait()
+
+	total := 0
+	for _, state := range states {
+		count := cm.CountByState(state)
+		assert.Equal(t, countByScan(cm, state), count, "count for %s", state)
+		assert.Len(t, cm.GetConnectionsByState(state), count, "connections for %s", state)
+		for _, conn := range cm.GetConnectionsByState(state) {
+			assert.Equal(t, state, conn.State)
+		}
+		total += count
+	}
+	assert.Equal(t, cm.GetConnectionCount(), total, "every connection is indexed exactly once")
+}
+
+func TestConnectionManager_Broadcast(t *testing.T) {
+	cm := NewConnectionManager()
+	modern, _ := cm.NewConnection("modern")
+	cm.UpdateState("modern", StateReady)
+	cm.SetProtocolVersion("modern", "2025-03-26")
+	legacy, _ := cm.NewConnection("legacy")
+	cm.UpdateState("legacy", StateReady)
+	cm.SetProtocolVersion("legacy", "2024-11-05")
+	initializing, _ := cm.NewConnection("initializing")
+	cm.SetProtocolVersion("initializing", "2025-03-26")
+	closed, _ := cm.NewConnection("closed")
+	cm.UpdateState("closed", StateReady)
+	cm.SetProtocolVersion("closed", "2025-03-26")
+	closed.shutdownChannel()
+
+	notification := newJSONRPCNotification("notifications/tools/list_changed", nil)
+	assert.NotPanics(t, func() {
+		cm.Broadcast(func(conn *Connection) bool {
+			return isReady(conn) && conn.ProtocolVersion == "2025-03-26"
+		}, notification)
+	})
+
+	if assert.Len(t, modern.Channel, 1) {
+		assert.Equal(t, notification, <-modern.Channel)
+	}
+	assert.Empty(t, legacy.Channel, "connections not matching the filter get nothing")
+	assert.Empty(t, initializing.Channel)
+	_, open := <-closed.Channel
+	assert.False(t, open, "a closed connection is skipped without a panic")
+}
+
+// benchmarkManager returns a manager with n connections spread over the states.
+func benchmarkManager(b *testing.B, n int) *ConnectionManager {
+	b.Helper()
+	log.SetOutput(io.Discard)
+	b.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	cm := &ConnectionManager{connections: make(map[string]*Connection)}
+	states := []ConnectionState{StateConnected, StateInitializing, StateReady}
+	for i := 0; i < n; i++ {
+		cm.addLocked(fmt.Sprintf("bench-%d", i), &Connection{State: states[i%len(states)]})
+	}
+	// A handful of connections in the state being queried
+	for i := 0; i < 10; i++ {
+		cm.addLocked(fmt.Sprintf("bench-shutdown-%d", i), &Connection{State: StateShutdown})
+	}
+	return cm
+}
+
+func BenchmarkConnectionManager_CountByState(b *testing.B) {
+	cm := benchmarkManager(b, 10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cm.CountByState(StateShutdown)
+	}
+}
+
+func BenchmarkConnectionManager_CountByScan(b *testing.B) {
+	cm := benchmarkManager(b, 10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		countByScan(cm, StateShutdown)
+	}
+}
+
+func BenchmarkConnectionManager_GetConnectionsByState(b *testing.B) {
+	cm := benchmarkManager(b, 10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cm.GetConnectionsByState(StateShutdown)
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// ContentHandler formats upstream success bodies of a media type, see mcp.ContentHandler. It may
+// return a string (text content), a ToolResultContent or []ToolResultContent (used as is), or any
+// other value, which is sent as JSON text.
+type ContentHandler = mcp.ContentHandler
+
+// RegisterContentHandler formats tool results whose upstream Content-Type matches pattern with
+// handler instead of the built-in formatting. pattern is an exact media type or a path.Match
+// pattern, matched case-insensitively against the type without parameters, e.g.
+// "application/x-ndjson" or "application/vnd.*+json". When several patterns match, the most
+// recently registered wins, so a handler can override an earlier one.
+func (s *Server) RegisterContentHandler(pattern string, handler ContentHandler) error {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if !strings.Contains(pattern, "/") {
+		return fmt.Errorf("invalid media type pattern '%s': must be type/subtype", pattern)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid media type pattern '%s': %w", pattern, err)
+	}
+	if handler == nil {
+		return fmt.Errorf("media type pattern '%s' has no handler", pattern)
+	}
+	err := s.tools.update(func(current *mcp.ToolSet) (*mcp.ToolSet, error) {
+		next := cloneToolSet(current)
+		next.ContentHandlers = append(next.ContentHandlers, mcp.ContentHandlerEntry{Pattern: pattern, Handler: handler})
+		return next, nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[ContentHandler] Registered handler for %s", pattern)
+	return nil
+}
+
+// contentHandlerFor returns the most recently registered handler matching mediaType.
+func contentHandlerFor(handlers []mcp.ContentHandlerEntry, mediaType string) (ContentHandler, string, bool) {
+	for i := len(handlers) - 1; i >= 0; i-- {
+		if matched, _ := path.Match(handlers[i].Pattern, mediaType); matched {
+			return handlers[i].Handler, handlers[i].Pattern, true
+		}
+	}
+	return nil, "", false
+}
+
+// customResponseContent formats body with a registered handler for mediaType. ok is false when no
+// handler matches or the handler failed, in which case the built-in formatting applies.
+func customResponseContent(toolName, mediaType string, body []byte, handlers []mcp.ContentHandlerEntry) ([]ToolResultContent, bool) {
+	handler, pattern, found := contentHandlerFor(handlers, mediaType)
+	if !found {
+		return nil, false
+	}
+	result, err := handler(mediaType, body)
+	if err != nil {
+		log.Printf("[ContentHandler] Handler for %s failed on tool '%s', using the default formatting: %v", pattern, toolName, err)
+		return nil, false
+	}
+	switch v := result.(type) {
+	case string:
+		return []ToolResultContent{{Type: "text", Text: v}}, true
+	case ToolResultContent:
+		return []ToolResultContent{v}, true
+	case []ToolResultContent:
+		return v, true
+	}
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		log.Printf("[ContentHandler] Result of the handler for %s on tool '%s' cannot be encoded as JSON, using the default formatting: %v", pattern, toolName, err)
+		return nil, false
+	}
+	return []ToolResultContent{{Type: "text", Text: string(encoded)}}, true
+}
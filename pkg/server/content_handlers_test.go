@@ -0,0 +1,103 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ndjsonRecords is a content handler returning one text content per NDJSON record.
+func ndjsonRecords(mediaType string, body []byte) (interface{}, error) {
+	var content []ToolResultContent
+	for _, line := range bytes.Split(body, []byte("\n")) {
+		if line = bytes.TrimSpace(line); len(line) == 0 {
+			continue
+		}
+		if !json.Valid(line) {
+			return nil, errors.New("invalid record")
+		}
+		content = append(content, ToolResultContent{Type: "text", Text: string(line)})
+	}
+	return content, nil
+}
+
+func callWithContentType(t *testing.T, s *Server, contentType, body string) ToolResultPayload {
+	t.Helper()
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Write([]byte(body))
+	}))
+	defer backend.Close()
+	s.cfg.ServerBaseURL = backend.URL
+
+	params, _ := json.Marshal(ToolCallParams{ToolName: "export_events", Input: map[string]interface{}{}})
+	req := &jsonRPCRequest{Jsonrpc: "2.0", Method: "tools/call", Params: json.RawMessage(params), ID: "content-1"}
+	resp := handleToolCallJSONRPC("test-conn", req, s.tools.Load(), s.cfg)
+	result, ok := resp.Result.(ToolResultPayload)
+	require.True(t, ok)
+	require.False(t, result.IsError)
+	return result
+}
+
+func newContentHandlerTestServer() *Server {
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"export_events": {Method: "GET", Path: "/events"},
+	}}
+	return NewServer(toolSet, &config.Config{DisableInputValidation: true})
+}
+
+func TestRegisterContentHandler_NDJSON(t *testing.T) {
+	s := newContentHandlerTestServer()
+	require.NoError(t, s.RegisterContentHandler("application/x-ndjson", ndjsonRecords))
+
+	result := callWithContentType(t, s, "application/x-ndjson; charset=utf-8", "{\"id\": 1}\n{\"id\": 2}\n\n{\"id\": 3}\n")
+	assert.Equal(t, []ToolResultContent{
+		{Type: "text", Text: `{"id": 1}`},
+		{Type: "text", Text: `{"id": 2}`},
+		{Type: "text", Text: `{"id": 3}`},
+	}, result.Content)
+
+	// A failing handler falls back to the built-in formatting
+	result = callWithContentType(t, s, "application/x-ndjson", "not json\n")
+	assert.Equal(t, []ToolResultContent{{Type: "text", Text: "not json\n"}}, result.Content)
+
+	// Other media types are unaffected
+	result = callWithContentType(t, s, "application/json", `{"id": 1}`)
+	assert.Equal(t, []ToolResultContent{{Type: "text", Text: `{"id": 1}`}}, result.Content)
+}
+
+func TestRegisterContentHandler_PatternsAndResults(t *testing.T) {
+	s := newContentHandlerTestServer()
+	require.NoError(t, s.RegisterContentHandler("application/vnd.*+json", func(mediaType string, body []byte) (interface{}, error) {
+		return map[string]interface{}{"vendor": mediaType, "bytes": len(body)}, nil
+	}))
+
+	result := callWithContentType(t, s, "application/vnd.company+json", `{"a": 1}`)
+	require.Len(t, result.Content, 1)
+	assert.JSONEq(t, `{"vendor": "application/vnd.company+json", "bytes": 8}`, result.Content[0].Text)
+
+	// The most recent matching registration wins
+	require.NoError(t, s.RegisterContentHandler("APPLICATION/VND.COMPANY+JSON", func(mediaType string, body []byte) (interface{}, error) {
+		return "company: " + string(body), nil
+	}))
+	result = callWithContentType(t, s, "application/vnd.company+json", `{"a": 1}`)
+	assert.Equal(t, []ToolResultContent{{Type: "text", Text: `company: {"a": 1}`}}, result.Content)
+	result = callWithContentType(t, s, "application/vnd.other+json", `{}`)
+	assert.JSONEq(t, `{"vendor": "application/vnd.other+json", "bytes": 2}`, result.Content[0].Text)
+}
+
+func TestRegisterContentHandler_Invalid(t *testing.T) {
+	s := newContentHandlerTestServer()
+	assert.Error(t, s.RegisterContentHandler("ndjson", ndjsonRecords))
+	assert.Error(t, s.RegisterContentHandler("application/[", ndjsonRecords))
+	assert.Error(t, s.RegisterContentHandler("application/x-ndjson", nil))
+	assert.Empty(t, s.tools.Load().ContentHandlers)
+}
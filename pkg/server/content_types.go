@@ -0,0 +1,123 @@
+package server
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// defaultAccept is requested when neither the config nor the operation names a media type.
+const defaultAccept = "application/json"
+
+// effectiveAccept resolves the Accept header to send for a tool. Precedence: per-tool config,
+// then the global config, then the operation's declared media types, then application/json.
+func effectiveAccept(toolName string, operation mcp.OperationDetail, cfg *config.Config) string {
+	if cfg != nil {
+		if accept := cfg.OperationAccept[toolName]; accept != "" {
+			return accept
+		}
+		if cfg.AcceptHeader != "" {
+			return cfg.AcceptHeader
+		}
+	}
+	if operation.Accept != "" {
+		return operation.Accept
+	}
+	return defaultAccept
+}
+
+// responseContent turns a successful upstream body into tool result content according to its
+// Content-Type: textual types (JSON, XML, text/*, ...) become text, images and audio become
+// base64 image/audio content, and any other binary type is described and base64-encoded as text.
+// A handler registered for the media type is consulted first.
+func responseContent(toolName string, httpResp *http.Response, body []byte, handlers []mcp.ContentHandlerEntry) []ToolResultContent {
+	contentType := httpResp.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType)) // Empty or malformed: treated as text
+	}
+
+	if httpResp.Request != nil { // Mock responses have no request
+		if accept := httpResp.Request.Header.Get("Accept"); accept != "" && mediaType != "" && !acceptsMediaType(accept, mediaType) {
+			log.Printf("[ExecuteToolCall] Tool '%s' requested %s but the upstream returned %s", toolName, accept, mediaType)
+		}
+	}
+
+	if content, ok := customResponseContent(toolName, mediaType, body, handlers); ok {
+		return content
+	}
+
+	switch {
+	case isTextualMediaType(mediaType):
+		return []ToolResultContent{{Type: "text", Text: string(body)}}
+	case strings.HasPrefix(mediaType, "image/"):
+		return []ToolResultContent{{Type: "image", Data: base64.StdEncoding.EncodeToString(body), MimeType: mediaType}}
+	case strings.HasPrefix(mediaType, "audio/"):
+		return []ToolResultContent{{Type: "audio", Data: base64.StdEncoding.EncodeToString(body), MimeType: mediaType}}
+	default:
+		return []ToolResultContent{{
+			Type: "text",
+			Text: fmt.Sprintf("Binary response (%s, %d bytes), base64-encoded:\n%s", mediaType, len(body), base64.StdEncoding.EncodeToString(body)),
+		}}
+	}
+}
+
+// isTextualMediaType reports whether a media type's body is readable text. An empty type is
+// assumed to be text, matching how responses were always returned.
+func isTextualMediaType(mediaType string) bool {
+	if mediaType == "" || strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	for _, suffix := range []string{"/json", "+json", "/xml", "+xml", "/yaml", "+yaml", "/x-yaml", "/javascript", "/x-www-form-urlencoded", "/x-ndjson", "/graphql"} {
+		if strings.HasSuffix(mediaType, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsMediaType reports whether an Accept header value admits the media type.
+func acceptsMediaType(accept, mediaType string) bool {
+	for _, item := range strings.Split(accept, ",") {
+		pattern := strings.ToLower(strings.TrimSpace(strings.Split(item, ";")[0]))
+		switch {
+		case pattern == "*/*" || pattern == mediaType:
+			return true
+		case strings.HasSuffix(pattern, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*")):
+			return true
+		}
+	}
+	return false
+}
+
+// isNoContentStatus reports whether a status never carries a body: 204 No Content and 205 Reset
+// Content.
+func isNoContentStatus(status int) bool {
+	return status == http.StatusNoContent || status == http.StatusResetContent
+}
+
+// isNoContentResponse reports whether an upstream response is a bodiless success: 204 or 205, or a
+// 304 Not Modified without a body.
+func isNoContentResponse(httpResp *http.Response, body []byte) bool {
+	return isNoContentStatus(httpResp.StatusCode) || (httpResp.StatusCode == http.StatusNotModified && len(body) == 0)
+}
+
+// noContentResult is the success result for a bodiless response. The body is not parsed or
+// projected; the text and structured content just report the status.
+func noContentResult(toolName string, req *jsonRPCRequest, httpResp *http.Response, cfg *config.Config) ToolResultPayload {
+	message := fmt.Sprintf("Operation completed (%s)", httpResp.Status)
+	log.Printf("[ExecuteToolCall] Tool '%s' returned no content: %s", toolName, httpResp.Status)
+
+	result := ToolResultPayload{
+		Content:           []ToolResultContent{{Type: "text", Text: message}},
+		StructuredContent: map[string]interface{}{"status": httpResp.StatusCode, "message": message},
+		ToolCallID:        fmt.Sprintf("%v", req.ID),
+	}
+	return withStructuredResult(toolName, httpResp, nil, cfg, result)
+}
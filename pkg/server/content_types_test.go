@@ -0,0 +1,157 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveAccept(t *testing.T) {
+	operation := mcp.OperationDetail{Accept: "application/json"}
+	assert.Equal(t, "application/json", effectiveAccept("get_report", operation, &config.Config{}))
+	assert.Equal(t, "application/xml", effectiveAccept("get_report", operation, &config.Config{AcceptHeader: "application/xml"}))
+	assert.Equal(t, "text/csv", effectiveAccept("get_report", operation, &config.Config{
+		AcceptHeader:    "application/xml",
+		OperationAccept: map[string]string{"get_report": "text/csv"},
+	}))
+	assert.Equal(t, "image/png", effectiveAccept("get_logo", mcp.OperationDetail{Accept: "image/png"}, &config.Config{}))
+	assert.Equal(t, "application/json", effectiveAccept("get_logo", mcp.OperationDetail{}, &config.Config{}), "historic default when nothing is declared")
+}
+
+func TestToolCall_AcceptHeader(t *testing.T) {
+	var receivedAccept string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedAccept = r.Header.Get("Accept")
+		switch {
+		case r.URL.Path == "/logo":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte{0x89, 'P', 'N', 'G'})
+		case receivedAccept == "application/xml":
+			w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+			w.Write([]byte(`<report><total>3</total></report>`))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"total": 3}`))
+		}
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"get_report": {Method: "GET", Path: "/report", BaseURL: backend.URL, Accept: "application/json"},
+		"get_logo":   {Method: "GET", Path: "/logo", BaseURL: backend.URL},
+	}}
+	call := func(toolName string, cfg *config.Config) ToolResultPayload {
+		params, _ := json.Marshal(ToolCallParams{ToolName: toolName, Input: map[string]interface{}{}})
+		req := &jsonRPCRequest{Jsonrpc: "2.0", Method: "tools/call", Params: json.RawMessage(params), ID: "accept"}
+		result, ok := handleToolCallJSONRPC("test-conn", req, toolSet, cfg).Result.(ToolResultPayload)
+		require.True(t, ok)
+		require.False(t, result.IsError)
+		return result
+	}
+
+	// Defaults to the operation's JSON media type
+	result := call("get_report", &config.Config{})
+	assert.Equal(t, "application/json", receivedAccept)
+	assert.Equal(t, []ToolResultContent{{Type: "text", Text: `{"total": 3}`}}, result.Content)
+
+	// The configured Accept header is sent and the XML response is returned as text
+	result = call("get_report", &config.Config{OperationAccept: map[string]string{"get_report": "application/xml"}})
+	assert.Equal(t, "application/xml", receivedAccept)
+	assert.Equal(t, []ToolResultContent{{Type: "text", Text: `<report><total>3</total></report>`}}, result.Content)
+
+	// A type other than the requested one is dispatched on its actual Content-Type
+	result = call("get_logo", &config.Config{AcceptHeader: "application/json"})
+	assert.Equal(t, "application/json", receivedAccept)
+	assert.Equal(t, []ToolResultContent{{Type: "image", Data: "iVBORw==", MimeType: "image/png"}}, result.Content)
+	encoded, err := json.Marshal(result.Content[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type": "image", "data": "iVBORw==", "mimeType": "image/png"}`, string(encoded))
+}
+
+func TestResponseContent_Binary(t *testing.T) {
+	httpResp := &http.Response{Header: http.Header{"Content-Type": []string{"application/pdf"}}}
+	content := responseContent("get_invoice", httpResp, []byte("%PDF"), nil)
+	require.Len(t, content, 1)
+	assert.Equal(t, "text", content[0].Type)
+	assert.Equal(t, "Binary response (application/pdf, 4 bytes), base64-encoded:\nJVBERg==", content[0].Text)
+
+	// No Content-Type is treated as text
+	content = responseContent("get_invoice", &http.Response{Header: http.Header{}}, []byte("plain"), nil)
+	assert.Equal(t, []ToolResultContent{{Type: "text", Text: "plain"}}, content)
+}
+
+func TestAcceptsMediaType(t *testing.T) {
+	assert.True(t, acceptsMediaType("application/json", "application/json"))
+	assert.True(t, acceptsMediaType("application/xml;q=0.9, application/json", "application/json"))
+	assert.True(t, acceptsMediaType("image/*", "image/png"))
+	assert.True(t, acceptsMediaType("*/*", "text/csv"))
+	assert.False(t, acceptsMediaType("application/json", "application/xml"))
+}
+
+func TestCallToolUpstream_NoContent(t *testing.T) {
+	var gotMethod string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		switch r.URL.Path {
+		case "/items/1":
+			w.Header().Set("Content-Type", "application/json") // Claims JSON, but there is no body
+			w.WriteHeader(http.StatusNoContent)
+		case "/form":
+			w.WriteHeader(http.StatusResetContent)
+		case "/cached":
+			w.WriteHeader(http.StatusNotModified)
+		}
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"delete_item": {Method: "DELETE", Path: "/items/1", BaseURL: backend.URL},
+		"reset_form":  {Method: "PUT", Path: "/form", BaseURL: backend.URL},
+		"get_cached":  {Method: "GET", Path: "/cached", BaseURL: backend.URL},
+	}}
+	// A projection would parse the body, so it shows whether a parse was attempted
+	cfg := &config.Config{ResponseProjections: map[string][]string{"delete_item": {"id"}}}
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	params := &ToolCallParams{ToolName: "delete_item", Input: map[string]interface{}{}}
+	result := callToolUpstream("conn", &jsonRPCRequest{ID: 1}, params, toolSet, cfg)
+	assert.Equal(t, http.MethodDelete, gotMethod)
+	assert.False(t, result.IsError)
+	require.Len(t, result.Content, 1)
+	assert.Equal(t, "text", result.Content[0].Type)
+	assert.Equal(t, "Operation completed (204 No Content)", result.Content[0].Text)
+	assert.Equal(t, map[string]interface{}{"status": 204, "message": "Operation completed (204 No Content)"}, result.StructuredContent)
+	assert.NotContains(t, logged.String(), "[ResponseProjection]", "the empty body is not parsed")
+
+	for toolName, expected := range map[string]string{
+		"reset_form": "Operation completed (205 Reset Content)",
+		"get_cached": "Operation completed (304 Not Modified)",
+	} {
+		params := &ToolCallParams{ToolName: toolName, Input: map[string]interface{}{}}
+		result := callToolUpstream("conn", &jsonRPCRequest{ID: 1}, params, toolSet, cfg)
+		assert.False(t, result.IsError, toolName)
+		require.Len(t, result.Content, 1, toolName)
+		assert.Equal(t, expected, result.Content[0].Text, toolName)
+	}
+}
+
+func TestIsNoContentResponse(t *testing.T) {
+	assert.True(t, isNoContentResponse(&http.Response{StatusCode: http.StatusNoContent}, nil))
+	assert.True(t, isNoContentResponse(&http.Response{StatusCode: http.StatusNoContent}, []byte("ignored")), "a 204 body is never read")
+	assert.True(t, isNoContentResponse(&http.Response{StatusCode: http.StatusResetContent}, nil))
+	assert.True(t, isNoContentResponse(&http.Response{StatusCode: http.StatusNotModified}, nil))
+	assert.False(t, isNoContentResponse(&http.Response{StatusCode: http.StatusNotModified}, []byte("stale")), "a 304 with a body is an API error as before")
+	assert.False(t, isNoContentResponse(&http.Response{StatusCode: http.StatusOK}, nil))
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// ToolHandler serves a tool added with RegisterTool, see mcp.ToolHandler. Besides strings and
+// JSON values, a handler may return a ToolResultPayload to control the result fully.
+type ToolHandler = mcp.ToolHandler
+
+// RegisterTool adds a hand-written tool next to the generated ones. It is listed by tools/list
+// and called through tools/call like any other tool, including input validation against
+// inputSchema (a JSON Schema object; empty means any object). Numbers in the arguments arrive as
+// json.Number. Registering a name that is already taken by a generated, built-in or registered
+// tool is an error.
+func (s *Server) RegisterTool(name, description string, inputSchema json.RawMessage, handler ToolHandler) error {
+	if name == "" {
+		return fmt.Errorf("tool name must not be empty")
+	}
+	if handler == nil {
+		return fmt.Errorf("tool '%s' has no handler", name)
+	}
+	schema := mcp.Schema{Type: "object"}
+	if len(inputSchema) > 0 {
+		schema = mcp.Schema{}
+		if err := json.Unmarshal(inputSchema, &schema); err != nil {
+			return fmt.Errorf("invalid input schema for tool '%s': %w", name, err)
+		}
+		if schema.Type != "object" {
+			return fmt.Errorf("invalid input schema for tool '%s': type must be \"object\", got %q", name, schema.Type)
+		}
+	}
+	tool := mcp.Tool{Name: name, Description: description, InputSchema: schema}
+
+	err := s.tools.update(func(current *mcp.ToolSet) (*mcp.ToolSet, error) {
+		next := cloneToolSet(current)
+		if err := addCustomTool(next, tool, handler); err != nil {
+			return nil, err
+		}
+		return next, nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[CustomTool] Registered tool '%s'", name)
+	return nil
+}
+
+// cloneToolSet returns a copy of toolSet whose tool list, handler map, middleware and content
+// handlers can be changed without affecting the original.
+func cloneToolSet(toolSet *mcp.ToolSet) *mcp.ToolSet {
+	next := &mcp.ToolSet{}
+	if toolSet != nil {
+		*next = *toolSet
+	}
+	next.Tools = append([]mcp.Tool(nil), next.Tools...)
+	next.Middleware = append([]mcp.ToolMiddleware(nil), next.Middleware...)
+	next.ContentHandlers = append([]mcp.ContentHandlerEntry(nil), next.ContentHandlers...)
+	handlers := make(map[string]ToolHandler, len(next.CustomHandlers)+1)
+	for name, handler := range next.CustomHandlers {
+		handlers[name] = handler
+	}
+	next.CustomHandlers = handlers
+	return next
+}
+
+// addCustomTool adds a registered tool to a tool set the caller owns, rejecting name collisions.
+func addCustomTool(toolSet *mcp.ToolSet, tool mcp.Tool, handler ToolHandler) error {
+	if tool.Name == describeOperationToolName || tool.Name == upstreamHealthToolName {
+		return fmt.Errorf("tool name '%s' is reserved for a built-in tool", tool.Name)
+	}
+	if _, ok := toolSet.Operations[tool.Name]; ok {
+		return fmt.Errorf("tool '%s' is already defined by the OpenAPI spec", tool.Name)
+	}
+	for _, existing := range toolSet.Tools {
+		if existing.Name == tool.Name {
+			return fmt.Errorf("tool '%s' is already registered", tool.Name)
+		}
+	}
+	if toolSet.CustomHandlers == nil {
+		toolSet.CustomHandlers = make(map[string]ToolHandler)
+	}
+	toolSet.Tools = append(toolSet.Tools, tool)
+	toolSet.CustomHandlers[tool.Name] = handler
+	return nil
+}
+
+// callCustomTool runs a registered tool's handler, bounded by the default upstream timeout (or the
+// tool's --operation-timeout).
+func callCustomTool(ctx context.Context, connID string, params *ToolCallParams, handler ToolHandler, cfg *config.Config) (interface{}, error) {
+	log.Printf("[CustomTool] Calling tool '%s' for %s", params.ToolName, connID)
+	ctx, cancel := context.WithTimeout(ctx, effectiveTimeout(params.ToolName, mcp.OperationDetail{}, cfg))
+	defer cancel()
+
+	arguments := params.Input
+	if arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+	return handler(ctx, arguments)
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const calculatorSchema = `{
+  "type": "object",
+  "properties": {"a": {"type": "number"}, "b": {"type": "number"}},
+  "required": ["a", "b"]
+}`
+
+// addNumbers is a custom tool handler adding two numbers.
+func addNumbers(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	a, err := args["a"].(json.Number).Float64()
+	if err != nil {
+		return nil, err
+	}
+	b, err := args["b"].(json.Number).Float64()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"sum": a + b}, nil
+}
+
+// dispatchToReadyConnection sends req through the normal dispatch flow on a ready connection.
+func dispatchToReadyConnection(t *testing.T, s *Server, method string, params interface{}) jsonRPCResponse {
+	t.Helper()
+	connID := "test-custom-tool-conn"
+	conn, _ := setupTestConnection(connID)
+	t.Cleanup(func() { cleanupTestConnection(connID) })
+	mcpConnectionManager.UpdateState(conn.ID, StateReady)
+
+	rawParams, err := json.Marshal(params)
+	require.NoError(t, err)
+	req := &jsonRPCRequest{Jsonrpc: "2.0", ID: 1, Method: method, Params: json.RawMessage(rawParams)}
+	resp, respond := dispatchJSONRPC(conn, conn.ID, req, s.tools.Load(), s.cfg)
+	require.True(t, respond)
+	return resp
+}
+
+func TestServer_RegisterTool(t *testing.T) {
+	s := NewServer(createTestToolSetForCall(), &config.Config{})
+	require.NoError(t, s.RegisterTool("add", "Add two numbers", json.RawMessage(calculatorSchema), addNumbers))
+
+	// Listed next to the generated tools
+	resp := dispatchToReadyConnection(t, s, "tools/list", map[string]interface{}{})
+	require.Nil(t, resp.Error)
+	tools := resp.Result.(map[string]interface{})["tools"].([]mcp.Tool)
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+		if tool.Name == "add" {
+			assert.Equal(t, "Add two numbers", tool.Description)
+			assert.Equal(t, []string{"a", "b"}, tool.InputSchema.Required)
+		}
+	}
+	assert.Contains(t, names, "get_user")
+	assert.Contains(t, names, "add")
+
+	// Called through tools/call
+	resp = dispatchToReadyConnection(t, s, "tools/call", map[string]interface{}{
+		"name":      "add",
+		"arguments": map[string]interface{}{"a": 2, "b": 40},
+	})
+	require.Nil(t, resp.Error)
+	result := resp.Result.(ToolResultPayload)
+	assert.False(t, result.IsError)
+	require.Len(t, result.Content, 1)
+	assert.JSONEq(t, `{"sum": 42}`, result.Content[0].Text)
+	assert.Equal(t, map[string]interface{}{"sum": float64(42)}, result.StructuredContent)
+
+	// Input validation applies to registered tools too
+	resp = dispatchToReadyConnection(t, s, "tools/call", map[string]interface{}{
+		"name":      "add",
+		"arguments": map[string]interface{}{"a": 2},
+	})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, -32602, resp.Error.Code)
+}
+
+func TestServer_RegisterToolResults(t *testing.T) {
+	s := NewServer(&mcp.ToolSet{}, &config.Config{})
+	require.NoError(t, s.RegisterTool("greet", "", nil, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return "hello", nil
+	}))
+	require.NoError(t, s.RegisterTool("fail", "", nil, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return nil, errors.New("out of coffee")
+	}))
+
+	result := dispatchToReadyConnection(t, s, "tools/call", map[string]interface{}{"name": "greet"}).Result.(ToolResultPayload)
+	assert.False(t, result.IsError)
+	assert.Equal(t, []ToolResultContent{{Type: "text", Text: "hello"}}, result.Content)
+	assert.Nil(t, result.StructuredContent)
+
+	result = dispatchToReadyConnection(t, s, "tools/call", map[string]interface{}{"name": "fail"}).Result.(ToolResultPayload)
+	assert.True(t, result.IsError)
+	require.Len(t, result.Content, 1)
+	assert.Equal(t, "Tool 'fail' failed: out of coffee", result.Content[0].Text)
+}
+
+func TestServer_RegisterToolCollisions(t *testing.T) {
+	toolSet := createTestToolSetForCall()
+	s := NewServer(toolSet, &config.Config{})
+
+	err := s.RegisterTool("get_user", "", nil, addNumbers)
+	assert.ErrorContains(t, err, "already defined by the OpenAPI spec")
+	err = s.RegisterTool(describeOperationToolName, "", nil, addNumbers)
+	assert.ErrorContains(t, err, "reserved")
+
+	require.NoError(t, s.RegisterTool("add", "", nil, addNumbers))
+	err = s.RegisterTool("add", "", nil, addNumbers)
+	assert.ErrorContains(t, err, "already registered")
+
+	assert.Error(t, s.RegisterTool("", "", nil, addNumbers))
+	assert.Error(t, s.RegisterTool("noop", "", nil, nil))
+	assert.Error(t, s.RegisterTool("bad_schema", "", json.RawMessage(`{"type": "string"}`), addNumbers))
+	assert.Error(t, s.RegisterTool("broken_schema", "", json.RawMessage(`{`), addNumbers))
+
+	assert.Len(t, toolSet.Tools, 2, "the tool set given to NewServer is not modified")
+	assert.Nil(t, toolSet.CustomHandlers)
+}
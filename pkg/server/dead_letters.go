@@ -0,0 +1,30 @@
+package server
+
+import (
+	"log"
+	"sync/atomic"
+)
+
+// undeliveredMessages counts responses and notifications that could not be queued on a
+// connection's Channel because it was shut down (client gone) or full.
+var undeliveredMessages atomic.Int64
+
+// UndeliveredMessages returns how many responses and notifications were dropped since start
+// because their connection was closed or its buffer full. They are logged with [DeadLetter].
+func (s *Server) UndeliveredMessages() int64 {
+	return undeliveredMessages.Load()
+}
+
+// recordUndelivered logs and counts a message that could not be queued for connID.
+func recordUndelivered(connID string, resp jsonRPCResponse, err error) {
+	undeliveredMessages.Add(1)
+	if resp.Method != "" {
+		log.Printf("[DeadLetter] Dropped notification '%s' for %s: %v", resp.Method, connID, err)
+		return
+	}
+	method := resp.requestMethod
+	if method == "" {
+		method = "(unknown)"
+	}
+	log.Printf("[DeadLetter] Dropped response (ID: %v, method: %s) for %s: %v", resp.ID, method, connID, err)
+}
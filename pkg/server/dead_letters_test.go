@@ -0,0 +1,51 @@
+package server
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrySend_ClosedConnectionIsDeadLettered(t *testing.T) {
+	connID := "test-dead-letter-conn"
+	conn, _ := setupTestConnection(connID)
+	t.Cleanup(func() { cleanupTestConnection(connID) })
+	mcpConnectionManager.UpdateState(conn.ID, StateReady)
+
+	req := &jsonRPCRequest{Jsonrpc: "2.0", ID: 7, Method: "tools/list"}
+	resp, respond := dispatchJSONRPC(conn, connID, req, createTestToolSetForCall(), &config.Config{})
+	require.True(t, respond)
+
+	// The client goes away while the request is being handled
+	cleanupTestConnection(connID)
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	s := NewServer(createTestToolSetForCall(), &config.Config{})
+	before := s.UndeliveredMessages()
+	require.NotPanics(t, func() {
+		assert.ErrorIs(t, conn.trySend(resp), errConnectionShutdown)
+	})
+	assert.Equal(t, before+1, s.UndeliveredMessages())
+	assert.Contains(t, logged.String(), "[DeadLetter] Dropped response (ID: 7, method: tools/list) for test-dead-letter-conn")
+
+	require.NotPanics(t, func() {
+		assert.Error(t, conn.sendOrDone(newJSONRPCNotification("notifications/tools/list_changed", nil), nil))
+	})
+	assert.Equal(t, before+2, s.UndeliveredMessages())
+	assert.Contains(t, logged.String(), "Dropped notification 'notifications/tools/list_changed' for test-dead-letter-conn")
+}
+
+func TestTrySend_FullChannelIsDeadLettered(t *testing.T) {
+	conn := &Connection{ID: "full", Channel: make(chan jsonRPCResponse)}
+	before := undeliveredMessages.Load()
+	assert.ErrorIs(t, conn.trySend(jsonRPCResponse{ID: 1}), errChannelFull)
+	assert.Equal(t, before+1, undeliveredMessages.Load())
+}
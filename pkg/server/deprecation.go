@@ -0,0 +1,51 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// deprecationWarning returns a one-line warning for a deprecated or sunsetting tool, or "" when
+// the operation has neither. A Sunset response header (RFC 8594) takes precedence over the
+// spec's x-sunset date, since it reflects the live API.
+func deprecationWarning(toolName string, operation mcp.OperationDetail, httpResp *http.Response) string {
+	sunset := operation.Sunset
+	if httpResp != nil {
+		if header := httpResp.Header.Get("Sunset"); header != "" {
+			if t, err := http.ParseTime(header); err == nil {
+				sunset = t.UTC().Format(time.DateOnly)
+			} else {
+				sunset = header
+			}
+		}
+	}
+	switch {
+	case sunset != "":
+		return fmt.Sprintf("Warning: tool '%s' is scheduled for removal on %s; consider migrating to a replacement.", toolName, sunset)
+	case operation.Deprecated:
+		return fmt.Sprintf("Warning: tool '%s' is deprecated and may be removed; consider migrating to a replacement.", toolName)
+	default:
+		return ""
+	}
+}
+
+// withDeprecationWarning appends the tool's deprecation warning to a result, at most once per
+// connection and tool. Calls on an unknown connection always get the warning.
+func withDeprecationWarning(connID string, toolName string, toolSet *mcp.ToolSet, httpResp *http.Response, result ToolResultPayload) ToolResultPayload {
+	warning := deprecationWarning(toolName, toolSet.Operations[toolName], httpResp)
+	if warning == "" {
+		return result
+	}
+	if conn := mcpConnectionManager.GetConnection(connID); conn != nil {
+		if _, warned := conn.deprecationWarned.LoadOrStore(toolName, true); warned {
+			return result
+		}
+	}
+	log.Printf("[Deprecation] %s (connection %s)", warning, connID)
+	result.Content = append(result.Content, ToolResultContent{Type: "text", Text: warning})
+	return result
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolCall_DeprecationWarning(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/live" {
+			w.Header().Set("Sunset", "Wed, 30 Jun 2027 23:59:59 GMT")
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"listLegacyOrders": {Method: "GET", Path: "/v1/orders", BaseURL: backend.URL, Deprecated: true, Sunset: "2026-12-31"},
+		"getOldReport":     {Method: "GET", Path: "/v1/report", BaseURL: backend.URL, Deprecated: true},
+		"getLiveStatus":    {Method: "GET", Path: "/v1/live", BaseURL: backend.URL},
+		"listOrders":       {Method: "GET", Path: "/v2/orders", BaseURL: backend.URL},
+	}}
+
+	call := func(connID, toolName string) []ToolResultContent {
+		params, _ := json.Marshal(ToolCallParams{ToolName: toolName, Input: map[string]interface{}{}})
+		req := &jsonRPCRequest{Jsonrpc: "2.0", Method: "tools/call", Params: json.RawMessage(params), ID: 1}
+		result, ok := handleToolCallJSONRPC(connID, req, toolSet, &config.Config{}).Result.(ToolResultPayload)
+		require.True(t, ok)
+		require.False(t, result.IsError)
+		return result.Content
+	}
+
+	connID := "deprecation-" + uuid.NewString()
+	setupTestConnection(connID)
+	defer cleanupTestConnection(connID)
+
+	t.Run("x-sunset warns on the first call only", func(t *testing.T) {
+		content := call(connID, "listLegacyOrders")
+		require.Len(t, content, 2)
+		assert.Equal(t, `{"ok":true}`, content[0].Text, "the warning is added after the response")
+		assert.Equal(t, "Warning: tool 'listLegacyOrders' is scheduled for removal on 2026-12-31; consider migrating to a replacement.", content[1].Text)
+
+		assert.Len(t, call(connID, "listLegacyOrders"), 1, "no repeat on the same connection")
+	})
+
+	t.Run("Each connection is warned once", func(t *testing.T) {
+		otherID := "deprecation-" + uuid.NewString()
+		setupTestConnection(otherID)
+		defer cleanupTestConnection(otherID)
+		assert.Len(t, call(otherID, "listLegacyOrders"), 2)
+	})
+
+	t.Run("Deprecated without a date", func(t *testing.T) {
+		content := call(connID, "getOldReport")
+		require.Len(t, content, 2)
+		assert.Contains(t, content[1].Text, "tool 'getOldReport' is deprecated")
+	})
+
+	t.Run("Sunset response header", func(t *testing.T) {
+		content := call(connID, "getLiveStatus")
+		require.Len(t, content, 2)
+		assert.Contains(t, content[1].Text, "scheduled for removal on 2027-06-30")
+	})
+
+	t.Run("Current tools get no warning", func(t *testing.T) {
+		assert.Len(t, call(connID, "listOrders"), 1)
+	})
+}
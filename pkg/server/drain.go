@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync/atomic"
+)
+
+// serverDrainingCode is the JSON-RPC error code for a tool call refused while draining.
+const serverDrainingCode = -32000
+
+// serverDraining is set once the process starts draining. Like mcpConnectionManager it is shared
+// by every transport, all of which dispatch through dispatchJSONRPC.
+var serverDraining atomic.Bool
+
+// Drain stops the server from accepting tool calls ahead of a shutdown, so a rolling update can
+// move clients elsewhere without cutting them off. Tool calls are refused with a "server draining"
+// error while initialize, tools/list, ping and the other metadata methods keep working, and
+// /readyz reports not ready so load balancers send new sessions to other instances. Calls already
+// running finish. Draining cannot be undone; follow it with Shutdown once the drain period is over.
+func (s *Server) Drain() {
+	if serverDraining.CompareAndSwap(false, true) {
+		log.Printf("[Drain] Draining: refusing new tool calls, still serving metadata; /readyz reports not ready")
+	}
+}
+
+// Draining reports whether Drain was called.
+func (s *Server) Draining() bool {
+	return serverDraining.Load()
+}
+
+// Shutdown drains the server if it isn't already, then stops the listener started by
+// ListenAndServe, waiting for in-flight HTTP requests until ctx is done. ListenAndServe then
+// returns http.ErrServerClosed. Hijacked WebSocket connections are not waited for.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.Drain()
+	s.mu.Lock()
+	httpServer := s.httpServer
+	s.mu.Unlock()
+	if httpServer == nil {
+		return nil
+	}
+	log.Printf("[Drain] Shutting down the HTTP server")
+	return httpServer.Shutdown(ctx)
+}
+
+// createServerDrainingError refuses a tool call while the server drains.
+func createServerDrainingError(id interface{}) jsonRPCResponse {
+	return createJSONRPCError(id, serverDrainingCode, "Server draining: not accepting new tool calls", map[string]interface{}{
+		"reason": "draining",
+		"hint":   "reconnect to start a session on another instance",
+	})
+}
+
+// drainingReadiness is the /readyz body while draining.
+func drainingReadiness() (int, map[string]interface{}) {
+	return http.StatusServiceUnavailable, map[string]interface{}{
+		"status": "not ready",
+		"reason": "draining: the server is shutting down and accepts no new tool calls",
+	}
+}
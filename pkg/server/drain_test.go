@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// undrainAfterTest resets the process-wide drain flag once a test is done.
+func undrainAfterTest(t *testing.T) {
+	t.Cleanup(func() { serverDraining.Store(false) })
+}
+
+func TestServer_Drain(t *testing.T) {
+	undrainAfterTest(t)
+	s := NewServer(createTestToolSetForCall(), &config.Config{})
+	require.False(t, s.Draining())
+
+	s.Drain()
+	assert.True(t, s.Draining())
+	s.Drain() // Idempotent
+
+	resp := dispatchToReadyConnection(t, s, "tools/call", map[string]interface{}{"name": "get_user", "arguments": map[string]interface{}{"user_id": "1"}})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, serverDrainingCode, resp.Error.Code)
+	assert.Contains(t, resp.Error.Message, "draining")
+
+	// Metadata keeps working
+	resp = dispatchToReadyConnection(t, s, "tools/list", map[string]interface{}{})
+	require.Nil(t, resp.Error)
+	assert.NotEmpty(t, resp.Result.(map[string]interface{})["tools"].([]mcp.Tool))
+	resp = dispatchToReadyConnection(t, s, "ping", map[string]interface{}{})
+	assert.Nil(t, resp.Error)
+
+	conn, _ := setupTestConnection("drain-initialize")
+	defer cleanupTestConnection("drain-initialize")
+	initialize := &jsonRPCRequest{Jsonrpc: "2.0", ID: 1, Method: "initialize", Params: map[string]interface{}{"protocolVersion": defaultProtocolVersion}}
+	resp, _ = dispatchJSONRPC(conn, conn.ID, initialize, s.tools.Load(), s.cfg)
+	assert.Nil(t, resp.Error)
+}
+
+func TestReadyz_Draining(t *testing.T) {
+	undrainAfterTest(t)
+	s := NewServer(createTestToolSetForCall(), &config.Config{})
+	handler := s.Handler()
+
+	readyz := func() (int, map[string]interface{}) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		return recorder.Code, body
+	}
+
+	status, _ := readyz()
+	assert.Equal(t, http.StatusOK, status)
+
+	s.Drain()
+	status, body := readyz()
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "not ready", body["status"])
+	assert.Contains(t, body["reason"], "draining")
+}
+
+func TestServer_Shutdown(t *testing.T) {
+	undrainAfterTest(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	s := NewServer(createTestToolSetForCall(), &config.Config{ConnectionIDMaxLength: 128})
+	served := make(chan error, 1)
+	go func() { served <- s.ListenAndServe(addr) }()
+	require.Eventually(t, func() bool {
+		resp, err := http.Get("http://" + addr + "/readyz")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, s.Shutdown(ctx))
+	assert.True(t, s.Draining(), "shutting down drains first")
+	select {
+	case err := <-served:
+		assert.True(t, errors.Is(err, http.ErrServerClosed), "got %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("ListenAndServe did not return")
+	}
+
+	// Without ListenAndServe there is nothing to stop
+	assert.NoError(t, NewServer(createTestToolSetForCall(), &config.Config{}).Shutdown(ctx))
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// Categories of failed tool calls, given with whether retrying can help in the error result's
+// _meta so clients can decide between retrying, fixing the arguments and giving up.
+const (
+	ErrorCategoryClientError = "client-error" // The call itself is wrong; fix the arguments
+	ErrorCategoryServerError = "server-error" // The upstream failed or couldn't be reached
+	ErrorCategoryTimeout     = "timeout"      // The upstream didn't answer in time
+	ErrorCategoryRateLimited = "rate-limited" // Too many calls; retry after backing off
+	ErrorCategoryAuth        = "auth"         // Credentials are missing, wrong or not allowed, or the session expired
+)
+
+// failureCategory is a failed call's category and whether the same call may succeed later.
+type failureCategory struct {
+	category  string
+	retryable bool
+}
+
+// statusFailureCategory categorizes a non-2xx upstream status. Server errors are retryable except
+// those that won't change, such as 501 Not Implemented.
+func statusFailureCategory(status int) failureCategory {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return failureCategory{ErrorCategoryAuth, false}
+	case status == http.StatusTooManyRequests:
+		return failureCategory{ErrorCategoryRateLimited, true}
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		return failureCategory{ErrorCategoryTimeout, true}
+	case status == http.StatusNotImplemented || status == http.StatusHTTPVersionNotSupported:
+		return failureCategory{ErrorCategoryServerError, false}
+	case status >= 500:
+		return failureCategory{ErrorCategoryServerError, true}
+	default:
+		return failureCategory{ErrorCategoryClientError, false}
+	}
+}
+
+// execFailureCategory categorizes an error from executeToolCall. Errors that never reached the
+// upstream came from building the request out of the arguments.
+func execFailureCategory(err error) failureCategory {
+	var timeoutErr *errUpstreamTimeout
+	var phaseErr *errUpstreamPhaseTimeout
+	if errors.As(err, &timeoutErr) || errors.As(err, &phaseErr) || isTimeoutError(err) {
+		return failureCategory{ErrorCategoryTimeout, true}
+	}
+	var loginErr *errLoginRedirect
+	var securityErr *errUnsatisfiedSecurity
+	if errors.As(err, &loginErr) || errors.As(err, &securityErr) {
+		return failureCategory{ErrorCategoryAuth, false}
+	}
+	var circuitErr *errCircuitOpen
+	var transportErr *url.Error
+	if errors.As(err, &circuitErr) || errors.As(err, &transportErr) {
+		return failureCategory{ErrorCategoryServerError, true}
+	}
+	return failureCategory{ErrorCategoryClientError, false}
+}
+
+// withFailureCategory adds the failure's category and retryable flag to the result's _meta.
+func withFailureCategory(result ToolResultPayload, failure failureCategory) ToolResultPayload {
+	if result.Meta == nil {
+		result.Meta = make(map[string]interface{})
+	}
+	result.Meta["category"] = failure.category
+	result.Meta["retryable"] = failure.retryable
+	return result
+}
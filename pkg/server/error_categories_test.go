@@ -0,0 +1,101 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallToolUpstream_FailureCategory(t *testing.T) {
+	release := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bad":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "missing field"}`))
+		case "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/not-modified":
+			w.WriteHeader(http.StatusNotModified)
+		case "/slow":
+			<-release
+		default:
+			w.Write([]byte(`{"ok": true}`))
+		}
+	}))
+	defer backend.Close()
+	defer close(release)
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"bad_request": {Method: "GET", Path: "/bad", BaseURL: backend.URL},
+		"unavailable": {Method: "GET", Path: "/unavailable", BaseURL: backend.URL},
+		"slow":        {Method: "GET", Path: "/slow", BaseURL: backend.URL},
+		"fine":        {Method: "GET", Path: "/fine", BaseURL: backend.URL},
+		"cached":      {Method: "GET", Path: "/not-modified", BaseURL: backend.URL},
+		"no_creds": {Method: "GET", Path: "/fine", BaseURL: backend.URL,
+			Security: []map[string][]string{{"bearerAuth": {}}}},
+	}, SecuritySchemes: map[string]mcp.SecurityScheme{"bearerAuth": {Type: "http", Scheme: "bearer"}}}
+	cfg := &config.Config{OperationTimeouts: map[string]time.Duration{"slow": 50 * time.Millisecond}}
+	call := func(toolName string) ToolResultPayload {
+		return callToolUpstream("conn", &jsonRPCRequest{ID: 1}, &ToolCallParams{ToolName: toolName, Input: map[string]interface{}{}}, toolSet, cfg)
+	}
+
+	tests := []struct {
+		toolName  string
+		category  string
+		retryable bool
+	}{
+		{"bad_request", ErrorCategoryClientError, false},
+		{"unavailable", ErrorCategoryServerError, true},
+		{"slow", ErrorCategoryTimeout, true},
+		{"no_creds", ErrorCategoryAuth, false},
+	}
+	for _, tc := range tests {
+		result := call(tc.toolName)
+		assert.True(t, result.IsError, tc.toolName)
+		assert.Equal(t, tc.category, result.Meta["category"], tc.toolName)
+		assert.Equal(t, tc.retryable, result.Meta["retryable"], tc.toolName)
+	}
+
+	for _, toolName := range []string{"fine", "cached"} {
+		result := call(toolName)
+		assert.False(t, result.IsError, toolName)
+		assert.NotContains(t, result.Meta, "category", "successful calls, a bodiless 304 included, aren't categorized")
+	}
+}
+
+func TestStatusFailureCategory(t *testing.T) {
+	tests := []struct {
+		status int
+		want   failureCategory
+	}{
+		{http.StatusBadRequest, failureCategory{ErrorCategoryClientError, false}},
+		{http.StatusNotFound, failureCategory{ErrorCategoryClientError, false}},
+		{http.StatusUnauthorized, failureCategory{ErrorCategoryAuth, false}},
+		{http.StatusForbidden, failureCategory{ErrorCategoryAuth, false}},
+		{http.StatusTooManyRequests, failureCategory{ErrorCategoryRateLimited, true}},
+		{http.StatusGatewayTimeout, failureCategory{ErrorCategoryTimeout, true}},
+		{http.StatusInternalServerError, failureCategory{ErrorCategoryServerError, true}},
+		{http.StatusNotImplemented, failureCategory{ErrorCategoryServerError, false}},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, statusFailureCategory(tc.status), tc.status)
+	}
+}
+
+func TestExecFailureCategory(t *testing.T) {
+	assert.Equal(t, failureCategory{ErrorCategoryTimeout, true}, execFailureCategory(&errUpstreamTimeout{budget: time.Second}))
+	assert.Equal(t, failureCategory{ErrorCategoryServerError, true}, execFailureCategory(&errCircuitOpen{host: "api.example.com"}))
+	refused := &url.Error{Op: "Get", URL: "http://api.example.com", Err: errors.New("connection refused")}
+	assert.Equal(t, failureCategory{ErrorCategoryServerError, true}, execFailureCategory(refused))
+	missingAuth := &errUnsatisfiedSecurity{toolName: "getPet", missing: []string{"bearerAuth"}}
+	assert.Equal(t, failureCategory{ErrorCategoryAuth, false}, execFailureCategory(missingAuth))
+	assert.Equal(t, failureCategory{ErrorCategoryClientError, false}, execFailureCategory(errors.New("missing required path parameter")))
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+)
+
+// defaultStreamMaxEvents caps how many events are forwarded from one upstream event stream when
+// not configured.
+const defaultStreamMaxEvents = 1000
+
+// sseEvent is one Server-Sent Event read from an upstream text/event-stream response.
+type sseEvent struct {
+	ID    string `json:"id,omitempty"`
+	Event string `json:"event,omitempty"`
+	Data  string `json:"data"`
+}
+
+// eventStreamSummary is the terminal result of an event stream tool call.
+type eventStreamSummary struct {
+	EventCount int        `json:"eventCount"`
+	Events     []sseEvent `json:"events"`
+	Stopped    string     `json:"stopped,omitempty"` // Why reading stopped before the upstream closed the stream
+}
+
+// isEventStream reports whether an upstream response is a text/event-stream.
+func isEventStream(httpResp *http.Response) bool {
+	mediaType, _, err := mime.ParseMediaType(httpResp.Header.Get("Content-Type"))
+	return err == nil && mediaType == "text/event-stream"
+}
+
+// readSSEEvent reads lines up to the next dispatched event. Comments, retry fields and events
+// without data are skipped, and an event cut off by the end of the stream is discarded, as the
+// EventSource specification does. io.EOF is returned once the stream ends.
+func readSSEEvent(reader *bufio.Reader) (sseEvent, error) {
+	var event sseEvent
+	var data []string
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			line = strings.TrimRight(line, "\r\n")
+			switch {
+			case line == "":
+				if data != nil {
+					event.Data = strings.Join(data, "\n")
+					return event, nil
+				}
+				event = sseEvent{}
+			case strings.HasPrefix(line, ":"):
+			default:
+				field, value, _ := strings.Cut(line, ":")
+				value = strings.TrimPrefix(value, " ")
+				switch field {
+				case "data":
+					data = append(data, value)
+				case "event":
+					event.Event = value
+				case "id":
+					event.ID = value
+				}
+			}
+		}
+		if err != nil {
+			return sseEvent{}, err
+		}
+	}
+}
+
+// streamEventStreamResponse parses Server-Sent Events from the upstream body, queueing each one's
+// data on the connection's Channel as a notifications/progress message as it arrives. Reading
+// stops when the upstream closes the stream, or early at the event cap or the maximum duration;
+// the terminal result lists the events received.
+func streamEventStreamResponse(connID string, req *jsonRPCRequest, params *ToolCallParams, httpResp *http.Response, cfg *config.Config) ToolResultPayload {
+	maxBytes := int64(defaultStreamMaxBytes)
+	maxEvents := defaultStreamMaxEvents
+	var maxDuration time.Duration
+	if cfg != nil {
+		if cfg.StreamMaxBytes > 0 {
+			maxBytes = cfg.StreamMaxBytes
+		}
+		if cfg.StreamMaxEvents > 0 {
+			maxEvents = cfg.StreamMaxEvents
+		}
+		maxDuration = cfg.StreamMaxDuration
+	}
+
+	var progressToken interface{} = req.ID
+	if token, ok := params.Meta["progressToken"]; ok && token != nil {
+		progressToken = token
+	}
+
+	// Closing the body unblocks a read waiting on an upstream that has gone quiet
+	var expired atomic.Bool
+	if maxDuration > 0 {
+		timer := time.AfterFunc(maxDuration, func() {
+			expired.Store(true)
+			httpResp.Body.Close()
+		})
+		defer timer.Stop()
+	}
+
+	conn := mcpConnectionManager.GetConnection(connID)
+	counted := &countingReader{reader: io.LimitReader(httpResp.Body, maxBytes+1)}
+	reader := bufio.NewReader(counted)
+	summary := eventStreamSummary{Events: []sseEvent{}}
+
+	for summary.EventCount < maxEvents {
+		event, err := readSSEEvent(reader)
+		if counted.count > maxBytes {
+			log.Printf("[StreamToolCall] Event stream for tool '%s' exceeded %d bytes, aborting", params.ToolName, maxBytes)
+			return streamFailure(req, fmt.Sprintf("Tool '%s' stream exceeded the maximum size of %d bytes", params.ToolName, maxBytes), failureCategory{ErrorCategoryClientError, false})
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if expired.Load() {
+				summary.Stopped = fmt.Sprintf("maximum duration of %s reached", maxDuration)
+				break
+			}
+			log.Printf("[StreamToolCall] Error reading event stream for tool '%s': %v", params.ToolName, err)
+			message := fmt.Sprintf("Failed to read stream from tool '%s': %v", params.ToolName, err)
+			failure := failureCategory{ErrorCategoryServerError, true}
+			if isTimeoutError(err) {
+				message = fmt.Sprintf("Failed to read stream from tool '%s': upstream stream did not complete in time", params.ToolName)
+				failure = failureCategory{ErrorCategoryTimeout, true}
+			}
+			return streamFailure(req, message, failure)
+		}
+		summary.EventCount++
+		summary.Events = append(summary.Events, event)
+		queueStreamChunk(conn, connID, progressToken, summary.EventCount, event.Data)
+	}
+	if summary.EventCount >= maxEvents {
+		summary.Stopped = fmt.Sprintf("maximum of %d events reached", maxEvents)
+	}
+
+	if summary.Stopped != "" {
+		log.Printf("[StreamToolCall] Event stream for tool '%s' stopped after %d events: %s", params.ToolName, summary.EventCount, summary.Stopped)
+	} else {
+		log.Printf("[StreamToolCall] Event stream for tool '%s' completed with %d events", params.ToolName, summary.EventCount)
+	}
+	text, _ := json.Marshal(summary)
+	return ToolResultPayload{
+		Content:           []ToolResultContent{{Type: "text", Text: string(text)}},
+		StructuredContent: summary,
+		ToolCallID:        fmt.Sprintf("%v", req.ID),
+	}
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSSEEvent(t *testing.T) {
+	stream := ": keep-alive\n" +
+		"retry: 1000\n" +
+		"event: progress\r\nid: 1\r\ndata: 10%\r\n\r\n" +
+		"data: first line\ndata:second line\n\n" +
+		"event: ignored\n\n" +
+		"data: cut off"
+	reader := bufio.NewReader(strings.NewReader(stream))
+
+	event, err := readSSEEvent(reader)
+	require.NoError(t, err)
+	assert.Equal(t, sseEvent{ID: "1", Event: "progress", Data: "10%"}, event)
+
+	event, err = readSSEEvent(reader)
+	require.NoError(t, err)
+	assert.Equal(t, sseEvent{Data: "first line\nsecond line"}, event, "data lines are joined and the event name does not carry over")
+
+	_, err = readSSEEvent(reader)
+	assert.ErrorIs(t, err, io.EOF, "events without data are skipped and an unterminated event is discarded")
+}
+
+// eventStreamBackend writes count events, then keeps the stream open for hold before closing it.
+func eventStreamBackend(t *testing.T, count int, hold time.Duration) *httptest.Server {
+	t.Helper()
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		for i := 1; i <= count; i++ {
+			fmt.Fprintf(w, "event: progress\nid: %d\ndata: {\"percent\": %d}\n\n", i, i*10)
+			flusher.Flush()
+		}
+		select {
+		case <-time.After(hold):
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(backend.Close)
+	return backend
+}
+
+func callEventStreamTool(t *testing.T, connID string, backend *httptest.Server, cfg *config.Config) ToolResultPayload {
+	t.Helper()
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"watch_job": {Method: "GET", Path: "/jobs/1/events", BaseURL: backend.URL},
+	}}
+	params := &ToolCallParams{ToolName: "watch_job", Input: map[string]interface{}{}, Meta: map[string]interface{}{"progressToken": "job-1"}}
+	return callToolUpstream(connID, &jsonRPCRequest{ID: "sse-1"}, params, toolSet, cfg)
+}
+
+func TestCallToolUpstream_EventStream(t *testing.T) {
+	connID := "event-stream-conn"
+	_, channel := setupTestConnection(connID)
+	defer cleanupTestConnection(connID)
+
+	result := callEventStreamTool(t, connID, eventStreamBackend(t, 3, 0), &config.Config{})
+	require.False(t, result.IsError, "an event stream is handled without being marked as streaming")
+
+	for i := 1; i <= 3; i++ {
+		select {
+		case notification := <-channel:
+			assert.Equal(t, "notifications/progress", notification.Method)
+			progress := notification.Params.(map[string]interface{})
+			assert.Equal(t, "job-1", progress["progressToken"])
+			assert.Equal(t, i, progress["progress"])
+			assert.Equal(t, fmt.Sprintf(`{"percent": %d}`, i*10), progress["message"])
+		default:
+			t.Fatalf("event %d was not forwarded", i)
+		}
+	}
+
+	summary, ok := result.StructuredContent.(eventStreamSummary)
+	require.True(t, ok)
+	assert.Equal(t, 3, summary.EventCount)
+	assert.Empty(t, summary.Stopped)
+	assert.Equal(t, sseEvent{ID: "2", Event: "progress", Data: `{"percent": 20}`}, summary.Events[1])
+
+	var text map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &text))
+	assert.Equal(t, float64(3), text["eventCount"])
+}
+
+func TestCallToolUpstream_EventStreamMaxEvents(t *testing.T) {
+	result := callEventStreamTool(t, "no-such-conn", eventStreamBackend(t, 5, time.Minute), &config.Config{StreamMaxEvents: 2})
+	require.False(t, result.IsError)
+
+	summary := result.StructuredContent.(eventStreamSummary)
+	assert.Equal(t, 2, summary.EventCount)
+	assert.Equal(t, "maximum of 2 events reached", summary.Stopped)
+}
+
+func TestCallToolUpstream_EventStreamMaxDuration(t *testing.T) {
+	start := time.Now()
+	result := callEventStreamTool(t, "no-such-conn", eventStreamBackend(t, 1, time.Minute), &config.Config{StreamMaxDuration: 100 * time.Millisecond})
+	require.False(t, result.IsError)
+	assert.Less(t, time.Since(start), 5*time.Second, "a quiet stream is closed at the maximum duration")
+
+	summary := result.StructuredContent.(eventStreamSummary)
+	assert.Equal(t, 1, summary.EventCount)
+	assert.Equal(t, "maximum duration of 100ms reached", summary.Stopped)
+}
+
+func TestCallToolUpstream_EventStreamResultMeta(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("X-RateLimit-Remaining", "41")
+		fmt.Fprint(w, "data: "+strings.Repeat("x", 64)+"\n\n")
+	}))
+	defer backend.Close()
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"watch_job": {Method: "GET", Path: "/jobs/1/events", BaseURL: backend.URL, Deprecated: true},
+	}}
+	call := func(cfg *config.Config) ToolResultPayload {
+		params := &ToolCallParams{ToolName: "watch_job", Input: map[string]interface{}{}}
+		return callToolUpstream("no-such-conn", &jsonRPCRequest{ID: "sse-1"}, params, toolSet, cfg)
+	}
+
+	result := call(&config.Config{})
+	require.False(t, result.IsError)
+	assert.Equal(t, map[string]interface{}{"rateLimit": map[string]string{"X-Ratelimit-Remaining": "41"}}, result.Meta)
+	require.Len(t, result.Content, 2, "streamed results get the deprecation warning too")
+	assert.Contains(t, result.Content[1].Text, "tool 'watch_job' is deprecated")
+
+	result = call(&config.Config{StreamMaxBytes: 16})
+	require.True(t, result.IsError)
+	assert.Equal(t, ErrorCategoryClientError, result.Meta["category"])
+	assert.Equal(t, false, result.Meta["retryable"])
+	assert.Contains(t, result.Meta, "rateLimit")
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// formatCheckers validate string values for the well-known formats checked with ValidateFormats.
+// Other formats are accepted as-is.
+var formatCheckers = map[string]func(string) bool{
+	"date-time": func(v string) bool { _, err := time.Parse(time.RFC3339, v); return err == nil },
+	"date":      func(v string) bool { _, err := time.Parse(time.DateOnly, v); return err == nil },
+	"email": func(v string) bool {
+		address, err := mail.ParseAddress(v)
+		return err == nil && address.Address == v
+	},
+	"uuid": func(v string) bool { _, err := uuid.Parse(v); return err == nil && len(v) == 36 },
+	"uri": func(v string) bool {
+		parsed, err := url.Parse(v)
+		return err == nil && parsed.Scheme != ""
+	},
+	"ipv4": func(v string) bool {
+		ip := net.ParseIP(v)
+		return ip != nil && ip.To4() != nil && !strings.Contains(v, ":")
+	},
+	"ipv6": func(v string) bool { ip := net.ParseIP(v); return ip != nil && strings.Contains(v, ":") },
+}
+
+// validateToolInputFormats checks string arguments against the formats in formatCheckers,
+// complementing validateToolInput. Values of the wrong type are left to validateToolInput.
+func validateToolInputFormats(schema mcp.Schema, input map[string]interface{}) []ValidationError {
+	var errs []ValidationError
+	validateFormatsIn(schema, input, "", &errs)
+	sortValidationErrors(errs)
+	return errs
+}
+
+// validateFormatsIn walks value alongside schema, appending a "format" error for every string
+// that does not match its schema's format.
+func validateFormatsIn(schema mcp.Schema, value interface{}, path string, errs *[]ValidationError) {
+	switch v := value.(type) {
+	case string:
+		format := strings.ToLower(schema.Format)
+		if check, ok := formatCheckers[format]; ok && !check(v) {
+			*errs = append(*errs, ValidationError{
+				Path:    path,
+				Keyword: "format",
+				Message: fmt.Sprintf("value %q is not a valid %s", v, format),
+			})
+		}
+	case map[string]interface{}:
+		for name, property := range schema.Properties {
+			if propertyValue, ok := v[name]; ok {
+				validateFormatsIn(property, propertyValue, path+"/"+escapeJSONPointer(name), errs)
+			}
+		}
+	case []interface{}:
+		if schema.Items != nil {
+			for i, item := range v {
+				validateFormatsIn(*schema.Items, item, path+"/"+strconv.Itoa(i), errs)
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var eventsInputSchema = mcp.Schema{
+	Type: "object",
+	Properties: map[string]mcp.Schema{
+		"since":     {Type: "string", Format: "date-time"},
+		"day":       {Type: "string", Format: "date"},
+		"organizer": {Type: "object", Properties: map[string]mcp.Schema{"email": {Type: "string", Format: "email"}}},
+		"attendees": {Type: "array", Items: &mcp.Schema{Type: "string", Format: "uuid"}},
+		"callback":  {Type: "string", Format: "uri"},
+		"host":      {Type: "string", Format: "ipv4"},
+		"color":     {Type: "string", Format: "hex-color"},
+	},
+}
+
+func TestValidateToolInputFormats(t *testing.T) {
+	var valid map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"since": "2024-01-31T13:45:00+01:00", "day": "2024-01-31", "organizer": {"email": "ada@example.com"},
+		"attendees": ["123e4567-e89b-12d3-a456-426614174000"], "callback": "https://example.com/hook",
+		"host": "10.0.0.1", "color": "not checked"
+	}`), &valid))
+	assert.Empty(t, validateToolInputFormats(eventsInputSchema, valid))
+
+	var invalid map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"since": "31/01/2024", "day": "2024-01-31T00:00:00Z", "organizer": {"email": "Ada <ada@example.com>"},
+		"attendees": ["123e4567-e89b-12d3-a456-426614174000", "nope"], "callback": "/relative",
+		"host": "::1"
+	}`), &invalid))
+	assert.Equal(t, []ValidationError{
+		{Path: "/attendees/1", Keyword: "format", Message: `value "nope" is not a valid uuid`},
+		{Path: "/callback", Keyword: "format", Message: `value "/relative" is not a valid uri`},
+		{Path: "/day", Keyword: "format", Message: `value "2024-01-31T00:00:00Z" is not a valid date`},
+		{Path: "/host", Keyword: "format", Message: `value "::1" is not a valid ipv4`},
+		{Path: "/organizer/email", Keyword: "format", Message: `value "Ada <ada@example.com>" is not a valid email`},
+		{Path: "/since", Keyword: "format", Message: `value "31/01/2024" is not a valid date-time`},
+	}, validateToolInputFormats(eventsInputSchema, invalid))
+}
+
+func TestToolCall_ValidateFormats(t *testing.T) {
+	toolSet := &mcp.ToolSet{
+		Tools:      []mcp.Tool{{Name: "list_events", InputSchema: eventsInputSchema}},
+		Operations: map[string]mcp.OperationDetail{"list_events": {Method: "GET", Path: "/events"}},
+	}
+	params := map[string]interface{}{"name": "list_events", "arguments": map[string]interface{}{"since": "yesterday"}}
+
+	resp := dispatchToReadyConnection(t, NewServer(toolSet, &config.Config{ValidateFormats: true}), "tools/call", params)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, -32602, resp.Error.Code)
+	assert.Equal(t, []ValidationError{{Path: "/since", Keyword: "format", Message: `value "yesterday" is not a valid date-time`}}, resp.Error.Data)
+
+	resp = dispatchToReadyConnection(t, NewServer(toolSet, &config.Config{}), "tools/call", params)
+	assert.Nil(t, resp.Error, "formats are not checked by default")
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"log"
+	"time"
+)
+
+// SetHandshakeDeadline gives every connection created from now on deadline to complete the
+// initialize handshake. A connection that is not ready by then, e.g. because the client never
+// sent notifications/initialized, is shut down and removed. Unlike the reaper's periodic scan,
+// each connection gets its own timer, stopped once it is ready. Zero or less disables it.
+func (cm *ConnectionManager) SetHandshakeDeadline(deadline time.Duration) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	cm.handshakeDeadline = deadline
+}
+
+// startHandshakeTimerLocked arms the handshake deadline for a new connection. Callers must hold
+// the write lock.
+func (cm *ConnectionManager) startHandshakeTimerLocked(conn *Connection) {
+	if cm.handshakeDeadline <= 0 {
+		return
+	}
+	deadline := cm.handshakeDeadline
+	conn.handshakeTimer = time.AfterFunc(deadline, func() {
+		cm.expireHandshake(conn, deadline)
+	})
+}
+
+// expireHandshake shuts conn down if it is still registered and not ready.
+func (cm *ConnectionManager) expireHandshake(conn *Connection, deadline time.Duration) {
+	defer cm.flushState()
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	if cm.connections[conn.ID] != conn || conn.State == StateReady {
+		return
+	}
+	log.Printf("[ConnectionManager] Connection %s is still %s %s after connecting; shutting it down (handshake deadline)", conn.ID, conn.State, deadline)
+	cm.deleteLocked(conn.ID)
+	conn.State = StateShutdown
+	conn.shutdownChannel()
+	cm.persist()
+}
+
+// stopHandshakeTimer cancels the connection's handshake deadline, if any.
+func (c *Connection) stopHandshakeTimer() {
+	if c.handshakeTimer != nil {
+		c.handshakeTimer.Stop()
+		c.handshakeTimer = nil
+	}
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionManager_HandshakeDeadline(t *testing.T) {
+	cm := NewConnectionManager()
+	cm.SetHandshakeDeadline(50 * time.Millisecond)
+
+	stuck, _ := cm.NewConnection("never-ready")
+	cm.UpdateState("never-ready", StateInitializing)
+	cm.NewConnection("ready-in-time")
+	cm.UpdateState("ready-in-time", StateInitializing)
+	cm.UpdateState("ready-in-time", StateReady)
+
+	require.Eventually(t, func() bool { return cm.GetConnection("never-ready") == nil }, time.Second, 10*time.Millisecond,
+		"a connection that never readies is removed after the deadline")
+	assert.Equal(t, StateShutdown, stuck.State)
+	_, open := <-stuck.Channel
+	assert.False(t, open, "the expired connection's channel is closed")
+
+	time.Sleep(100 * time.Millisecond)
+	ready := cm.GetConnection("ready-in-time")
+	require.NotNil(t, ready, "a connection that readies in time survives the deadline")
+	assert.Equal(t, StateReady, ready.State)
+	assert.Nil(t, ready.handshakeTimer, "the timer is canceled once ready")
+}
+
+func TestConnectionManager_HandshakeDeadlineReconnect(t *testing.T) {
+	cm := NewConnectionManager()
+	cm.SetHandshakeDeadline(50 * time.Millisecond)
+
+	old, _ := cm.NewConnection("reconnecting")
+	time.Sleep(30 * time.Millisecond)
+	replacement, _ := cm.NewConnection("reconnecting")
+	assert.Nil(t, old.handshakeTimer, "the replaced connection's timer is stopped")
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Same(t, replacement, cm.GetConnection("reconnecting"), "the old timer does not remove the replacement")
+	require.Eventually(t, func() bool { return cm.GetConnection("reconnecting") == nil }, time.Second, 10*time.Millisecond,
+		"the replacement gets its own deadline")
+}
+
+func TestConnectionManager_HandshakeDeadlineDisabled(t *testing.T) {
+	cm := NewConnectionManager()
+	conn, _ := cm.NewConnection("no-deadline")
+	assert.Nil(t, conn.handshakeTimer)
+}
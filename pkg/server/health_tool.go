@@ -0,0 +1,116 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// upstreamHealthToolName is the built-in tool that probes the upstream API, enabled with
+// config.UpstreamHealthTool.
+const upstreamHealthToolName = "__upstream_health"
+
+// healthProbeTimeout bounds a base URL probe; probes of a health operation use its own timeout.
+const healthProbeTimeout = 10 * time.Second
+
+// maxHealthProbeBody is how much of a probe's response body is read before closing it.
+const maxHealthProbeBody = 64 << 10
+
+// UpstreamHealth is the structured result of __upstream_health.
+type UpstreamHealth struct {
+	Healthy   bool   `json:"healthy"`
+	Reachable bool   `json:"reachable"`           // The upstream answered at all
+	Target    string `json:"target"`              // Method and URL (or path, for an operation) probed
+	Operation string `json:"operation,omitempty"` // Tool of the x-mcp-health-check operation, if one was probed
+	Status    int    `json:"status,omitempty"`
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+// upstreamHealthTool returns the tool definition advertised for __upstream_health.
+func upstreamHealthTool() mcp.Tool {
+	readOnly, openWorld := true, true
+	return mcp.Tool{
+		Name:        upstreamHealthToolName,
+		Description: "Check whether the upstream API is reachable: returns whether it is healthy, the HTTP status and the latency. Use it when tool calls keep failing to tell an outage from a bad call.",
+		InputSchema: mcp.Schema{Type: "object", Properties: map[string]mcp.Schema{}},
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:  &readOnly,
+			OpenWorldHint: &openWorld,
+		},
+	}
+}
+
+// upstreamHealthEnabled reports whether __upstream_health is listed and served.
+func upstreamHealthEnabled(cfg *config.Config) bool {
+	return cfg != nil && cfg.UpstreamHealthTool
+}
+
+// probeUpstream calls the operation marked x-mcp-health-check (the first by tool name), which is
+// healthy when it answers 2xx. Without one it sends a GET to the upstream base URL, which is
+// healthy when it answers anything but a 5xx: the root of an API often is a 404.
+func probeUpstream(toolSet *mcp.ToolSet, cfg *config.Config) UpstreamHealth {
+	names := make([]string, 0, len(toolSet.Operations))
+	for name := range toolSet.Operations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !toolSet.Operations[name].HealthCheck {
+			continue
+		}
+		operation := toolSet.Operations[name]
+		health := UpstreamHealth{Target: fmt.Sprintf("%s %s", operation.Method, operation.Path), Operation: name}
+		start := time.Now()
+		resp, err := executeToolCall(&ToolCallParams{ToolName: name, Input: map[string]interface{}{}}, toolSet, cfg)
+		return finishProbe(health, start, resp, err, resp != nil && resp.StatusCode >= 200 && resp.StatusCode < 300)
+	}
+
+	baseURL := ""
+	if cfg != nil && cfg.ServerBaseURL != "" {
+		baseURL = cfg.ServerBaseURL
+	}
+	for _, name := range names {
+		if baseURL != "" {
+			break
+		}
+		baseURL = toolSet.Operations[name].BaseURL
+	}
+	if baseURL == "" {
+		return UpstreamHealth{Error: "no upstream base URL is configured"}
+	}
+
+	health := UpstreamHealth{Target: "GET " + baseURL}
+	start := time.Now()
+	req, err := http.NewRequest(http.MethodGet, baseURL, nil)
+	if err != nil {
+		return finishProbe(health, start, nil, err, false)
+	}
+	client := &http.Client{Timeout: healthProbeTimeout, Transport: upstreamTransport(cfg)}
+	resp, err := client.Do(req)
+	return finishProbe(health, start, resp, err, resp != nil && resp.StatusCode < 500)
+}
+
+// finishProbe records the outcome of a probe request and closes its response.
+func finishProbe(health UpstreamHealth, start time.Time, resp *http.Response, err error, healthy bool) UpstreamHealth {
+	health.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxHealthProbeBody))
+	health.Reachable = true
+	health.Status = resp.StatusCode
+	health.Healthy = healthy
+	if !healthy {
+		health.Error = fmt.Sprintf("upstream answered %s", resp.Status)
+	}
+	return health
+}
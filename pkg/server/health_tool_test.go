@@ -0,0 +1,113 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func callUpstreamHealth(t *testing.T, toolSet *mcp.ToolSet, cfg *config.Config) UpstreamHealth {
+	t.Helper()
+	params, _ := json.Marshal(ToolCallParams{ToolName: upstreamHealthToolName, Input: map[string]interface{}{}})
+	req := &jsonRPCRequest{Jsonrpc: "2.0", Method: "tools/call", Params: json.RawMessage(params), ID: "health-1"}
+	resp := handleToolCallJSONRPC("test-conn", req, toolSet, cfg)
+	require.Nil(t, resp.Error)
+	result, ok := resp.Result.(ToolResultPayload)
+	require.True(t, ok)
+	assert.False(t, result.IsError, "an unhealthy upstream is still a result")
+	health, ok := result.StructuredContent.(UpstreamHealth)
+	require.True(t, ok, "got %T", result.StructuredContent)
+	return health
+}
+
+func TestUpstreamHealthTool_BaseURL(t *testing.T) {
+	status := http.StatusNotFound
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer upstream.Close()
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"get_report": {Method: "GET", Path: "/report", BaseURL: upstream.URL},
+	}}
+	cfg := &config.Config{UpstreamHealthTool: true}
+
+	// Any answer short of a 5xx means the upstream is up
+	health := callUpstreamHealth(t, toolSet, cfg)
+	assert.True(t, health.Healthy)
+	assert.True(t, health.Reachable)
+	assert.Equal(t, http.StatusNotFound, health.Status)
+	assert.Equal(t, "GET "+upstream.URL, health.Target)
+	assert.GreaterOrEqual(t, health.LatencyMs, int64(0))
+
+	status = http.StatusServiceUnavailable
+	health = callUpstreamHealth(t, toolSet, cfg)
+	assert.False(t, health.Healthy)
+	assert.True(t, health.Reachable)
+	assert.Equal(t, http.StatusServiceUnavailable, health.Status)
+	assert.Contains(t, health.Error, "503")
+
+	upstream.Close()
+	health = callUpstreamHealth(t, toolSet, cfg)
+	assert.False(t, health.Healthy)
+	assert.False(t, health.Reachable)
+	assert.NotEmpty(t, health.Error)
+}
+
+func TestUpstreamHealthTool_HealthCheckOperation(t *testing.T) {
+	var probed []string
+	healthStatus := http.StatusOK
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probed = append(probed, r.URL.Path)
+		if r.URL.Path == "/healthz" {
+			w.WriteHeader(healthStatus)
+		}
+	}))
+	defer upstream.Close()
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"get_report": {Method: "GET", Path: "/report", BaseURL: upstream.URL},
+		"ping":       {Method: "GET", Path: "/healthz", BaseURL: upstream.URL, HealthCheck: true},
+	}}
+	cfg := &config.Config{UpstreamHealthTool: true}
+
+	health := callUpstreamHealth(t, toolSet, cfg)
+	assert.True(t, health.Healthy)
+	assert.Equal(t, "ping", health.Operation)
+	assert.Equal(t, "GET /healthz", health.Target)
+	assert.Equal(t, []string{"/healthz"}, probed)
+
+	// A health operation must answer 2xx
+	healthStatus = http.StatusNotFound
+	health = callUpstreamHealth(t, toolSet, cfg)
+	assert.False(t, health.Healthy)
+	assert.True(t, health.Reachable)
+	assert.Equal(t, http.StatusNotFound, health.Status)
+}
+
+func TestUpstreamHealthTool_Disabled(t *testing.T) {
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{}}
+
+	names := func(cfg *config.Config) []string {
+		var names []string
+		for _, tool := range listTools(toolSet, cfg) {
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+	assert.NotContains(t, names(&config.Config{}), upstreamHealthToolName)
+	assert.Contains(t, names(&config.Config{UpstreamHealthTool: true}), upstreamHealthToolName)
+
+	params, _ := json.Marshal(ToolCallParams{ToolName: upstreamHealthToolName, Input: map[string]interface{}{}})
+	req := &jsonRPCRequest{Jsonrpc: "2.0", Method: "tools/call", Params: json.RawMessage(params), ID: "health-2"}
+	_, handled := handleBuiltinToolCall(req, &ToolCallParams{ToolName: upstreamHealthToolName}, toolSet, &config.Config{})
+	assert.False(t, handled)
+
+	health := probeUpstream(toolSet, &config.Config{UpstreamHealthTool: true})
+	assert.False(t, health.Healthy)
+	assert.Equal(t, "no upstream base URL is configured", health.Error)
+}
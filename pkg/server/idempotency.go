@@ -0,0 +1,149 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// idempotencyEntry tracks one logical tool call: its idempotency key, and once finished,
+// its result (successful results only) until the entry expires.
+type idempotencyEntry struct {
+	key       string
+	done      chan struct{} // Closed when the in-flight call finishes
+	inFlight  bool
+	hasResult bool
+	result    ToolResultPayload
+	expires   time.Time
+}
+
+// idempotencyCache deduplicates identical tool calls within a window.
+type idempotencyCache struct {
+	mutex   sync.Mutex
+	entries map[string]*idempotencyEntry
+	now     func() time.Time
+}
+
+func newIdempotencyCache() *idempotencyCache {
+	return &idempotencyCache{
+		entries: make(map[string]*idempotencyEntry),
+		now:     time.Now,
+	}
+}
+
+// Global deduplicator shared by all connections, since retries may arrive on a new connection
+var toolCallDeduplicator = newIdempotencyCache()
+
+// usesIdempotencyKey reports whether calls to a tool should be deduplicated and carry an idempotency key.
+func usesIdempotencyKey(toolName string, toolSet *mcp.ToolSet, cfg *config.Config) bool {
+	if cfg == nil || cfg.IdempotencyWindow <= 0 {
+		return false
+	}
+	if operation, ok := toolSet.Operations[toolName]; ok && operation.Idempotency {
+		return true
+	}
+	return sliceContainsString(cfg.IdempotentOperations, toolName)
+}
+
+// deduplicateToolCall runs call at most once per identical (tool, arguments, session headers)
+// triple within the configured window. Concurrent duplicates wait for the in-flight call and share
+// its result; later duplicates get the cached result. Failed calls are not cached, but a retry
+// reuses the same idempotency key so the upstream can recognize it. Connections with different
+// session headers, such as another tenant, never share a call.
+func deduplicateToolCall(connID string, req *jsonRPCRequest, params *ToolCallParams, cfg *config.Config, call func(key string) ToolResultPayload) ToolResultPayload {
+	argsJSON, err := json.Marshal(params.Input) // Map keys are sorted, so this is canonical
+	if err != nil {
+		log.Printf("[Idempotency] Could not fingerprint call to '%s', executing without deduplication: %v", params.ToolName, err)
+		return call(uuid.NewString())
+	}
+	fingerprint := params.ToolName + "\x00" + string(argsJSON) + "\x00" + sessionFingerprint(mcpConnectionManager.SessionHeaders(connID))
+
+	result := toolCallDeduplicator.do(fingerprint, cfg.IdempotencyWindow, call)
+	result.ToolCallID = fmt.Sprintf("%v", req.ID)
+	return result
+}
+
+// sessionFingerprint hashes a connection's session headers, sensitive ones included, so that the
+// cache tells connections apart without holding their values.
+func sessionFingerprint(headers http.Header) string {
+	if len(headers) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	hash := sha256.New()
+	for _, name := range names {
+		for _, value := range headers[name] {
+			fmt.Fprintf(hash, "%s\x00%s\x00", name, value)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// do implements the deduplication described on deduplicateToolCall.
+func (c *idempotencyCache) do(fingerprint string, window time.Duration, call func(key string) ToolResultPayload) ToolResultPayload {
+	c.mutex.Lock()
+	for {
+		c.purgeExpired()
+		entry := c.entries[fingerprint]
+		if entry == nil {
+			break
+		}
+		if entry.inFlight {
+			log.Printf("[Idempotency] Waiting on in-flight duplicate call (key %s)", entry.key)
+			c.mutex.Unlock()
+			<-entry.done
+			c.mutex.Lock()
+			continue
+		}
+		if entry.hasResult {
+			log.Printf("[Idempotency] Returning result of recent duplicate call (key %s)", entry.key)
+			c.mutex.Unlock()
+			return entry.result
+		}
+		break // Previous attempt failed: retry below with the same key
+	}
+
+	key := uuid.NewString()
+	if previous := c.entries[fingerprint]; previous != nil {
+		key = previous.key
+	}
+	entry := &idempotencyEntry{key: key, done: make(chan struct{}), inFlight: true}
+	c.entries[fingerprint] = entry
+	c.mutex.Unlock()
+
+	result := call(key)
+
+	c.mutex.Lock()
+	entry.inFlight = false
+	entry.hasResult = !result.IsError
+	entry.result = result
+	entry.expires = c.now().Add(window)
+	close(entry.done)
+	c.mutex.Unlock()
+
+	return result
+}
+
+// purgeExpired drops finished entries past their window. Callers must hold the mutex.
+func (c *idempotencyCache) purgeExpired() {
+	now := c.now()
+	for fingerprint, entry := range c.entries {
+		if !entry.inFlight && now.After(entry.expires) {
+			delete(c.entries, fingerprint)
+		}
+	}
+}
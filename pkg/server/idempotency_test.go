@@ -0,0 +1,186 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func callChargeTool(t *testing.T, toolSet *mcp.ToolSet, cfg *config.Config, id string, amount int) ToolResultPayload {
+	t.Helper()
+	params, _ := json.Marshal(ToolCallParams{ToolName: "create_charge", Input: map[string]interface{}{"amount": amount}})
+	req := &jsonRPCRequest{Jsonrpc: "2.0", Method: "tools/call", Params: json.RawMessage(params), ID: id}
+	result, ok := handleToolCallJSONRPC("test-conn", req, toolSet, cfg).Result.(ToolResultPayload)
+	require.True(t, ok)
+	return result
+}
+
+func TestToolCall_DeduplicatesIdenticalCalls(t *testing.T) {
+	toolCallDeduplicator = newIdempotencyCache()
+
+	var hits int32
+	var keys sync.Map
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		keys.Store(r.Header.Get("Idempotency-Key"), true)
+		time.Sleep(50 * time.Millisecond) // Keep the first call in flight while the duplicate arrives
+		w.Write([]byte(`{"charge":"ch_1"}`))
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"create_charge": {Method: "POST", Path: "/charges", BaseURL: backend.URL, Idempotency: true},
+	}}
+	cfg := &config.Config{IdempotencyWindow: time.Minute}
+
+	// Two identical concurrent calls: the second waits for the first
+	var wg sync.WaitGroup
+	results := make([]ToolResultPayload, 2)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = callChargeTool(t, toolSet, cfg, []string{"call-a", "call-b"}[i], 100)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits), "upstream should be hit once")
+	for _, result := range results {
+		assert.False(t, result.IsError)
+		assert.Equal(t, `{"charge":"ch_1"}`, result.Content[0].Text)
+	}
+	assert.ElementsMatch(t, []string{"call-a", "call-b"}, []string{results[0].ToolCallID, results[1].ToolCallID})
+
+	// A later identical call within the window is served from the cache
+	callChargeTool(t, toolSet, cfg, "call-c", 100)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+
+	// Different arguments are a different logical call with a different key
+	callChargeTool(t, toolSet, cfg, "call-d", 200)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+	keyCount := 0
+	keys.Range(func(key, _ interface{}) bool {
+		assert.NotEmpty(t, key)
+		keyCount++
+		return true
+	})
+	assert.Equal(t, 2, keyCount)
+}
+
+func TestToolCall_FailedCallRetriesWithSameKey(t *testing.T) {
+	toolCallDeduplicator = newIdempotencyCache()
+
+	var receivedKeys []string
+	var mu sync.Mutex
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		receivedKeys = append(receivedKeys, r.Header.Get("X-Request-Key"))
+		first := len(receivedKeys) == 1
+		mu.Unlock()
+		if first {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"create_charge": {Method: "POST", Path: "/charges", BaseURL: backend.URL},
+	}}
+	cfg := &config.Config{IdempotencyWindow: time.Minute, IdempotencyKeyHeader: "X-Request-Key", IdempotentOperations: []string{"create_charge"}}
+
+	assert.True(t, callChargeTool(t, toolSet, cfg, "try-1", 100).IsError)
+	assert.False(t, callChargeTool(t, toolSet, cfg, "try-2", 100).IsError)
+
+	require.Len(t, receivedKeys, 2, "failed calls are not cached")
+	assert.NotEmpty(t, receivedKeys[0])
+	assert.Equal(t, receivedKeys[0], receivedKeys[1], "the retry reuses the idempotency key")
+}
+
+func TestToolCall_DeduplicationIsPerTenant(t *testing.T) {
+	toolCallDeduplicator = newIdempotencyCache()
+
+	var hits int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Write([]byte(`{"tenant":"` + r.Header.Get("X-Tenant-ID") + `"}`))
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"create_charge": {Method: "POST", Path: "/charges", BaseURL: backend.URL, Idempotency: true},
+	}}
+	cfg := &config.Config{IdempotencyWindow: time.Minute, SessionHeaders: map[string]config.SessionHeader{
+		"tenant": {Header: "X-Tenant-ID"},
+		"token":  {Header: "X-Session-Token", Sensitive: true},
+	}}
+	callAs := func(connID string, meta map[string]interface{}) ToolResultPayload {
+		t.Helper()
+		conn, _ := setupTestConnection(connID)
+		t.Cleanup(func() { cleanupTestConnection(connID) })
+		initialize := &jsonRPCRequest{Jsonrpc: "2.0", ID: 1, Method: "initialize", Params: map[string]interface{}{
+			"protocolVersion": defaultProtocolVersion,
+			"_meta":           meta,
+		}}
+		resp, _ := dispatchJSONRPC(conn, connID, initialize, toolSet, cfg)
+		require.Nil(t, resp.Error)
+		mcpConnectionManager.UpdateState(connID, StateReady)
+
+		rawParams, _ := json.Marshal(map[string]interface{}{"name": "create_charge", "arguments": map[string]interface{}{"amount": 100}})
+		call := &jsonRPCRequest{Jsonrpc: "2.0", ID: 2, Method: "tools/call", Params: json.RawMessage(rawParams)}
+		return handleToolCallJSONRPC(connID, call, toolSet, cfg).Result.(ToolResultPayload)
+	}
+
+	acme := callAs("tenant-acme", map[string]interface{}{"tenant": "acme"})
+	globex := callAs("tenant-globex", map[string]interface{}{"tenant": "globex"})
+	assert.Equal(t, `{"tenant":"acme"}`, acme.Content[0].Text)
+	assert.Equal(t, `{"tenant":"globex"}`, globex.Content[0].Text, "another tenant never gets a cached result")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+
+	// Sensitive session headers tell connections apart too
+	callAs("tenant-acme-other-token", map[string]interface{}{"tenant": "acme", "token": "t-2"})
+	assert.Equal(t, int32(3), atomic.LoadInt32(&hits))
+
+	// Another connection of the same tenant still shares the call
+	again := callAs("tenant-acme-2", map[string]interface{}{"tenant": "acme"})
+	assert.Equal(t, `{"tenant":"acme"}`, again.Content[0].Text)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&hits))
+}
+
+func TestIdempotencyCache_WindowExpiry(t *testing.T) {
+	cache := newIdempotencyCache()
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	calls := 0
+	call := func(key string) ToolResultPayload {
+		calls++
+		return ToolResultPayload{Content: []ToolResultContent{{Type: "text", Text: key}}}
+	}
+
+	first := cache.do("fp", time.Minute, call)
+	cache.do("fp", time.Minute, call)
+	assert.Equal(t, 1, calls)
+
+	now = now.Add(2 * time.Minute)
+	second := cache.do("fp", time.Minute, call)
+	assert.Equal(t, 2, calls)
+	assert.NotEqual(t, first.Content[0].Text, second.Content[0].Text, "a new logical call gets a new key")
+}
+
+func TestUsesIdempotencyKey_DisabledWithoutWindow(t *testing.T) {
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{"create_charge": {Idempotency: true}}}
+	assert.False(t, usesIdempotencyKey("create_charge", toolSet, &config.Config{}))
+	assert.True(t, usesIdempotencyKey("create_charge", toolSet, &config.Config{IdempotencyWindow: time.Second}))
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// decodeJSON unmarshals data like json.Unmarshal, but keeps numbers as json.Number so that large
+// integer IDs and arguments are not rounded through float64 (which is exact only up to 2^53).
+func decodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		if err == io.EOF {
+			return errors.New("unexpected end of JSON input")
+		}
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("invalid character after top-level value")
+	}
+	return nil
+}
+
+// formatScalar renders an argument value for a URL, header or cookie. Numbers keep their exact
+// digits, without a ".0" suffix or scientific notation.
+func formatScalar(value interface{}) string {
+	switch v := value.(type) {
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+// integerText returns the plain integer digits of a number with an integral value, e.g. "1000"
+// for 1e3 or "3" for 3.0. It reports false for fractional or non-numeric values.
+func integerText(value interface{}) (string, bool) {
+	var text string
+	switch v := value.(type) {
+	case json.Number:
+		text = v.String()
+		if !strings.ContainsAny(text, ".eE") {
+			return text, true
+		}
+	case float64:
+		if v != math.Trunc(v) || math.IsInf(v, 0) {
+			return "", false
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int, int32, int64:
+		return fmt.Sprintf("%d", v), true
+	default:
+		return "", false
+	}
+
+	// Parsing as a float first bounds the exponent, so exact parsing below stays cheap
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil || f != math.Trunc(f) {
+		return "", false
+	}
+	if f == 0 {
+		return "0", true
+	}
+	r, ok := new(big.Rat).SetString(text)
+	if !ok || !r.IsInt() {
+		return "", false
+	}
+	return r.Num().String(), true
+}
+
+// coerceNumbers rewrites integral numbers in value to plain integer form wherever the schema
+// declares type integer, so e.g. 1e3 reaches the upstream as 1000. Values declared as number, and
+// values without a declared type, are left as the client sent them.
+func coerceNumbers(schema mcp.Schema, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, propSchema := range schema.Properties {
+			if propValue, ok := v[name]; ok {
+				v[name] = coerceNumbers(propSchema, propValue)
+			}
+		}
+		return v
+	case []interface{}:
+		if schema.Items != nil {
+			for i, element := range v {
+				v[i] = coerceNumbers(*schema.Items, element)
+			}
+		}
+		return v
+	}
+	if schema.Type == "integer" {
+		if text, ok := integerText(value); ok {
+			return json.Number(text)
+		}
+	}
+	return value
+}
+
+// coerceToolInput applies coerceNumbers to a tool's arguments using the tool's input schema. The
+// input map is modified in place and returned.
+func coerceToolInput(toolName string, toolSet *mcp.ToolSet, input map[string]interface{}) map[string]interface{} {
+	for _, tool := range toolSet.Tools {
+		if tool.Name == toolName {
+			coerceNumbers(tool.InputSchema, input)
+			break
+		}
+	}
+	return input
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLargeIntegerRoundTrip(t *testing.T) {
+	const bigID = "1234567890123456789" // 19 digits, beyond float64's exact range
+
+	var upstreamURI, upstreamBody string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamURI = r.RequestURI
+		body, _ := io.ReadAll(r.Body)
+		upstreamBody = string(body)
+		w.Write([]byte(`{"id": ` + bigID + `, "name": "Rex"}`))
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{
+		Tools: []mcp.Tool{{
+			Name: "updatePet",
+			InputSchema: mcp.Schema{Type: "object", Properties: map[string]mcp.Schema{
+				"petId":   {Type: "integer"},
+				"ownerId": {Type: "integer"},
+				"weight":  {Type: "number"},
+			}},
+		}},
+		Operations: map[string]mcp.OperationDetail{
+			"updatePet": {
+				Method:     "PUT",
+				Path:       "/pets/{petId}",
+				BaseURL:    backend.URL,
+				Parameters: []mcp.ParameterDetail{{Name: "petId", In: "path"}},
+			},
+		},
+	}
+	cfg := &config.Config{ResponseProjections: map[string][]string{"updatePet": {"id"}}}
+
+	connID := uuid.NewString()
+	_, channel := setupTestConnection(connID)
+	defer cleanupTestConnection(connID)
+	mcpConnectionManager.UpdateState(connID, StateReady)
+
+	body := `{"jsonrpc": "2.0", "method": "tools/call", "id": ` + bigID + `, "params": {"name": "updatePet", "arguments": {"petId": ` + bigID + `, "ownerId": 1e3, "weight": 2.0}}}`
+	r := httptest.NewRequest(http.MethodPost, "/messages/"+connID, strings.NewReader(body))
+	r.SetPathValue("connectionId", connID)
+	httpMethodPostHandler(httptest.NewRecorder(), r, toolSet, cfg, false)
+
+	assert.Equal(t, "/pets/"+bigID, upstreamURI, "path argument keeps every digit")
+	assert.JSONEq(t, `{"ownerId": 1000, "weight": 2.0}`, upstreamBody)
+	assert.Contains(t, upstreamBody, `"ownerId":1000`, "integer-typed values are sent in integer form")
+	assert.Contains(t, upstreamBody, `"weight":2.0`, "number-typed values are sent as given")
+
+	require.Len(t, channel, 1)
+	encoded, err := json.Marshal(<-channel)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"id":`+bigID, "the request ID round-trips unchanged")
+	assert.Contains(t, string(encoded), `{\"id\":`+bigID+`}`, "the projected response keeps every digit")
+}
+
+func TestDecodeJSON(t *testing.T) {
+	var value map[string]interface{}
+	require.NoError(t, decodeJSON([]byte(`{"n": 9007199254740993} `), &value))
+	assert.Equal(t, json.Number("9007199254740993"), value["n"])
+
+	assert.Error(t, decodeJSON([]byte(`{"n": 1} {"n": 2}`), &value), "trailing data is rejected like json.Unmarshal")
+	assert.Error(t, decodeJSON([]byte(``), &value))
+}
+
+func TestIntegerText(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+		ok       bool
+	}{
+		{json.Number("1234567890123456789"), "1234567890123456789", true},
+		{json.Number("1e3"), "1000", true},
+		{json.Number("-12.000"), "-12", true},
+		{json.Number("0e10"), "0", true},
+		{json.Number("2.5"), "", false},
+		{json.Number("1e400"), "", false},
+		{float64(1e21), "1000000000000000000000", true},
+		{float64(0.5), "", false},
+		{42, "42", true},
+		{"42", "", false},
+	}
+	for _, tc := range tests {
+		text, ok := integerText(tc.value)
+		assert.Equal(t, tc.ok, ok, "%v", tc.value)
+		assert.Equal(t, tc.expected, text, "%v", tc.value)
+	}
+}
+
+func TestFormatScalar(t *testing.T) {
+	assert.Equal(t, "1234567890123456789", formatScalar(json.Number("1234567890123456789")))
+	assert.Equal(t, "1000000000000000000000", formatScalar(float64(1e21)), "no scientific notation")
+	assert.Equal(t, "3", formatScalar(float64(3)), "no .0 suffix")
+	assert.Equal(t, "2.5", formatScalar(2.5))
+	assert.Equal(t, "true", formatScalar(true))
+}
+
+func TestValidateToolInput_JSONNumbers(t *testing.T) {
+	schema := mcp.Schema{Type: "object", Properties: map[string]mcp.Schema{
+		"id":    {Type: "integer"},
+		"ratio": {Type: "number"},
+	}}
+
+	assert.Empty(t, validateToolInput(schema, map[string]interface{}{"id": json.Number("1234567890123456789"), "ratio": json.Number("0.5")}))
+
+	errs := validateToolInput(schema, map[string]interface{}{"id": json.Number("1.5")})
+	require.Len(t, errs, 1)
+	assert.Equal(t, "expected integer, got number", errs[0].Message)
+}
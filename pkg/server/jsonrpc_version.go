@@ -0,0 +1,34 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+)
+
+// JSON-RPC version strictness modes, see config.JSONRPCStrictness.
+const (
+	JSONRPCStrict  = "strict"  // Only "2.0" is accepted, as the specification requires
+	JSONRPCLenient = "lenient" // A missing version or a variant of 2.0 is accepted too
+)
+
+// acceptsJSONRPCVersion reports whether a request's jsonrpc field is accepted. In lenient mode a
+// missing field and variants such as "2", "2.0.0" or " 2.0 " pass; other major versions never do.
+// Responses always carry "2.0".
+func acceptsJSONRPCVersion(version string, cfg *config.Config) bool {
+	if version == "2.0" {
+		return true
+	}
+	if cfg == nil || cfg.JSONRPCStrictness != JSONRPCLenient {
+		return false
+	}
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return true
+	}
+	major, minor, _ := strings.Cut(version, ".")
+	if major != "2" {
+		return false
+	}
+	return strings.Trim(minor, "0.") == ""
+}
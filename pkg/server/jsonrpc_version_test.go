@@ -0,0 +1,56 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dispatchWithVersion sends a ping with the given jsonrpc field on a ready connection.
+func dispatchWithVersion(t *testing.T, version string, cfg *config.Config) jsonRPCResponse {
+	t.Helper()
+	connID := "test-jsonrpc-version-conn"
+	conn, _ := setupTestConnection(connID)
+	t.Cleanup(func() { cleanupTestConnection(connID) })
+	mcpConnectionManager.UpdateState(conn.ID, StateReady)
+
+	req := &jsonRPCRequest{Jsonrpc: version, ID: 1, Method: "ping", Params: json.RawMessage(`{}`)}
+	resp, respond := dispatchJSONRPC(conn, conn.ID, req, createTestToolSetForCall(), cfg)
+	require.True(t, respond)
+	return resp
+}
+
+func TestDispatchJSONRPC_StrictVersion(t *testing.T) {
+	for _, cfg := range []*config.Config{{}, {JSONRPCStrictness: JSONRPCStrict}} {
+		resp := dispatchWithVersion(t, "", cfg)
+		require.NotNil(t, resp.Error, "a missing version is rejected")
+		assert.Equal(t, -32600, resp.Error.Code)
+		assert.Equal(t, "2.0", resp.Jsonrpc)
+
+		resp = dispatchWithVersion(t, "2.0", cfg)
+		assert.Nil(t, resp.Error)
+	}
+}
+
+func TestDispatchJSONRPC_LenientVersion(t *testing.T) {
+	cfg := &config.Config{JSONRPCStrictness: JSONRPCLenient}
+
+	resp := dispatchWithVersion(t, "", cfg)
+	assert.Nil(t, resp.Error, "a missing version is accepted")
+	assert.Equal(t, "2.0", resp.Jsonrpc, "the response is normalized to 2.0")
+
+	for _, version := range []string{"2", "2.0.0", " 2.0 "} {
+		resp = dispatchWithVersion(t, version, cfg)
+		assert.Nil(t, resp.Error, "version %q is accepted", version)
+		assert.Equal(t, "2.0", resp.Jsonrpc)
+	}
+
+	for _, version := range []string{"1.0", "3.0", "2.1", "two"} {
+		resp = dispatchWithVersion(t, version, cfg)
+		require.NotNil(t, resp.Error, "version %q is rejected", version)
+		assert.Equal(t, -32600, resp.Error.Code)
+	}
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// defaultMaxRequestBytes caps the size of a single inbound JSON-RPC message when not configured.
+const defaultMaxRequestBytes = 4 << 20
+
+// defaultMaxResponseBytes caps the size of an upstream response body when not configured.
+const defaultMaxResponseBytes = 10 << 20
+
+// maxRequestBytes resolves the inbound message size limit from config, applying the default.
+func maxRequestBytes(cfg *config.Config) int64 {
+	if cfg != nil && cfg.MaxRequestBytes > 0 {
+		return cfg.MaxRequestBytes
+	}
+	return defaultMaxRequestBytes
+}
+
+// createRequestTooLargeError builds the JSON-RPC error returned for messages over the size limit.
+func createRequestTooLargeError(id interface{}, limit int64) jsonRPCResponse {
+	return createJSONRPCError(id, -32600, fmt.Sprintf("Invalid Request: message exceeds the maximum size of %d bytes", limit), map[string]interface{}{
+		"maxBytes": limit,
+	})
+}
+
+// effectiveMaxResponseBytes resolves the response size limit for a tool: a per-tool config
+// override first, then the operation's x-mcp-max-response-bytes, then the configured default.
+func effectiveMaxResponseBytes(toolName string, operation mcp.OperationDetail, cfg *config.Config) int64 {
+	if cfg != nil {
+		if limit, ok := cfg.OperationMaxResponseBytes[toolName]; ok && limit > 0 {
+			return limit
+		}
+	}
+	if operation.MaxResponse > 0 {
+		return operation.MaxResponse
+	}
+	if cfg != nil && cfg.MaxResponseBytes > 0 {
+		return cfg.MaxResponseBytes
+	}
+	return defaultMaxResponseBytes
+}
+
+// errResponseTooLarge reports an upstream response body over the tool's size limit.
+type errResponseTooLarge struct {
+	limit int64
+}
+
+func (e *errResponseTooLarge) Error() string {
+	return fmt.Sprintf("response exceeds the maximum size of %d bytes", e.limit)
+}
+
+// readLimitedBody reads body up to limit bytes. It stops reading as soon as the limit is passed,
+// so an oversized response is never buffered in full.
+func readLimitedBody(body io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, &errResponseTooLarge{limit: limit}
+	}
+	return data, nil
+}
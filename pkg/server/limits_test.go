@@ -0,0 +1,150 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPost_OversizedPayloadRejected(t *testing.T) {
+	var upstreamCalls int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&upstreamCalls, 1)
+		w.Write([]byte(`{}`))
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"create_note": {Method: "POST", Path: "/notes", BaseURL: backend.URL},
+	}}
+	cfg := &config.Config{MaxRequestBytes: 1024, DisableInputValidation: true}
+
+	connID := uuid.NewString()
+	setupTestConnection(connID)
+	defer cleanupTestConnection(connID)
+	mcpConnectionManager.UpdateState(connID, StateReady)
+
+	srv := httptest.NewServer(newMCPMux(toolSet, cfg))
+	defer srv.Close()
+
+	post := func(body string) jsonRPCResponse {
+		httpReq, err := http.NewRequest(http.MethodPost, srv.URL+"/messages", strings.NewReader(body))
+		require.NoError(t, err)
+		httpReq.Header.Set("Mcp-Session-Id", connID)
+		httpResp, err := http.DefaultClient.Do(httpReq)
+		require.NoError(t, err)
+		defer httpResp.Body.Close()
+		data, err := io.ReadAll(httpResp.Body)
+		require.NoError(t, err)
+
+		var resp jsonRPCResponse
+		require.NoError(t, json.Unmarshal(data, &resp), string(data))
+		return resp
+	}
+
+	oversized := `{"jsonrpc": "2.0", "method": "tools/call", "id": "big", "params": {"name": "create_note", "arguments": {"text": "` + strings.Repeat("x", 4096) + `"}}}`
+	resp := post(oversized)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, -32600, resp.Error.Code)
+	assert.Contains(t, resp.Error.Message, "exceeds the maximum size of 1024 bytes")
+	assert.Equal(t, float64(1024), resp.Error.Data.(map[string]interface{})["maxBytes"])
+	assert.Equal(t, int32(0), atomic.LoadInt32(&upstreamCalls), "oversized call must not reach the upstream")
+
+	// A call under the limit still goes through
+	resp = post(`{"jsonrpc": "2.0", "method": "tools/call", "id": "small", "params": {"name": "create_note", "arguments": {"text": "hi"}}}`)
+	assert.Nil(t, resp.Error)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&upstreamCalls))
+}
+
+func TestDispatch_RejectsOversizedMessage(t *testing.T) {
+	connID := uuid.NewString()
+	conn, _ := setupTestConnection(connID)
+	defer cleanupTestConnection(connID)
+	mcpConnectionManager.UpdateState(connID, StateReady)
+
+	req := &jsonRPCRequest{Jsonrpc: "2.0", Method: "tools/list", ID: 9, size: 2048}
+	resp, respond := dispatchJSONRPC(conn, connID, req, &mcp.ToolSet{}, &config.Config{MaxRequestBytes: 1024})
+	require.True(t, respond)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, -32600, resp.Error.Code)
+	assert.Equal(t, 9, resp.ID)
+}
+
+func TestCallToolUpstream_MaxResponseBytes(t *testing.T) {
+	body := strings.Repeat("x", 1000)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(backend.Close)
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"get_report": {Method: "GET", Path: "/report", BaseURL: backend.URL},
+		"get_export": {Method: "GET", Path: "/export", BaseURL: backend.URL, MaxResponse: 500},
+	}}
+	call := func(toolName string, cfg *config.Config) ToolResultPayload {
+		params := &ToolCallParams{ToolName: toolName, Input: map[string]interface{}{}}
+		return callToolUpstream("conn", &jsonRPCRequest{ID: 1}, params, toolSet, cfg)
+	}
+
+	t.Run("Per-tool override above the global limit", func(t *testing.T) {
+		cfg := &config.Config{MaxResponseBytes: 100, OperationMaxResponseBytes: map[string]int64{"get_report": 1000}}
+		result := call("get_report", cfg)
+		assert.False(t, result.IsError)
+		require.Len(t, result.Content, 1)
+		assert.Equal(t, body, result.Content[0].Text)
+	})
+
+	t.Run("Per-tool override below the response size", func(t *testing.T) {
+		cfg := &config.Config{OperationMaxResponseBytes: map[string]int64{"get_report": 999}}
+		result := call("get_report", cfg)
+		assert.True(t, result.IsError)
+		require.Len(t, result.Content, 1)
+		assert.Equal(t, "Tool 'get_report' response exceeded the maximum size of 999 bytes", result.Content[0].Text)
+	})
+
+	t.Run("x-mcp-max-response-bytes applies over the global limit", func(t *testing.T) {
+		result := call("get_export", &config.Config{MaxResponseBytes: 2000})
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].Text, "maximum size of 500 bytes")
+
+		result = call("get_export", &config.Config{OperationMaxResponseBytes: map[string]int64{"get_export": 2000}})
+		assert.False(t, result.IsError, "the config override wins over the extension")
+	})
+
+	t.Run("Global limit", func(t *testing.T) {
+		assert.True(t, call("get_report", &config.Config{MaxResponseBytes: 10}).IsError)
+		assert.False(t, call("get_report", &config.Config{}).IsError, "the default limit is well above 1000 bytes")
+	})
+}
+
+func TestReadLimitedBody(t *testing.T) {
+	data, err := readLimitedBody(strings.NewReader("12345"), 5)
+	require.NoError(t, err)
+	assert.Equal(t, "12345", string(data))
+
+	// An endless body is cut off just past the limit
+	_, err = readLimitedBody(io.MultiReader(strings.NewReader("123456"), neverEndingReader{}), 5)
+	var tooLarge *errResponseTooLarge
+	require.ErrorAs(t, err, &tooLarge)
+	assert.Equal(t, int64(5), tooLarge.limit)
+}
+
+// neverEndingReader yields zero bytes forever.
+type neverEndingReader struct{}
+
+func (neverEndingReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCertificate is a generated certificate with its PEM encodings.
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// issueTestCertificate creates a certificate signed by parent, or self-signed when parent is nil.
+func issueTestCertificate(t *testing.T, commonName string, isCA bool, parent *testCertificate) *testCertificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	signerCert, signerKey := template, key
+	if parent != nil {
+		signerCert, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return &testCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// writeTestFile writes content to a file in dir and returns its path.
+func writeTestFile(t *testing.T, dir, name string, content []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+	return path
+}
+
+// freePort returns a TCP port that was free a moment ago.
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestServeMCP_ChosenPort(t *testing.T) {
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(freePort(t)))
+	go ServeMCP(addr, &mcp.ToolSet{}, &config.Config{})
+
+	var resp *http.Response
+	var err error
+	require.Eventually(t, func() bool {
+		resp, err = http.Get("http://" + addr + "/admin/connections")
+		return err == nil
+	}, 2*time.Second, 20*time.Millisecond, "server should come up on %s", addr)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// A second server on the same address fails at startup with a clear error
+	err = ServeMCP(addr, &mcp.ToolSet{}, &config.Config{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot listen on "+addr)
+}
+
+func TestListenMCP_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := issueTestCertificate(t, "test-ca", true, nil)
+	serverCert := issueTestCertificate(t, "server", false, ca)
+	clientCert := issueTestCertificate(t, "client", false, ca)
+
+	cfg := &config.Config{
+		TLSCertFile:     writeTestFile(t, dir, "server.pem", serverCert.certPEM),
+		TLSKeyFile:      writeTestFile(t, dir, "server-key.pem", serverCert.keyPEM),
+		TLSClientCAFile: writeTestFile(t, dir, "ca.pem", ca.certPEM),
+		TLSMinVersion:   "1.2",
+	}
+	listener, err := listenMCP("127.0.0.1:0", cfg)
+	require.NoError(t, err)
+	server := &http.Server{Handler: newMCPMux(&mcp.ToolSet{}, cfg)}
+	go server.Serve(listener)
+	defer server.Close()
+	url := "https://" + listener.Addr().String() + "/admin/connections"
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	t.Run("Client without a certificate is rejected", func(t *testing.T) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+		_, err := client.Get(url)
+		assert.Error(t, err)
+	})
+
+	t.Run("Client with a CA-signed certificate is accepted", func(t *testing.T) {
+		pair, err := tls.X509KeyPair(clientCert.certPEM, clientCert.keyPEM)
+		require.NoError(t, err)
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{pair}}}}
+		resp, err := client.Get(url)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
+
+func TestBuildTLSConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		wantErr string
+	}{
+		{name: "Key without certificate", cfg: config.Config{TLSKeyFile: "key.pem"}, wantErr: "both a certificate and a key"},
+		{name: "Client CA without TLS", cfg: config.Config{TLSClientCAFile: "ca.pem"}, wantErr: "requires TLS"},
+		{name: "Missing certificate file", cfg: config.Config{TLSCertFile: "missing.pem", TLSKeyFile: "missing-key.pem"}, wantErr: "cannot load TLS certificate"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := buildTLSConfig(&tc.cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+
+	tlsConfig, err := buildTLSConfig(&config.Config{})
+	assert.NoError(t, err)
+	assert.Nil(t, tlsConfig, "no TLS unless configured")
+
+	_, err = parseTLSVersion("1.1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"log"
+	"regexp"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+)
+
+// localeMetaKey is the tools/call _meta entry carrying the caller's locale, e.g. "de-CH".
+const localeMetaKey = "locale"
+
+// languageRangePattern matches an Accept-Language value: language ranges such as "en-US" or "*",
+// optionally weighted ("fr;q=0.8") and comma-separated. It keeps header injection out.
+var languageRangePattern = regexp.MustCompile(`^[A-Za-z0-9*]{1,8}(-[A-Za-z0-9]{1,8})*(;\s*q=[01](\.[0-9]{0,3})?)?(\s*,\s*[A-Za-z0-9*]{1,8}(-[A-Za-z0-9]{1,8})*(;\s*q=[01](\.[0-9]{0,3})?)?)*$`)
+
+// acceptLanguage returns the Accept-Language header for an upstream request: the locale in the
+// call's _meta when LocaleFromMeta is on, else the configured AcceptLanguage. "" sends none.
+func acceptLanguage(params *ToolCallParams, cfg *config.Config) string {
+	if cfg.LocaleFromMeta {
+		if locale, ok := params.Meta[localeMetaKey].(string); ok && locale != "" {
+			if languageRangePattern.MatchString(locale) {
+				return locale
+			}
+			log.Printf("[ExecuteToolCall] Ignoring invalid _meta.%s %q for tool '%s'", localeMetaKey, locale, params.ToolName)
+		}
+	}
+	return cfg.AcceptLanguage
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// callWithLocale calls a tool against a backend echoing the Accept-Language it received.
+func callWithLocale(t *testing.T, meta map[string]interface{}, cfg *config.Config) string {
+	t.Helper()
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(r.Header.Get("Accept-Language")))
+	}))
+	t.Cleanup(backend.Close)
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"get_greeting": {Method: "GET", Path: "/greeting", BaseURL: backend.URL},
+	}}
+	params := &ToolCallParams{ToolName: "get_greeting", Input: map[string]interface{}{}, Meta: meta}
+	result := callToolUpstream("conn", &jsonRPCRequest{ID: 1}, params, toolSet, cfg)
+	require.False(t, result.IsError)
+	require.Len(t, result.Content, 1)
+	return result.Content[0].Text
+}
+
+func TestExecuteToolCall_AcceptLanguage(t *testing.T) {
+	assert.Equal(t, "", callWithLocale(t, nil, &config.Config{}), "no Accept-Language unless configured")
+	assert.Equal(t, "de, en;q=0.5", callWithLocale(t, nil, &config.Config{AcceptLanguage: "de, en;q=0.5"}))
+}
+
+func TestExecuteToolCall_LocaleFromMeta(t *testing.T) {
+	cfg := &config.Config{AcceptLanguage: "en-US", LocaleFromMeta: true}
+	assert.Equal(t, "fr-CA", callWithLocale(t, map[string]interface{}{"locale": "fr-CA"}, cfg), "the per-call locale takes precedence")
+	assert.Equal(t, "en-US", callWithLocale(t, nil, cfg), "falls back to the configured default")
+	assert.Equal(t, "en-US", callWithLocale(t, map[string]interface{}{"locale": "fr\r\nX-Injected: 1"}, cfg), "invalid locales are ignored")
+	assert.Equal(t, "en-US", callWithLocale(t, map[string]interface{}{"locale": 42}, cfg))
+
+	cfg.LocaleFromMeta = false
+	assert.Equal(t, "en-US", callWithLocale(t, map[string]interface{}{"locale": "fr-CA"}, cfg), "_meta is ignored unless enabled")
+}
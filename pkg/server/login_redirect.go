@@ -0,0 +1,163 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// errLoginRedirect reports that the upstream redirected a call to its login page, as session-auth
+// backends do for an expired session instead of answering 401.
+type errLoginRedirect struct {
+	location string
+}
+
+func (e *errLoginRedirect) Error() string {
+	return fmt.Sprintf("session expired: the upstream redirected to its login page (%s); re-authenticate and retry", e.location)
+}
+
+// loginPatterns caches compiled LoginRedirectPattern values; main rejects invalid ones at startup.
+var loginPatterns sync.Map
+
+// isLoginPage reports whether a redirect target matches the configured login page pattern.
+func isLoginPage(target string, cfg *config.Config) bool {
+	if cfg == nil || cfg.LoginRedirectPattern == "" {
+		return false
+	}
+	compiled, ok := loginPatterns.Load(cfg.LoginRedirectPattern)
+	if !ok {
+		pattern, err := regexp.Compile(cfg.LoginRedirectPattern)
+		if err != nil {
+			log.Printf("[ExecuteToolCall] Warning: invalid login redirect pattern %q: %v", cfg.LoginRedirectPattern, err)
+			return false
+		}
+		compiled, _ = loginPatterns.LoadOrStore(cfg.LoginRedirectPattern, pattern)
+	}
+	return compiled.(*regexp.Regexp).MatchString(target)
+}
+
+// redirectPolicy stops at redirects to the login page instead of following them, so the call
+// fails as an expired session rather than succeeding with the login page's HTML. The login
+// operation itself never follows redirects, so the session cookies set along with one are kept.
+func redirectPolicy(params *ToolCallParams, cfg *config.Config) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if params.login || isLoginPage(req.URL.String(), cfg) {
+			return http.ErrUseLastResponse
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+}
+
+// loginRedirect returns an errLoginRedirect when resp redirects to the login page.
+func loginRedirect(resp *http.Response, cfg *config.Config) error {
+	if resp.StatusCode < 300 || resp.StatusCode >= 400 {
+		return nil
+	}
+	location, err := resp.Location()
+	if err != nil || !isLoginPage(location.String(), cfg) {
+		return nil
+	}
+	return &errLoginRedirect{location: location.String()}
+}
+
+// loginSession holds the cookies the login operation's last response set, keyed by host. They
+// are sent with every call to that host, replacing cookies of the same name.
+var loginSession = &loginCookies{byHost: make(map[string][]*http.Cookie)}
+
+type loginCookies struct {
+	mutex  sync.Mutex
+	byHost map[string][]*http.Cookie
+}
+
+func (l *loginCookies) set(host string, cookies []*http.Cookie) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.byHost[host] = cookies
+}
+
+// addTo adds the host's session cookies to req, replacing cookies of the same name.
+func (l *loginCookies) addTo(req *http.Request) {
+	l.mutex.Lock()
+	cookies := l.byHost[req.URL.Host]
+	l.mutex.Unlock()
+	if len(cookies) == 0 {
+		return
+	}
+	replaced := make(map[string]bool, len(cookies))
+	for _, cookie := range cookies {
+		replaced[cookie.Name] = true
+	}
+	kept := req.Cookies()
+	req.Header.Del("Cookie")
+	for _, cookie := range kept {
+		if !replaced[cookie.Name] {
+			req.AddCookie(cookie)
+		}
+	}
+	for _, cookie := range cookies {
+		req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+}
+
+// reloginEnabled reports whether an expired session triggers a call of the login operation.
+func reloginEnabled(toolSet *mcp.ToolSet, cfg *config.Config) bool {
+	if cfg == nil || cfg.LoginOperation == "" {
+		return false
+	}
+	_, ok := toolSet.Operations[cfg.LoginOperation]
+	return ok
+}
+
+// relogin calls the login operation with the configured arguments and keeps the session cookies
+// it sets.
+func relogin(toolSet *mcp.ToolSet, cfg *config.Config) error {
+	log.Printf("[ExecuteToolCall] Session expired; logging in again with '%s'", cfg.LoginOperation)
+	input := make(map[string]interface{}, len(cfg.LoginArguments))
+	for name, value := range cfg.LoginArguments {
+		input[name] = value
+	}
+	resp, err := executeToolCall(&ToolCallParams{ToolName: cfg.LoginOperation, Input: input, login: true}, toolSet, cfg)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("login operation answered %s", resp.Status)
+	}
+	cookies := resp.Cookies()
+	if len(cookies) == 0 {
+		return errors.New("login operation set no session cookies")
+	}
+	loginSession.set(resp.Request.URL.Host, cookies)
+	names := make([]string, 0, len(cookies))
+	for _, cookie := range cookies {
+		names = append(names, cookie.Name)
+	}
+	log.Printf("[ExecuteToolCall] Logged in again; sending cookies %s to %s", strings.Join(names, ", "), resp.Request.URL.Host)
+	return nil
+}
+
+// executeWithRelogin is executeToolCall, logging in again and retrying the call once when the
+// session expired and a login operation is configured.
+func executeWithRelogin(params *ToolCallParams, toolSet *mcp.ToolSet, cfg *config.Config) (*http.Response, error) {
+	resp, err := executeToolCall(params, toolSet, cfg)
+	var expired *errLoginRedirect
+	if !errors.As(err, &expired) || !reloginEnabled(toolSet, cfg) || params.ToolName == cfg.LoginOperation {
+		return resp, err
+	}
+	if loginErr := relogin(toolSet, cfg); loginErr != nil {
+		log.Printf("[ExecuteToolCall] Login with '%s' failed: %v", cfg.LoginOperation, loginErr)
+		return nil, fmt.Errorf("%w; logging in again with '%s' failed: %v", err, cfg.LoginOperation, loginErr)
+	}
+	return executeToolCall(params, toolSet, cfg)
+}
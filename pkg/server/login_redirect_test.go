@@ -0,0 +1,120 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sessionBackend serves /orders only with a valid session cookie, redirecting to /login like a
+// session-auth web app otherwise. POST /session logs in, then redirects to /dashboard.
+type sessionBackend struct {
+	*httptest.Server
+	logins        atomic.Int32
+	loginPageHits atomic.Int32
+}
+
+func newSessionBackend(t *testing.T) *sessionBackend {
+	backend := &sessionBackend{}
+	backend.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/orders":
+			if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "fresh" {
+				http.Redirect(w, r, "/login?next=/orders", http.StatusFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"orders": [1, 2]}`))
+		case "/login":
+			backend.loginPageHits.Add(1)
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><form action="/session">Sign in</form></html>`))
+		case "/session":
+			var credentials map[string]string
+			json.NewDecoder(r.Body).Decode(&credentials)
+			if credentials["username"] != "svc" || credentials["password"] != "s3cret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			backend.logins.Add(1)
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "fresh", Path: "/"})
+			http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(backend.Close)
+	t.Cleanup(func() { loginSession = &loginCookies{byHost: make(map[string][]*http.Cookie)} })
+	return backend
+}
+
+func sessionToolSet(baseURL string) *mcp.ToolSet {
+	return &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"list_orders": {Method: "GET", Path: "/orders", BaseURL: baseURL},
+		"login":       {Method: "POST", Path: "/session", BaseURL: baseURL},
+	}}
+}
+
+func TestCallToolUpstream_LoginRedirectIsAuthError(t *testing.T) {
+	backend := newSessionBackend(t)
+	params := &ToolCallParams{ToolName: "list_orders", Input: map[string]interface{}{}}
+	cfg := &config.Config{LoginRedirectPattern: `/login\b`}
+
+	result := callToolUpstream("conn", &jsonRPCRequest{ID: 1}, params, sessionToolSet(backend.URL), cfg)
+	assert.True(t, result.IsError)
+	require.Len(t, result.Content, 1)
+	assert.Contains(t, result.Content[0].Text, "session expired")
+	assert.Contains(t, result.Content[0].Text, "re-authenticate")
+	assert.Equal(t, ErrorCategoryAuth, result.Meta["category"])
+	assert.Equal(t, false, result.Meta["retryable"])
+	assert.Zero(t, backend.loginPageHits.Load(), "the login page is not followed")
+
+	// Without a pattern the redirect is followed, as before
+	result = callToolUpstream("conn", &jsonRPCRequest{ID: 1}, params, sessionToolSet(backend.URL), &config.Config{})
+	assert.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "Sign in")
+}
+
+func TestCallToolUpstream_LoginRedirectRelogin(t *testing.T) {
+	backend := newSessionBackend(t)
+	toolSet := sessionToolSet(backend.URL)
+	params := &ToolCallParams{ToolName: "list_orders", Input: map[string]interface{}{}}
+	cfg := &config.Config{
+		LoginRedirectPattern: `/login\b`,
+		LoginOperation:       "login",
+		LoginArguments:       map[string]interface{}{"username": "svc", "password": "s3cret"},
+	}
+
+	result := callToolUpstream("conn", &jsonRPCRequest{ID: 1}, params, toolSet, cfg)
+	assert.False(t, result.IsError, "the call is retried after logging in")
+	assert.JSONEq(t, `{"orders": [1, 2]}`, result.Content[0].Text)
+	assert.Equal(t, int32(1), backend.logins.Load())
+
+	// The session cookie is kept for later calls
+	result = callToolUpstream("conn", &jsonRPCRequest{ID: 2}, params, toolSet, cfg)
+	assert.False(t, result.IsError)
+	assert.Equal(t, int32(1), backend.logins.Load())
+}
+
+func TestCallToolUpstream_LoginRedirectReloginFails(t *testing.T) {
+	backend := newSessionBackend(t)
+	params := &ToolCallParams{ToolName: "list_orders", Input: map[string]interface{}{}}
+	cfg := &config.Config{
+		LoginRedirectPattern: `/login\b`,
+		LoginOperation:       "login",
+		LoginArguments:       map[string]interface{}{"username": "svc", "password": "wrong"},
+	}
+
+	result := callToolUpstream("conn", &jsonRPCRequest{ID: 1}, params, sessionToolSet(backend.URL), cfg)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "session expired")
+	assert.Contains(t, result.Content[0].Text, "logging in again with 'login' failed: login operation answered 401")
+	assert.Equal(t, ErrorCategoryAuth, result.Meta["category"])
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// HTTP method classes limited by config.ReadRateLimit and config.WriteRateLimit.
+const (
+	methodClassRead  = "read"  // GET, HEAD and OPTIONS
+	methodClassWrite = "write" // Everything else
+)
+
+// tokenBucket allows rate calls per second on average and burst at once.
+type tokenBucket struct {
+	rate   float64
+	burst  int
+	tokens float64
+	last   time.Time
+}
+
+// take spends a token if one is available at now. If not, it returns how long until one is.
+func (b *tokenBucket) take(now time.Time) (bool, time.Duration) {
+	b.tokens = math.Min(float64(b.burst), b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// methodRateLimiter holds one token bucket per limited method class, shared by every connection.
+type methodRateLimiter struct {
+	mutex   sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+var methodRateLimits = &methodRateLimiter{buckets: make(map[string]*tokenBucket)}
+
+// methodClass returns the rate limit class of an HTTP method.
+func methodClass(method string) string {
+	if config.IsReadOnlyMethod(method) {
+		return methodClassRead
+	}
+	return methodClassWrite
+}
+
+// methodClassRate returns the configured rate and burst of a method class. A zero burst uses the
+// rate, at least 1.
+func methodClassRate(class string, cfg *config.Config) (float64, int) {
+	if cfg == nil {
+		return 0, 0
+	}
+	rate, burst := cfg.WriteRateLimit, cfg.WriteRateBurst
+	if class == methodClassRead {
+		rate, burst = cfg.ReadRateLimit, cfg.ReadRateBurst
+	}
+	if rate > 0 && burst <= 0 {
+		burst = int(math.Max(1, math.Ceil(rate)))
+	}
+	return rate, burst
+}
+
+// allow spends a token of the class's bucket, replacing the bucket when the rate changed. It
+// returns how long until a token is available when none is.
+func (l *methodRateLimiter) allow(class string, rate float64, burst int, now time.Time) (bool, time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	bucket, ok := l.buckets[class]
+	if !ok || bucket.rate != rate || bucket.burst != burst {
+		bucket = &tokenBucket{rate: rate, burst: burst, tokens: float64(burst), last: now}
+		l.buckets[class] = bucket
+	}
+	return bucket.take(now)
+}
+
+// checkMethodRateLimit refuses a call of a generated tool whose method class is over its
+// server-wide rate, returning the rate-limit error to send instead. Custom tools aren't limited.
+func checkMethodRateLimit(req *jsonRPCRequest, toolName string, toolSet *mcp.ToolSet, cfg *config.Config) (ToolResultPayload, bool) {
+	operation, ok := toolSet.Operations[toolName]
+	if !ok {
+		return ToolResultPayload{}, false
+	}
+	class := methodClass(operation.Method)
+	rate, burst := methodClassRate(class, cfg)
+	if rate <= 0 {
+		return ToolResultPayload{}, false
+	}
+	allowed, wait := methodRateLimits.allow(class, rate, burst, time.Now())
+	if allowed {
+		return ToolResultPayload{}, false
+	}
+
+	retryAfter := int(math.Ceil(wait.Seconds()))
+	message := fmt.Sprintf("Tool '%s' was rate limited: %s calls are limited to %g per second across the server; retry after %s", toolName, class, rate, wait.Round(time.Millisecond))
+	log.Printf("[RateLimit] %s", message)
+	return withFailureCategory(ToolResultPayload{
+		IsError: true,
+		Content: []ToolResultContent{{Type: "text", Text: message}},
+		Error: &MCPError{
+			Code:    http.StatusTooManyRequests,
+			Message: message,
+			Data:    map[string]interface{}{"retryAfterSeconds": retryAfter, "methodClass": class},
+		},
+		ToolCallID: fmt.Sprintf("%v", req.ID),
+	}, failureCategory{ErrorCategoryRateLimited, true}), true
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resetMethodRateLimits gives the test fresh buckets, so earlier calls don't count against it.
+func resetMethodRateLimits(t *testing.T) {
+	methodRateLimits = &methodRateLimiter{buckets: make(map[string]*tokenBucket)}
+	t.Cleanup(func() { methodRateLimits = &methodRateLimiter{buckets: make(map[string]*tokenBucket)} })
+}
+
+func TestTokenBucket(t *testing.T) {
+	start := time.Now()
+	bucket := &tokenBucket{rate: 2, burst: 2, tokens: 2, last: start}
+	ok, _ := bucket.take(start)
+	assert.True(t, ok)
+	ok, _ = bucket.take(start)
+	assert.True(t, ok)
+	ok, wait := bucket.take(start)
+	assert.False(t, ok, "burst spent")
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	ok, _ = bucket.take(start.Add(500 * time.Millisecond))
+	assert.True(t, ok, "refilled at the rate")
+	ok, _ = bucket.take(start.Add(time.Hour))
+	assert.True(t, ok)
+	assert.Equal(t, float64(1), bucket.tokens, "refills no further than the burst")
+}
+
+func TestMethodClassRate(t *testing.T) {
+	cfg := &config.Config{ReadRateLimit: 0.5, WriteRateLimit: 5, WriteRateBurst: 20}
+	rate, burst := methodClassRate(methodClass("GET"), cfg)
+	assert.Equal(t, 0.5, rate)
+	assert.Equal(t, 1, burst, "a zero burst uses the rate, at least 1")
+	rate, burst = methodClassRate(methodClass("delete"), cfg)
+	assert.Equal(t, float64(5), rate)
+	assert.Equal(t, 20, burst)
+	rate, _ = methodClassRate(methodClassRead, nil)
+	assert.Zero(t, rate, "unlimited without a config")
+}
+
+func TestInvokeTool_WriteRateLimit(t *testing.T) {
+	resetMethodRateLimits(t)
+	var served atomic.Int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served.Add(1)
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"list_orders":  {Method: "GET", Path: "/orders", BaseURL: backend.URL},
+		"create_order": {Method: "POST", Path: "/orders", BaseURL: backend.URL},
+		"delete_order": {Method: "DELETE", Path: "/orders", BaseURL: backend.URL},
+	}}
+	cfg := &config.Config{WriteRateLimit: 1, WriteRateBurst: 3}
+	call := func(toolName string) ToolResultPayload {
+		params := &ToolCallParams{ToolName: toolName, Input: map[string]interface{}{}}
+		result, err := invokeTool(context.Background(), "conn", &jsonRPCRequest{ID: 1}, params, toolSet, cfg)
+		require.NoError(t, err)
+		return result.(ToolResultPayload)
+	}
+
+	var writes, throttled, reads int
+	for i := 0; i < 10; i++ {
+		for _, toolName := range []string{"create_order", "delete_order"} {
+			if result := call(toolName); result.IsError {
+				throttled++
+				require.NotNil(t, result.Error)
+				assert.Equal(t, http.StatusTooManyRequests, result.Error.Code)
+				assert.Contains(t, result.Content[0].Text, "rate limited")
+				data := result.Error.Data.(map[string]interface{})
+				assert.Equal(t, methodClassWrite, data["methodClass"])
+				assert.Equal(t, 1, data["retryAfterSeconds"])
+			} else {
+				writes++
+			}
+		}
+		if result := call("list_orders"); !result.IsError {
+			reads++
+		}
+	}
+
+	assert.Equal(t, 3, writes, "writes across tools share the burst")
+	assert.Equal(t, 17, throttled)
+	assert.Equal(t, 10, reads, "reads aren't limited")
+	assert.Equal(t, int32(13), served.Load(), "throttled calls never reach the upstream")
+}
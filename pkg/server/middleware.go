@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// ToolCall is a tool invocation passed through middleware, see mcp.ToolCall.
+type ToolCall = mcp.ToolCall
+
+// Handler serves a tool call in the middleware chain. The last one runs the tool: a built-in, a
+// registered tool, or the upstream API call, whose result is a ToolResultPayload.
+type Handler = mcp.ToolCallHandler
+
+// Middleware wraps the rest of the chain, see mcp.ToolMiddleware.
+type Middleware = mcp.ToolMiddleware
+
+// Use adds middleware around every tool call. Middleware runs in the order it was added, so the
+// first is outermost. Arguments reaching middleware have passed input validation; changed
+// arguments are not validated again. A returned error is sent to the client as a tool error, and
+// other results are rendered like RegisterTool results.
+func (s *Server) Use(middleware ...Middleware) {
+	filtered := make([]Middleware, 0, len(middleware))
+	for _, m := range middleware {
+		if m == nil {
+			log.Printf("[Middleware] Ignoring nil middleware")
+			continue
+		}
+		filtered = append(filtered, m)
+	}
+	s.tools.update(func(current *mcp.ToolSet) (*mcp.ToolSet, error) {
+		next := cloneToolSet(current)
+		next.Middleware = append(next.Middleware, filtered...)
+		return next, nil
+	})
+}
+
+// chainToolCall wraps final in middleware, the first element outermost.
+func chainToolCall(middleware []Middleware, final Handler) Handler {
+	handler := final
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
+// toolCallResult renders what a tool call's handler chain returned: an error becomes a tool error,
+// a string text content, a ToolResultPayload is used as is, and anything else is sent as JSON text
+// with the value as structured content.
+func toolCallResult(toolName string, req *jsonRPCRequest, result interface{}, err error) ToolResultPayload {
+	toolCallID := fmt.Sprintf("%v", req.ID)
+	if err != nil {
+		log.Printf("[ExecuteToolCall] Tool '%s' failed: %v", toolName, err)
+		message := fmt.Sprintf("Tool '%s' failed: %v", toolName, err)
+		return ToolResultPayload{
+			IsError:    true,
+			Content:    []ToolResultContent{{Type: "text", Text: message}},
+			Error:      &MCPError{Message: message},
+			ToolCallID: toolCallID,
+		}
+	}
+
+	switch v := result.(type) {
+	case ToolResultPayload:
+		v.ToolCallID = toolCallID
+		return v
+	case *ToolResultPayload:
+		if v != nil {
+			payload := *v
+			payload.ToolCallID = toolCallID
+			return payload
+		}
+	case string:
+		return ToolResultPayload{Content: []ToolResultContent{{Type: "text", Text: v}}, ToolCallID: toolCallID}
+	}
+
+	encoded, marshalErr := json.Marshal(result)
+	if marshalErr != nil {
+		return toolCallResult(toolName, req, nil, fmt.Errorf("result cannot be encoded as JSON: %w", marshalErr))
+	}
+	return ToolResultPayload{
+		Content:           []ToolResultContent{{Type: "text", Text: string(encoded)}},
+		StructuredContent: result,
+		ToolCallID:        toolCallID,
+	}
+}
+
+// rpcErrorResult carries a JSON-RPC error response out of the chain, for the rare built-in
+// failure that is not a tool error.
+type rpcErrorResult struct {
+	resp jsonRPCResponse
+}
+
+func (e *rpcErrorResult) Error() string {
+	return e.resp.Error.Message
+}
+
+// invokeTool is the innermost handler: it runs a built-in or registered tool locally, or calls the
+// upstream API, once the tool's concurrency limit allows.
+func invokeTool(ctx context.Context, connID string, req *jsonRPCRequest, params *ToolCallParams, toolSet *mcp.ToolSet, cfg *config.Config) (interface{}, error) {
+	if builtinResp, handled := handleBuiltinToolCall(req, params, toolSet, cfg); handled {
+		if builtinResp.Error != nil {
+			return nil, &rpcErrorResult{resp: builtinResp}
+		}
+		return builtinResp.Result, nil
+	}
+	if limited, ok := checkMethodRateLimit(req, params.ToolName, toolSet, cfg); ok {
+		return limited, nil
+	}
+	release, err := acquireToolSlot(ctx, params.ToolName, toolSet, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if handler, ok := toolSet.CustomHandlers[params.ToolName]; ok {
+		return callCustomTool(ctx, connID, params, handler, cfg)
+	}
+
+	if operation, ok := toolSet.Operations[params.ToolName]; ok && operation.Confirm {
+		auditConfirmedCall(connID, params.ToolName, operation)
+	}
+	log.Printf("Executing tool '%s' for %s with input: %+v", params.ToolName, connID, params.Input)
+	if usesIdempotencyKey(params.ToolName, toolSet, cfg) {
+		return deduplicateToolCall(connID, req, params, cfg, func(key string) ToolResultPayload {
+			params.idempotencyKey = key
+			return callToolUpstream(connID, req, params, toolSet, cfg)
+		}), nil
+	}
+	return callToolUpstream(connID, req, params, toolSet, cfg), nil
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// denyTool is a middleware rejecting calls to one tool without running it.
+func denyTool(name string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *ToolCall) (interface{}, error) {
+			if call.Name == name {
+				return nil, errors.New("access denied")
+			}
+			return next(ctx, call)
+		}
+	}
+}
+
+// setArgument is a middleware overriding one argument on every call.
+func setArgument(name string, value interface{}) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *ToolCall) (interface{}, error) {
+			arguments := map[string]interface{}{name: value}
+			for k, v := range call.Arguments {
+				if k != name {
+					arguments[k] = v
+				}
+			}
+			call.Arguments = arguments
+			return next(ctx, call)
+		}
+	}
+}
+
+func TestServer_Use(t *testing.T) {
+	var upstreamCalls int
+	var userID string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamCalls++
+		userID = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer backend.Close()
+
+	toolSet := createTestToolSetForCall()
+	for name, op := range toolSet.Operations {
+		op.BaseURL = backend.URL
+		toolSet.Operations[name] = op
+	}
+	s := NewServer(toolSet, &config.Config{})
+	s.Use(denyTool("post_data"), setArgument("user_id", "42"))
+
+	resp := dispatchToReadyConnection(t, s, "tools/call", map[string]interface{}{
+		"name":      "post_data",
+		"arguments": map[string]interface{}{"data": "x"},
+	})
+	require.Nil(t, resp.Error)
+	result := resp.Result.(ToolResultPayload)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "Tool 'post_data' failed: access denied", result.Content[0].Text)
+	assert.Zero(t, upstreamCalls, "a denied call never reaches the API")
+
+	resp = dispatchToReadyConnection(t, s, "tools/call", map[string]interface{}{
+		"name":      "get_user",
+		"arguments": map[string]interface{}{"user_id": "7"},
+	})
+	require.Nil(t, resp.Error)
+	result = resp.Result.(ToolResultPayload)
+	assert.False(t, result.IsError)
+	assert.Equal(t, 1, upstreamCalls)
+	assert.Equal(t, "/users/42", userID, "the mutated argument is sent upstream")
+}
+
+func TestServer_UseObservesResults(t *testing.T) {
+	s := NewServer(&mcp.ToolSet{}, &config.Config{})
+	require.NoError(t, s.RegisterTool("echo", "", nil, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return args["text"], nil
+	}))
+
+	var order []string
+	var observed interface{}
+	s.Use(func(next Handler) Handler {
+		return func(ctx context.Context, call *ToolCall) (interface{}, error) {
+			order = append(order, "outer")
+			result, err := next(ctx, call)
+			observed = result
+			return map[string]interface{}{"wrapped": result}, err
+		}
+	}, nil, func(next Handler) Handler {
+		return func(ctx context.Context, call *ToolCall) (interface{}, error) {
+			order = append(order, "inner")
+			assert.Equal(t, "test-custom-tool-conn", call.ConnectionID)
+			return next(ctx, call)
+		}
+	})
+
+	resp := dispatchToReadyConnection(t, s, "tools/call", map[string]interface{}{
+		"name":      "echo",
+		"arguments": map[string]interface{}{"text": "hi"},
+	})
+	require.Nil(t, resp.Error)
+	result := resp.Result.(ToolResultPayload)
+	assert.Equal(t, []string{"outer", "inner"}, order, "middleware added first runs outermost")
+	assert.Equal(t, "hi", observed)
+	assert.JSONEq(t, `{"wrapped": "hi"}`, result.Content[0].Text, "middleware can replace the result")
+}
+
+func TestToolCallResult_Payload(t *testing.T) {
+	payload := ToolResultPayload{Content: []ToolResultContent{{Type: "text", Text: "raw"}}}
+	result := toolCallResult("tool", &jsonRPCRequest{ID: json.Number("3")}, &payload, nil)
+	assert.Equal(t, "3", result.ToolCallID)
+	assert.Equal(t, "raw", result.Content[0].Text)
+}
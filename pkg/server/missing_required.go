@@ -0,0 +1,91 @@
+package server
+
+import (
+	"log"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// What happens to a tool call that leaves out a required argument, see config.MissingRequired.
+const (
+	MissingRequiredReject      = "reject"      // Fail validation (the default)
+	MissingRequiredDefault     = "default"     // Substitute the configured value, see config.MissingRequiredDefaults
+	MissingRequiredPassthrough = "passthrough" // Call the upstream without it and let it decide
+)
+
+// MissingRequiredAllArguments stands for every required argument of a tool in
+// config.MissingRequired.
+const MissingRequiredAllArguments = "*"
+
+// missingRequiredAction returns the action for a missing required argument: a configured default
+// wins, then the argument's own action, then the tool-wide one.
+func missingRequiredAction(toolName, argument string, cfg *config.Config) (action string, value interface{}) {
+	if cfg == nil {
+		return MissingRequiredReject, nil
+	}
+	if value, ok := cfg.MissingRequiredDefaults[toolName][argument]; ok {
+		return MissingRequiredDefault, value
+	}
+	if action, ok := cfg.MissingRequired[toolName][argument]; ok {
+		return action, nil
+	}
+	if action, ok := cfg.MissingRequired[toolName][MissingRequiredAllArguments]; ok {
+		return action, nil
+	}
+	return MissingRequiredReject, nil
+}
+
+// applyMissingRequired handles the required top-level arguments a call leaves out. Configured
+// defaults are filled in, in a copy of input; the arguments to pass through are returned so
+// validation ignores their absence. Anything else is left for validation to reject.
+func applyMissingRequired(toolName string, schema mcp.Schema, input map[string]interface{}, cfg *config.Config) (map[string]interface{}, map[string]bool) {
+	if cfg == nil || (len(cfg.MissingRequired[toolName]) == 0 && len(cfg.MissingRequiredDefaults[toolName]) == 0) {
+		return input, nil
+	}
+	var passthrough map[string]bool
+	filled, copied := input, false
+	for _, name := range schema.Required {
+		if _, ok := input[name]; ok {
+			continue
+		}
+		switch action, value := missingRequiredAction(toolName, name, cfg); action {
+		case MissingRequiredDefault:
+			if !copied {
+				filled = make(map[string]interface{}, len(input)+1)
+				for key, existing := range input {
+					filled[key] = existing
+				}
+				copied = true
+			}
+			filled[name] = value
+			log.Printf("[ExecuteToolCall] Using the configured default for missing required argument '%s' of tool '%s'", name, toolName)
+		case MissingRequiredPassthrough:
+			if passthrough == nil {
+				passthrough = make(map[string]bool)
+			}
+			passthrough[name] = true
+			log.Printf("[ExecuteToolCall] Passing tool '%s' upstream without required argument '%s'", toolName, name)
+		}
+	}
+	return filled, passthrough
+}
+
+// withoutPassthroughErrors drops the missing-argument errors of arguments passed through.
+func withoutPassthroughErrors(errs []ValidationError, passthrough map[string]bool) []ValidationError {
+	if len(passthrough) == 0 {
+		return errs
+	}
+	skipped := make(map[string]bool, len(passthrough))
+	for name := range passthrough {
+		skipped["/"+escapeJSONPointer(name)] = true
+	}
+	var kept []ValidationError
+	for _, err := range errs {
+		if err.Keyword == "required" && skipped[err.Path] {
+			continue
+		}
+		kept = append(kept, err)
+	}
+	return kept
+}
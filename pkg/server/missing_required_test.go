@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// missingRequiredToolSet has a tool with two required query parameters.
+func missingRequiredToolSet(baseURL string) *mcp.ToolSet {
+	return &mcp.ToolSet{
+		Tools: []mcp.Tool{{
+			Name: "list_orders",
+			InputSchema: mcp.Schema{
+				Type: "object",
+				Properties: map[string]mcp.Schema{
+					"region":   {Type: "string"},
+					"pageSize": {Type: "integer"},
+				},
+				Required: []string{"pageSize", "region"},
+			},
+		}},
+		Operations: map[string]mcp.OperationDetail{
+			"list_orders": {
+				Method:  "GET",
+				Path:    "/orders",
+				BaseURL: baseURL,
+				Parameters: []mcp.ParameterDetail{
+					{Name: "region", In: "query"},
+					{Name: "pageSize", In: "query"},
+				},
+			},
+		},
+	}
+}
+
+func TestToolCall_MissingRequired(t *testing.T) {
+	var query string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	}))
+	defer upstream.Close()
+	toolSet := missingRequiredToolSet(upstream.URL)
+
+	call := func(cfg *config.Config, input map[string]interface{}) jsonRPCResponse {
+		query = ""
+		params, _ := json.Marshal(ToolCallParams{ToolName: "list_orders", Input: input})
+		req := &jsonRPCRequest{Jsonrpc: "2.0", Method: "tools/call", Params: json.RawMessage(params), ID: "missing-1"}
+		return handleToolCallJSONRPC("test-conn", req, toolSet, cfg)
+	}
+
+	t.Run("reject by default", func(t *testing.T) {
+		resp := call(&config.Config{}, map[string]interface{}{"region": "eu"})
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, -32602, resp.Error.Code)
+		errs := resp.Error.Data.([]ValidationError)
+		require.Len(t, errs, 1)
+		assert.Equal(t, "/pageSize", errs[0].Path)
+		assert.Empty(t, query, "the upstream is not called")
+
+		// Explicit reject for one argument, passthrough for the rest
+		cfg := &config.Config{MissingRequired: map[string]map[string]string{"list_orders": {"*": MissingRequiredPassthrough, "pageSize": MissingRequiredReject}}}
+		resp = call(cfg, map[string]interface{}{})
+		require.NotNil(t, resp.Error)
+		errs = resp.Error.Data.([]ValidationError)
+		require.Len(t, errs, 1)
+		assert.Equal(t, "/pageSize", errs[0].Path)
+	})
+
+	t.Run("substitute a default", func(t *testing.T) {
+		cfg := &config.Config{MissingRequiredDefaults: map[string]map[string]interface{}{"list_orders": {"pageSize": json.Number("50")}}}
+		resp := call(cfg, map[string]interface{}{"region": "eu"})
+		require.Nil(t, resp.Error)
+		assert.False(t, resp.Result.(ToolResultPayload).IsError)
+		assert.Equal(t, "pageSize=50&region=eu", query)
+
+		// A client value wins
+		call(cfg, map[string]interface{}{"region": "eu", "pageSize": 10})
+		assert.Equal(t, "pageSize=10&region=eu", query)
+
+		// The default is validated like a client value
+		bad := &config.Config{MissingRequiredDefaults: map[string]map[string]interface{}{"list_orders": {"pageSize": "lots"}}}
+		resp = call(bad, map[string]interface{}{"region": "eu"})
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, "type", resp.Error.Data.([]ValidationError)[0].Keyword)
+	})
+
+	t.Run("pass through", func(t *testing.T) {
+		cfg := &config.Config{MissingRequired: map[string]map[string]string{"list_orders": {"pageSize": MissingRequiredPassthrough}}}
+		resp := call(cfg, map[string]interface{}{"region": "eu"})
+		require.Nil(t, resp.Error)
+		assert.False(t, resp.Result.(ToolResultPayload).IsError)
+		assert.Equal(t, "region=eu", query, "the upstream decides without it")
+
+		// Other required arguments are still checked
+		resp = call(cfg, map[string]interface{}{})
+		require.NotNil(t, resp.Error)
+		errs := resp.Error.Data.([]ValidationError)
+		require.Len(t, errs, 1)
+		assert.Equal(t, "/region", errs[0].Path)
+	})
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// maxMockSchemaDepth bounds recursion when synthesizing values from (possibly cyclic) schemas.
+const maxMockSchemaDepth = 8
+
+// mockToolCall builds an upstream-shaped response from the operation's declared examples,
+// falling back to a value synthesized from the response schema. No network request is made.
+func mockToolCall(toolName string, operation mcp.OperationDetail, requestedStatus string) (*http.Response, error) {
+	status := selectMockStatus(operation, requestedStatus)
+
+	var body interface{}
+	if example, ok := operation.Examples[status]; ok {
+		log.Printf("[MockToolCall] Returning declared example for tool '%s' (status %s)", toolName, status)
+		body = example
+	} else {
+		log.Printf("[MockToolCall] No example for tool '%s' (status %s), synthesizing from schema", toolName, status)
+		body = synthesizeFromSchema(operation.Responses[status], 0)
+	}
+
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling mock response for tool '%s': %w", toolName, err)
+	}
+
+	statusCode := http.StatusOK
+	if code, err := strconv.Atoi(status); err == nil {
+		statusCode = code
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(bodyBytes)),
+		ContentLength: int64(len(bodyBytes)),
+	}, nil
+}
+
+// selectMockStatus picks which declared response to mock: the requested status if the operation
+// declares it, otherwise the lowest 2xx, then "default", then the lowest declared status.
+func selectMockStatus(operation mcp.OperationDetail, requestedStatus string) string {
+	declared := make(map[string]bool)
+	for status := range operation.Responses {
+		declared[status] = true
+	}
+	for status := range operation.Examples {
+		declared[status] = true
+	}
+
+	if requestedStatus != "" && declared[requestedStatus] {
+		return requestedStatus
+	}
+
+	statuses := make([]string, 0, len(declared))
+	for status := range declared {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		if strings.HasPrefix(status, "2") {
+			return status
+		}
+	}
+	if declared["default"] {
+		return "default"
+	}
+	if len(statuses) > 0 {
+		return statuses[0]
+	}
+	return "200"
+}
+
+// synthesizeFromSchema produces a placeholder instance that conforms to the schema's shape.
+func synthesizeFromSchema(schema mcp.Schema, depth int) interface{} {
+	if depth > maxMockSchemaDepth {
+		return nil
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+
+	switch schema.Type {
+	case "object":
+		obj := make(map[string]interface{}, len(schema.Properties))
+		for name, propSchema := range schema.Properties {
+			obj[name] = synthesizeFromSchema(propSchema, depth+1)
+		}
+		return obj
+	case "array":
+		if schema.Items == nil {
+			return []interface{}{}
+		}
+		return []interface{}{synthesizeFromSchema(*schema.Items, depth+1)}
+	case "integer":
+		return 0
+	case "number":
+		return 0.0
+	case "boolean":
+		return false
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			return "1970-01-01T00:00:00Z"
+		case "date":
+			return "1970-01-01"
+		case "uuid":
+			return "00000000-0000-0000-0000-000000000000"
+		case "email":
+			return "user@example.com"
+		case "uri", "url":
+			return "https://example.com"
+		default:
+			return "string"
+		}
+	default:
+		// Untyped schema (e.g. a response with only a description)
+		if len(schema.Properties) > 0 {
+			return synthesizeFromSchema(mcp.Schema{Type: "object", Properties: schema.Properties}, depth)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestToolSetForMock() *mcp.ToolSet {
+	// BaseURL points nowhere; any real request would fail the test
+	return &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"get_pet": {
+			Method:  "GET",
+			Path:    "/pets/{id}",
+			BaseURL: "http://127.0.0.1:1",
+			Responses: map[string]mcp.Schema{
+				"200": {Type: "object", Properties: map[string]mcp.Schema{"name": {Type: "string"}}},
+				"404": {Description: "Not found"},
+			},
+			Examples: map[string]interface{}{
+				"200": map[string]interface{}{"name": "Rex"},
+				"404": map[string]interface{}{"error": "no such pet"},
+			},
+		},
+		"create_pet": {
+			Method:  "POST",
+			Path:    "/pets",
+			BaseURL: "http://127.0.0.1:1",
+			Responses: map[string]mcp.Schema{
+				"201": {
+					Type: "object",
+					Properties: map[string]mcp.Schema{
+						"id":      {Type: "integer"},
+						"created": {Type: "string", Format: "date-time"},
+						"status":  {Type: "string", Enum: []interface{}{"available", "sold"}},
+						"tags":    {Type: "array", Items: &mcp.Schema{Type: "string"}},
+					},
+				},
+				"default": {Description: "Unexpected error"},
+			},
+		},
+	}}
+}
+
+func callMockedTool(t *testing.T, toolName string, cfg *config.Config) (ToolResultPayload, map[string]interface{}) {
+	t.Helper()
+	params, _ := json.Marshal(ToolCallParams{ToolName: toolName, Input: map[string]interface{}{"id": "1"}})
+	req := &jsonRPCRequest{Jsonrpc: "2.0", Method: "tools/call", Params: json.RawMessage(params), ID: "mock-1"}
+	resp := handleToolCallJSONRPC("test-conn", req, createTestToolSetForMock(), cfg)
+	require.Nil(t, resp.Error)
+	result, ok := resp.Result.(ToolResultPayload)
+	require.True(t, ok)
+	require.Len(t, result.Content, 1)
+	var body map[string]interface{}
+	if !result.IsError {
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &body), "mock body should be JSON: %s", result.Content[0].Text)
+	}
+	return result, body
+}
+
+func TestMockMode_ReturnsSpecExample(t *testing.T) {
+	result, body := callMockedTool(t, "get_pet", &config.Config{MockMode: true})
+	assert.False(t, result.IsError)
+	assert.Equal(t, map[string]interface{}{"name": "Rex"}, body)
+}
+
+func TestMockMode_RequestedStatus(t *testing.T) {
+	result, _ := callMockedTool(t, "get_pet", &config.Config{MockMode: true, MockStatus: "404"})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "404")
+
+	// An undeclared status falls back to the first 2xx
+	result, body := callMockedTool(t, "get_pet", &config.Config{MockMode: true, MockStatus: "418"})
+	assert.False(t, result.IsError)
+	assert.Equal(t, "Rex", body["name"])
+}
+
+func TestMockMode_SynthesizesFromSchema(t *testing.T) {
+	result, body := callMockedTool(t, "create_pet", &config.Config{MockMode: true})
+	assert.False(t, result.IsError)
+	assert.Equal(t, float64(0), body["id"])
+	assert.Equal(t, "1970-01-01T00:00:00Z", body["created"])
+	assert.Equal(t, "available", body["status"])
+	assert.Equal(t, []interface{}{"string"}, body["tags"])
+}
+
+func TestSelectMockStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses map[string]mcp.Schema
+		requested string
+		expected  string
+	}{
+		{name: "Lowest 2xx", responses: map[string]mcp.Schema{"404": {}, "202": {}, "201": {}}, expected: "201"},
+		{name: "Requested status declared", responses: map[string]mcp.Schema{"200": {}, "400": {}}, requested: "400", expected: "400"},
+		{name: "Default when no 2xx", responses: map[string]mcp.Schema{"default": {}, "500": {}}, expected: "default"},
+		{name: "Lowest declared otherwise", responses: map[string]mcp.Schema{"503": {}, "400": {}}, expected: "400"},
+		{name: "Nothing declared", responses: nil, expected: "200"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, selectMockStatus(mcp.OperationDetail{Responses: tc.responses}, tc.requested))
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package server
+
+import "sync"
+
+// responseSequencer delivers a connection's responses in request arrival order. Each request
+// takes a ticket on arrival and is dispatched concurrently as usual; only the write to the
+// connection's Channel waits until every earlier ticket has been delivered.
+type responseSequencer struct {
+	mutex  sync.Mutex
+	turn   *sync.Cond
+	issued uint64 // Next ticket to hand out
+	next   uint64 // Ticket whose response may be delivered now
+}
+
+func newResponseSequencer() *responseSequencer {
+	s := &responseSequencer{}
+	s.turn = sync.NewCond(&s.mutex)
+	return s
+}
+
+// take issues the next ticket. Every ticket must eventually be finished.
+func (s *responseSequencer) take() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ticket := s.issued
+	s.issued++
+	return ticket
+}
+
+// waitTurn blocks until all responses for earlier tickets have been delivered.
+func (s *responseSequencer) waitTurn(ticket uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for s.next != ticket {
+		s.turn.Wait()
+	}
+}
+
+// finish marks a ticket delivered (or as having nothing to deliver), releasing the next one.
+// It waits for the ticket's turn first, so requests without a response keep their place.
+func (s *responseSequencer) finish(ticket uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for s.next != ticket {
+		s.turn.Wait()
+	}
+	s.next++
+	s.turn.Broadcast()
+}
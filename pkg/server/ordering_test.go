@@ -0,0 +1,83 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// responseOrder posts a slow and then a fast tool call on one connection and returns the
+// request IDs in the order their responses were queued.
+func responseOrder(t *testing.T, cfg *config.Config) []interface{} {
+	slowArrived := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(slowArrived)
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"slow_call": {Method: "GET", Path: "/slow", BaseURL: backend.URL},
+		"fast_call": {Method: "GET", Path: "/fast", BaseURL: backend.URL},
+	}}
+
+	connID := uuid.NewString()
+	conn, channel := setupTestConnection(connID)
+	defer cleanupTestConnection(connID)
+	mcpConnectionManager.UpdateState(connID, StateReady)
+
+	post := func(id, toolName string, done chan<- struct{}) {
+		body := `{"jsonrpc": "2.0", "method": "tools/call", "id": "` + id + `", "params": {"name": "` + toolName + `", "arguments": {}}}`
+		r := httptest.NewRequest(http.MethodPost, "/messages/"+connID, strings.NewReader(body))
+		r.SetPathValue("connectionId", connID)
+		httpMethodPostHandler(httptest.NewRecorder(), r, toolSet, cfg, false)
+		close(done)
+	}
+
+	slowDone, fastDone := make(chan struct{}), make(chan struct{})
+	go post("slow", "slow_call", slowDone)
+	<-slowArrived // The slow request has arrived and is in flight upstream
+	go post("fast", "fast_call", fastDone)
+	<-slowDone
+	<-fastDone
+
+	require.Len(t, conn.Channel, 2)
+	return []interface{}{(<-channel).ID, (<-channel).ID}
+}
+
+func TestOrderedResponses(t *testing.T) {
+	// By default the fast call overtakes the slow one
+	assert.Equal(t, []interface{}{"fast", "slow"}, responseOrder(t, &config.Config{}))
+
+	// With ordering on, responses follow request arrival
+	assert.Equal(t, []interface{}{"slow", "fast"}, responseOrder(t, &config.Config{OrderedResponses: true}))
+}
+
+func TestResponseSequencer_NotificationKeepsItsPlace(t *testing.T) {
+	s := newResponseSequencer()
+	first, second := s.take(), s.take()
+
+	delivered := make(chan uint64, 2)
+	go func() {
+		s.waitTurn(second)
+		delivered <- second
+		s.finish(second)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(t, delivered, "second must wait for first")
+
+	// First has no response to deliver (e.g. a notification), but still releases its turn
+	s.finish(first)
+	assert.Equal(t, second, <-delivered)
+}
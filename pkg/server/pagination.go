@@ -0,0 +1,170 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// paginationEnabled reports whether GET tool responses follow Link rel="next" headers.
+func paginationEnabled(cfg *config.Config) bool {
+	return cfg != nil && cfg.PaginateMaxPages > 1
+}
+
+// linkRelations parses RFC 8288 (formerly RFC 5988) Link header values into target URLs keyed by
+// lowercased relation type. The first link for a relation wins.
+func linkRelations(values []string) map[string]string {
+	links := make(map[string]string)
+	for _, value := range values {
+		rest := value
+		for {
+			start := strings.Index(rest, "<")
+			if start == -1 {
+				break
+			}
+			end := strings.Index(rest[start:], ">")
+			if end == -1 {
+				break
+			}
+			target := rest[start+1 : start+end]
+			rest = rest[start+end+1:]
+
+			// Parameters run up to the next link, which starts after a comma
+			params := rest
+			if next := strings.Index(rest, "<"); next != -1 {
+				params = rest[:next]
+			}
+			for _, param := range strings.Split(params, ";") {
+				name, paramValue, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || !strings.EqualFold(strings.TrimSpace(name), "rel") {
+					continue
+				}
+				paramValue = strings.Trim(paramValue, "\" ,")
+				for _, rel := range strings.Fields(strings.ToLower(paramValue)) {
+					if _, seen := links[rel]; !seen {
+						links[rel] = target
+					}
+				}
+			}
+		}
+	}
+	return links
+}
+
+// nextPageURL returns the absolute URL of resp's rel="next" link, if any.
+func nextPageURL(resp *http.Response) (*url.URL, bool) {
+	target, ok := linkRelations(resp.Header.Values("Link"))["next"]
+	if !ok || resp.Request == nil {
+		return nil, false
+	}
+	next, err := resp.Request.URL.Parse(target)
+	if err != nil {
+		log.Printf("[ExecuteToolCall] Ignoring invalid next page link %q: %v", target, err)
+		return nil, false
+	}
+	return next, true
+}
+
+// followNextLinks fetches the pages after a successful GET response by following its Link
+// rel="next" headers, up to PaginateMaxPages pages in total, and merges them with body, the first
+// page. Pages are re-requested with the first request's headers, so links leaving its scheme and
+// host are not followed. maxBytes bounds all pages together.
+func followNextLinks(toolName string, httpResp *http.Response, body []byte, maxBytes int64, operation mcp.OperationDetail, cfg *config.Config) ([]byte, error) {
+	if !paginationEnabled(cfg) || httpResp.Request == nil || httpResp.Request.Method != http.MethodGet ||
+		httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 || isNoContentStatus(httpResp.StatusCode) {
+		return body, nil
+	}
+
+	first := httpResp.Request.URL
+	pages := [][]byte{body}
+	total := int64(len(body))
+	visited := map[string]bool{first.String(): true}
+	client := &http.Client{Timeout: effectiveTimeout(toolName, operation, cfg), Transport: upstreamTransport(cfg)}
+	resp := httpResp
+	for len(pages) < cfg.PaginateMaxPages {
+		next, ok := nextPageURL(resp)
+		if !ok {
+			break
+		}
+		if next.Scheme != first.Scheme || next.Host != first.Host {
+			log.Printf("[ExecuteToolCall] Not following next page link of tool '%s' to another host: %s", toolName, next.Redacted())
+			break
+		}
+		if visited[next.String()] {
+			log.Printf("[ExecuteToolCall] Next page link of tool '%s' points back to a fetched page, stopping", toolName)
+			break
+		}
+		visited[next.String()] = true
+
+		pageReq := resp.Request.Clone(context.Background())
+		pageReq.URL = next
+		pageReq.Host = next.Host
+		log.Printf("[ExecuteToolCall] Fetching page %d of tool '%s': %s", len(pages)+1, toolName, next.Redacted())
+		pageResp, err := client.Do(pageReq)
+		if err != nil {
+			return nil, fmt.Errorf("fetching page %d: %w", len(pages)+1, err)
+		}
+		page, err := readPage(pageResp, maxBytes-total)
+		if err != nil {
+			var tooLargeErr *errResponseTooLarge
+			if errors.As(err, &tooLargeErr) {
+				return nil, &errResponseTooLarge{limit: maxBytes}
+			}
+			return nil, fmt.Errorf("fetching page %d: %w", len(pages)+1, err)
+		}
+		pages = append(pages, page)
+		total += int64(len(page))
+		resp = pageResp
+	}
+	if len(pages) == 1 {
+		return body, nil
+	}
+	log.Printf("[ExecuteToolCall] Merged %d pages of tool '%s'", len(pages), toolName)
+	return mergePages(pages), nil
+}
+
+// readPage reads one further page, which must be a success response.
+func readPage(resp *http.Response, limit int64) ([]byte, error) {
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("upstream returned status %s", resp.Status)
+	}
+	if err := decodeResponseBody(resp); err != nil {
+		return nil, err
+	}
+	return readLimitedBody(resp.Body, limit)
+}
+
+// mergePages concatenates pages that are all JSON arrays into one array. Any other pages are
+// joined with newlines.
+func mergePages(pages [][]byte) []byte {
+	var items []json.RawMessage
+	for _, page := range pages {
+		var pageItems []json.RawMessage
+		if err := json.Unmarshal(page, &pageItems); err != nil || pageItems == nil {
+			return []byte(strings.Join(pageTexts(pages), "\n"))
+		}
+		items = append(items, pageItems...)
+	}
+	merged, err := json.Marshal(items)
+	if err != nil {
+		return []byte(strings.Join(pageTexts(pages), "\n"))
+	}
+	return merged
+}
+
+func pageTexts(pages [][]byte) []string {
+	texts := make([]string, len(pages))
+	for i, page := range pages {
+		texts[i] = strings.TrimRight(string(page), "\n")
+	}
+	return texts
+}
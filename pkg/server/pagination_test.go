@@ -0,0 +1,93 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkRelations(t *testing.T) {
+	links := linkRelations([]string{
+		`<https://api.example.com/items?page=2>; rel="next", <https://api.example.com/items?page=9>; rel="last"`,
+		`</items?page=1,a>; title="first, really"; REL=first`,
+	})
+	assert.Equal(t, map[string]string{
+		"next":  "https://api.example.com/items?page=2",
+		"last":  "https://api.example.com/items?page=9",
+		"first": "/items?page=1,a",
+	}, links)
+	assert.Empty(t, linkRelations([]string{"garbage"}))
+}
+
+// paginatedBackend serves /items in pages, linking each to the next with a relative Link header.
+func paginatedBackend(t *testing.T, pages []string) *httptest.Server {
+	t.Helper()
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"), "later pages keep the request headers")
+		page := 1
+		fmt.Sscanf(r.URL.Query().Get("page"), "%d", &page)
+		if page < len(pages) {
+			w.Header().Set("Link", fmt.Sprintf(`</items?page=%d>; rel="next"`, page+1))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(pages[page-1]))
+	}))
+	t.Cleanup(backend.Close)
+	return backend
+}
+
+func callPaginatedTool(t *testing.T, backend *httptest.Server, cfg *config.Config) ToolResultPayload {
+	t.Helper()
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"list_items": {Method: "GET", Path: "/items", BaseURL: backend.URL, Parameters: []mcp.ParameterDetail{{Name: "Authorization", In: "header"}}},
+	}}
+	params := &ToolCallParams{ToolName: "list_items", Input: map[string]interface{}{"Authorization": "Bearer secret"}}
+	return callToolUpstream("conn", &jsonRPCRequest{ID: 1}, params, toolSet, cfg)
+}
+
+func TestCallToolUpstream_FollowsNextLinks(t *testing.T) {
+	backend := paginatedBackend(t, []string{`[{"id": 1}, {"id": 2}]`, `[{"id": 3}]`})
+
+	result := callPaginatedTool(t, backend, &config.Config{PaginateMaxPages: 5})
+	require.False(t, result.IsError, "%+v", result.Content)
+	assert.JSONEq(t, `[{"id": 1}, {"id": 2}, {"id": 3}]`, result.Content[0].Text)
+
+	result = callPaginatedTool(t, backend, &config.Config{})
+	assert.JSONEq(t, `[{"id": 1}, {"id": 2}]`, result.Content[0].Text, "pagination is opt-in")
+}
+
+func TestCallToolUpstream_PaginationCaps(t *testing.T) {
+	backend := paginatedBackend(t, []string{`[1]`, `[2]`, `[3]`})
+	result := callPaginatedTool(t, backend, &config.Config{PaginateMaxPages: 2})
+	assert.JSONEq(t, `[1, 2]`, result.Content[0].Text, "no more than the page cap is fetched")
+
+	backend = paginatedBackend(t, []string{`{"page": 1}`, `{"page": 2}`})
+	result = callPaginatedTool(t, backend, &config.Config{PaginateMaxPages: 5})
+	assert.Equal(t, "{\"page\": 1}\n{\"page\": 2}", result.Content[0].Text, "non-array pages are joined")
+
+	backend = paginatedBackend(t, []string{`[1, 2, 3]`, `[4, 5, 6]`})
+	result = callPaginatedTool(t, backend, &config.Config{PaginateMaxPages: 5, MaxResponseBytes: 12})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "exceeded the maximum size of 12 bytes", "the size cap covers all pages")
+}
+
+func TestFollowNextLinks_StaysOnHost(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("a link to another host was followed")
+	}))
+	defer other.Close()
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", fmt.Sprintf(`<%s/items?page=2>; rel="next"`, other.URL))
+		w.Write([]byte(`[1]`))
+	}))
+	defer backend.Close()
+
+	result := callPaginatedTool(t, backend, &config.Config{PaginateMaxPages: 5})
+	assert.JSONEq(t, `[1]`, result.Content[0].Text)
+}
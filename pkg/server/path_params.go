@@ -0,0 +1,94 @@
+package server
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// Path parameter styles from the OpenAPI parameter serialization rules.
+const (
+	pathStyleSimple = "simple" // 5, 3,4,5
+	pathStyleLabel  = "label"  // .5, .3,4,5
+	pathStyleMatrix = "matrix" // ;id=5, ;id=3,4,5
+)
+
+// serializePathParam renders a path parameter value per the parameter's declared style and explode
+// flag, defaulting to simple without explode as the spec does for path parameters. Values are
+// percent-encoded so they cannot introduce path separators or delimiters of their own.
+func serializePathParam(param mcp.ParameterDetail, value interface{}) string {
+	style := param.Style
+	if style == "" {
+		style = pathStyleSimple
+	}
+	explode := param.Explode != nil && *param.Explode
+
+	// Flatten the value into its items; objects become alternating names and values
+	var items, names []string
+	switch v := value.(type) {
+	case []interface{}:
+		for _, element := range v {
+			items = append(items, url.PathEscape(formatScalar(element)))
+		}
+	case map[string]interface{}:
+		for key := range v {
+			names = append(names, key)
+		}
+		sort.Strings(names) // Maps are unordered; keep URLs deterministic
+		for _, key := range names {
+			items = append(items, url.PathEscape(formatScalar(v[key])))
+		}
+	default:
+		return prefixPathValue(style, param.Name, url.PathEscape(formatScalar(value)))
+	}
+
+	// Exploded objects are name=value pairs; everything else is a list of items
+	if names != nil && explode {
+		pairs := make([]string, len(names))
+		for i, name := range names {
+			pairs[i] = url.PathEscape(name) + "=" + items[i]
+		}
+		switch style {
+		case pathStyleLabel:
+			return "." + strings.Join(pairs, ".")
+		case pathStyleMatrix:
+			return ";" + strings.Join(pairs, ";")
+		default:
+			return strings.Join(pairs, ",")
+		}
+	}
+	if names != nil {
+		interleaved := make([]string, 0, 2*len(names))
+		for i, name := range names {
+			interleaved = append(interleaved, url.PathEscape(name), items[i])
+		}
+		items = interleaved
+	}
+
+	if !explode || style == pathStyleSimple {
+		return prefixPathValue(style, param.Name, strings.Join(items, ","))
+	}
+	if style == pathStyleLabel {
+		return "." + strings.Join(items, ".")
+	}
+	// Exploded matrix arrays repeat the parameter name for each item
+	exploded := make([]string, len(items))
+	for i, item := range items {
+		exploded[i] = ";" + url.PathEscape(param.Name) + "=" + item
+	}
+	return strings.Join(exploded, "")
+}
+
+// prefixPathValue adds the style's prefix to an already-joined value.
+func prefixPathValue(style, name, joined string) string {
+	switch style {
+	case pathStyleLabel:
+		return "." + joined
+	case pathStyleMatrix:
+		return ";" + url.PathEscape(name) + "=" + joined
+	default:
+		return joined
+	}
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/litui/openapi-mcp-claude/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerializePathParam(t *testing.T) {
+	explode := true
+	noExplode := false
+	array := []interface{}{3, 4, 5}
+	object := map[string]interface{}{"role": "admin", "firstName": "Alex"}
+
+	tests := []struct {
+		name    string
+		style   string
+		explode *bool
+		value   interface{}
+		want    string
+	}{
+		{name: "Default is simple", value: 5, want: "5"},
+		{name: "Simple scalar", style: "simple", value: "abc", want: "abc"},
+		{name: "Simple array", style: "simple", value: array, want: "3,4,5"},
+		{name: "Simple array exploded", style: "simple", explode: &explode, value: array, want: "3,4,5"},
+		{name: "Simple object", style: "simple", value: object, want: "firstName,Alex,role,admin"},
+		{name: "Simple object exploded", style: "simple", explode: &explode, value: object, want: "firstName=Alex,role=admin"},
+
+		{name: "Label scalar", style: "label", value: 123, want: ".123"},
+		{name: "Label array", style: "label", explode: &noExplode, value: array, want: ".3,4,5"},
+		{name: "Label array exploded", style: "label", explode: &explode, value: array, want: ".3.4.5"},
+		{name: "Label object exploded", style: "label", explode: &explode, value: object, want: ".firstName=Alex.role=admin"},
+
+		{name: "Matrix scalar", style: "matrix", value: 123, want: ";id=123"},
+		{name: "Matrix array", style: "matrix", value: array, want: ";id=3,4,5"},
+		{name: "Matrix array exploded", style: "matrix", explode: &explode, value: array, want: ";id=3;id=4;id=5"},
+		{name: "Matrix object", style: "matrix", value: object, want: ";id=firstName,Alex,role,admin"},
+		{name: "Matrix object exploded", style: "matrix", explode: &explode, value: object, want: ";firstName=Alex;role=admin"},
+
+		{name: "Values are escaped", style: "simple", value: []interface{}{"a/b", "c,d"}, want: "a%2Fb,c%2Cd"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			param := mcp.ParameterDetail{Name: "id", In: "path", Style: tc.style, Explode: tc.explode}
+			assert.Equal(t, tc.want, serializePathParam(param, tc.value))
+		})
+	}
+}
+
+func TestExecuteToolCall_PathParamStyles(t *testing.T) {
+	var requestURI string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.RequestURI
+		w.Write([]byte(`{}`))
+	}))
+	defer backend.Close()
+
+	explode := true
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"get_user": {Method: "GET", Path: "/users/{id}", BaseURL: backend.URL,
+			Parameters: []mcp.ParameterDetail{{Name: "id", In: "path", Style: "label"}}},
+		"get_users": {Method: "GET", Path: "/users/{ids}", BaseURL: backend.URL,
+			Parameters: []mcp.ParameterDetail{{Name: "ids", In: "path", Style: "matrix", Explode: &explode}}},
+	}}
+
+	resp, err := executeToolCall(&ToolCallParams{ToolName: "get_user", Input: map[string]interface{}{"id": 123}}, toolSet, &config.Config{})
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "/users/.123", requestURI)
+
+	resp, err = executeToolCall(&ToolCallParams{ToolName: "get_users", Input: map[string]interface{}{"ids": []interface{}{1, 2}}}, toolSet, &config.Config{})
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "/users/;ids=1;ids=2", requestURI)
+}
+
+func TestExecuteToolCall_PathOverride(t *testing.T) {
+	var requestURI string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.RequestURI
+		w.Write([]byte(`{}`))
+	}))
+	defer backend.Close()
+
+	specPath := filepath.Join(t.TempDir(), "spec.json")
+	require.NoError(t, os.WriteFile(specPath, []byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "Proxy API", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "paths": {
+    "/api/v1/users/{userId}": {
+      "get": {
+        "operationId": "getUser",
+        "parameters": [{"name": "userId", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`), 0o600))
+	doc, version, err := parser.LoadSwagger(specPath)
+	require.NoError(t, err)
+
+	// The override composes with the base URL override
+	cfg := &config.Config{ServerBaseURL: backend.URL, PathOverrides: map[string]string{"getUser": "/users/{userId}/profile"}}
+	toolSet, err := parser.GenerateToolSet(doc, version, cfg)
+	require.NoError(t, err)
+
+	resp, err := executeToolCall(&ToolCallParams{ToolName: "getUser", Input: map[string]interface{}{"userId": "u-42"}}, toolSet, cfg)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "/users/u-42/profile", requestURI)
+}
+
+func TestExecuteToolCall_OperationServer(t *testing.T) {
+	var rootHits, hookHits []string
+	root := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rootHits = append(rootHits, r.Method+" "+r.URL.Path)
+		w.Write([]byte(`{}`))
+	}))
+	defer root.Close()
+	hooks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hookHits = append(hookHits, r.Method+" "+r.URL.Path)
+		w.Write([]byte(`{}`))
+	}))
+	defer hooks.Close()
+
+	specPath := filepath.Join(t.TempDir(), "spec.json")
+	require.NoError(t, os.WriteFile(specPath, []byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "Callbacks API", "version": "1.0.0"},
+  "servers": [{"url": "`+root.URL+`/v1"}],
+  "paths": {
+    "/events": {
+      "get": {"operationId": "listEvents", "responses": {"200": {"description": "OK"}}},
+      "post": {
+        "operationId": "deliverEvent",
+        "servers": [{"url": "`+hooks.URL+`/{stage}", "variables": {"stage": {"default": "live"}}}],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`), 0o600))
+	doc, version, err := parser.LoadSwagger(specPath)
+	require.NoError(t, err)
+	cfg := &config.Config{}
+	toolSet, err := parser.GenerateToolSet(doc, version, cfg)
+	require.NoError(t, err)
+
+	for _, toolName := range []string{"deliverEvent", "listEvents"} {
+		resp, err := executeToolCall(&ToolCallParams{ToolName: toolName, Input: map[string]interface{}{}}, toolSet, cfg)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.Equal(t, []string{"POST /live/events"}, hookHits, "the operation-level server wins")
+	assert.Equal(t, []string{"GET /v1/events"}, rootHits, "siblings keep the root server")
+}
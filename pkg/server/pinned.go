@@ -0,0 +1,43 @@
+package server
+
+import (
+	"log"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// withPinnedArguments returns the tool input with the tool's pinned arguments applied. Pinned values
+// win over client values. The client's map is not modified.
+func withPinnedArguments(toolName string, input map[string]interface{}, cfg *config.Config) map[string]interface{} {
+	if cfg == nil || len(cfg.PinnedArguments[toolName]) == 0 {
+		return input
+	}
+	merged := make(map[string]interface{}, len(input)+len(cfg.PinnedArguments[toolName]))
+	for name, value := range input {
+		merged[name] = value
+	}
+	for name, value := range cfg.PinnedArguments[toolName] {
+		if _, supplied := input[name]; supplied {
+			log.Printf("[ExecuteToolCall] Replacing client value of '%s' with its pinned value for tool '%s'", name, toolName)
+		}
+		merged[name] = value
+	}
+	return merged
+}
+
+// withTagArguments returns the tool input with the arguments pinned for the operation's tags
+// applied. Like pins they win over client values; the client's map is not modified.
+func withTagArguments(operation mcp.OperationDetail, input map[string]interface{}) map[string]interface{} {
+	if len(operation.TagArguments) == 0 {
+		return input
+	}
+	merged := make(map[string]interface{}, len(input)+len(operation.TagArguments))
+	for name, value := range input {
+		merged[name] = value
+	}
+	for name, value := range operation.TagArguments {
+		merged[name] = value
+	}
+	return merged
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteToolCall_PinnedArguments(t *testing.T) {
+	var signature string
+	var body map[string]interface{}
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-Signature")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"createOrder": {
+			Method:     "POST",
+			Path:       "/orders",
+			BaseURL:    backend.URL,
+			Parameters: []mcp.ParameterDetail{{Name: "X-Signature", In: "header"}},
+		},
+	}}
+	cfg := &config.Config{PinnedArguments: map[string]map[string]interface{}{
+		"createOrder": {"X-Signature": "server-sig", "pageSize": float64(50)},
+	}}
+
+	input := map[string]interface{}{"item": "book", "pageSize": float64(1000)}
+	resp, err := executeToolCall(&ToolCallParams{ToolName: "createOrder", Input: input}, toolSet, cfg)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "server-sig", signature, "hidden header parameter is supplied from the pin")
+	assert.Equal(t, map[string]interface{}{"item": "book", "pageSize": float64(50)}, body, "pins override client values")
+	assert.Equal(t, float64(1000), input["pageSize"], "client input is not modified")
+}
+
+func TestWithPinnedArguments_NoPins(t *testing.T) {
+	input := map[string]interface{}{"a": 1}
+	assert.Equal(t, input, withPinnedArguments("tool", input, &config.Config{}))
+	assert.Equal(t, input, withPinnedArguments("tool", input, nil))
+}
+
+func TestExecuteToolCall_TagPinnedArguments(t *testing.T) {
+	var tenant, region string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant = r.Header.Get("X-Tenant-Id")
+		region = r.URL.Query().Get("region")
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"listProjects": {
+			Method:  "GET",
+			Path:    "/projects",
+			BaseURL: backend.URL,
+			Parameters: []mcp.ParameterDetail{
+				{Name: "X-Tenant-Id", In: "header"},
+				{Name: "region", In: "query"},
+			},
+			TagArguments: map[string]interface{}{"X-Tenant-Id": "acme", "region": "us"},
+		},
+	}}
+	cfg := &config.Config{PinnedArguments: map[string]map[string]interface{}{
+		"listProjects": {"region": "eu"},
+	}}
+
+	input := map[string]interface{}{"X-Tenant-Id": "spoofed"}
+	resp, err := executeToolCall(&ToolCallParams{ToolName: "listProjects", Input: input}, toolSet, cfg)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "acme", tenant, "tag-level pins override client values")
+	assert.Equal(t, "eu", region, "tool pins override tag-level ones")
+	assert.Equal(t, "spoofed", input["X-Tenant-Id"], "client input is not modified")
+}
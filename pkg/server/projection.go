@@ -0,0 +1,141 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// projectionNode is one level of a compiled response projection. A leaf keeps the whole value;
+// otherwise only the listed children are kept.
+type projectionNode struct {
+	leaf     bool
+	children map[string]*projectionNode
+}
+
+// compileProjection builds a projection tree from field specs. A spec starting with "/" is a
+// JSON pointer (RFC 6901) into nested objects; anything else names a top-level field.
+func compileProjection(fields []string) *projectionNode {
+	root := &projectionNode{children: make(map[string]*projectionNode)}
+	for _, field := range fields {
+		var segments []string
+		if strings.HasPrefix(field, "/") {
+			for _, segment := range strings.Split(field[1:], "/") {
+				segments = append(segments, unescapeJSONPointer(segment))
+			}
+		} else if field != "" {
+			segments = []string{field}
+		}
+		if len(segments) == 0 {
+			continue
+		}
+
+		node := root
+		for _, segment := range segments {
+			if node.leaf {
+				break // An ancestor is already kept whole
+			}
+			child := node.children[segment]
+			if child == nil {
+				child = &projectionNode{children: make(map[string]*projectionNode)}
+				node.children[segment] = child
+			}
+			node = child
+		}
+		node.leaf = true
+		node.children = nil // Keeping the whole value subsumes narrower projections
+	}
+	return root
+}
+
+// project applies a projection to a decoded JSON value. Arrays project each element; objects keep
+// only projected fields. It returns false when a projection descends into a non-container value,
+// so the field is dropped like any missing field.
+func (n *projectionNode) project(value interface{}) (interface{}, bool) {
+	if n.leaf {
+		return value, true
+	}
+	switch v := value.(type) {
+	case []interface{}:
+		projected := make([]interface{}, 0, len(v))
+		for _, element := range v {
+			if p, ok := n.project(element); ok {
+				projected = append(projected, p)
+			}
+		}
+		return projected, true
+	case map[string]interface{}:
+		projected := make(map[string]interface{}, len(n.children))
+		for key, child := range n.children {
+			if fieldValue, present := v[key]; present {
+				if p, ok := child.project(fieldValue); ok {
+					projected[key] = p
+				}
+			}
+		}
+		return projected, true
+	default:
+		return nil, false
+	}
+}
+
+// projectSchema returns the schema of a value after the projection, the one transforms are checked
+// against. Arrays project their items; objects keep only the projected properties. A part the
+// projection can't follow through the schema comes back as an empty schema, which is not checked.
+func (n *projectionNode) projectSchema(schema mcp.Schema) mcp.Schema {
+	if n.leaf {
+		return schema
+	}
+	switch {
+	case schema.Type == "array" && schema.Items != nil:
+		items := n.projectSchema(*schema.Items)
+		projected := schema
+		projected.Items = &items
+		return projected
+	case (schema.Type == "object" || schema.Type == "") && len(schema.Properties) > 0:
+		projected := mcp.Schema{
+			Type:                 schema.Type,
+			Properties:           make(map[string]mcp.Schema, len(n.children)),
+			AdditionalProperties: schema.AdditionalProperties, // Undeclared fields may still be projected
+		}
+		for key, child := range n.children {
+			if property, ok := schema.Properties[key]; ok {
+				projected.Properties[key] = child.projectSchema(property)
+			}
+		}
+		return projected
+	}
+	return mcp.Schema{}
+}
+
+// projectResponseBody trims a JSON success body to the tool's configured projection. Bodies of tools
+// without a projection, and bodies that are not JSON, are returned unchanged.
+func projectResponseBody(toolName string, body []byte, cfg *config.Config) []byte {
+	if cfg == nil || len(cfg.ResponseProjections[toolName]) == 0 {
+		return body
+	}
+	var decoded interface{}
+	if err := decodeJSON(body, &decoded); err != nil {
+		log.Printf("[ResponseProjection] Response for tool '%s' is not JSON, returning it unprojected", toolName)
+		return body
+	}
+	projected, ok := compileProjection(cfg.ResponseProjections[toolName]).project(decoded)
+	if !ok {
+		projected = nil // A scalar body has none of the projected fields
+	}
+	projectedBytes, err := json.Marshal(projected)
+	if err != nil {
+		log.Printf("[ResponseProjection] Could not encode projected response for tool '%s': %v", toolName, err)
+		return body
+	}
+	log.Printf("[ResponseProjection] Projected response for tool '%s' from %d to %d bytes", toolName, len(body), len(projectedBytes))
+	return projectedBytes
+}
+
+// unescapeJSONPointer decodes a JSON pointer segment, the inverse of escapeJSONPointer.
+func unescapeJSONPointer(segment string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectResponseBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		fields   []string
+		body     string
+		expected string
+	}{
+		{
+			name:     "Top-level allow-list",
+			fields:   []string{"id", "name"},
+			body:     `{"id": 7, "name": "Ada", "bio": "long text", "avatar": "base64..."}`,
+			expected: `{"id": 7, "name": "Ada"}`,
+		},
+		{
+			name:     "JSON pointer into nested object",
+			fields:   []string{"id", "/address/city"},
+			body:     `{"id": 7, "address": {"city": "London", "street": "1 Main St"}, "bio": "x"}`,
+			expected: `{"id": 7, "address": {"city": "London"}}`,
+		},
+		{
+			name:     "Array elements projected",
+			fields:   []string{"id"},
+			body:     `[{"id": 1, "blob": "a"}, {"id": 2, "blob": "b"}]`,
+			expected: `[{"id": 1}, {"id": 2}]`,
+		},
+		{
+			name:     "Nested array projected through pointer",
+			fields:   []string{"/items/sku", "total"},
+			body:     `{"items": [{"sku": "A", "desc": "x"}, {"sku": "B", "desc": "y"}], "total": 3, "meta": {}}`,
+			expected: `{"items": [{"sku": "A"}, {"sku": "B"}], "total": 3}`,
+		},
+		{
+			name:     "Missing field is absent",
+			fields:   []string{"id", "email", "/address/zip"},
+			body:     `{"id": 7, "address": "unknown"}`,
+			expected: `{"id": 7}`,
+		},
+		{
+			name:     "Escaped pointer segment",
+			fields:   []string{"/a~1b"},
+			body:     `{"a/b": 1, "c": 2}`,
+			expected: `{"a/b": 1}`,
+		},
+		{
+			name:     "Whole field subsumes narrower pointer",
+			fields:   []string{"/address/city", "address"},
+			body:     `{"address": {"city": "London", "street": "1 Main St"}}`,
+			expected: `{"address": {"city": "London", "street": "1 Main St"}}`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &config.Config{ResponseProjections: map[string][]string{"get_user": tc.fields}}
+			assert.JSONEq(t, tc.expected, string(projectResponseBody("get_user", []byte(tc.body), cfg)))
+		})
+	}
+
+	// No projection configured, or a non-JSON body: unchanged
+	body := []byte(`{"id": 7, "bio": "x"}`)
+	assert.Equal(t, body, projectResponseBody("get_user", body, &config.Config{}))
+	cfg := &config.Config{ResponseProjections: map[string][]string{"get_user": {"id"}}}
+	assert.Equal(t, []byte("plain text"), projectResponseBody("get_user", []byte("plain text"), cfg))
+}
+
+func TestToolCall_ResponseProjection(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": [{"id": 1, "name": "Ada", "history": [1, 2, 3]}, {"id": 2, "name": "Grace", "history": []}], "debug": {"trace": "..."}}`))
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"list_users": {Method: "GET", Path: "/users", BaseURL: backend.URL},
+	}}
+	cfg := &config.Config{
+		DisableInputValidation: true,
+		ResponseProjections:    map[string][]string{"list_users": {"/data/name"}},
+	}
+
+	params, _ := json.Marshal(ToolCallParams{ToolName: "list_users", Input: map[string]interface{}{}})
+	req := &jsonRPCRequest{Jsonrpc: "2.0", Method: "tools/call", Params: json.RawMessage(params), ID: "projection-1"}
+	resp := handleToolCallJSONRPC("test-conn", req, toolSet, cfg)
+
+	result, ok := resp.Result.(ToolResultPayload)
+	require.True(t, ok)
+	require.False(t, result.IsError)
+	assert.JSONEq(t, `{"data": [{"name": "Ada"}, {"name": "Grace"}]}`, result.Content[0].Text)
+}
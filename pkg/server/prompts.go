@@ -0,0 +1,93 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// PromptGetParams are the params of a prompts/get request.
+type PromptGetParams struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
+
+// promptsAvailable reports whether prompt methods may be served on a connection: prompts must be
+// configured, and the client must have advertised the prompts capability during initialize.
+func promptsAvailable(conn *Connection, toolSet *mcp.ToolSet) bool {
+	if toolSet == nil || len(toolSet.Prompts) == 0 || conn == nil {
+		return false
+	}
+	_, ok := conn.ClientCapabilities["prompts"]
+	return ok
+}
+
+func handlePromptsListJSONRPC(connID string, req *jsonRPCRequest, toolSet *mcp.ToolSet) jsonRPCResponse {
+	log.Printf("Handling 'prompts/list' (JSON-RPC) for %s", connID)
+
+	return jsonRPCResponse{
+		Jsonrpc: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"prompts": toolSet.Prompts,
+		},
+	}
+}
+
+func handlePromptsGetJSONRPC(connID string, req *jsonRPCRequest, toolSet *mcp.ToolSet) jsonRPCResponse {
+	log.Printf("Handling 'prompts/get' (JSON-RPC) for %s", connID)
+
+	var params PromptGetParams
+	paramsBytes, err := json.Marshal(req.Params)
+	if err == nil {
+		err = decodeJSON(paramsBytes, &params)
+	}
+	if err != nil || params.Name == "" {
+		log.Printf("Invalid prompts/get params for %s: %v", connID, err)
+		return createJSONRPCError(req.ID, -32602, "Invalid params: prompt name is required", nil)
+	}
+
+	var prompt *mcp.Prompt
+	for i := range toolSet.Prompts {
+		if toolSet.Prompts[i].Name == params.Name {
+			prompt = &toolSet.Prompts[i]
+			break
+		}
+	}
+	if prompt == nil {
+		log.Printf("Unknown prompt '%s' requested by %s", params.Name, connID)
+		return createJSONRPCError(req.ID, -32602, fmt.Sprintf("Unknown prompt: %s", params.Name), nil)
+	}
+
+	// Arguments are strings per the MCP spec; stringify anything else the client sent
+	args := make(map[string]string, len(params.Arguments))
+	for name, value := range params.Arguments {
+		if s, ok := value.(string); ok {
+			args[name] = s
+		} else {
+			args[name] = formatScalar(value)
+		}
+	}
+
+	text, err := prompt.Render(args)
+	if err != nil {
+		log.Printf("Cannot render prompt '%s' for %s: %v", prompt.Name, connID, err)
+		return createJSONRPCError(req.ID, -32602, fmt.Sprintf("Invalid arguments for prompt '%s'", prompt.Name), err.Error())
+	}
+
+	return jsonRPCResponse{
+		Jsonrpc: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"description": prompt.Description,
+			"messages": []map[string]interface{}{
+				{
+					"role":    "user",
+					"content": map[string]interface{}{"type": "text", "text": text},
+				},
+			},
+		},
+	}
+}
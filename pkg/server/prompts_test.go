@@ -0,0 +1,134 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestToolSetWithPrompts() *mcp.ToolSet {
+	return &mcp.ToolSet{
+		Prompts: []mcp.Prompt{
+			{
+				Name:        "summarize_invoices",
+				Description: "Summarize invoices for a customer",
+				Arguments: []mcp.PromptArgument{
+					{Name: "customer", Description: "Customer ID", Required: true},
+					{Name: "period"},
+				},
+				Template: "Summarize invoices for customer {{customer}} over {{ period }}.",
+			},
+		},
+	}
+}
+
+// readyPromptConnection creates a Ready connection whose client advertised the given capabilities.
+func readyPromptConnection(t *testing.T, capabilities map[string]interface{}) (*Connection, string) {
+	connID := uuid.NewString()
+	conn, _ := setupTestConnection(connID)
+	t.Cleanup(func() { cleanupTestConnection(connID) })
+	mcpConnectionManager.SetClientCapabilities(connID, capabilities)
+	mcpConnectionManager.UpdateState(connID, StateReady)
+	return conn, connID
+}
+
+func TestPrompts_ListAndGet(t *testing.T) {
+	toolSet := createTestToolSetWithPrompts()
+	conn, connID := readyPromptConnection(t, map[string]interface{}{"prompts": map[string]interface{}{}})
+
+	resp, respond := dispatchJSONRPC(conn, connID, &jsonRPCRequest{Jsonrpc: "2.0", ID: 1, Method: "prompts/list"}, toolSet, &config.Config{})
+	require.True(t, respond)
+	require.Nil(t, resp.Error)
+	prompts := resp.Result.(map[string]interface{})["prompts"].([]mcp.Prompt)
+	require.Len(t, prompts, 1)
+	assert.Equal(t, "summarize_invoices", prompts[0].Name)
+	assert.Len(t, prompts[0].Arguments, 2)
+
+	resp, _ = dispatchJSONRPC(conn, connID, &jsonRPCRequest{
+		Jsonrpc: "2.0",
+		ID:      2,
+		Method:  "prompts/get",
+		Params: map[string]interface{}{
+			"name":      "summarize_invoices",
+			"arguments": map[string]interface{}{"customer": "ACME-42", "period": "Q3"},
+		},
+	}, toolSet, &config.Config{})
+	require.Nil(t, resp.Error)
+	result := resp.Result.(map[string]interface{})
+	assert.Equal(t, "Summarize invoices for a customer", result["description"])
+	messages := result["messages"].([]map[string]interface{})
+	require.Len(t, messages, 1)
+	assert.Equal(t, "user", messages[0]["role"])
+	assert.Equal(t, map[string]interface{}{"type": "text", "text": "Summarize invoices for customer ACME-42 over Q3."}, messages[0]["content"])
+}
+
+func TestPrompts_GetErrors(t *testing.T) {
+	toolSet := createTestToolSetWithPrompts()
+	conn, connID := readyPromptConnection(t, map[string]interface{}{"prompts": map[string]interface{}{}})
+
+	get := func(params interface{}) jsonRPCResponse {
+		resp, _ := dispatchJSONRPC(conn, connID, &jsonRPCRequest{Jsonrpc: "2.0", ID: 3, Method: "prompts/get", Params: params}, toolSet, &config.Config{})
+		return resp
+	}
+
+	// Missing required argument
+	resp := get(map[string]interface{}{"name": "summarize_invoices", "arguments": map[string]interface{}{"period": "Q3"}})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, -32602, resp.Error.Code)
+	assert.Contains(t, resp.Error.Data, "customer")
+
+	// Optional argument omitted renders empty
+	resp = get(map[string]interface{}{"name": "summarize_invoices", "arguments": map[string]interface{}{"customer": "ACME-42"}})
+	require.Nil(t, resp.Error)
+
+	// Unknown prompt
+	resp = get(map[string]interface{}{"name": "nope"})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, -32602, resp.Error.Code)
+
+	// No name
+	resp = get(map[string]interface{}{})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, -32602, resp.Error.Code)
+}
+
+func TestPrompts_RequireClientCapability(t *testing.T) {
+	toolSet := createTestToolSetWithPrompts()
+	conn, connID := readyPromptConnection(t, map[string]interface{}{"roots": map[string]interface{}{}})
+
+	resp, _ := dispatchJSONRPC(conn, connID, &jsonRPCRequest{Jsonrpc: "2.0", ID: 1, Method: "prompts/list"}, toolSet, &config.Config{})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, -32601, resp.Error.Code)
+
+	// No prompts configured: unavailable even when the client advertises the capability
+	conn, connID = readyPromptConnection(t, map[string]interface{}{"prompts": map[string]interface{}{}})
+	resp, _ = dispatchJSONRPC(conn, connID, &jsonRPCRequest{Jsonrpc: "2.0", ID: 1, Method: "prompts/list"}, &mcp.ToolSet{}, &config.Config{})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, -32601, resp.Error.Code)
+}
+
+func TestHandleInitialize_RecordsClientCapabilities(t *testing.T) {
+	connID := uuid.NewString()
+	conn, _ := setupTestConnection(connID)
+	defer cleanupTestConnection(connID)
+
+	req := &jsonRPCRequest{
+		Jsonrpc: "2.0",
+		Method:  "initialize",
+		ID:      1,
+		Params: map[string]interface{}{
+			"protocolVersion": "2025-03-26",
+			"capabilities":    map[string]interface{}{"prompts": map[string]interface{}{}},
+		},
+	}
+	resp := handleInitializeJSONRPC(connID, req, createTestToolSetWithPrompts(), nil)
+	require.Nil(t, resp.Error)
+	assert.Contains(t, conn.ClientCapabilities, "prompts")
+
+	capabilities := resp.Result.(map[string]interface{})["capabilities"].(map[string]interface{})
+	assert.Equal(t, true, capabilities["prompts"].(map[string]interface{})["enabled"])
+}
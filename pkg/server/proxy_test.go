@@ -0,0 +1,122 @@
+package server
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProxy answers every request itself and records the absolute URLs it was asked for.
+type fakeProxy struct {
+	*httptest.Server
+	mu            sync.Mutex
+	requested     []string
+	authorization string
+}
+
+func newFakeProxy(t *testing.T) *fakeProxy {
+	proxy := &fakeProxy{}
+	proxy.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxy.mu.Lock()
+		proxy.requested = append(proxy.requested, r.URL.String())
+		proxy.authorization = r.Header.Get("Proxy-Authorization")
+		proxy.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"via": "proxy"}`))
+	}))
+	t.Cleanup(proxy.Close)
+	return proxy
+}
+
+func callThroughTransport(t *testing.T, baseURL string, cfg *config.Config) string {
+	t.Helper()
+	params := &ToolCallParams{ToolName: "get_report", Input: map[string]interface{}{}}
+	resp, err := executeToolCall(params, phaseTimeoutToolSet(baseURL), cfg)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestExecuteToolCall_UpstreamProxy(t *testing.T) {
+	proxy := newFakeProxy(t)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"via": "direct"}`))
+	}))
+	defer upstream.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+	proxyURL.User = url.UserPassword("svc", "s3cret")
+	upstreamHost := strings.TrimPrefix(upstream.URL, "http://")
+	cfg := &config.Config{UpstreamProxyURL: proxyURL.String(), UpstreamNoProxy: []string{"internal.corp"}}
+
+	// The proxy resolves the host, so it needn't exist
+	assert.JSONEq(t, `{"via": "proxy"}`, callThroughTransport(t, "http://api.example.test/v1", cfg))
+	// Loopback is proxied too unless listed
+	assert.JSONEq(t, `{"via": "proxy"}`, callThroughTransport(t, upstream.URL, cfg))
+
+	proxy.mu.Lock()
+	assert.Equal(t, []string{"http://api.example.test/v1/report", upstream.URL + "/report"}, proxy.requested)
+	assert.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("svc:s3cret")), proxy.authorization)
+	proxy.mu.Unlock()
+
+	// A no-proxy host bypasses it
+	bypass := &config.Config{UpstreamProxyURL: proxy.URL, UpstreamNoProxy: []string{"internal.corp", upstreamHost}}
+	assert.JSONEq(t, `{"via": "direct"}`, callThroughTransport(t, upstream.URL, bypass))
+	proxy.mu.Lock()
+	assert.Len(t, proxy.requested, 2)
+	proxy.mu.Unlock()
+}
+
+func TestBypassesProxy(t *testing.T) {
+	noProxy := []string{"internal.corp", ".svc.local", "10.0.0.0/8", "192.168.1.5", "api.example.com:8443", "[::1]"}
+	tests := []struct {
+		target string
+		bypass bool
+	}{
+		{"http://internal.corp/x", true},
+		{"https://billing.internal.corp/x", true},
+		{"http://notinternal.corp/x", false},
+		{"http://svc.local/x", true},
+		{"http://db.svc.local:5000/x", true},
+		{"http://10.1.2.3/x", true},
+		{"http://11.1.2.3/x", false},
+		{"http://192.168.1.5:8080/x", true},
+		{"https://api.example.com:8443/x", true},
+		{"https://api.example.com/x", false},
+		{"http://[::1]:9000/x", true},
+		{"http://example.org/x", false},
+	}
+	for _, tc := range tests {
+		target, err := url.Parse(tc.target)
+		require.NoError(t, err)
+		assert.Equal(t, tc.bypass, bypassesProxy(target, noProxy), tc.target)
+	}
+
+	target, _ := url.Parse("http://anything.example/")
+	assert.True(t, bypassesProxy(target, []string{"*"}))
+	assert.False(t, bypassesProxy(target, nil))
+}
+
+func TestParseProxyURL(t *testing.T) {
+	for _, valid := range []string{"http://proxy.corp:3128", "https://user:pw@proxy.corp", "socks5://127.0.0.1:1080"} {
+		_, err := ParseProxyURL(valid)
+		assert.NoError(t, err, valid)
+	}
+	for _, invalid := range []string{"ftp://proxy.corp", "proxy.corp:3128", "http://", "::"} {
+		_, err := ParseProxyURL(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
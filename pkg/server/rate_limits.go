@@ -0,0 +1,93 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+)
+
+// defaultRateLimitHeaders are surfaced when no header set is configured: Retry-After plus the
+// de-facto X-RateLimit-* headers and the IETF draft RateLimit-* headers.
+var defaultRateLimitHeaders = []string{
+	"Retry-After",
+	"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset",
+	"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy",
+}
+
+// rateLimitHeaders resolves which upstream headers to surface.
+func rateLimitHeaders(cfg *config.Config) []string {
+	if cfg == nil || cfg.RateLimitHeaders == nil {
+		return defaultRateLimitHeaders
+	}
+	return cfg.RateLimitHeaders
+}
+
+// rateLimitMeta collects the configured rate-limit headers of an upstream response into a tool
+// result _meta object, so the model can pace itself. It returns nil when none are present.
+func rateLimitMeta(httpResp *http.Response, cfg *config.Config) map[string]interface{} {
+	if httpResp == nil {
+		return nil
+	}
+	headers := make(map[string]string)
+	for _, name := range rateLimitHeaders(cfg) {
+		if value := httpResp.Header.Get(name); value != "" {
+			headers[http.CanonicalHeaderKey(name)] = value
+		}
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+	return map[string]interface{}{"rateLimit": headers}
+}
+
+// parseRetryAfter reads a Retry-After value given in seconds or as an HTTP date relative to now.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := date.Sub(now)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay.Round(time.Second), true
+	}
+	return 0, false
+}
+
+// rateLimitedResult is the tool error for an upstream 429. Unlike other API failures it tells the
+// client how long to back off, in the text and as structured error data.
+func rateLimitedResult(toolName string, req *jsonRPCRequest, httpResp *http.Response) ToolResultPayload {
+	message := fmt.Sprintf("Tool '%s' was rate limited by the upstream API (status %s)", toolName, httpResp.Status)
+	var data interface{}
+	if delay, ok := parseRetryAfter(httpResp.Header.Get("Retry-After"), time.Now()); ok {
+		message += fmt.Sprintf("; retry after %s", delay)
+		data = map[string]interface{}{"retryAfterSeconds": int(delay / time.Second)}
+	} else {
+		message += "; slow down before retrying"
+	}
+	log.Printf("[RateLimit] %s", message)
+
+	return ToolResultPayload{
+		IsError: true,
+		Content: []ToolResultContent{{Type: "text", Text: message}},
+		Error: &MCPError{
+			Code:    http.StatusTooManyRequests,
+			Message: message,
+			Data:    data,
+		},
+		ToolCallID: fmt.Sprintf("%v", req.ID),
+	}
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rateLimitTestToolSet(url string) *mcp.ToolSet {
+	return &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"list_items": {Method: "GET", Path: "/items", BaseURL: url},
+	}}
+}
+
+func TestCallToolUpstream_RateLimited(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer backend.Close()
+
+	req := &jsonRPCRequest{ID: 7}
+	params := &ToolCallParams{ToolName: "list_items", Input: map[string]interface{}{}}
+	result := callToolUpstream("conn", req, params, rateLimitTestToolSet(backend.URL), &config.Config{})
+
+	assert.True(t, result.IsError)
+	require.Len(t, result.Content, 1)
+	assert.Contains(t, result.Content[0].Text, "rate limited")
+	assert.Contains(t, result.Content[0].Text, "retry after 30s")
+	require.NotNil(t, result.Error)
+	assert.Equal(t, http.StatusTooManyRequests, result.Error.Code)
+	assert.Equal(t, map[string]interface{}{"retryAfterSeconds": 30}, result.Error.Data)
+	assert.Equal(t, map[string]interface{}{
+		"rateLimit": map[string]string{"Retry-After": "30", "X-Ratelimit-Remaining": "0"},
+		"category":  ErrorCategoryRateLimited,
+		"retryable": true,
+	}, result.Meta)
+}
+
+func TestCallToolUpstream_RateLimitMeta(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "3")
+		w.Header().Set("X-Quota-Left", "12")
+		w.Write([]byte(`{}`))
+	}))
+	defer backend.Close()
+
+	req := &jsonRPCRequest{ID: 1}
+	params := &ToolCallParams{ToolName: "list_items", Input: map[string]interface{}{}}
+
+	result := callToolUpstream("conn", req, params, rateLimitTestToolSet(backend.URL), &config.Config{})
+	assert.False(t, result.IsError)
+	assert.Equal(t, map[string]interface{}{"rateLimit": map[string]string{"X-Ratelimit-Remaining": "3"}}, result.Meta)
+
+	// A configured header set replaces the default one
+	result = callToolUpstream("conn", req, params, rateLimitTestToolSet(backend.URL), &config.Config{RateLimitHeaders: []string{"x-quota-left"}})
+	assert.Equal(t, map[string]interface{}{"rateLimit": map[string]string{"X-Quota-Left": "12"}}, result.Meta)
+
+	result = callToolUpstream("conn", req, params, rateLimitTestToolSet(backend.URL), &config.Config{RateLimitHeaders: []string{"Retry-After"}})
+	assert.Nil(t, result.Meta, "no _meta without rate-limit headers")
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	delay, ok := parseRetryAfter("120", now)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, delay)
+
+	delay, ok = parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now)
+	assert.True(t, ok)
+	assert.Equal(t, 90*time.Second, delay)
+
+	delay, ok = parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now)
+	assert.True(t, ok, "a date in the past means retry now")
+	assert.Zero(t, delay)
+
+	for _, invalid := range []string{"", "-5", "soon"} {
+		_, ok := parseRetryAfter(invalid, now)
+		assert.False(t, ok, invalid)
+	}
+}
+
+func TestRateLimitedResult_WithoutRetryAfter(t *testing.T) {
+	httpResp := &http.Response{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests", Header: http.Header{}}
+	result := rateLimitedResult("list_items", &jsonRPCRequest{ID: 1}, httpResp)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "slow down before retrying")
+	assert.Nil(t, result.Error.Data)
+}
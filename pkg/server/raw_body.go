@@ -0,0 +1,46 @@
+package server
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+)
+
+// defaultMaxRawBodyBytes caps a decoded binary request body when not configured.
+const defaultMaxRawBodyBytes = 2 << 20
+
+// maxRawBodyBytes resolves the binary request body size limit from config, applying the default.
+func maxRawBodyBytes(cfg *config.Config) int64 {
+	if cfg != nil && cfg.MaxRawBodyBytes > 0 {
+		return cfg.MaxRawBodyBytes
+	}
+	return defaultMaxRawBodyBytes
+}
+
+// decodeRawBody decodes the base64 argument of a binary request body. Standard and URL-safe
+// alphabets are accepted, with or without padding. Bodies over limit bytes are rejected.
+func decodeRawBody(value interface{}, limit int64) ([]byte, error) {
+	encoded, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a base64 string, got %T", value)
+	}
+	encoded = strings.TrimSpace(encoded)
+	// Checked before decoding so an oversized body is never allocated
+	if int64(base64.RawStdEncoding.DecodedLen(len(strings.TrimRight(encoded, "=")))) > limit {
+		return nil, fmt.Errorf("decoded body exceeds the maximum size of %d bytes", limit)
+	}
+
+	var firstErr error
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		decoded, err := encoding.DecodeString(encoded)
+		if err == nil {
+			return decoded, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, fmt.Errorf("invalid base64: %w", firstErr)
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteToolCall_RawBody(t *testing.T) {
+	var received []byte
+	var contentType, path string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+		contentType = r.Header.Get("Content-Type")
+		path = r.URL.Path
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"uploadFile": {
+			Method:           "PUT",
+			Path:             "/files/{name}",
+			BaseURL:          backend.URL,
+			Parameters:       []mcp.ParameterDetail{{Name: "name", In: "path"}},
+			RawBodyMediaType: "application/octet-stream",
+		},
+	}}
+	payload := []byte{0x00, 0xff, 0x10, 'P', 'K', 0x03, 0x04}
+	input := map[string]interface{}{
+		"name":              "archive.zip",
+		mcp.RawBodyArgument: base64.StdEncoding.EncodeToString(payload),
+	}
+
+	resp, err := executeToolCall(&ToolCallParams{ToolName: "uploadFile", Input: input}, toolSet, &config.Config{})
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, payload, received, "the upstream receives the decoded bytes")
+	assert.Equal(t, "application/octet-stream", contentType)
+	assert.Equal(t, "/files/archive.zip", path)
+}
+
+func TestExecuteToolCall_RawBodyRejected(t *testing.T) {
+	var calls int
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { calls++ }))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"upload": {Method: "POST", Path: "/upload", BaseURL: backend.URL, RawBodyMediaType: "image/png"},
+	}}
+	call := func(encoded interface{}, cfg *config.Config) error {
+		_, err := executeToolCall(&ToolCallParams{ToolName: "upload", Input: map[string]interface{}{mcp.RawBodyArgument: encoded}}, toolSet, cfg)
+		return err
+	}
+
+	assert.ErrorContains(t, call(base64.StdEncoding.EncodeToString(make([]byte, 16)), &config.Config{MaxRawBodyBytes: 8}), "exceeds the maximum size of 8 bytes")
+	assert.ErrorContains(t, call("not base64!", &config.Config{}), "invalid base64")
+	assert.ErrorContains(t, call(42, &config.Config{}), "expected a base64 string")
+	assert.Zero(t, calls, "rejected bodies never reach the API")
+}
+
+func TestDecodeRawBody_Encodings(t *testing.T) {
+	payload := []byte{0xfb, 0xff, 0xfe, 'a'}
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		decoded, err := decodeRawBody(encoding.EncodeToString(payload), 16)
+		require.NoError(t, err)
+		assert.Equal(t, payload, decoded)
+	}
+}
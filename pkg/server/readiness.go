@@ -0,0 +1,52 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// emptyToolSetReason explains why a server with no tools is not ready.
+const emptyToolSetReason = "the OpenAPI spec produced no tools; check the include/exclude filters, --path-prefix, --allow-method and --read-only"
+
+// warnIfNoTools logs a prominent warning when toolSet has no tools, so a server that clients can
+// connect to but not use is noticed. It reports whether the tool set is empty.
+func warnIfNoTools(toolSet *mcp.ToolSet) bool {
+	if toolSet != nil && len(toolSet.Tools) > 0 {
+		return false
+	}
+	log.Printf("WARNING: ******************************************************************")
+	log.Printf("WARNING: No tools are being served: %s.", emptyToolSetReason)
+	log.Printf("WARNING: Clients can connect, but tools/list is empty and /readyz reports not ready.")
+	log.Printf("WARNING: ******************************************************************")
+	return true
+}
+
+// readyzHandler answers GET /readyz with 200 while tools are served and 503 while the tool set is
+// empty or the server drains, so orchestrators do not route clients to a server they cannot use.
+func readyzHandler(tools *toolSetSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		toolSet := tools.Load()
+		count := 0
+		if toolSet != nil {
+			count = len(toolSet.Tools)
+		}
+		body := map[string]interface{}{"status": "ready", "tools": count}
+		status := http.StatusOK
+		if serverDraining.Load() {
+			status, body = drainingReadiness()
+			body["tools"] = count
+		} else if count == 0 {
+			body["status"] = "not ready"
+			body["reason"] = emptyToolSetReason
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(body); err != nil {
+			log.Printf("Error writing readiness response: %v", err)
+		}
+	}
+}
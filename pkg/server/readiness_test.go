@@ -0,0 +1,69 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getReadyz(t *testing.T, handler http.Handler) (int, map[string]interface{}) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/readyz")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	return resp.StatusCode, body
+}
+
+func TestReadiness_FiltersEliminateEverything(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "spec.json")
+	require.NoError(t, os.WriteFile(specPath, []byte(reloadSpecJSON("listItems", "listOrders")), 0o600))
+	doc, version, err := parser.LoadSwagger(specPath)
+	require.NoError(t, err)
+	cfg := &config.Config{ExcludeOperations: []string{"listItems", "listOrders"}, AllowEmptyToolSet: true}
+	toolSet, err := parser.GenerateToolSet(doc, version, cfg)
+	require.NoError(t, err)
+	require.Empty(t, toolSet.Tools)
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	empty := warnIfNoTools(toolSet)
+	log.SetOutput(os.Stderr)
+	assert.True(t, empty)
+	assert.Contains(t, buf.String(), "WARNING: No tools are being served")
+
+	status, body := getReadyz(t, newMCPMux(toolSet, cfg))
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "not ready", body["status"])
+	assert.Equal(t, float64(0), body["tools"])
+	assert.Contains(t, body["reason"], "no tools")
+}
+
+func TestReadiness_ToolsServed(t *testing.T) {
+	toolSet := createTestToolSetForCall()
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	empty := warnIfNoTools(toolSet)
+	log.SetOutput(os.Stderr)
+	assert.False(t, empty)
+	assert.Empty(t, buf.String())
+
+	status, body := getReadyz(t, newMCPMux(toolSet, &config.Config{}))
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "ready", body["status"])
+	assert.Equal(t, float64(2), body["tools"])
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"log"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+)
+
+// minReapInterval bounds how often the reaper scans connections.
+const minReapInterval = time.Second
+
+// reapWarnings counts warnings logged for connections about to be reaped.
+var reapWarnings atomic.Int64
+
+// reapingEnabled reports whether any connection timeout is configured.
+func reapingEnabled(cfg *config.Config) bool {
+	return cfg != nil && (cfg.ConnectionInitTimeout > 0 || cfg.ConnectionIdleTimeout > 0)
+}
+
+// reapInterval is how often the reaper scans: half the shortest configured timeout, so a
+// connection is reaped at most that much after it expires. With reap warnings it is half the
+// time between the warning and the timeout, so every connection is warned before it is removed.
+func reapInterval(cfg *config.Config) time.Duration {
+	shortest := cfg.ConnectionInitTimeout
+	if shortest <= 0 || (cfg.ConnectionIdleTimeout > 0 && cfg.ConnectionIdleTimeout < shortest) {
+		shortest = cfg.ConnectionIdleTimeout
+	}
+	if validReapWarnFraction(cfg.ConnectionReapWarnFraction) {
+		shortest = time.Duration(float64(shortest) * (1 - cfg.ConnectionReapWarnFraction)).Round(time.Millisecond)
+	}
+	if shortest/2 < minReapInterval {
+		return minReapInterval
+	}
+	return shortest / 2
+}
+
+// validReapWarnFraction reports whether fraction enables reap warnings.
+func validReapWarnFraction(fraction float64) bool {
+	return fraction > 0 && fraction < 1
+}
+
+// reapConnections removes expired connections every reapInterval until stop is closed.
+func reapConnections(cm *ConnectionManager, cfg *config.Config, stop <-chan struct{}) {
+	cm.SetReapWarnFraction(cfg.ConnectionReapWarnFraction)
+	ticker := time.NewTicker(reapInterval(cfg))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			cm.Reap(cfg.ConnectionInitTimeout, cfg.ConnectionIdleTimeout)
+		}
+	}
+}
+
+// SetReapWarnFraction makes Reap log a warning, once per connection, when a connection has used
+// that fraction of its timeout, e.g. 0.8. Values outside (0, 1) disable the warning.
+func (cm *ConnectionManager) SetReapWarnFraction(fraction float64) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	cm.reapWarnFraction = fraction
+}
+
+// Reap removes connections that never finished the initialize handshake within initTimeout of
+// connecting (state Connected or Initializing), and ready connections without activity for
+// idleTimeout. A timeout of 0 or less disables that check. It returns the removed IDs, sorted.
+func (cm *ConnectionManager) Reap(initTimeout, idleTimeout time.Duration) []string {
+	return cm.reapAt(time.Now(), initTimeout, idleTimeout)
+}
+
+// reapAt is Reap relative to the given time.
+func (cm *ConnectionManager) reapAt(now time.Time, initTimeout, idleTimeout time.Duration) []string {
+	defer cm.flushState()
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	var reaped []string
+	for id, conn := range cm.connections {
+		var age, timeout time.Duration
+		var reason string
+		switch conn.State {
+		case StateConnected, StateInitializing:
+			age, timeout = now.Sub(conn.CreatedAt), initTimeout
+			reason = "still " + conn.State.String() + " " + age.Round(time.Second).String() + " after connecting"
+		case StateReady:
+			age, timeout = now.Sub(conn.LastActivity), idleTimeout
+			reason = "idle for " + age.Round(time.Second).String()
+		default:
+			continue
+		}
+		if timeout <= 0 {
+			continue
+		}
+		if age <= timeout {
+			if validReapWarnFraction(cm.reapWarnFraction) && !conn.reapWarned && float64(age) > cm.reapWarnFraction*float64(timeout) {
+				conn.reapWarned = true
+				reapWarnings.Add(1)
+				log.Printf("[ConnectionManager] Warning: %s is %s and will be reaped in %s", id, reason, (timeout - age).Round(time.Second))
+			}
+			continue
+		}
+		log.Printf("[ConnectionManager] Reaping %s: %s", id, reason)
+		cm.deleteLocked(id)
+		conn.State = StateShutdown
+		conn.shutdownChannel()
+		reaped = append(reaped, id)
+	}
+	if len(reaped) == 0 {
+		return nil
+	}
+	cm.persist()
+	sort.Strings(reaped)
+	return reaped
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionManager_ReapStuckInitializing(t *testing.T) {
+	cm := NewConnectionManager()
+	start := time.Now()
+
+	stuck, _ := cm.NewConnection("stuck")
+	cm.UpdateState("stuck", StateInitializing)
+	cm.NewConnection("connected")
+
+	// A ready connection that connected just as long ago but is active
+	cm.NewConnection("ready")
+	cm.UpdateState("ready", StateReady)
+
+	initTimeout, idleTimeout := 30*time.Second, 10*time.Minute
+	assert.Empty(t, cm.reapAt(start.Add(20*time.Second), initTimeout, idleTimeout), "nothing has expired yet")
+
+	cm.Touch("ready")
+	reaped := cm.reapAt(start.Add(time.Minute), initTimeout, idleTimeout)
+	assert.Equal(t, []string{"connected", "stuck"}, reaped)
+	assert.Nil(t, cm.GetConnection("stuck"))
+	assert.Equal(t, StateShutdown, stuck.State)
+	_, open := <-stuck.Channel
+	assert.False(t, open, "the reaped connection's channel is closed")
+	require.NotNil(t, cm.GetConnection("ready"), "a ready connection survives the init timeout")
+
+	// Idle ready connections go on the longer timeout
+	assert.Equal(t, []string{"ready"}, cm.reapAt(start.Add(time.Hour), initTimeout, idleTimeout))
+	assert.Zero(t, cm.GetConnectionCount())
+}
+
+func TestConnectionManager_ReapDisabled(t *testing.T) {
+	cm := NewConnectionManager()
+	cm.NewConnection("stuck")
+	cm.NewConnection("ready")
+	cm.UpdateState("ready", StateReady)
+
+	assert.Empty(t, cm.reapAt(time.Now().Add(24*time.Hour), 0, 0))
+	assert.Equal(t, 2, cm.GetConnectionCount())
+}
+
+func TestReapInterval(t *testing.T) {
+	assert.False(t, reapingEnabled(&config.Config{}))
+	assert.Equal(t, time.Minute, reapInterval(&config.Config{ConnectionInitTimeout: 2 * time.Minute}))
+	assert.Equal(t, 15*time.Second, reapInterval(&config.Config{ConnectionInitTimeout: 2 * time.Minute, ConnectionIdleTimeout: 30 * time.Second}))
+	assert.Equal(t, 5*time.Minute, reapInterval(&config.Config{ConnectionIdleTimeout: 10 * time.Minute}))
+	assert.Equal(t, minReapInterval, reapInterval(&config.Config{ConnectionInitTimeout: time.Millisecond}))
+	assert.Equal(t, time.Minute, reapInterval(&config.Config{ConnectionIdleTimeout: 10 * time.Minute, ConnectionReapWarnFraction: 0.8}), "warnings need a scan between the warning and the timeout")
+}
+
+func TestConnectionManager_ReapWarning(t *testing.T) {
+	cm := NewConnectionManager()
+	cm.SetReapWarnFraction(0.8)
+	start := time.Now()
+	cm.NewConnection("quiet")
+	cm.UpdateState("quiet", StateReady)
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	idleTimeout := 10 * time.Minute
+	warningsBefore := reapWarnings.Load()
+	assert.Empty(t, cm.reapAt(start.Add(7*time.Minute), 0, idleTimeout))
+	assert.NotContains(t, logged.String(), "will be reaped", "no warning before the threshold")
+
+	assert.Empty(t, cm.reapAt(start.Add(9*time.Minute), 0, idleTimeout))
+	assert.NotNil(t, cm.GetConnection("quiet"), "a warned connection is kept")
+	assert.Contains(t, logged.String(), "[ConnectionManager] Warning: quiet is idle for 9m0s and will be reaped in 1m0s")
+	assert.Equal(t, warningsBefore+1, reapWarnings.Load())
+
+	assert.Empty(t, cm.reapAt(start.Add(9*time.Minute+30*time.Second), 0, idleTimeout))
+	assert.Equal(t, 1, strings.Count(logged.String(), "will be reaped"), "each connection is warned once")
+
+	assert.Equal(t, []string{"quiet"}, cm.reapAt(start.Add(11*time.Minute), 0, idleTimeout))
+	assert.Nil(t, cm.GetConnection("quiet"))
+}
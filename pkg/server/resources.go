@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// resourceNotFoundCode is the MCP error code for a resources/read of an unknown URI.
+const resourceNotFoundCode = -32002
+
+// ResourceReadParams are the params of a resources/read request.
+type ResourceReadParams struct {
+	URI string `json:"uri"`
+}
+
+func handleResourcesListJSONRPC(connID string, req *jsonRPCRequest, toolSet *mcp.ToolSet) jsonRPCResponse {
+	log.Printf("Handling 'resources/list' (JSON-RPC) for %s", connID)
+
+	resources := []mcp.Resource{}
+	if toolSet != nil && toolSet.Resources != nil {
+		resources = toolSet.Resources
+	}
+	return jsonRPCResponse{
+		Jsonrpc: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"resources": resources,
+		},
+	}
+}
+
+func handleResourcesReadJSONRPC(connID string, req *jsonRPCRequest, toolSet *mcp.ToolSet) jsonRPCResponse {
+	log.Printf("Handling 'resources/read' (JSON-RPC) for %s", connID)
+
+	var params ResourceReadParams
+	paramsBytes, err := json.Marshal(req.Params)
+	if err == nil {
+		err = decodeJSON(paramsBytes, &params)
+	}
+	if err != nil || params.URI == "" {
+		log.Printf("Invalid resources/read params for %s: %v", connID, err)
+		return createJSONRPCError(req.ID, -32602, "Invalid params: resource uri is required", nil)
+	}
+
+	if toolSet != nil {
+		for _, resource := range toolSet.Resources {
+			if resource.URI != params.URI {
+				continue
+			}
+			return jsonRPCResponse{
+				Jsonrpc: "2.0",
+				ID:      req.ID,
+				Result: map[string]interface{}{
+					"contents": []map[string]interface{}{
+						{"uri": resource.URI, "mimeType": resource.MimeType, "text": resource.Text},
+					},
+				},
+			}
+		}
+	}
+	log.Printf("Unknown resource '%s' requested by %s", params.URI, connID)
+	return createJSONRPCError(req.ID, resourceNotFoundCode, fmt.Sprintf("Resource not found: %s", params.URI), map[string]interface{}{"uri": params.URI})
+}
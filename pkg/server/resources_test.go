@@ -0,0 +1,59 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResources_ListAndRead(t *testing.T) {
+	toolSet := createTestToolSetForCall()
+	toolSet.Resources = []mcp.Resource{{
+		URI:         "openapi://webhooks/orderPaid",
+		Name:        "webhook orderPaid",
+		Description: "Event sent by the API (webhook, not callable): An order was paid",
+		MimeType:    "application/json",
+		Text:        `{"kind": "webhook", "name": "orderPaid"}`,
+	}}
+	s := NewServer(toolSet, &config.Config{})
+
+	resp := dispatchToReadyConnection(t, s, "resources/list", map[string]interface{}{})
+	require.Nil(t, resp.Error)
+	resources := resp.Result.(map[string]interface{})["resources"].([]mcp.Resource)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "openapi://webhooks/orderPaid", resources[0].URI)
+
+	resp = dispatchToReadyConnection(t, s, "resources/read", map[string]interface{}{"uri": "openapi://webhooks/orderPaid"})
+	require.Nil(t, resp.Error)
+	contents := resp.Result.(map[string]interface{})["contents"].([]map[string]interface{})
+	require.Len(t, contents, 1)
+	assert.Equal(t, "application/json", contents[0]["mimeType"])
+	assert.JSONEq(t, `{"kind": "webhook", "name": "orderPaid"}`, contents[0]["text"].(string))
+
+	// Events are not tools
+	resp = dispatchToReadyConnection(t, s, "tools/list", map[string]interface{}{})
+	for _, tool := range resp.Result.(map[string]interface{})["tools"].([]mcp.Tool) {
+		assert.NotEqual(t, "orderPaid", tool.Name)
+	}
+	resp = dispatchToReadyConnection(t, s, "tools/call", map[string]interface{}{"name": "orderPaid"})
+	assert.True(t, resp.Error != nil || resp.Result.(ToolResultPayload).IsError)
+
+	resp = dispatchToReadyConnection(t, s, "resources/read", map[string]interface{}{"uri": "openapi://webhooks/unknown"})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, resourceNotFoundCode, resp.Error.Code)
+
+	resp = dispatchToReadyConnection(t, s, "resources/read", map[string]interface{}{})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, -32602, resp.Error.Code)
+}
+
+func TestResources_EmptyList(t *testing.T) {
+	s := NewServer(createTestToolSetForCall(), &config.Config{})
+
+	resp := dispatchToReadyConnection(t, s, "resources/list", map[string]interface{}{})
+	require.Nil(t, resp.Error)
+	assert.Equal(t, []mcp.Resource{}, resp.Result.(map[string]interface{})["resources"])
+}
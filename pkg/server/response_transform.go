@@ -0,0 +1,688 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// Response transforms reshape a tool's JSON success body with an expression in a restricted
+// subset of JMESPath (https://jmespath.org). Only data selection is supported, so a transform
+// cannot loop, call functions or reach anything but the response:
+//
+//	foo.bar         field access; a quoted "field name" for other keys
+//	foo[0], foo[-1] array index
+//	foo[*].bar      list projection; foo.* projects object values; foo[] flattens one level
+//	{id: id, n: a.b} multiselect hash; [a, b] multiselect list
+//	a | b           pipe, which ends a projection
+//	@, `json`, 'raw' current value, JSON literal, raw string literal
+//
+// Missing fields evaluate to null, and projections drop null results, as in JMESPath.
+
+// transformExpr is a compiled transform expression.
+type transformExpr interface {
+	eval(value interface{}) interface{}
+}
+
+// transformChain applies steps in order. A projection step applies the rest of the chain to each
+// element of its input.
+type transformChain struct {
+	steps []transformStep
+}
+
+// transformStep is one step of a chain. Exactly one of the fields is set.
+type transformStep struct {
+	field    *string                  // Field access
+	index    *int                     // Array index; negative counts from the end
+	project  string                   // "list" for [*], "flatten" for [], "values" for .*
+	hashKeys []string                 // Multiselect hash keys, in source order
+	hash     map[string]transformExpr // Multiselect hash values
+	list     []transformExpr          // Multiselect list
+	literal  *transformLiteral        // Literal value
+	current  bool                     // @
+}
+
+// transformLiteral wraps a literal so a JSON null literal is distinguishable from no literal.
+type transformLiteral struct {
+	value interface{}
+}
+
+// transformPipe evaluates right against the result of left.
+type transformPipe struct {
+	left, right transformExpr
+}
+
+func (p *transformPipe) eval(value interface{}) interface{} {
+	return p.right.eval(p.left.eval(value))
+}
+
+func (c *transformChain) eval(value interface{}) interface{} {
+	return evalSteps(c.steps, value)
+}
+
+// evalSteps applies steps to value, handling projections.
+func evalSteps(steps []transformStep, value interface{}) interface{} {
+	for i, step := range steps {
+		if step.project == "" {
+			value = step.apply(value)
+			continue
+		}
+		var elements []interface{}
+		switch step.project {
+		case "list":
+			list, ok := value.([]interface{})
+			if !ok {
+				return nil
+			}
+			elements = list
+		case "flatten":
+			list, ok := value.([]interface{})
+			if !ok {
+				return nil
+			}
+			for _, element := range list {
+				if inner, ok := element.([]interface{}); ok {
+					elements = append(elements, inner...)
+				} else {
+					elements = append(elements, element)
+				}
+			}
+		case "values":
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			keys := make([]string, 0, len(object))
+			for key := range object {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys) // Objects are unordered; sorting keeps the output stable
+			for _, key := range keys {
+				elements = append(elements, object[key])
+			}
+		}
+		projected := make([]interface{}, 0, len(elements))
+		for _, element := range elements {
+			if result := evalSteps(steps[i+1:], element); result != nil {
+				projected = append(projected, result)
+			}
+		}
+		return projected
+	}
+	return value
+}
+
+// apply evaluates a non-projection step.
+func (s transformStep) apply(value interface{}) interface{} {
+	switch {
+	case s.current:
+		return value
+	case s.literal != nil:
+		return s.literal.value
+	case s.field != nil:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		return object[*s.field]
+	case s.index != nil:
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil
+		}
+		i := *s.index
+		if i < 0 {
+			i += len(list)
+		}
+		if i < 0 || i >= len(list) {
+			return nil
+		}
+		return list[i]
+	case s.hash != nil:
+		if value == nil {
+			return nil
+		}
+		result := make(map[string]interface{}, len(s.hash))
+		for key, expr := range s.hash {
+			result[key] = expr.eval(value)
+		}
+		return result
+	case s.list != nil:
+		if value == nil {
+			return nil
+		}
+		result := make([]interface{}, len(s.list))
+		for i, expr := range s.list {
+			result[i] = expr.eval(value)
+		}
+		return result
+	}
+	return nil
+}
+
+// compileTransform parses a transform expression.
+func compileTransform(expression string) (transformExpr, error) {
+	p := &transformParser{input: expression}
+	p.skipSpace()
+	if p.done() {
+		return nil, fmt.Errorf("empty expression")
+	}
+	expr, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, p.errorf("unexpected %q", string(p.peek()))
+	}
+	return expr, nil
+}
+
+// transformParser is a recursive-descent parser over the expression text.
+type transformParser struct {
+	input string
+	pos   int
+}
+
+func (p *transformParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *transformParser) done() bool { return p.pos >= len(p.input) }
+
+func (p *transformParser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *transformParser) skipSpace() {
+	for !p.done() && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+// accept consumes c, after any whitespace, if it is next.
+func (p *transformParser) accept(c byte) bool {
+	p.skipSpace()
+	if p.peek() == c {
+		p.pos++
+		p.skipSpace()
+		return true
+	}
+	return false
+}
+
+func (p *transformParser) expect(c byte) error {
+	if !p.accept(c) {
+		if p.done() {
+			return p.errorf("expected %q, got end of expression", string(c))
+		}
+		return p.errorf("expected %q, got %q", string(c), string(p.peek()))
+	}
+	return nil
+}
+
+func (p *transformParser) parsePipe() (transformExpr, error) {
+	left, err := p.parseChain()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept('|') {
+		right, err := p.parseChain()
+		if err != nil {
+			return nil, err
+		}
+		left = &transformPipe{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *transformParser) parseChain() (transformExpr, error) {
+	p.skipSpace()
+	first, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	chain := &transformChain{steps: first}
+	for {
+		p.skipSpace()
+		switch p.peek() {
+		case '.':
+			p.pos++
+			p.skipSpace()
+			step, err := p.parseDotted()
+			if err != nil {
+				return nil, err
+			}
+			chain.steps = append(chain.steps, step)
+		case '[':
+			step, err := p.parseBracket(false)
+			if err != nil {
+				return nil, err
+			}
+			chain.steps = append(chain.steps, step)
+		default:
+			return chain, nil
+		}
+	}
+}
+
+// parsePrimary parses the start of a chain.
+func (p *transformParser) parsePrimary() ([]transformStep, error) {
+	switch c := p.peek(); {
+	case c == '@':
+		p.pos++
+		return []transformStep{{current: true}}, nil
+	case c == '`':
+		value, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		return []transformStep{{literal: &transformLiteral{value: value}}}, nil
+	case c == '\'':
+		text, err := p.parseRawString()
+		if err != nil {
+			return nil, err
+		}
+		return []transformStep{{literal: &transformLiteral{value: text}}}, nil
+	case c == '{':
+		step, err := p.parseHash()
+		if err != nil {
+			return nil, err
+		}
+		return []transformStep{step}, nil
+	case c == '[':
+		step, err := p.parseBracket(true)
+		if err != nil {
+			return nil, err
+		}
+		return []transformStep{step}, nil
+	case c == '*':
+		p.pos++
+		return []transformStep{{project: "values"}}, nil
+	default:
+		name, err := p.parseIdentifier()
+		if err != nil {
+			return nil, err
+		}
+		return []transformStep{{field: &name}}, nil
+	}
+}
+
+// parseDotted parses what follows a ".": a field, a multiselect or a value projection.
+func (p *transformParser) parseDotted() (transformStep, error) {
+	switch p.peek() {
+	case '*':
+		p.pos++
+		return transformStep{project: "values"}, nil
+	case '{':
+		return p.parseHash()
+	case '[':
+		list, err := p.parseMultiselectList()
+		return transformStep{list: list}, err
+	}
+	name, err := p.parseIdentifier()
+	return transformStep{field: &name}, err
+}
+
+// parseBracket parses an index, [*], [] or, at the start of an expression, a multiselect list.
+func (p *transformParser) parseBracket(allowList bool) (transformStep, error) {
+	start := p.pos
+	p.pos++ // [
+	p.skipSpace()
+	switch c := p.peek(); {
+	case c == ']':
+		p.pos++
+		return transformStep{project: "flatten"}, nil
+	case c == '*':
+		p.pos++
+		if err := p.expect(']'); err != nil {
+			return transformStep{}, err
+		}
+		return transformStep{project: "list"}, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		digits := p.pos
+		p.pos++
+		for !p.done() && p.peek() >= '0' && p.peek() <= '9' {
+			p.pos++
+		}
+		index, err := strconv.Atoi(p.input[digits:p.pos])
+		if err != nil {
+			return transformStep{}, p.errorf("invalid index %q", p.input[digits:p.pos])
+		}
+		if err := p.expect(']'); err != nil {
+			return transformStep{}, err
+		}
+		return transformStep{index: &index}, nil
+	case c == ':' || c == '?':
+		return transformStep{}, p.errorf("slices and filters are not supported")
+	}
+	if !allowList {
+		return transformStep{}, p.errorf("expected an index, '*' or ']'; use .[a, b] for a multiselect list")
+	}
+	p.pos = start
+	list, err := p.parseMultiselectList()
+	return transformStep{list: list}, err
+}
+
+func (p *transformParser) parseMultiselectList() ([]transformExpr, error) {
+	if err := p.expect('['); err != nil {
+		return nil, err
+	}
+	var list []transformExpr
+	for {
+		expr, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, expr)
+		if !p.accept(',') {
+			break
+		}
+	}
+	if err := p.expect(']'); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (p *transformParser) parseHash() (transformStep, error) {
+	if err := p.expect('{'); err != nil {
+		return transformStep{}, err
+	}
+	step := transformStep{hash: make(map[string]transformExpr)}
+	for {
+		key, err := p.parseIdentifier()
+		if err != nil {
+			return transformStep{}, err
+		}
+		if _, duplicate := step.hash[key]; duplicate {
+			return transformStep{}, p.errorf("duplicate key %q", key)
+		}
+		if err := p.expect(':'); err != nil {
+			return transformStep{}, err
+		}
+		expr, err := p.parsePipe()
+		if err != nil {
+			return transformStep{}, err
+		}
+		step.hashKeys = append(step.hashKeys, key)
+		step.hash[key] = expr
+		if !p.accept(',') {
+			break
+		}
+	}
+	if err := p.expect('}'); err != nil {
+		return transformStep{}, err
+	}
+	return step, nil
+}
+
+// parseIdentifier parses a bare identifier or a JSON-quoted one.
+func (p *transformParser) parseIdentifier() (string, error) {
+	p.skipSpace()
+	if p.peek() == '"' {
+		end := p.pos + 1
+		for end < len(p.input) && p.input[end] != '"' {
+			if p.input[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.input) {
+			return "", p.errorf("unterminated quoted identifier")
+		}
+		var name string
+		if err := json.Unmarshal([]byte(p.input[p.pos:end+1]), &name); err != nil {
+			return "", p.errorf("invalid quoted identifier: %v", err)
+		}
+		p.pos = end + 1
+		return name, nil
+	}
+	start := p.pos
+	for !p.done() {
+		c := p.peek()
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (p.pos > start && c >= '0' && c <= '9') {
+			p.pos++
+			continue
+		}
+		break
+	}
+	if p.pos == start {
+		if p.done() {
+			return "", p.errorf("expected a field name, got end of expression")
+		}
+		if p.peek() == '(' || p.peek() == '&' {
+			return "", p.errorf("functions are not supported")
+		}
+		return "", p.errorf("expected a field name, got %q", string(p.peek()))
+	}
+	name := p.input[start:p.pos]
+	if p.accept('(') {
+		return "", p.errorf("functions are not supported")
+	}
+	return name, nil
+}
+
+// parseLiteral parses a `json` literal.
+func (p *transformParser) parseLiteral() (interface{}, error) {
+	end := strings.IndexByte(p.input[p.pos+1:], '`')
+	if end < 0 {
+		return nil, p.errorf("unterminated literal")
+	}
+	var value interface{}
+	if err := decodeJSON([]byte(p.input[p.pos+1:p.pos+1+end]), &value); err != nil {
+		return nil, p.errorf("invalid JSON literal: %v", err)
+	}
+	p.pos += end + 2
+	return value, nil
+}
+
+// parseRawString parses a 'raw string' literal.
+func (p *transformParser) parseRawString() (string, error) {
+	var text strings.Builder
+	for i := p.pos + 1; i < len(p.input); i++ {
+		switch c := p.input[i]; {
+		case c == '\\' && i+1 < len(p.input) && (p.input[i+1] == '\'' || p.input[i+1] == '\\'):
+			text.WriteByte(p.input[i+1])
+			i++
+		case c == '\'':
+			p.pos = i + 1
+			return text.String(), nil
+		default:
+			text.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated raw string")
+}
+
+// checkTransformSchema checks the fields and indexes expr selects against the response schema,
+// so a transform naming a field the response does not have fails at startup. Parts of the
+// response the schema leaves open (no properties, additionalProperties, unknown type) are not
+// checked.
+func checkTransformSchema(expr transformExpr, schema *mcp.Schema) error {
+	_, err := transformResultSchema(expr, schema)
+	return err
+}
+
+// transformResultSchema checks expr against schema and returns the schema of its result when it
+// is known, or nil.
+func transformResultSchema(expr transformExpr, schema *mcp.Schema) (*mcp.Schema, error) {
+	switch e := expr.(type) {
+	case *transformPipe:
+		left, err := transformResultSchema(e.left, schema)
+		if err != nil {
+			return nil, err
+		}
+		return transformResultSchema(e.right, left)
+	case *transformChain:
+		return stepsResultSchema(e.steps, schema)
+	}
+	return nil, nil
+}
+
+func stepsResultSchema(steps []transformStep, schema *mcp.Schema) (*mcp.Schema, error) {
+	for i, step := range steps {
+		if schema == nil {
+			return nil, nil
+		}
+		switch {
+		case step.field != nil:
+			if schema.Type != "" && schema.Type != "object" {
+				return nil, fmt.Errorf("field %q is selected from a value of type %s", *step.field, schema.Type)
+			}
+			property, ok := schema.Properties[*step.field]
+			if !ok {
+				if len(schema.Properties) > 0 && (schema.AdditionalProperties == nil || !*schema.AdditionalProperties) {
+					return nil, fmt.Errorf("field %q is not in the response schema", *step.field)
+				}
+				return nil, nil
+			}
+			schema = &property
+		case step.index != nil || step.project == "list" || step.project == "flatten":
+			if schema.Type != "" && schema.Type != "array" {
+				return nil, fmt.Errorf("a value of type %s is indexed as an array", schema.Type)
+			}
+			if step.project == "flatten" {
+				return nil, nil // Elements may or may not be arrays themselves
+			}
+			schema = schema.Items
+			if step.project == "list" {
+				_, err := stepsResultSchema(steps[i+1:], schema)
+				return nil, err
+			}
+		case step.project == "values":
+			return nil, nil
+		case step.hash != nil:
+			for _, key := range step.hashKeys {
+				if _, err := transformResultSchema(step.hash[key], schema); err != nil {
+					return nil, err
+				}
+			}
+			return nil, nil
+		case step.list != nil:
+			for _, expr := range step.list {
+				if _, err := transformResultSchema(expr, schema); err != nil {
+					return nil, err
+				}
+			}
+			return nil, nil
+		case step.literal != nil:
+			return nil, nil
+		}
+	}
+	return schema, nil
+}
+
+// successResponseSchema returns the schema of an operation's 200 response, else of its lowest
+// other 2xx response, or nil.
+func successResponseSchema(operation mcp.OperationDetail) *mcp.Schema {
+	if schema, ok := operation.Responses["200"]; ok {
+		return &schema
+	}
+	var statuses []string
+	for status := range operation.Responses {
+		if len(status) == 3 && status[0] == '2' {
+			statuses = append(statuses, status)
+		}
+	}
+	if len(statuses) == 0 {
+		return nil
+	}
+	sort.Strings(statuses)
+	schema := operation.Responses[statuses[0]]
+	return &schema
+}
+
+// CompileResponseTransforms compiles the configured response transforms and checks each against
+// its tool's success response schema, trimmed by the tool's response projection since transforms
+// run on the projected body. Call it at startup: an error names the tool and the problem, and a
+// transform for a tool that does not exist is an error too. Compiled transforms are cached for
+// tool calls.
+func CompileResponseTransforms(toolSet *mcp.ToolSet, cfg *config.Config) error {
+	compiled := make(map[string]transformExpr, len(cfg.ResponseTransforms))
+	for toolName, expression := range cfg.ResponseTransforms {
+		operation, ok := toolSet.Operations[toolName]
+		if !ok {
+			return fmt.Errorf("response transform for unknown tool '%s'", toolName)
+		}
+		expr, err := compileTransform(expression)
+		if err != nil {
+			return fmt.Errorf("invalid response transform for tool '%s': %w", toolName, err)
+		}
+		schema := successResponseSchema(operation)
+		if fields := cfg.ResponseProjections[toolName]; schema != nil && len(fields) > 0 {
+			projected := compileProjection(fields).projectSchema(*schema)
+			schema = &projected
+		}
+		if err := checkTransformSchema(expr, schema); err != nil {
+			return fmt.Errorf("invalid response transform for tool '%s': %w", toolName, err)
+		}
+		compiled[toolName] = expr
+	}
+	compiledTransforms.Lock()
+	defer compiledTransforms.Unlock()
+	compiledTransforms.byExpression = make(map[string]transformExpr, len(compiled))
+	for toolName, expr := range compiled {
+		compiledTransforms.byExpression[cfg.ResponseTransforms[toolName]] = expr
+	}
+	return nil
+}
+
+// compiledTransforms caches compiled transforms by expression text.
+var compiledTransforms struct {
+	sync.Mutex
+	byExpression map[string]transformExpr
+}
+
+// responseTransform returns the compiled transform for expression, compiling it on first use.
+func responseTransform(expression string) (transformExpr, error) {
+	compiledTransforms.Lock()
+	defer compiledTransforms.Unlock()
+	if expr, ok := compiledTransforms.byExpression[expression]; ok {
+		return expr, nil
+	}
+	expr, err := compileTransform(expression)
+	if err != nil {
+		return nil, err
+	}
+	if compiledTransforms.byExpression == nil {
+		compiledTransforms.byExpression = make(map[string]transformExpr)
+	}
+	compiledTransforms.byExpression[expression] = expr
+	return expr, nil
+}
+
+// transformResponseBody reshapes a JSON success body with the tool's configured transform. Bodies
+// of tools without a transform, and bodies that are not JSON, are returned unchanged.
+func transformResponseBody(toolName string, body []byte, cfg *config.Config) []byte {
+	if cfg == nil || cfg.ResponseTransforms[toolName] == "" {
+		return body
+	}
+	expr, err := responseTransform(cfg.ResponseTransforms[toolName])
+	if err != nil {
+		log.Printf("[ResponseTransform] Invalid transform for tool '%s', returning the response untransformed: %v", toolName, err)
+		return body
+	}
+	var decoded interface{}
+	if err := decodeJSON(body, &decoded); err != nil {
+		log.Printf("[ResponseTransform] Response for tool '%s' is not JSON, returning it untransformed", toolName)
+		return body
+	}
+	transformed, err := json.Marshal(expr.eval(decoded))
+	if err != nil {
+		log.Printf("[ResponseTransform] Could not encode transformed response for tool '%s': %v", toolName, err)
+		return body
+	}
+	log.Printf("[ResponseTransform] Transformed response for tool '%s' from %d to %d bytes", toolName, len(body), len(transformed))
+	return transformed
+}
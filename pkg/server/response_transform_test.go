@@ -0,0 +1,173 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileTransform_Eval(t *testing.T) {
+	const body = `{
+		"id": 7,
+		"profile": {"name": "Ada", "tags": ["admin", "ops"]},
+		"address": {"city": "London", "street": "1 Main St"},
+		"orders": [{"sku": "A", "qty": 1}, {"sku": "B", "qty": 2}, {"qty": 3}],
+		"groups": [[1, 2], [3], 4],
+		"counts": {"b": 2, "a": 1},
+		"odd key": true
+	}`
+	tests := []struct {
+		name       string
+		expression string
+		expected   string
+	}{
+		{"Field path", "profile.name", `"Ada"`},
+		{"Missing field is null", "profile.email", `null`},
+		{"Field of a non-object is null", "id.value", `null`},
+		{"Index", "profile.tags[0]", `"admin"`},
+		{"Negative index", "profile.tags[-1]", `"ops"`},
+		{"Index out of range", "profile.tags[5]", `null`},
+		{"List projection drops nulls", "orders[*].sku", `["A", "B"]`},
+		{"Flatten", "groups[]", `[1, 2, 3, 4]`},
+		{"Value projection in key order", "counts.*", `[1, 2]`},
+		{"Quoted identifier", `"odd key"`, `true`},
+		{"Multiselect list", "[id, profile.name]", `[7, "Ada"]`},
+		{"Multiselect hash per element", "orders[*].{s: sku, q: qty}", `[{"s": "A", "q": 1}, {"s": "B", "q": 2}, {"s": null, "q": 3}]`},
+		{"Pipe ends a projection", "orders[*].qty | [0]", `1`},
+		{"Literals", "{n: `42`, s: 'text', c: @.id}", `{"n": 42, "s": "text", "c": 7}`},
+	}
+	var decoded interface{}
+	require.NoError(t, decodeJSON([]byte(body), &decoded))
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := compileTransform(tc.expression)
+			require.NoError(t, err)
+			result, err := json.Marshal(expr.eval(decoded))
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(result))
+		})
+	}
+}
+
+func TestCompileTransform_Errors(t *testing.T) {
+	tests := map[string]string{
+		"Empty":               "  ",
+		"Unclosed hash":       "{id: id",
+		"Missing hash value":  "{id}",
+		"Duplicate hash key":  "{a: id, a: name}",
+		"Trailing dot":        "profile.",
+		"Function call":       "length(orders)",
+		"Filter":              "orders[?qty > `1`]",
+		"Slice":               "orders[:2]",
+		"Unterminated string": "'abc",
+		"Bad literal":         "`{nope`",
+		"Trailing input":      "id id",
+	}
+	for name, expression := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := compileTransform(expression)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func userResponseToolSet(baseURL string) *mcp.ToolSet {
+	return &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"get_user": {Method: "GET", Path: "/user", BaseURL: baseURL, Responses: map[string]mcp.Schema{
+			"200": {Type: "object", Properties: map[string]mcp.Schema{
+				"id":      {Type: "integer"},
+				"profile": {Type: "object", Properties: map[string]mcp.Schema{"name": {Type: "string"}}},
+				"address": {Type: "object", Properties: map[string]mcp.Schema{"city": {Type: "string"}}},
+				"roles":   {Type: "array", Items: &mcp.Schema{Type: "object", Properties: map[string]mcp.Schema{"name": {Type: "string"}}}},
+				"extra":   {Type: "object"},
+			}},
+		}},
+	}}
+}
+
+func TestCompileResponseTransforms(t *testing.T) {
+	toolSet := userResponseToolSet("http://unused")
+
+	valid := &config.Config{ResponseTransforms: map[string]string{
+		"get_user": "{id: id, name: profile.name, roles: roles[*].name, x: extra.anything}",
+	}}
+	assert.NoError(t, CompileResponseTransforms(toolSet, valid))
+
+	tests := map[string]struct {
+		expression string
+		message    string
+	}{
+		"Unknown top-level field": {"{id: id, mail: email}", `field "email" is not in the response schema`},
+		"Unknown nested field":    {"profile.nickname", `field "nickname" is not in the response schema`},
+		"Unknown field in items":  {"roles[*].title", `field "title" is not in the response schema`},
+		"Indexing an object":      {"profile[0]", "indexed as an array"},
+		"Field of a scalar":       {"id.value", "selected from a value of type integer"},
+		"Syntax error":            {"{id: id", "invalid response transform for tool 'get_user'"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := &config.Config{ResponseTransforms: map[string]string{"get_user": tc.expression}}
+			assert.ErrorContains(t, CompileResponseTransforms(toolSet, cfg), tc.message)
+		})
+	}
+
+	cfg := &config.Config{ResponseTransforms: map[string]string{"no_such_tool": "id"}}
+	assert.ErrorContains(t, CompileResponseTransforms(toolSet, cfg), "unknown tool 'no_such_tool'")
+
+	// Transforms run on the projected body, so they are checked against the projected schema
+	projected := &config.Config{
+		ResponseProjections: map[string][]string{"get_user": {"id", "/profile/name", "roles"}},
+		ResponseTransforms:  map[string]string{"get_user": "{id: id, name: profile.name, roles: roles[*].name}"},
+	}
+	assert.NoError(t, CompileResponseTransforms(toolSet, projected))
+	projected.ResponseTransforms["get_user"] = "address.city"
+	assert.ErrorContains(t, CompileResponseTransforms(toolSet, projected), `field "address" is not in the response schema`)
+	projected.ResponseTransforms["get_user"] = "extra.anything"
+	assert.ErrorContains(t, CompileResponseTransforms(toolSet, projected), `field "extra" is not in the response schema`)
+
+	// Without a response schema only the syntax is checked
+	noSchema := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{"get_user": {Method: "GET", Path: "/user"}}}
+	cfg = &config.Config{ResponseTransforms: map[string]string{"get_user": "anything.goes[*]"}}
+	assert.NoError(t, CompileResponseTransforms(noSchema, cfg))
+}
+
+func TestToolCall_ResponseTransform(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 7, "profile": {"name": "Ada", "bio": "long"}, "address": {"city": "London", "street": "1 Main St"}, "roles": [{"name": "admin"}, {"name": "ops"}]}`))
+	}))
+	defer backend.Close()
+
+	toolSet := userResponseToolSet(backend.URL)
+	cfg := &config.Config{
+		DisableInputValidation: true,
+		ResponseTransforms: map[string]string{
+			"get_user": "{id: id, name: profile.name, city: address.city, roles: roles[*].name}",
+		},
+	}
+	require.NoError(t, CompileResponseTransforms(toolSet, cfg))
+
+	params, _ := json.Marshal(ToolCallParams{ToolName: "get_user", Input: map[string]interface{}{}})
+	req := &jsonRPCRequest{Jsonrpc: "2.0", Method: "tools/call", Params: json.RawMessage(params), ID: "transform-1"}
+	resp := handleToolCallJSONRPC("test-conn", req, toolSet, cfg)
+
+	result, ok := resp.Result.(ToolResultPayload)
+	require.True(t, ok)
+	require.False(t, result.IsError)
+	assert.JSONEq(t, `{"id": 7, "name": "Ada", "city": "London", "roles": ["admin", "ops"]}`, result.Content[0].Text)
+}
+
+func TestTransformResponseBody_Unchanged(t *testing.T) {
+	body := []byte(`{"id": 7}`)
+	assert.Equal(t, body, transformResponseBody("get_user", body, &config.Config{}))
+
+	cfg := &config.Config{ResponseTransforms: map[string]string{"get_user": "id"}}
+	assert.Equal(t, []byte("plain text"), transformResponseBody("get_user", []byte("plain text"), cfg))
+	assert.Equal(t, []byte("7"), transformResponseBody("get_user", body, cfg))
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// securityCredential is a configured credential chosen to satisfy one scheme of a requirement.
+type securityCredential struct {
+	schemeName string
+	scheme     mcp.SecurityScheme
+	value      string
+}
+
+// errUnsatisfiedSecurity reports that none of a tool's security requirement alternatives can be
+// met with the configured credentials, naming the schemes each alternative is missing.
+type errUnsatisfiedSecurity struct {
+	toolName string
+	missing  []string
+}
+
+func (e *errUnsatisfiedSecurity) Error() string {
+	return fmt.Sprintf("no security requirement of tool '%s' can be satisfied with the configured credentials: missing %s", e.toolName, strings.Join(e.missing, ", or "))
+}
+
+// resolveSecurity picks the first of the operation's security requirement alternatives (OR) whose
+// schemes (AND) can all be satisfied, and returns the credentials to inject for it. Schemes already
+// covered by the server API key or a custom header need no credential. Operations without
+// requirements, or with an empty alternative, need nothing. When no alternative can be satisfied,
+// the error is an *errUnsatisfiedSecurity naming the schemes each one is missing.
+func resolveSecurity(toolName string, operation mcp.OperationDetail, toolSet *mcp.ToolSet, cfg *config.Config, serverKey string) ([]securityCredential, error) {
+	if len(operation.Security) == 0 {
+		return nil, nil
+	}
+	covered := customHeaderNames(cfg)
+
+	var missingPerAlternative []string
+	for _, requirement := range operation.Security {
+		schemeNames := make([]string, 0, len(requirement))
+		for name := range requirement {
+			schemeNames = append(schemeNames, name)
+		}
+		sort.Strings(schemeNames)
+
+		var credentials []securityCredential
+		var missing []string
+		for _, name := range schemeNames {
+			scheme, known := toolSet.SecuritySchemes[name]
+			switch {
+			case !known:
+				missing = append(missing, name)
+			case coveredByServerKey(scheme, cfg, serverKey), coveredByCustomHeader(scheme, covered):
+				// Sent by the existing injection, nothing to add
+			default:
+				value := cfg.GetSecurityCredential(name)
+				if value == "" {
+					missing = append(missing, name)
+					continue
+				}
+				credentials = append(credentials, securityCredential{schemeName: name, scheme: scheme, value: value})
+			}
+		}
+		if len(missing) == 0 {
+			if len(schemeNames) > 0 {
+				log.Printf("[Security] Tool '%s' satisfies security requirement %s", toolName, strings.Join(schemeNames, " + "))
+			}
+			return credentials, nil
+		}
+		missingPerAlternative = append(missingPerAlternative, strings.Join(missing, " and "))
+	}
+	return nil, &errUnsatisfiedSecurity{toolName: toolName, missing: missingPerAlternative}
+}
+
+// coveredByServerKey reports whether the --api-key settings already send scheme's credential.
+func coveredByServerKey(scheme mcp.SecurityScheme, cfg *config.Config, serverKey string) bool {
+	return scheme.Type == "apiKey" && serverKey != "" &&
+		strings.EqualFold(scheme.Name, cfg.APIKeyName) && scheme.In == string(cfg.APIKeyLocation)
+}
+
+// coveredByCustomHeader reports whether a configured custom header already carries scheme's credential.
+func coveredByCustomHeader(scheme mcp.SecurityScheme, headers map[string]bool) bool {
+	switch scheme.Type {
+	case "apiKey":
+		return scheme.In == "header" && headers[http.CanonicalHeaderKey(scheme.Name)]
+	case "http", "oauth2", "openIdConnect":
+		return headers["Authorization"]
+	}
+	return false
+}
+
+// customHeaderNames returns the canonical names of the headers set with --custom-headers.
+func customHeaderNames(cfg *config.Config) map[string]bool {
+	names := make(map[string]bool)
+	for _, h := range strings.Split(cfg.CustomHeaders, ",") {
+		if name, _, ok := strings.Cut(h, ":"); ok && strings.TrimSpace(name) != "" {
+			names[http.CanonicalHeaderKey(strings.TrimSpace(name))] = true
+		}
+	}
+	return names
+}
+
+// redactedHeaders returns a copy of header that is safe to log: the values of headers that can
+// carry credentials are replaced. These are Authorization, Proxy-Authorization and Cookie, the
+// server API key header, the headers of apiKey schemes, custom headers and sensitive session
+// headers.
+func redactedHeaders(header http.Header, toolSet *mcp.ToolSet, cfg *config.Config) http.Header {
+	secret := customHeaderNames(cfg)
+	for _, name := range []string{"Authorization", "Proxy-Authorization", "Cookie"} {
+		secret[name] = true
+	}
+	if cfg.APIKeyLocation == config.APIKeyLocationHeader && cfg.APIKeyName != "" {
+		secret[http.CanonicalHeaderKey(cfg.APIKeyName)] = true
+	}
+	for _, scheme := range toolSet.SecuritySchemes {
+		if scheme.Type == "apiKey" && scheme.In == "header" {
+			secret[http.CanonicalHeaderKey(scheme.Name)] = true
+		}
+	}
+	for _, sessionHeader := range cfg.SessionHeaders {
+		if sessionHeader.Sensitive {
+			secret[http.CanonicalHeaderKey(sessionHeader.Header)] = true
+		}
+	}
+
+	redacted := make(http.Header, len(header))
+	for name, values := range header {
+		if secret[http.CanonicalHeaderKey(name)] {
+			redacted[name] = []string{"[REDACTED]"}
+		} else {
+			redacted[name] = values
+		}
+	}
+	return redacted
+}
+
+// injectSecurityCredential adds one credential to the outgoing request parts, replacing any value
+// the client supplied under the same name.
+func injectSecurityCredential(cred securityCredential, queryParams url.Values, headerParams http.Header, cookieParams []*http.Cookie) []*http.Cookie {
+	switch cred.scheme.Type {
+	case "apiKey":
+		switch cred.scheme.In {
+		case "query":
+			queryParams.Set(cred.scheme.Name, cred.value)
+		case "header":
+			headerParams.Set(cred.scheme.Name, cred.value)
+		case "cookie":
+			for i, c := range cookieParams {
+				if c.Name == cred.scheme.Name {
+					cookieParams[i] = &http.Cookie{Name: c.Name, Value: cred.value}
+					return cookieParams
+				}
+			}
+			cookieParams = append(cookieParams, &http.Cookie{Name: cred.scheme.Name, Value: cred.value})
+		default:
+			log.Printf("[Security] Warning: Unsupported location '%s' for API key scheme '%s'", cred.scheme.In, cred.schemeName)
+			return cookieParams
+		}
+	case "http":
+		switch cred.scheme.Scheme {
+		case "basic":
+			headerParams.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(cred.value)))
+		case "bearer", "":
+			headerParams.Set("Authorization", "Bearer "+cred.value)
+		default:
+			headerParams.Set("Authorization", cred.scheme.Scheme+" "+cred.value)
+		}
+	case "oauth2", "openIdConnect":
+		headerParams.Set("Authorization", "Bearer "+cred.value)
+	default:
+		log.Printf("[Security] Warning: Unsupported type '%s' for security scheme '%s'", cred.scheme.Type, cred.schemeName)
+		return cookieParams
+	}
+	log.Printf("[Security] Injected credential for scheme '%s' (%s)", cred.schemeName, cred.scheme.Type)
+	return cookieParams
+}
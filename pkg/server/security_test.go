@@ -0,0 +1,172 @@
+package server
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// securityToolSet builds a single-tool toolset with the given requirements against backendURL.
+func securityToolSet(backendURL string, security []map[string][]string) *mcp.ToolSet {
+	return &mcp.ToolSet{
+		Operations: map[string]mcp.OperationDetail{
+			"listPets": {Method: "GET", Path: "/pets", BaseURL: backendURL, Security: security},
+		},
+		SecuritySchemes: map[string]mcp.SecurityScheme{
+			"apiKey":   {Type: "apiKey", Name: "X-API-Key", In: "header"},
+			"queryKey": {Type: "apiKey", Name: "key", In: "query"},
+			"oauth":    {Type: "oauth2"},
+			"basic":    {Type: "http", Scheme: "basic"},
+		},
+	}
+}
+
+func TestExecuteToolCall_SecurityRequirements(t *testing.T) {
+	var received *http.Request
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		w.Write([]byte(`{}`))
+	}))
+	defer backend.Close()
+	call := &ToolCallParams{ToolName: "listPets", Input: map[string]interface{}{}}
+
+	t.Run("AND requirement injects every scheme", func(t *testing.T) {
+		toolSet := securityToolSet(backend.URL, []map[string][]string{{"apiKey": {}, "oauth": {"pets:read"}}})
+		cfg := &config.Config{SecurityCredentials: map[string]string{"apiKey": "k-123", "oauth": "tok-456"}}
+
+		resp, err := executeToolCall(call, toolSet, cfg)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, "k-123", received.Header.Get("X-API-Key"))
+		assert.Equal(t, "Bearer tok-456", received.Header.Get("Authorization"))
+	})
+
+	t.Run("AND requirement with one scheme missing fails", func(t *testing.T) {
+		toolSet := securityToolSet(backend.URL, []map[string][]string{{"apiKey": {}, "oauth": {}}})
+		cfg := &config.Config{SecurityCredentials: map[string]string{"apiKey": "k-123"}}
+
+		_, err := executeToolCall(call, toolSet, cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing oauth")
+	})
+
+	t.Run("OR uses the second alternative when only its credentials are configured", func(t *testing.T) {
+		toolSet := securityToolSet(backend.URL, []map[string][]string{{"basic": {}}, {"queryKey": {}}})
+		cfg := &config.Config{SecurityCredentials: map[string]string{"queryKey": "q-789"}}
+
+		resp, err := executeToolCall(call, toolSet, cfg)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, "q-789", received.URL.Query().Get("key"))
+		assert.Empty(t, received.Header.Get("Authorization"), "the unsatisfied alternative is not sent")
+	})
+
+	t.Run("First satisfiable alternative wins", func(t *testing.T) {
+		toolSet := securityToolSet(backend.URL, []map[string][]string{{"basic": {}}, {"queryKey": {}}})
+		cfg := &config.Config{SecurityCredentials: map[string]string{"basic": "user:pass", "queryKey": "q-789"}}
+
+		resp, err := executeToolCall(call, toolSet, cfg)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, "Basic dXNlcjpwYXNz", received.Header.Get("Authorization"))
+		assert.Empty(t, received.URL.Query().Get("key"))
+	})
+
+	t.Run("Secrets file credential overrides the configured placeholder", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "auth.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("credentials:\n  apiKey: k-secret\n"), 0o600))
+		secrets, err := config.LoadSecretsFile(path)
+		require.NoError(t, err)
+
+		toolSet := securityToolSet(backend.URL, []map[string][]string{{"apiKey": {}}})
+		cfg := &config.Config{
+			SecurityCredentials: map[string]string{"apiKey": "CHANGE_ME"},
+			SecretCredentials:   secrets,
+		}
+
+		resp, err := executeToolCall(call, toolSet, cfg)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, "k-secret", received.Header.Get("X-API-Key"))
+	})
+
+	t.Run("No alternative satisfiable names the missing schemes", func(t *testing.T) {
+		toolSet := securityToolSet(backend.URL, []map[string][]string{{"apiKey": {}, "oauth": {}}, {"basic": {}}})
+
+		_, err := executeToolCall(call, toolSet, &config.Config{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing apiKey and oauth, or basic")
+	})
+
+	t.Run("Empty alternative allows anonymous calls", func(t *testing.T) {
+		toolSet := securityToolSet(backend.URL, []map[string][]string{{"oauth": {}}, {}})
+
+		resp, err := executeToolCall(call, toolSet, &config.Config{})
+		require.NoError(t, err)
+		resp.Body.Close()
+	})
+
+	t.Run("Server API key and custom headers satisfy matching schemes", func(t *testing.T) {
+		toolSet := securityToolSet(backend.URL, []map[string][]string{{"apiKey": {}, "oauth": {}}})
+		cfg := &config.Config{
+			APIKey:         "server-key",
+			APIKeyName:     "X-API-Key",
+			APIKeyLocation: config.APIKeyLocationHeader,
+			CustomHeaders:  "Authorization:Bearer from-header",
+		}
+
+		resp, err := executeToolCall(call, toolSet, cfg)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, "server-key", received.Header.Get("X-API-Key"))
+		assert.Equal(t, "Bearer from-header", received.Header.Get("Authorization"))
+	})
+}
+
+func TestExecuteToolCall_LogsRedactedHeaders(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer backend.Close()
+
+	toolSet := securityToolSet(backend.URL, []map[string][]string{{"apiKey": {}, "basic": {}}})
+	cfg := &config.Config{
+		APIKey:              "server-secret",
+		APIKeyName:          "X-Server-Key",
+		APIKeyLocation:      config.APIKeyLocationHeader,
+		SecurityCredentials: map[string]string{"apiKey": "scheme-secret", "basic": "user:basic-secret"},
+		CustomHeaders:       "X-Custom-Auth:custom-secret",
+	}
+	params := &ToolCallParams{ToolName: "listPets", Input: map[string]interface{}{}}
+	params.sessionHeaders = http.Header{"X-Session-Token": {"session-secret"}, "X-Tenant-Id": {"acme"}}
+	cfg.SessionHeaders = map[string]config.SessionHeader{
+		"token":  {Header: "X-Session-Token", Sensitive: true},
+		"tenant": {Header: "X-Tenant-ID"},
+	}
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	resp, err := executeToolCall(params, toolSet, cfg)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	out := logged.String()
+	for _, secret := range []string{"server-secret", "scheme-secret", "basic-secret", "dXNlcjpiYXNpYy1zZWNyZXQ", "custom-secret", "session-secret"} {
+		assert.NotContains(t, out, secret)
+	}
+	assert.Equal(t, 1, strings.Count(out, "Sending request with headers"), "the headers are logged once")
+	assert.Contains(t, out, "X-Tenant-Id:[acme]", "other header values are logged")
+	assert.Contains(t, out, "Authorization:[[REDACTED]]")
+}
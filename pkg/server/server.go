@@ -2,20 +2,24 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	// "fmt" // No longer needed here
-	// "sync" // No longer needed here
 
 	"github.com/litui/openapi-mcp-claude/pkg/config"
 	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/litui/openapi-mcp-claude/pkg/parser"
+	"github.com/litui/openapi-mcp-claude/pkg/version"
 	// Import UUID package
 )
 
@@ -26,6 +30,8 @@ type jsonRPCRequest struct {
 	Method  string      `json:"method"`
 	Params  interface{} `json:"params,omitempty"`
 	ID      interface{} `json:"id,omitempty"` // Can be string, number, or null
+
+	size int64 // Encoded size in bytes as received by the transport (0 if unknown)
 }
 
 type jsonRPCResponse struct {
@@ -33,6 +39,30 @@ type jsonRPCResponse struct {
 	Result  interface{} `json:"result,omitempty"`
 	Error   *jsonError  `json:"error,omitempty"`
 	ID      interface{} `json:"id"` // ID should match the request ID
+
+	// Set only for server-initiated notifications queued on the same channel as responses.
+	Method string      `json:"-"`
+	Params interface{} `json:"-"`
+
+	requestMethod string // Method of the request answered, for logging undelivered responses
+}
+
+// MarshalJSON encodes notifications as {jsonrpc, method, params} and responses as usual.
+func (r jsonRPCResponse) MarshalJSON() ([]byte, error) {
+	if r.Method != "" {
+		return json.Marshal(struct {
+			Jsonrpc string      `json:"jsonrpc"`
+			Method  string      `json:"method"`
+			Params  interface{} `json:"params,omitempty"`
+		}{Jsonrpc: "2.0", Method: r.Method, Params: r.Params})
+	}
+	type plainResponse jsonRPCResponse // Drops this method to avoid recursion
+	return json.Marshal(plainResponse(r))
+}
+
+// newJSONRPCNotification builds a server-to-client notification for a connection's Channel.
+func newJSONRPCNotification(method string, params interface{}) jsonRPCResponse {
+	return jsonRPCResponse{Jsonrpc: "2.0", Method: method, Params: params}
 }
 
 type jsonError struct {
@@ -64,15 +94,35 @@ type MCPError struct {
 // ToolCallParams represents the expected payload for a tools/call request.
 // This will be the structure within the 'params' field of a jsonRPCRequest.
 type ToolCallParams struct {
-	ToolName string                 `json:"name"`      // Aligning with gin-mcp JSON-RPC 'name'
-	Input    map[string]interface{} `json:"arguments"` // Aligning with gin-mcp JSON-RPC 'arguments'
+	ToolName string                 `json:"name"`            // Aligning with gin-mcp JSON-RPC 'name'
+	Input    map[string]interface{} `json:"arguments"`       // Aligning with gin-mcp JSON-RPC 'arguments'
+	Meta     map[string]interface{} `json:"_meta,omitempty"` // Request metadata, e.g. progressToken
+
+	idempotencyKey string      // Set by the deduplicator; sent upstream as the idempotency key header
+	sessionHeaders http.Header // The calling connection's headers from initialize _meta
+	urlFallbackOf  string      // The tool whose URL was too long, when calling its long query fallback
+	login          bool        // A call of the login operation, see relogin
 }
 
 // ToolResultContent represents an item in the 'content' array of a tool_result.
 type ToolResultContent struct {
-	Type string `json:"type"`
-	Text string `json:"text"` // Assuming text/JSON string result
-	// Add other content types if needed
+	Type     string `json:"type"`               // "text", "image", or "audio"
+	Text     string `json:"text"`               // Set for text content
+	Data     string `json:"data,omitempty"`     // Base64-encoded payload for image/audio content
+	MimeType string `json:"mimeType,omitempty"` // Media type for image/audio content
+}
+
+// MarshalJSON omits text from image/audio content, and keeps it (even empty) for text content.
+func (c ToolResultContent) MarshalJSON() ([]byte, error) {
+	if c.Type == "image" || c.Type == "audio" {
+		return json.Marshal(struct {
+			Type     string `json:"type"`
+			Data     string `json:"data"`
+			MimeType string `json:"mimeType"`
+		}{c.Type, c.Data, c.MimeType})
+	}
+	type plainContent ToolResultContent
+	return json.Marshal(plainContent(c))
 }
 
 // ToolResultPayload represents the structure for the 'result' of a 'tool_result' JSON-RPC response.
@@ -81,6 +131,10 @@ type ToolResultPayload struct {
 	IsError    bool                `json:"isError"`                // Aligning with gin-mcp
 	Error      *MCPError           `json:"error,omitempty"`        // Detailed error info if IsError is true
 	ToolCallID string              `json:"tool_call_id,omitempty"` // Optional: Can be helpful
+
+	StructuredContent interface{} `json:"structuredContent,omitempty"` // Machine-readable result, if the tool provides one
+
+	Meta map[string]interface{} `json:"_meta,omitempty"` // Result metadata, e.g. upstream rate-limit headers
 }
 
 // --- Server State ---
@@ -93,16 +147,109 @@ const messageChannelBufferSize = 10
 
 // --- Server Implementation ---
 
+// sessionIDHeader returns the header that carries connection IDs, defaulting to Mcp-Session-Id.
+func sessionIDHeader(cfg *config.Config) string {
+	if cfg == nil {
+		return config.DefaultSessionIDHeader
+	}
+	return cfg.GetSessionIDHeader()
+}
+
 // ServeMCP starts an HTTP server handling MCP communication.
 func ServeMCP(addr string, toolSet *mcp.ToolSet, cfg *config.Config) error {
+	return NewServer(toolSet, cfg).ListenAndServe(addr)
+}
+
+// Server serves a tool set over every MCP transport. Go programs embedding the server build one
+// with NewServer, may add hand-written tools with RegisterTool, then serve it with ListenAndServe
+// or mount Handler in their own HTTP server.
+type Server struct {
+	cfg   *config.Config
+	tools *toolSetSource
+
+	mu         sync.Mutex
+	httpServer *http.Server // Set by ListenAndServe, for Shutdown
+
+	specTransforms []parser.SpecTransform // Applied to specs loaded by hot reload, see WithSpecTransform
+}
+
+// NewServer returns a server for toolSet. The tool set is not modified; registering tools or
+// reloading the spec serves updated copies.
+func NewServer(toolSet *mcp.ToolSet, cfg *config.Config) *Server {
+	return &Server{cfg: cfg, tools: newToolSetSource(toolSet)}
+}
+
+// Handler returns the HTTP routes for every MCP transport.
+func (s *Server) Handler() http.Handler {
+	return newMCPMuxWithSource(s.tools, s.cfg)
+}
+
+// ListenAndServe listens on addr (with TLS when configured) and serves MCP until it fails or
+// Shutdown is called. It also starts spec hot reload when SpecPollInterval is set, and the
+// connection reaper when a connection timeout is.
+func (s *Server) ListenAndServe(addr string) error {
 	log.Printf("Preparing ToolSet for MCP...")
+	cfg := s.cfg
+
+	mcpConnectionManager.SetCaseSensitiveIDs(cfg.CaseSensitiveSessionIDs)
+	mcpConnectionManager.SetPersistenceRetries(cfg.StatePersistRetries, cfg.StatePersistRetryBackoff)
+	mcpConnectionManager.SetHandshakeDeadline(cfg.HandshakeDeadline)
+	mcpConnectionManager.SetCallHistorySize(cfg.CallHistorySize)
+	idPolicy, err := ConnectionIDPolicyFromConfig(cfg.ConnectionIDMaxLength, cfg.ConnectionIDPattern)
+	if err != nil {
+		return err
+	}
+	mcpConnectionManager.SetIDPolicy(idPolicy)
+	if cfg.ConnectionIDFormat != "" {
+		generator, err := ConnectionIDGeneratorByName(cfg.ConnectionIDFormat)
+		if err != nil {
+			return err
+		}
+		mcpConnectionManager.SetIDGenerator(generator)
+	}
+
+	if hotReloadEnabled(cfg) {
+		go watchSpec(s.tools, cfg, parser.NewSpecWatcher(cfg.SpecPath, s.specLoadOptions()), nil)
+	}
+	if reapingEnabled(cfg) {
+		go reapConnections(mcpConnectionManager, cfg, nil)
+	}
+	mux := s.Handler()
+
+	listener, err := listenMCP(addr, cfg)
+	if err != nil {
+		return err
+	}
+
+	scheme := "http"
+	if cfg.TLSCertFile != "" {
+		scheme = "https"
+	}
+	log.Printf("MCP server listening on %s://%s/mcp", scheme, listener.Addr())
+	logStartupSummary(s.tools.Load(), cfg, fmt.Sprintf("%s://%s", scheme, listener.Addr()))
+	warnIfNoTools(s.tools.Load())
+	httpServer := &http.Server{Handler: mux}
+	s.mu.Lock()
+	s.httpServer = httpServer
+	s.mu.Unlock()
+	return httpServer.Serve(listener)
+}
+
+// newMCPMux builds the HTTP routes for every MCP transport, serving a fixed tool set.
+func newMCPMux(toolSet *mcp.ToolSet, cfg *config.Config) *http.ServeMux {
+	return newMCPMuxWithSource(newToolSetSource(toolSet), cfg)
+}
+
+// newMCPMuxWithSource builds the HTTP routes for every MCP transport. Each request is served from
+// the tool set current when it arrives, see toolSetSource.
+func newMCPMuxWithSource(tools *toolSetSource, cfg *config.Config) *http.ServeMux {
 
 	streamableHandler := func(w http.ResponseWriter, r *http.Request) {
 		// CORS Headers (Apply to all relevant requests)
 		w.Header().Set("Access-Control-Allow-Origin", "*") // Be more specific in production
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, Mcp-Session-Id")
-		w.Header().Set("Access-Control-Expose-Headers", "Mcp-Session-Id")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, "+sessionIDHeader(cfg))
+		w.Header().Set("Access-Control-Expose-Headers", sessionIDHeader(cfg))
 
 		if r.Method == http.MethodOptions {
 			log.Println("Responding to OPTIONS request")
@@ -114,11 +261,11 @@ func ServeMCP(addr string, toolSet *mcp.ToolSet, cfg *config.Config) error {
 			// Not set up yet. Claude seems to default to just POST so I'm not too worried.
 			// httpMethodGetHandler(w, r, true)
 		} else if r.Method == http.MethodPost {
-			httpMethodPostHandler(w, r, toolSet, cfg, true)
-			// Claude doesn't send Mcp-Session-Id by default, so just set it statically in your config.
-			connID := r.Header.Get("Mcp-Session-Id")
-			if connID != "" {
-				conn := mcpConnectionManager.GetConnection(connID)
+			httpMethodPostHandler(w, r, tools.Load(), cfg, true)
+			// The handler echoes the session's ID, including one it minted for an initialize
+			// request that came without it
+			connID := w.Header().Get(sessionIDHeader(cfg))
+			if conn := mcpConnectionManager.GetConnection(connID); connID != "" && conn != nil {
 				for i := 0; i < len(conn.Channel); i++ {
 					output, ok := <-conn.Channel
 					if ok {
@@ -141,9 +288,14 @@ func ServeMCP(addr string, toolSet *mcp.ToolSet, cfg *config.Config) error {
 
 	// See: https://blog.christianposta.com/ai/understanding-mcp-recent-change-around-http-sse/
 	mux.HandleFunc("/messages", streamableHandler)
-
-	log.Printf("MCP server listening on %s/mcp", addr)
-	return http.ListenAndServe(addr, mux)
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		webSocketHandler(w, r, tools, cfg)
+	})
+	mux.HandleFunc("GET /admin/connections", adminConnectionsHandler)
+	mux.HandleFunc("GET /admin/connections/{id}/history", adminConnectionHistoryHandler)
+	mux.HandleFunc("GET /readyz", readyzHandler(tools))
+
+	return mux
 }
 
 // httpMethodSSEHandler handles the initial GET request to establish the SSE connection.
@@ -282,6 +434,35 @@ func ServeMCP(addr string, toolSet *mcp.ToolSet, cfg *config.Config) error {
 // 	return nil
 // }
 
+// adminConnectionsHandler reports a snapshot of all tracked connections as JSON.
+func adminConnectionsHandler(w http.ResponseWriter, r *http.Request) {
+	snapshot := mcpConnectionManager.Snapshot()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"connections":          snapshot,
+		"count":                len(snapshot),
+		"undelivered_messages": undeliveredMessages.Load(),
+		"reap_warnings":        reapWarnings.Load(),
+		"validation_failures":  validationFailures.snapshot(),
+	}); err != nil {
+		log.Printf("Error writing admin connections snapshot: %v", err)
+	}
+}
+
+// isInitializePost reports whether a POST body is an initialize request. The body is read, up to
+// the request size limit, and put back for the handler to read again.
+func isInitializePost(w http.ResponseWriter, r *http.Request, cfg *config.Config) bool {
+	bodyBytes, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes(cfg)))
+	if err != nil {
+		return false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+	var message struct {
+		Method string `json:"method"`
+	}
+	return json.Unmarshal(bodyBytes, &message) == nil && message.Method == "initialize"
+}
+
 // httpMethodPostHandler handles incoming POST requests containing MCP messages.
 func httpMethodPostHandler(w http.ResponseWriter, r *http.Request, toolSet *mcp.ToolSet, cfg *config.Config, standalone bool) {
 	log.Printf("Inbound Post connection received to %s, type %s.", r.Host, r.Method)
@@ -292,13 +473,39 @@ func httpMethodPostHandler(w http.ResponseWriter, r *http.Request, toolSet *mcp.
 	var connID string
 
 	if standalone {
-		connID = r.Header.Get("Mcp-Session-Id")
-		if connID != "" {
-			conn = mcpConnectionManager.GetConnection(connID)
-			if conn == nil {
-				conn = mcpConnectionManager.NewConnection(connID)
+		connID = r.Header.Get(sessionIDHeader(cfg))
+		if connID == "" {
+			// An initialize request opens a session under an ID the server mints; anything else
+			// must name its session
+			if !isInitializePost(w, r, cfg) {
+				log.Printf("Error: POST request received without a %s header", sessionIDHeader(cfg))
+				http.Error(w, "Missing "+sessionIDHeader(cfg)+" header", http.StatusBadRequest)
+				return
+			}
+			generated, err := mcpConnectionManager.NewGeneratedConnection(r)
+			if err != nil {
+				log.Printf("Error: could not assign a connection ID: %v", err)
+				http.Error(w, "Could not assign a connection ID", http.StatusInternalServerError)
+				return
+			}
+			conn = generated
+			connID = conn.ID
+		} else {
+			reattached, ok, err := mcpConnectionManager.ReattachConnection(connID)
+			if err == nil && ok {
+				conn = reattached
+			} else if err == nil {
+				if conn = mcpConnectionManager.GetConnection(connID); conn == nil {
+					conn, err = mcpConnectionManager.NewConnection(connID)
+				}
+			}
+			if err != nil {
+				log.Printf("Error: rejecting %s header: %v", sessionIDHeader(cfg), err)
+				http.Error(w, "Invalid "+sessionIDHeader(cfg)+" header", http.StatusBadRequest)
+				return
 			}
 		}
+		w.Header().Set(sessionIDHeader(cfg), conn.ID) // Echo the ID the connection is tracked under
 	} else {
 		connID = r.PathValue("connectionId")
 
@@ -317,7 +524,40 @@ func httpMethodPostHandler(w http.ResponseWriter, r *http.Request, toolSet *mcp.
 		}
 	}
 
-	bodyBytes, err := io.ReadAll(r.Body)
+	// With ordered responses, this request's response waits for those of earlier requests
+	ordered := cfg != nil && cfg.OrderedResponses && conn != nil
+	var ticket uint64
+	if ordered {
+		ticket = conn.sequencer.take()
+		defer conn.sequencer.finish(ticket)
+	}
+	waitTurn := func() {
+		if ordered {
+			conn.sequencer.waitTurn(ticket)
+		}
+	}
+
+	// Enforce the size limit while reading, before anything is decoded
+	limit := maxRequestBytes(cfg)
+	bodyBytes, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	var tooLargeErr *http.MaxBytesError
+	if errors.As(err, &tooLargeErr) {
+		log.Printf("Rejecting POST request body for %s: exceeds %d bytes", connID, limit)
+		errResp := createRequestTooLargeError(nil, limit)
+		waitTurn()
+		if err := conn.trySend(errResp); err == nil {
+			if !standalone {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				fmt.Fprintln(w, "Request too large, error response will be sent via SSE.")
+			}
+		} else {
+			log.Printf("Error: Failed to queue size limit error for %s: %v", connID, err)
+			if !standalone {
+				http.Error(w, "Request too large", http.StatusRequestEntityTooLarge)
+			}
+		}
+		return
+	}
 	if err != nil {
 		log.Printf("Error reading POST request body for %s: %v", connID, err)
 		// Create error response in the ToolResultPayload format
@@ -337,16 +577,16 @@ func httpMethodPostHandler(w http.ResponseWriter, r *http.Request, toolSet *mcp.
 			Error:   nil, // Ensure top-level error is nil
 		}
 		// Attempt to send via SSE channel
-		select {
-		case conn.Channel <- errResp:
+		waitTurn()
+		if err := conn.trySend(errResp); err == nil {
 			log.Printf("Queued read error response (ID: %v) for %s onto SSE channel (as Result)", errResp.ID, connID)
 			if !standalone {
 				// Send HTTP 202 Accepted back to the POST request
 				w.WriteHeader(http.StatusAccepted)
 				fmt.Fprintln(w, "Request accepted (with parse error), response will be sent via SSE.")
 			}
-		default:
-			log.Printf("Error: Failed to queue read error response (ID: %v) for %s - SSE channel likely full or closed.", errResp.ID, connID)
+		} else {
+			log.Printf("Error: Failed to queue read error response (ID: %v) for %s: %v", errResp.ID, connID, err)
 			// Send an error back on the POST request if channel fails
 			if !standalone {
 				tryWriteHTTPError(w, http.StatusInternalServerError, "Failed to queue error response for SSE channel")
@@ -363,7 +603,7 @@ func httpMethodPostHandler(w http.ResponseWriter, r *http.Request, toolSet *mcp.
 	var reqID interface{} // Keep track of ID even if full unmarshal fails
 
 	// Try unmarshalling into raw map
-	if err := json.Unmarshal(bodyBytes, &rawReq); err == nil {
+	if err := decodeJSON(bodyBytes, &rawReq); err == nil {
 		// Ensure reqID is treated as a string or number if possible, handle potential null
 		if idVal, idExists := rawReq["id"]; idExists && idVal != nil {
 			reqID = idVal
@@ -377,14 +617,14 @@ func httpMethodPostHandler(w http.ResponseWriter, r *http.Request, toolSet *mcp.
 	}
 
 	var req jsonRPCRequest // Expect JSON-RPC request
-	if err := json.Unmarshal(bodyBytes, &req); err != nil {
+	if err := decodeJSON(bodyBytes, &req); err != nil {
 		log.Printf("Error decoding JSON-RPC request for %s: %v", connID, err)
 		// Use createJSONRPCError to correctly format the error response
 		errResp := createJSONRPCError(reqID, -32700, "Parse error decoding JSON request", err.Error())
 
 		// Attempt to send via SSE channel
-		select {
-		case conn.Channel <- errResp:
+		waitTurn()
+		if err := conn.trySend(errResp); err == nil {
 			log.Printf("Queued decode error response (ID: %v) for %s onto SSE channel", errResp.ID, connID)
 			if !standalone {
 				// Send HTTP 202 Accepted back to the POST request
@@ -392,8 +632,8 @@ func httpMethodPostHandler(w http.ResponseWriter, r *http.Request, toolSet *mcp.
 				// Use a specific message for decode errors
 				fmt.Fprintln(w, "Request accepted (with decode error), response will be sent via SSE.")
 			}
-		default:
-			log.Printf("Error: Failed to queue decode error response (ID: %v) for %s - SSE channel likely full or closed.", errResp.ID, connID)
+		} else {
+			log.Printf("Error: Failed to queue decode error response (ID: %v) for %s: %v", errResp.ID, connID, err)
 			// Send an error back on the POST request if channel fails
 			if !standalone {
 				tryWriteHTTPError(w, http.StatusInternalServerError, "Failed to queue error response for SSE channel")
@@ -402,6 +642,8 @@ func httpMethodPostHandler(w http.ResponseWriter, r *http.Request, toolSet *mcp.
 		return // Stop processing
 	}
 
+	req.size = int64(len(bodyBytes))
+
 	// If we successfully unmarshalled 'req', ensure reqID matches req.ID
 	if req.ID != nil {
 		reqID = req.ID
@@ -409,13 +651,56 @@ func httpMethodPostHandler(w http.ResponseWriter, r *http.Request, toolSet *mcp.
 		reqID = nil
 	}
 
+	respToSend, respond := dispatchJSONRPC(conn, connID, &req, toolSet, cfg)
+	if !respond {
+		// Notifications get no JSON-RPC response
+		if !standalone {
+			w.WriteHeader(http.StatusAccepted)
+			fmt.Fprintln(w, "Notification received.")
+		}
+		return
+	}
+
+	// --- Send response ---
+	waitTurn()
+	if err := conn.trySend(respToSend); err == nil {
+		log.Printf("Queued response (ID: %v) for %s", respToSend.ID, connID)
+		if !standalone {
+			// Send HTTP 202 Accepted back to the POST request
+			w.WriteHeader(http.StatusAccepted)
+			// Use the standard message for successfully queued responses
+			fmt.Fprintln(w, "Request accepted, response will be sent via SSE.")
+		}
+	} else {
+		log.Printf("Error: Failed to queue response (ID: %v) for %s: %v", respToSend.ID, connID, err)
+		if !standalone {
+			http.Error(w, "Failed to queue response for SSE channel", http.StatusInternalServerError)
+		}
+	}
+}
+
+// dispatchJSONRPC validates a decoded JSON-RPC request against the connection's state and routes it
+// to the matching handler. It returns false for notifications, which get no response.
+// Shared by every transport (HTTP POST, WebSocket).
+func dispatchJSONRPC(conn *Connection, connID string, req *jsonRPCRequest, toolSet *mcp.ToolSet, cfg *config.Config) (jsonRPCResponse, bool) {
+	mcpConnectionManager.Touch(connID)
+	reqID := req.ID
+
 	// --- Variable to hold the final response to be sent via SSE ---
 	var respToSend jsonRPCResponse
 
 	// --- Validate JSON-RPC Request ---
+	if req.Jsonrpc != "2.0" && acceptsJSONRPCVersion(req.Jsonrpc, cfg) {
+		log.Printf("Accepting JSON-RPC version '%s' as \"2.0\" for %s, ID: %v", req.Jsonrpc, connID, reqID)
+		req.Jsonrpc = "2.0"
+	}
 	if req.Jsonrpc != "2.0" {
 		log.Printf("Invalid JSON-RPC version ('%s') for %s, ID: %v", req.Jsonrpc, connID, reqID)
 		respToSend = createJSONRPCError(reqID, -32600, "Invalid Request: jsonrpc field must be \"2.0\"", nil)
+	} else if limit := maxRequestBytes(cfg); req.size > limit {
+		// Transports without a streaming limit of their own are still bounded here
+		log.Printf("Rejecting JSON-RPC message for %s, ID: %v: %d bytes exceeds %d", connID, reqID, req.size, limit)
+		respToSend = createRequestTooLargeError(reqID, limit)
 	} else if req.Method == "" {
 		log.Printf("Missing JSON-RPC method for %s, ID: %v", connID, reqID)
 		respToSend = createJSONRPCError(reqID, -32600, "Invalid Request: method field is missing or empty", nil)
@@ -426,43 +711,57 @@ func httpMethodPostHandler(w http.ResponseWriter, r *http.Request, toolSet *mcp.
 		// Check connection state and validate method based on state
 		switch req.Method {
 		case "initialize":
-			// if conn.State != StateConnected {
-			// 	log.Printf("Initialize request rejected for %s - wrong state: %s", connID, conn.State)
-			// 	respToSend = createJSONRPCError(reqID, -32600, "Invalid Request: already initialized or wrong state", nil)
-			// } else {
 			incomingInitializeJSON, _ := json.Marshal(req)
 			log.Printf("DEBUG: Handling 'initialize' for %s. Incoming request: %s", connID, string(incomingInitializeJSON))
-			respToSend = handleInitializeJSONRPC(connID, &req)
-			// Update state to Initializing after handling
-			mcpConnectionManager.UpdateState(connID, StateInitializing)
+			respToSend = handleInitializeJSONRPC(connID, req, toolSet, cfg)
+			// Update state to Initializing after a successful handshake
+			if respToSend.Error == nil {
+				mcpConnectionManager.UpdateState(connID, StateInitializing)
+			}
 			outgoingInitializeJSON, _ := json.Marshal(respToSend)
 			log.Printf("DEBUG: Prepared 'initialize' response for %s. Outgoing response: %s", connID, string(outgoingInitializeJSON))
-			// }
-		case "notifications/initialized":
+		case "notifications/initialized", "initialized": // Bare "initialized" accepted from older clients
 			if conn.State != StateInitializing {
 				log.Printf("Initialized notification rejected for %s - wrong state: %s", connID, conn.State)
 				respToSend = createJSONRPCError(reqID, -32600, "Invalid Request: not in initialization phase", nil)
 			} else {
 				log.Printf("Received 'initialized' notification for %s. Updating state to Ready.", connID)
 				mcpConnectionManager.UpdateState(connID, StateReady)
-				if !standalone {
-					w.WriteHeader(http.StatusAccepted)
-					fmt.Fprintln(w, "Notification received.")
-				}
-				return
+				return jsonRPCResponse{}, false
 			}
+		case "ping":
+			// Allowed in every state so clients can probe liveness before the handshake completes
+			respToSend = jsonRPCResponse{Jsonrpc: "2.0", ID: reqID, Result: map[string]interface{}{}}
 		default:
 			// All other methods require Ready state
 			if conn.State != StateReady {
 				log.Printf("Operation '%s' rejected for %s - not ready (state: %s)", req.Method, connID, conn.State)
-				respToSend = createJSONRPCError(reqID, -32600, "Invalid Request: not ready for operations", nil)
+				respToSend = createNotInitializedError(reqID, conn.State)
 			} else {
 				// Process normal operations
 				switch req.Method {
 				case "tools/list":
-					respToSend = handleToolsListJSONRPC(connID, &req, toolSet)
+					respToSend = handleToolsListJSONRPC(connID, req, toolSet, cfg)
 				case "tools/call":
-					respToSend = handleToolCallJSONRPC(connID, &req, toolSet, cfg)
+					if serverDraining.Load() {
+						log.Printf("Tool call rejected for %s - server draining", connID)
+						respToSend = createServerDrainingError(reqID)
+					} else {
+						respToSend = handleToolCallJSONRPC(connID, req, toolSet, cfg)
+					}
+				case "prompts/list", "prompts/get":
+					if !promptsAvailable(conn, toolSet) {
+						log.Printf("Prompt method '%s' rejected for %s - prompts not configured or not advertised by client", req.Method, connID)
+						respToSend = createJSONRPCError(reqID, -32601, fmt.Sprintf("Method not found: %s", req.Method), nil)
+					} else if req.Method == "prompts/list" {
+						respToSend = handlePromptsListJSONRPC(connID, req, toolSet)
+					} else {
+						respToSend = handlePromptsGetJSONRPC(connID, req, toolSet)
+					}
+				case "resources/list":
+					respToSend = handleResourcesListJSONRPC(connID, req, toolSet)
+				case "resources/read":
+					respToSend = handleResourcesReadJSONRPC(connID, req, toolSet)
 				default:
 					log.Printf("Received unknown JSON-RPC method '%s' for %s", req.Method, connID)
 					respToSend = createJSONRPCError(reqID, -32601, fmt.Sprintf("Method not found: %s", req.Method), nil)
@@ -471,41 +770,88 @@ func httpMethodPostHandler(w http.ResponseWriter, r *http.Request, toolSet *mcp.
 		}
 	}
 
-	// --- Send response ---
-	select {
-	case conn.Channel <- respToSend:
-		log.Printf("Queued response (ID: %v) for %s", respToSend.ID, connID)
-		if !standalone {
-			// Send HTTP 202 Accepted back to the POST request
-			w.WriteHeader(http.StatusAccepted)
-			// Use the standard message for successfully queued responses
-			fmt.Fprintln(w, "Request accepted, response will be sent via SSE.")
-		}
-	default:
-		log.Printf("Error: Failed to queue response (ID: %v) for %s - SSE channel likely full or closed.", respToSend.ID, connID)
-		if !standalone {
-			http.Error(w, "Failed to queue response for SSE channel", http.StatusInternalServerError)
-		}
-	}
+	respToSend.requestMethod = req.Method
+	return respToSend, true
+}
+
+// createNotInitializedError rejects a request that arrived before the initialize handshake
+// completed. The data tells the client which state the connection is in and how to proceed.
+func createNotInitializedError(id interface{}, state ConnectionState) jsonRPCResponse {
+	return createJSONRPCError(id, -32600, "Invalid Request: not ready for operations, the server is not initialized", map[string]interface{}{
+		"state": state.String(),
+		"hint":  "send initialize, then notifications/initialized",
+	})
 }
 
 // --- JSON-RPC Message Handlers --- // Implementations returning jsonRPCResponse
 
-func handleInitializeJSONRPC(connID string, req *jsonRPCRequest) jsonRPCResponse {
+// Protocol versions this server can speak, newest first.
+var supportedProtocolVersions = []string{"2025-03-26", "2024-11-05"}
+
+// defaultProtocolVersion is assumed when the client's initialize request omits protocolVersion.
+const defaultProtocolVersion = "2024-11-05"
+
+// negotiateProtocolVersion picks the protocol version to use for a connection.
+// A supported requested version is echoed back; a version newer than anything we
+// support is negotiated down to our latest. Anything older is rejected.
+func negotiateProtocolVersion(requested string) (string, error) {
+	if requested == "" {
+		return defaultProtocolVersion, nil
+	}
+	for _, v := range supportedProtocolVersions {
+		if v == requested {
+			return v, nil
+		}
+	}
+	// Versions are dates (YYYY-MM-DD), so lexical order matches chronological order.
+	if latest := supportedProtocolVersions[0]; requested > latest {
+		return latest, nil
+	}
+	return "", fmt.Errorf("unsupported protocol version %q (supported: %s)", requested, strings.Join(supportedProtocolVersions, ", "))
+}
+
+func handleInitializeJSONRPC(connID string, req *jsonRPCRequest, toolSet *mcp.ToolSet, cfg *config.Config) jsonRPCResponse {
 	log.Printf("Handling 'initialize' (JSON-RPC) for %s", connID)
 
+	requestedVersion := ""
+	var clientCapabilities, clientInfo, meta map[string]interface{}
+	if paramsMap, ok := req.Params.(map[string]interface{}); ok {
+		requestedVersion, _ = paramsMap["protocolVersion"].(string)
+		clientCapabilities, _ = paramsMap["capabilities"].(map[string]interface{})
+		clientInfo, _ = paramsMap["clientInfo"].(map[string]interface{})
+		meta, _ = paramsMap["_meta"].(map[string]interface{})
+	}
+
+	protocolVersion, err := negotiateProtocolVersion(requestedVersion)
+	if err != nil {
+		log.Printf("Initialize rejected for %s: %v", connID, err)
+		return createJSONRPCError(req.ID, -32602, "Unsupported protocol version", map[string]interface{}{
+			"requested": requestedVersion,
+			"supported": supportedProtocolVersions,
+		})
+	}
+	log.Printf("Negotiated protocol version %s for %s (client requested %q)", protocolVersion, connID, requestedVersion)
+	mcpConnectionManager.SetProtocolVersion(connID, protocolVersion)
+	mcpConnectionManager.SetClientCapabilities(connID, clientCapabilities)
+	mcpConnectionManager.SetClientInfo(connID, clientInfo)
+	if headers, sensitive := sessionHeadersFromMeta(meta, cfg); len(headers)+len(sensitive) > 0 {
+		mcpConnectionManager.SetSessionHeaders(connID, headers, sensitive)
+	}
+
 	// Construct the result payload based on gin-mcp's structure using map[string]interface{}
 	resultPayload := map[string]interface{}{
-		"protocolVersion": "2024-11-05", // Aligning with gin-mcp
+		"protocolVersion": protocolVersion,
 		"capabilities": map[string]interface{}{
 			"tools": map[string]interface{}{
-				"enabled": true,
+				"enabled":     true,
+				"listChanged": hotReloadEnabled(cfg), // Sent as notifications/tools/list_changed on reload
 				"config": map[string]interface{}{
-					"listChanged": false,
+					"listChanged": hotReloadEnabled(cfg),
 				},
 			},
 			"prompts": map[string]interface{}{
-				"enabled": false,
+				"enabled":     toolSet != nil && len(toolSet.Prompts) > 0,
+				"listChanged": false,
 			},
 			"resources": map[string]interface{}{
 				"enabled": true,
@@ -518,9 +864,8 @@ func handleInitializeJSONRPC(connID string, req *jsonRPCRequest) jsonRPCResponse
 			},
 		},
 		"serverInfo": map[string]interface{}{
-			"name":       "OpenAPI-MCP",       // Or use config name if available
-			"version":    "openapi-mcp-0.1.0", // Your server version
-			"apiVersion": "2024-11-05",        // MCP API version
+			"name":    version.Name,    // Set at build time, see pkg/version
+			"version": version.Version, // Set at build time, see pkg/version
 		},
 		"connectionId": connID, // Include the connection ID
 	}
@@ -532,15 +877,17 @@ func handleInitializeJSONRPC(connID string, req *jsonRPCRequest) jsonRPCResponse
 	}
 }
 
-func handleToolsListJSONRPC(connID string, req *jsonRPCRequest, toolSet *mcp.ToolSet) jsonRPCResponse {
+func handleToolsListJSONRPC(connID string, req *jsonRPCRequest, toolSet *mcp.ToolSet, cfg *config.Config) jsonRPCResponse {
 	log.Printf("Handling 'tools/list' (JSON-RPC) for %s", connID)
 
+	tools := filterToolsForClient(connID, listTools(toolSet, cfg), toolSet, cfg)
+
 	// Construct the result payload based on gin-mcp's structure
 	resultPayload := map[string]interface{}{
-		"tools": toolSet.Tools,
+		"tools": tools,
 		"metadata": map[string]interface{}{
 			"version": "2024-11-05", // Align with gin-mcp if possible
-			"count":   len(toolSet.Tools),
+			"count":   len(tools),
 		},
 	}
 
@@ -555,7 +902,10 @@ func handleToolsListJSONRPC(connID string, req *jsonRPCRequest, toolSet *mcp.Too
 // It now correctly handles API key injection based on the *cfg* parameter.
 func executeToolCall(params *ToolCallParams, toolSet *mcp.ToolSet, cfg *config.Config) (*http.Response, error) {
 	toolName := params.ToolName
-	toolInput := params.Input // This is the map[string]interface{} from the client
+	toolInput := withTagArguments(toolSet.Operations[toolName], params.Input)       // Client arguments plus tag-level pins
+	toolInput = withPinnedArguments(toolName, toolInput, cfg)                       // Tool pins override tag-level ones
+	toolInput = coerceToolInput(toolName, toolSet, toolInput)                       // Integer-typed values in plain integer form
+	toolInput = unaliasArguments(toolName, toolSet.Operations[toolName], toolInput) // Parameter aliases back to API names
 
 	log.Printf("[ExecuteToolCall] Looking up details for tool: %s", toolName)
 	operation, ok := toolSet.Operations[toolName]
@@ -565,6 +915,10 @@ func executeToolCall(params *ToolCallParams, toolSet *mcp.ToolSet, cfg *config.C
 	}
 	log.Printf("[ExecuteToolCall] Found operation: Method=%s, Path=%s", operation.Method, operation.Path)
 
+	if cfg.MockMode {
+		return mockToolCall(toolName, operation, cfg.MockStatus)
+	}
+
 	// --- Resolve API Key (using cfg passed from main) ---
 	resolvedKey := cfg.GetAPIKey()
 	apiKeyName := cfg.APIKeyName
@@ -573,6 +927,13 @@ func executeToolCall(params *ToolCallParams, toolSet *mcp.ToolSet, cfg *config.C
 
 	log.Printf("[ExecuteToolCall] API Key Details: Name='%s', In='%s', HasServerValue=%t", apiKeyName, apiKeyLocation, resolvedKey != "")
 
+	// --- Resolve Security Requirements ---
+	securityCredentials, err := resolveSecurity(toolName, operation, toolSet, cfg, resolvedKey)
+	if err != nil {
+		log.Printf("[ExecuteToolCall] Error: %v", err)
+		return nil, err
+	}
+
 	// --- Prepare Request Components ---
 	baseURL := operation.BaseURL // Use BaseURL from the specific operation
 	if cfg.ServerBaseURL != "" {
@@ -586,6 +947,7 @@ func executeToolCall(params *ToolCallParams, toolSet *mcp.ToolSet, cfg *config.C
 
 	path := operation.Path
 	queryParams := url.Values{}
+	var queryFlags []string // Presence-style boolean query parameters that are set
 	pathParams := make(map[string]string)
 	headerParams := make(http.Header)        // For headers to add
 	cookieParams := []*http.Cookie{}         // For cookies to add
@@ -594,13 +956,31 @@ func executeToolCall(params *ToolCallParams, toolSet *mcp.ToolSet, cfg *config.C
 
 	// Create a map of expected parameters from the operation details for easier lookup
 	expectedParams := make(map[string]string) // Map param name to its location ('in')
+	paramDetails := make(map[string]mcp.ParameterDetail)
 	for _, p := range operation.Parameters {
 		expectedParams[p.Name] = p.In
+		paramDetails[p.Name] = p
+	}
+
+	// --- Decode a Binary Request Body ---
+	var rawBody []byte
+	if operation.RawBodyMediaType != "" {
+		if encoded, ok := toolInput[mcp.RawBodyArgument]; ok {
+			decoded, err := decodeRawBody(encoded, maxRawBodyBytes(cfg))
+			if err != nil {
+				log.Printf("[ExecuteToolCall] Error decoding binary request body for tool '%s': %v", toolName, err)
+				return nil, fmt.Errorf("invalid '%s' argument: %w", mcp.RawBodyArgument, err)
+			}
+			rawBody = decoded
+		}
 	}
 
 	// --- Process Input Parameters (Separating and Handling API Key Override) ---
 	log.Printf("[ExecuteToolCall] Processing %d input parameters...", len(toolInput))
 	for key, value := range toolInput {
+		if operation.RawBodyMediaType != "" && key == mcp.RawBodyArgument {
+			continue // Sent as the raw body below
+		}
 		// --- API Key Override Check ---
 		// If this input param is the API key AND we have a valid server key config,
 		// skip processing the client's value entirely.
@@ -615,19 +995,30 @@ func executeToolCall(params *ToolCallParams, toolSet *mcp.ToolSet, cfg *config.C
 
 		if strings.Contains(path, pathPlaceholder) {
 			// Handle path parameter substitution
-			pathParams[key] = fmt.Sprintf("%v", value)
-			log.Printf("[ExecuteToolCall] Found path parameter %s=%v", key, value)
+			detail, ok := paramDetails[key]
+			if !ok {
+				detail = mcp.ParameterDetail{Name: key, In: "path"}
+			}
+			pathParams[key] = serializePathParam(detail, value)
+			log.Printf("[ExecuteToolCall] Found path parameter %s=%v (serialized as %s)", key, value, pathParams[key])
 		} else if knownParam {
 			// Handle parameters defined in the spec (query, header, cookie)
 			switch paramLocation {
 			case "query":
-				queryParams.Add(key, fmt.Sprintf("%v", value))
+				if booleanQueryStyle(toolName, paramDetails[key], cfg) == mcp.BooleanStylePresence {
+					var isBool bool
+					if queryFlags, isBool = addPresenceFlag(queryFlags, key, value); isBool {
+						log.Printf("[ExecuteToolCall] Found presence-style query flag %s=%v (from spec)", key, value)
+						break
+					}
+				}
+				queryParams.Add(key, formatScalar(value))
 				log.Printf("[ExecuteToolCall] Found query parameter %s=%v (from spec)", key, value)
 			case "header":
-				headerParams.Add(key, fmt.Sprintf("%v", value))
+				headerParams.Add(key, formatScalar(value))
 				log.Printf("[ExecuteToolCall] Found header parameter %s=%v (from spec)", key, value)
 			case "cookie":
-				cookieParams = append(cookieParams, &http.Cookie{Name: key, Value: fmt.Sprintf("%v", value)})
+				cookieParams = append(cookieParams, &http.Cookie{Name: key, Value: formatScalar(value)})
 				log.Printf("[ExecuteToolCall] Found cookie parameter %s=%v (from spec)", key, value)
 			// case "formData": // TODO: Handle form data if needed
 			// 	bodyData[key] = value // Or handle differently based on content type
@@ -638,7 +1029,7 @@ func executeToolCall(params *ToolCallParams, toolSet *mcp.ToolSet, cfg *config.C
 					// If spec says 'path' but it wasn't in the actual path, and it's a GET/DELETE,
 					// treat it as a query parameter as a fallback.
 					log.Printf("[ExecuteToolCall] Warning: Parameter '%s' is 'path' in spec but not in URL path '%s'. Adding to query parameters as fallback for GET/DELETE.", key, operation.Path)
-					queryParams.Add(key, fmt.Sprintf("%v", value))
+					queryParams.Add(key, formatScalar(value))
 				} else {
 					// Otherwise, log the warning and ignore.
 					log.Printf("[ExecuteToolCall] Warning: Parameter '%s' has unsupported or unhandled location '%s' in spec. Ignoring.", key, paramLocation)
@@ -702,20 +1093,50 @@ func executeToolCall(params *ToolCallParams, toolSet *mcp.ToolSet, cfg *config.C
 		log.Printf("[ExecuteToolCall] Skipping server API key injection (config incomplete or key unresolved).")
 	}
 
+	// --- Normalize the Trailing Slash ---
+	if mode := trailingSlashMode(toolName, baseURL, cfg); mode != TrailingSlashPreserve {
+		path = normalizeTrailingSlash(path, mode)
+	}
+
+	// --- Inject Credentials for the Chosen Security Requirement ---
+	for _, cred := range securityCredentials {
+		cookieParams = injectSecurityCredential(cred, queryParams, headerParams, cookieParams)
+	}
+
 	// --- Final URL Construction ---
 	// Reconstruct query string *after* potential API key injection
 	targetURL := baseURL + path
-	if len(queryParams) > 0 {
-		targetURL += "?" + queryParams.Encode()
+	if len(queryParams) > 0 || len(queryFlags) > 0 {
+		targetURL += "?" + encodeQuery(queryParams, queryFlags)
 	}
 	log.Printf("[ExecuteToolCall] Final Target URL: %s %s", operation.Method, targetURL)
+	if err := checkURLLength(toolName, targetURL, cfg); err != nil {
+		log.Printf("[ExecuteToolCall] Error: %v", err)
+		return nil, err
+	}
+
+	// --- Re-wrap an Unwrapped Request Body ---
+	if operation.BodyWrapper != "" && len(bodyData) > 0 {
+		bodyData = map[string]interface{}{operation.BodyWrapper: bodyData}
+		log.Printf("[ExecuteToolCall] Wrapped request body in property '%s'", operation.BodyWrapper)
+	}
 
 	// --- Prepare Request Body ---
 	var reqBody io.Reader
 	var bodyBytes []byte // Keep for logging
-	if requestBodyRequired && len(bodyData) > 0 {
+	bodyMediaType := "application/json"
+	if rawBody != nil {
+		bodyBytes = rawBody
+		reqBody = bytes.NewReader(rawBody)
+		log.Printf("[ExecuteToolCall] Request body: %d raw bytes of %s", len(rawBody), operation.RawBodyMediaType)
+	} else if requestBodyRequired && len(bodyData) > 0 {
 		var err error
-		bodyBytes, err = json.Marshal(bodyData)
+		if useFormBody(operation, cfg) {
+			bodyMediaType = formMediaType
+			bodyBytes, err = encodeFormBody(bodyData)
+		} else {
+			bodyBytes, err = json.Marshal(bodyData)
+		}
 		if err != nil {
 			log.Printf("[ExecuteToolCall] Error marshalling request body: %v", err)
 			return nil, fmt.Errorf("error marshalling request body: %w", err)
@@ -724,6 +1145,19 @@ func executeToolCall(params *ToolCallParams, toolSet *mcp.ToolSet, cfg *config.C
 		log.Printf("[ExecuteToolCall] Request body: %s", string(bodyBytes))
 	}
 
+	// --- Compress Large Request Bodies ---
+	contentEncoding := ""
+	if reqBody != nil && cfg.RequestCompressionMinBytes > 0 && len(bodyBytes) >= cfg.RequestCompressionMinBytes {
+		compressed, err := gzipBytes(bodyBytes)
+		if err != nil {
+			log.Printf("[ExecuteToolCall] Error compressing request body: %v", err)
+			return nil, fmt.Errorf("error compressing request body: %w", err)
+		}
+		reqBody = bytes.NewReader(compressed)
+		contentEncoding = "gzip"
+		log.Printf("[ExecuteToolCall] Compressed request body from %d to %d bytes", len(bodyBytes), len(compressed))
+	}
+
 	// --- Create HTTP Request ---
 	req, err := http.NewRequest(operation.Method, targetURL, reqBody)
 	if err != nil {
@@ -733,9 +1167,24 @@ func executeToolCall(params *ToolCallParams, toolSet *mcp.ToolSet, cfg *config.C
 
 	// --- Set Headers ---
 	// Default headers
-	req.Header.Set("Accept", "application/json") // Assume JSON response typical for APIs
-	if reqBody != nil {
-		req.Header.Set("Content-Type", "application/json") // Assume JSON body if body exists
+	req.Header.Set("Accept", effectiveAccept(toolName, operation, cfg)) // Header parameters below may override it
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	if language := acceptLanguage(params, cfg); language != "" {
+		req.Header.Set("Accept-Language", language)
+	}
+	if rawBody != nil {
+		req.Header.Set("Content-Type", operation.RawBodyMediaType)
+	} else if reqBody != nil {
+		req.Header.Set("Content-Type", bodyMediaType)
+	}
+	if contentEncoding != "" {
+		req.Header.Set("Content-Encoding", contentEncoding)
+	}
+
+	// Headers from the connection's initialize _meta. They come first so that header parameters,
+	// the API key, security credentials and custom headers below win over them
+	for key, values := range params.sessionHeaders {
+		req.Header[key] = values
 	}
 
 	// Add headers collected from input/spec AND potentially injected API key
@@ -763,31 +1212,77 @@ func executeToolCall(params *ToolCallParams, toolSet *mcp.ToolSet, cfg *config.C
 		}
 	}
 
+	// Idempotency key for deduplicated operations, unless the caller supplied one
+	if params.idempotencyKey != "" {
+		keyHeader := cfg.GetIdempotencyKeyHeader()
+		if req.Header.Get(keyHeader) == "" {
+			req.Header.Set(keyHeader, params.idempotencyKey)
+		}
+	}
+
 	// --- Add Cookies ---
 	for _, cookie := range cookieParams {
 		req.AddCookie(cookie)
 	}
+	loginSession.addTo(req) // Session cookies from the last re-login
 
-	log.Printf("[ExecuteToolCall] Sending request with headers: %v", req.Header)
-	if len(req.Cookies()) > 0 {
-		log.Printf("[ExecuteToolCall] Sending request with cookies: %+v", req.Cookies())
+	log.Printf("[ExecuteToolCall] Sending request with headers: %v", redactedHeaders(req.Header, toolSet, cfg))
+	if cookies := req.Cookies(); len(cookies) > 0 {
+		names := make([]string, len(cookies))
+		for i, cookie := range cookies {
+			names[i] = cookie.Name
+		}
+		log.Printf("[ExecuteToolCall] Sending request with cookies: %s", strings.Join(names, ", "))
 	}
 
 	// --- Execute HTTP Request ---
-	log.Printf("[ExecuteToolCall] Sending request with headers: %v", req.Header)
-	client := &http.Client{Timeout: 120 * time.Second}
+	timeout := effectiveTimeout(toolName, operation, cfg)
+	if err := upstreamCircuitBreaker.allow(req.URL.Host, cfg); err != nil {
+		log.Printf("[ExecuteToolCall] Failing fast: %v", err)
+		return nil, err
+	}
+	client := &http.Client{Timeout: timeout, Transport: upstreamTransport(cfg), CheckRedirect: redirectPolicy(params, cfg)}
 	resp, err := client.Do(req)
+	// A backend that can't read gzip bodies gets the body once more, uncompressed
+	if err == nil && contentEncoding != "" && rejectsRequestEncoding(resp) {
+		log.Printf("[ExecuteToolCall] %s rejected the gzip-encoded request body of tool '%s' with %s; retrying uncompressed. Consider disabling request compression for this host.", req.URL.Host, toolName, resp.Status)
+		resp.Body.Close()
+		retry, retryErr := uncompressedRequest(req, bodyBytes)
+		if retryErr != nil {
+			return nil, fmt.Errorf("error creating request: %w", retryErr)
+		}
+		resp, err = client.Do(retry)
+	}
+	// Transport errors and 5xx responses count against the host's circuit
+	upstreamCircuitBreaker.record(req.URL.Host, err == nil && resp.StatusCode < 500, cfg)
 	if err != nil {
 		log.Printf("[ExecuteToolCall] Error executing HTTP request: %v", err)
+		if phaseErr := upstreamPhaseTimeout(err, cfg); phaseErr != nil {
+			return nil, phaseErr
+		}
+		if isTimeoutError(err) {
+			return nil, &errUpstreamTimeout{budget: timeout}
+		}
 		return nil, fmt.Errorf("error executing request: %w", err)
 	}
+	if err := loginRedirect(resp, cfg); err != nil {
+		resp.Body.Close()
+		log.Printf("[ExecuteToolCall] Tool '%s': %v", toolName, err)
+		return nil, err
+	}
 
 	log.Printf("[ExecuteToolCall] Request executed. Status Code: %d", resp.StatusCode)
+
+	if err := decodeResponseBody(resp); err != nil {
+		resp.Body.Close()
+		log.Printf("[ExecuteToolCall] Error decoding response body: %v", err)
+		return nil, fmt.Errorf("error decoding response body: %w", err)
+	}
 	// Note: Don't close resp.Body here, the caller (handleToolCallJSONRPC) needs it.
 	return resp, nil
 }
 
-func handleToolCallJSONRPC(connID string, req *jsonRPCRequest, toolSet *mcp.ToolSet, cfg *config.Config) jsonRPCResponse {
+func handleToolCallJSONRPC(connID string, req *jsonRPCRequest, toolSet *mcp.ToolSet, cfg *config.Config) (resp jsonRPCResponse) {
 	// req.Params is interface{}, but should contain json.RawMessage for tools/call
 	rawParams, ok := req.Params.(json.RawMessage)
 	if !ok {
@@ -811,15 +1306,95 @@ func handleToolCallJSONRPC(connID string, req *jsonRPCRequest, toolSet *mcp.Tool
 
 	// Now, unmarshal the rawParams ([]byte) into ToolCallParams
 	var params ToolCallParams
-	if err := json.Unmarshal(rawParams, &params); err != nil {
+	if err := decodeJSON(rawParams, &params); err != nil {
 		log.Printf("Error unmarshalling tools/call params for %s: %v", connID, err)
 		return createJSONRPCError(req.ID, -32602, "Invalid parameters structure (unmarshal)", err.Error())
 	}
+	started := time.Now()
+	defer func() { mcpConnectionManager.RecordToolCall(connID, toolCallRecord(params.ToolName, started, resp)) }()
+
+	if cfg != nil && cfg.CoerceStringArguments {
+		coerceStringArguments(params.ToolName, connID, toolSet, params.Input)
+	}
 
-	log.Printf("Executing tool '%s' for %s with input: %+v", params.ToolName, connID, params.Input)
+	// Fill in or pass through missing required arguments as configured for the tool
+	var passthrough map[string]bool
+	if _, generated := toolSet.Operations[params.ToolName]; generated {
+		for _, tool := range toolSet.Tools {
+			if tool.Name == params.ToolName {
+				params.Input, passthrough = applyMissingRequired(params.ToolName, tool.InputSchema, params.Input, cfg)
+				break
+			}
+		}
+	}
+
+	// Reject arguments that don't match the tool's input schema before doing any work
+	if cfg == nil || !cfg.DisableInputValidation {
+		for _, tool := range listTools(toolSet, cfg) {
+			if tool.Name != params.ToolName {
+				continue
+			}
+			validationErrors := withoutPassthroughErrors(validateToolInput(tool.InputSchema, params.Input), passthrough)
+			if cfg != nil && cfg.ValidateFormats {
+				validationErrors = append(validationErrors, validateToolInputFormats(tool.InputSchema, params.Input)...)
+				sortValidationErrors(validationErrors)
+			}
+			if len(validationErrors) > 0 {
+				log.Printf("Rejecting tool call '%s' for %s: %d validation error(s)", params.ToolName, connID, len(validationErrors))
+				validationFailures.record(params.ToolName, validationErrors)
+				return createJSONRPCError(req.ID, -32602, fmt.Sprintf("Invalid arguments for tool '%s'", params.ToolName), validationErrors)
+			}
+			break
+		}
+	}
 
+	// Middleware wraps the tool itself; built-in and registered tools are answered locally
+	// without calling the upstream API
+	final := func(ctx context.Context, call *ToolCall) (interface{}, error) {
+		params.Input = call.Arguments
+		return invokeTool(ctx, connID, req, &params, toolSet, cfg)
+	}
+	call := &ToolCall{Name: params.ToolName, Arguments: params.Input, ConnectionID: connID}
+	result, err := chainToolCall(toolSet.Middleware, final)(context.Background(), call)
+	var rpcErr *rpcErrorResult
+	if errors.As(err, &rpcErr) {
+		return rpcErr.resp
+	}
+
+	// --- Send Response ---
+	return jsonRPCResponse{
+		Jsonrpc: "2.0",
+		ID:      req.ID, // Match request ID
+		Result:  toolCallResult(params.ToolName, req, result, err),
+	}
+}
+
+// callToolUpstream executes a tool call against the upstream API and converts the HTTP
+// response into a tool result.
+func callToolUpstream(connID string, req *jsonRPCRequest, params *ToolCallParams, toolSet *mcp.ToolSet, cfg *config.Config) ToolResultPayload {
 	// --- Execute the actual tool call ---
-	httpResp, execErr := executeToolCall(&params, toolSet, cfg)
+	params.sessionHeaders = mcpConnectionManager.SessionHeaders(connID)
+	httpResp, execErr := executeWithRetries(params, toolSet, cfg)
+	var tooLong *errURLTooLong
+	if errors.As(execErr, &tooLong) {
+		if fallback, ok := longQueryFallback(params, toolSet, cfg); ok {
+			log.Printf("[ExecuteToolCall] URL of tool '%s' is %d bytes, calling its long query fallback '%s' instead", params.ToolName, tooLong.length, fallback.ToolName)
+			return callLongQueryFallback(connID, req, fallback, toolSet, cfg)
+		}
+	}
+
+	// Streaming operations forward the body incrementally instead of buffering it; an event stream
+	// never ends on its own terms, so it is always forwarded event by event
+	if execErr == nil && httpResp.StatusCode >= 200 && httpResp.StatusCode < 300 && !isNoContentStatus(httpResp.StatusCode) {
+		if isEventStream(httpResp) {
+			defer httpResp.Body.Close()
+			return withResponseMeta(connID, params.ToolName, toolSet, httpResp, cfg, streamEventStreamResponse(connID, req, params, httpResp, cfg))
+		}
+		if isStreamingOperation(params.ToolName, toolSet, cfg) {
+			defer httpResp.Body.Close()
+			return withResponseMeta(connID, params.ToolName, toolSet, httpResp, cfg, streamToolResponse(connID, req, params, httpResp, cfg))
+		}
+	}
 
 	// --- Process Response ---
 	var resultPayload ToolResultPayload
@@ -833,15 +1408,32 @@ func handleToolCallJSONRPC(connID string, req *jsonRPCRequest, toolSet *mcp.Tool
 					Text: fmt.Sprintf("Failed to execute tool '%s': %v", params.ToolName, execErr),
 				},
 			},
-			// Error: &MCPError{
-			// 	Message: fmt.Sprintf("Failed to execute tool '%s': %v", params.ToolName, execErr),
-			// },
+			Error: &MCPError{
+				Message: fmt.Sprintf("Failed to execute tool '%s': %v", params.ToolName, execErr),
+			},
 			ToolCallID: fmt.Sprintf("%v", req.ID),
 		}
+		resultPayload = withFailureCategory(resultPayload, execFailureCategory(execErr))
 	} else {
 		defer httpResp.Body.Close() // Ensure body is closed
-		bodyBytes, readErr := io.ReadAll(httpResp.Body)
-		if readErr != nil {
+		var failure *failureCategory
+		maxBytes := effectiveMaxResponseBytes(params.ToolName, toolSet.Operations[params.ToolName], cfg)
+		bodyBytes, readErr := readLimitedBody(httpResp.Body, maxBytes)
+		if readErr == nil {
+			bodyBytes, readErr = followNextLinks(params.ToolName, httpResp, bodyBytes, maxBytes, toolSet.Operations[params.ToolName], cfg)
+		}
+		var tooLargeErr *errResponseTooLarge
+		if errors.As(readErr, &tooLargeErr) {
+			log.Printf("Response for tool '%s' exceeded %d bytes, discarding it", params.ToolName, maxBytes)
+			message := fmt.Sprintf("Tool '%s' response exceeded the maximum size of %d bytes", params.ToolName, maxBytes)
+			resultPayload = ToolResultPayload{
+				IsError:    true,
+				Content:    []ToolResultContent{{Type: "text", Text: message}},
+				Error:      &MCPError{Message: message},
+				ToolCallID: fmt.Sprintf("%v", req.ID),
+			}
+			failure = &failureCategory{ErrorCategoryClientError, false}
+		} else if readErr != nil {
 			log.Printf("Error reading response body for tool '%s': %v", params.ToolName, readErr)
 			resultPayload = ToolResultPayload{
 				IsError: true,
@@ -853,10 +1445,19 @@ func handleToolCallJSONRPC(connID string, req *jsonRPCRequest, toolSet *mcp.Tool
 				},
 				ToolCallID: fmt.Sprintf("%v", req.ID),
 			}
+			failure = &failureCategory{ErrorCategoryServerError, true}
 		} else {
 			log.Printf("Received response body for tool '%s': %s", params.ToolName, string(bodyBytes))
-			// Check status code for API-level errors
-			if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+			// Check status code for API-level errors; a bodiless 304 is a success, not one of them
+			if httpResp.StatusCode == http.StatusTooManyRequests {
+				resultPayload = rateLimitedResult(params.ToolName, req, httpResp)
+				category := statusFailureCategory(httpResp.StatusCode)
+				failure = &category
+			} else if isNoContentResponse(httpResp, bodyBytes) {
+				resultPayload = noContentResult(params.ToolName, req, httpResp, cfg)
+			} else if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+				category := statusFailureCategory(httpResp.StatusCode)
+				failure = &category
 				resultPayload = ToolResultPayload{
 					IsError: true,
 					Content: []ToolResultContent{
@@ -872,29 +1473,40 @@ func handleToolCallJSONRPC(connID string, req *jsonRPCRequest, toolSet *mcp.Tool
 					// },
 					ToolCallID: fmt.Sprintf("%v", req.ID),
 				}
+				resultPayload = withStructuredResult(params.ToolName, httpResp, bodyBytes, cfg, resultPayload)
 			} else {
 				// Successful execution
-				resultContent := []ToolResultContent{
-					{
-						Type: "text", // TODO: Handle JSON responses properly if Content-Type indicates it
-						Text: string(bodyBytes),
-					},
-				}
+				bodyBytes = projectResponseBody(params.ToolName, bodyBytes, cfg)
+				bodyBytes = transformResponseBody(params.ToolName, bodyBytes, cfg)
+				resultContent := responseContent(params.ToolName, httpResp, bodyBytes, toolSet.ContentHandlers)
+				resultContent = withBinaryFieldContent(params.ToolName, httpResp, resultContent, toolSet.Operations[params.ToolName], cfg)
 				resultPayload = ToolResultPayload{
 					Content:    resultContent,
 					IsError:    false,
 					ToolCallID: fmt.Sprintf("%v", req.ID),
 				}
+				resultPayload = withStructuredResult(params.ToolName, httpResp, bodyBytes, cfg, resultPayload)
 			}
 		}
+		if failure != nil {
+			resultPayload = withFailureCategory(resultPayload, *failure)
+		}
+		resultPayload = withResponseMeta(connID, params.ToolName, toolSet, httpResp, cfg, resultPayload)
 	}
 
-	// --- Send Response ---
-	return jsonRPCResponse{
-		Jsonrpc: "2.0",
-		ID:      req.ID,        // Match request ID
-		Result:  resultPayload, // Use the actual result payload
+	return resultPayload
+}
+
+// withResponseMeta adds what every result of an upstream response carries, streamed or not: the
+// upstream's rate limit headers in _meta and the tool's deprecation warning.
+func withResponseMeta(connID string, toolName string, toolSet *mcp.ToolSet, httpResp *http.Response, cfg *config.Config, result ToolResultPayload) ToolResultPayload {
+	for key, value := range rateLimitMeta(httpResp, cfg) {
+		if result.Meta == nil {
+			result.Meta = make(map[string]interface{})
+		}
+		result.Meta[key] = value
 	}
+	return withDeprecationWarning(connID, toolName, toolSet, httpResp, result)
 }
 
 // --- Helper Functions (Updated for JSON-RPC) ---
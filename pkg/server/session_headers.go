@@ -0,0 +1,37 @@
+package server
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+)
+
+// sessionHeadersFromMeta maps the _meta of an initialize request to upstream headers through
+// cfg.SessionHeaders, splitting off the sensitive ones. Keys the client did not send, or sent
+// with a non-scalar value, are left out.
+func sessionHeadersFromMeta(meta map[string]interface{}, cfg *config.Config) (headers, sensitive map[string]string) {
+	if cfg == nil || len(cfg.SessionHeaders) == 0 || len(meta) == 0 {
+		return nil, nil
+	}
+	for key, mapping := range cfg.SessionHeaders {
+		value, ok := meta[key]
+		if !ok || value == nil {
+			continue
+		}
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			log.Printf("Warning: ignoring initialize _meta.%s for header %s: not a string, number or boolean", key, mapping.Header)
+			continue
+		}
+		target := &headers
+		if mapping.Sensitive {
+			target = &sensitive
+		}
+		if *target == nil {
+			*target = make(map[string]string)
+		}
+		(*target)[http.CanonicalHeaderKey(mapping.Header)] = formatScalar(value)
+	}
+	return headers, sensitive
+}
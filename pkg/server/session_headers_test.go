@@ -0,0 +1,125 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionHeadersFromMeta(t *testing.T) {
+	cfg := &config.Config{SessionHeaders: map[string]config.SessionHeader{
+		"tenant":  {Header: "x-tenant-id"},
+		"env":     {Header: "X-Environment"},
+		"token":   {Header: "X-Session-Token", Sensitive: true},
+		"profile": {Header: "X-Profile"},
+	}}
+	headers, sensitive := sessionHeadersFromMeta(map[string]interface{}{
+		"tenant":  "acme",
+		"token":   "s3cr3t",
+		"profile": map[string]interface{}{"name": "x"},
+		"other":   "ignored",
+	}, cfg)
+	assert.Equal(t, map[string]string{"X-Tenant-Id": "acme"}, headers, "missing and non-scalar keys are not sent")
+	assert.Equal(t, map[string]string{"X-Session-Token": "s3cr3t"}, sensitive)
+
+	headers, sensitive = sessionHeadersFromMeta(map[string]interface{}{"tenant": "acme"}, &config.Config{})
+	assert.Nil(t, headers)
+	assert.Nil(t, sensitive)
+}
+
+func TestSessionHeaders_SentOnToolCalls(t *testing.T) {
+	var received http.Header
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.Write([]byte(`{}`))
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"get_report": {Method: "GET", Path: "/report", BaseURL: backend.URL},
+	}}
+	cfg := &config.Config{SessionHeaders: map[string]config.SessionHeader{
+		"tenant":      {Header: "X-Tenant-ID"},
+		"environment": {Header: "X-Environment"},
+		"token":       {Header: "X-Session-Token", Sensitive: true},
+	}}
+
+	connID := "session-headers-conn"
+	conn, _ := setupTestConnection(connID)
+	defer cleanupTestConnection(connID)
+
+	initialize := &jsonRPCRequest{Jsonrpc: "2.0", ID: 1, Method: "initialize", Params: map[string]interface{}{
+		"protocolVersion": defaultProtocolVersion,
+		"_meta":           map[string]interface{}{"tenant": "acme", "token": "s3cr3t"},
+	}}
+	resp, _ := dispatchJSONRPC(conn, connID, initialize, toolSet, cfg)
+	require.Nil(t, resp.Error)
+	mcpConnectionManager.UpdateState(connID, StateReady)
+
+	rawParams, _ := json.Marshal(map[string]interface{}{"name": "get_report", "arguments": map[string]interface{}{}})
+	call := &jsonRPCRequest{Jsonrpc: "2.0", ID: 2, Method: "tools/call", Params: json.RawMessage(rawParams)}
+	result := handleToolCallJSONRPC(connID, call, toolSet, cfg).Result.(ToolResultPayload)
+	require.False(t, result.IsError)
+
+	assert.Equal(t, "acme", received.Get("X-Tenant-ID"))
+	assert.Equal(t, "s3cr3t", received.Get("X-Session-Token"))
+	assert.Empty(t, received.Values("X-Environment"), "keys missing from initialize are not sent")
+
+	stored := mcpConnectionManager.GetConnection(connID)
+	assert.Equal(t, map[string]string{"X-Tenant-Id": "acme"}, stored.SessionHeaders, "sensitive values stay out of the persisted fields")
+}
+
+func TestSessionHeaders_DoNotOverrideCredentials(t *testing.T) {
+	var received http.Header
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.Write([]byte(`{}`))
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{
+		Operations: map[string]mcp.OperationDetail{
+			"get_report": {Method: "GET", Path: "/report", BaseURL: backend.URL,
+				Security: []map[string][]string{{"bearerAuth": {}}}},
+		},
+		SecuritySchemes: map[string]mcp.SecurityScheme{"bearerAuth": {Type: "http", Scheme: "bearer"}},
+	}
+	cfg := &config.Config{
+		APIKey:              "server-key",
+		APIKeyName:          "X-API-Key",
+		APIKeyLocation:      config.APIKeyLocationHeader,
+		SecurityCredentials: map[string]string{"bearerAuth": "server-token"},
+		SessionHeaders: map[string]config.SessionHeader{
+			"key":    {Header: "X-API-Key"},
+			"auth":   {Header: "Authorization", Sensitive: true},
+			"tenant": {Header: "X-Tenant-ID"},
+		},
+	}
+
+	connID := "session-headers-credentials-conn"
+	conn, _ := setupTestConnection(connID)
+	defer cleanupTestConnection(connID)
+
+	initialize := &jsonRPCRequest{Jsonrpc: "2.0", ID: 1, Method: "initialize", Params: map[string]interface{}{
+		"protocolVersion": defaultProtocolVersion,
+		"_meta":           map[string]interface{}{"key": "client-key", "auth": "Bearer client-token", "tenant": "acme"},
+	}}
+	resp, _ := dispatchJSONRPC(conn, connID, initialize, toolSet, cfg)
+	require.Nil(t, resp.Error)
+	mcpConnectionManager.UpdateState(connID, StateReady)
+
+	rawParams, _ := json.Marshal(map[string]interface{}{"name": "get_report", "arguments": map[string]interface{}{}})
+	call := &jsonRPCRequest{Jsonrpc: "2.0", ID: 2, Method: "tools/call", Params: json.RawMessage(rawParams)}
+	result := handleToolCallJSONRPC(connID, call, toolSet, cfg).Result.(ToolResultPayload)
+	require.False(t, result.IsError)
+
+	assert.Equal(t, []string{"server-key"}, received.Values("X-API-Key"), "the server API key wins")
+	assert.Equal(t, []string{"Bearer server-token"}, received.Values("Authorization"), "the configured credential wins")
+	assert.Equal(t, "acme", received.Get("X-Tenant-ID"), "other session headers are still sent")
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/litui/openapi-mcp-claude/pkg/parser"
+)
+
+// toolSetSource holds the tool set served to clients. Hot reload and tool registration swap in a
+// whole new tool set, so a request keeps using the snapshot it started with and never sees a
+// half-built one.
+type toolSetSource struct {
+	current atomic.Pointer[mcp.ToolSet]
+	mutex   sync.Mutex // Serializes updates; readers only Load
+}
+
+// newToolSetSource returns a source serving toolSet.
+func newToolSetSource(toolSet *mcp.ToolSet) *toolSetSource {
+	source := &toolSetSource{}
+	source.current.Store(toolSet)
+	return source
+}
+
+// Load returns the current tool set.
+func (s *toolSetSource) Load() *mcp.ToolSet {
+	return s.current.Load()
+}
+
+// update replaces the tool set with the one build returns for the current one. build must not
+// modify the current tool set; on error nothing changes.
+func (s *toolSetSource) update(build func(current *mcp.ToolSet) (*mcp.ToolSet, error)) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	next, err := build(s.current.Load())
+	if err != nil {
+		return err
+	}
+	s.current.Store(next)
+	return nil
+}
+
+// hotReloadEnabled reports whether the spec is polled for changes. A spec read from standard input
+// can't be read again.
+func hotReloadEnabled(cfg *config.Config) bool {
+	return cfg != nil && cfg.SpecPollInterval > 0 && cfg.SpecPath != parser.StdinSpecLocation
+}
+
+// specLoadOptions are the options the spec was loaded with at startup.
+func specLoadOptions(cfg *config.Config) parser.LoadOptions {
+	return parser.LoadOptions{
+		InterpolateEnv: cfg.SpecEnvInterpolation,
+		StrictEnv:      cfg.SpecEnvStrict,
+		SpecFormat:     cfg.SpecFormat,
+	}
+}
+
+// specLoadOptions are the options hot reload loads the spec with: those of startup plus the
+// server's spec transforms.
+func (s *Server) specLoadOptions() parser.LoadOptions {
+	opts := specLoadOptions(s.cfg)
+	for _, transform := range s.specTransforms {
+		opts = opts.WithSpecTransform(transform)
+	}
+	return opts
+}
+
+// WithSpecTransform adds a transform that hot reload applies to every reloaded spec before
+// regenerating the tools, after the transforms already added. The server's initial tools are
+// generated by the embedding program, which should load the spec with the same transforms, see
+// parser.LoadOptions.WithSpecTransform. Call it before ListenAndServe.
+func (s *Server) WithSpecTransform(transform parser.SpecTransform) *Server {
+	s.specTransforms = append(s.specTransforms, transform)
+	return s
+}
+
+// watchSpec polls the spec every SpecPollInterval until stop is closed, rebuilding the tools when
+// it changes. The first poll only records the current spec, which the server already serves.
+func watchSpec(source *toolSetSource, cfg *config.Config, watcher *parser.SpecWatcher, stop <-chan struct{}) {
+	if _, _, _, err := watcher.Check(); err != nil {
+		log.Printf("[SpecReload] Initial check of %s failed: %v", cfg.SpecPath, err)
+	}
+
+	ticker := time.NewTicker(cfg.SpecPollInterval)
+	defer ticker.Stop()
+	log.Printf("[SpecReload] Polling %s every %s", cfg.SpecPath, cfg.SpecPollInterval)
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if _, err := reloadSpec(source, cfg, watcher); err != nil {
+				log.Printf("[SpecReload] Keeping the current tools: %v", err)
+			}
+		}
+	}
+}
+
+// reloadSpec checks the spec once and, if it changed, regenerates the tools, swaps them in and
+// tells every ready connection with notifications/tools/list_changed. It reports whether the tools
+// were rebuilt. Prompts come from their own file, and registered tools, middleware and content
+// handlers from the embedding program, so all of them carry over.
+func reloadSpec(source *toolSetSource, cfg *config.Config, watcher *parser.SpecWatcher) (bool, error) {
+	doc, version, changed, err := watcher.Check()
+	if err != nil || !changed {
+		return false, err
+	}
+	generated, err := parser.GenerateToolSet(doc, version, cfg)
+	if err != nil {
+		return false, err
+	}
+	err = source.update(func(previous *mcp.ToolSet) (*mcp.ToolSet, error) {
+		if previous == nil {
+			return generated, nil
+		}
+		generated.Prompts = previous.Prompts
+		generated.Middleware = previous.Middleware
+		generated.ContentHandlers = previous.ContentHandlers
+		for _, tool := range previous.Tools {
+			if handler, ok := previous.CustomHandlers[tool.Name]; ok {
+				if err := addCustomTool(generated, tool, handler); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return generated, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	log.Printf("[SpecReload] Spec changed; now serving %d tools", len(generated.Tools))
+	warnIfNoTools(generated)
+
+	notifyToolsListChanged()
+	return true, nil
+}
+
+// notifyToolsListChanged queues notifications/tools/list_changed for every ready connection.
+func notifyToolsListChanged() {
+	mcpConnectionManager.Broadcast(isReady, newJSONRPCNotification("notifications/tools/list_changed", nil))
+}
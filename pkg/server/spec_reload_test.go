@@ -0,0 +1,182 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/litui/openapi-mcp-claude/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func reloadSpecJSON(operationIDs ...string) string {
+	paths := ""
+	for i, id := range operationIDs {
+		if i > 0 {
+			paths += ","
+		}
+		paths += fmt.Sprintf(`"/%s": {"get": {"operationId": %q, "responses": {"200": {"description": "OK"}}}}`, id, id)
+	}
+	return fmt.Sprintf(`{"openapi": "3.0.0", "info": {"title": "Reload API", "version": "1.0.0"}, "paths": {%s}}`, paths)
+}
+
+func toolSetNames(toolSet *mcp.ToolSet) []string {
+	var names []string
+	for _, tool := range toolSet.Tools {
+		if tool.Name != describeOperationToolName {
+			names = append(names, tool.Name)
+		}
+	}
+	return names
+}
+
+func TestReloadSpec_ConditionalURL(t *testing.T) {
+	var version atomic.Int32
+	version.Store(1)
+	var fullFetches atomic.Int32
+	specServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := fmt.Sprintf(`"v%d"`, version.Load())
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fullFetches.Add(1)
+		w.Header().Set("ETag", etag)
+		if version.Load() == 1 {
+			w.Write([]byte(reloadSpecJSON("listItems")))
+		} else {
+			w.Write([]byte(reloadSpecJSON("listItems", "listOrders")))
+		}
+	}))
+	defer specServer.Close()
+
+	cfg := &config.Config{SpecPath: specServer.URL + "/openapi.json", SpecPollInterval: 1}
+	watcher := parser.NewSpecWatcher(cfg.SpecPath, specLoadOptions(cfg))
+	doc, specVersion, _, err := watcher.Check() // As at startup
+	require.NoError(t, err)
+	initial, err := parser.GenerateToolSet(doc, specVersion, cfg)
+	require.NoError(t, err)
+	initial.Prompts = []mcp.Prompt{{Name: "summarize", Template: "Summarize"}}
+	tools := newToolSetSource(initial)
+
+	connID := "test-reload-conn"
+	conn, channel := setupTestConnection(connID)
+	defer cleanupTestConnection(connID)
+	mcpConnectionManager.UpdateState(conn.ID, StateReady)
+
+	// Unchanged spec: 304, nothing rebuilt or sent
+	rebuilt, err := reloadSpec(tools, cfg, watcher)
+	require.NoError(t, err)
+	assert.False(t, rebuilt)
+	assert.Same(t, initial, tools.Load())
+	assert.Empty(t, channel)
+	assert.Equal(t, int32(1), fullFetches.Load(), "the 304 downloaded nothing")
+
+	// Changed spec: 200, tools rebuilt and clients told
+	version.Store(2)
+	rebuilt, err = reloadSpec(tools, cfg, watcher)
+	require.NoError(t, err)
+	assert.True(t, rebuilt)
+	assert.Equal(t, []string{"listItems"}, toolSetNames(initial), "the previous snapshot is untouched")
+	assert.ElementsMatch(t, []string{"listItems", "listOrders"}, toolSetNames(tools.Load()))
+	assert.Equal(t, initial.Prompts, tools.Load().Prompts, "prompts carry over")
+
+	require.Len(t, channel, 1)
+	notification := <-channel
+	assert.Equal(t, "notifications/tools/list_changed", notification.Method)
+
+	// And unchanged again
+	rebuilt, err = reloadSpec(tools, cfg, watcher)
+	require.NoError(t, err)
+	assert.False(t, rebuilt)
+	assert.Equal(t, int32(2), fullFetches.Load())
+}
+
+func TestReloadSpec_KeepsToolsOnError(t *testing.T) {
+	var body atomic.Value
+	body.Store(reloadSpecJSON("listItems"))
+	specServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body.Load().(string)))
+	}))
+	defer specServer.Close()
+
+	cfg := &config.Config{SpecPath: specServer.URL + "/openapi.json", SpecPollInterval: 1}
+	watcher := parser.NewSpecWatcher(cfg.SpecPath, specLoadOptions(cfg))
+	_, _, _, err := watcher.Check()
+	require.NoError(t, err)
+	initial := &mcp.ToolSet{}
+	tools := newToolSetSource(initial)
+
+	body.Store(`{"openapi": "3.0.0", "paths": `) // Truncated
+	rebuilt, err := reloadSpec(tools, cfg, watcher)
+	assert.Error(t, err)
+	assert.False(t, rebuilt)
+	assert.Same(t, initial, tools.Load())
+}
+
+func TestInitialize_AdvertisesToolsListChanged(t *testing.T) {
+	connID := "test-list-changed-conn"
+	setupTestConnection(connID)
+	defer cleanupTestConnection(connID)
+	req := &jsonRPCRequest{Jsonrpc: "2.0", ID: 1, Method: "initialize", Params: map[string]interface{}{}}
+
+	toolsCapability := func(cfg *config.Config) map[string]interface{} {
+		resp := handleInitializeJSONRPC(connID, req, &mcp.ToolSet{}, cfg)
+		capabilities := resp.Result.(map[string]interface{})["capabilities"].(map[string]interface{})
+		return capabilities["tools"].(map[string]interface{})
+	}
+	assert.Equal(t, false, toolsCapability(&config.Config{})["listChanged"])
+	assert.Equal(t, true, toolsCapability(&config.Config{SpecPollInterval: 30})["listChanged"])
+}
+
+func TestReloadSpec_KeepsRegisteredTools(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.json")
+	require.NoError(t, os.WriteFile(specPath, []byte(reloadSpecJSON("listItems")), 0644))
+	cfg := &config.Config{SpecPath: specPath, SpecPollInterval: 1}
+	watcher := parser.NewSpecWatcher(cfg.SpecPath, specLoadOptions(cfg))
+	doc, specVersion, _, err := watcher.Check()
+	require.NoError(t, err)
+	initial, err := parser.GenerateToolSet(doc, specVersion, cfg)
+	require.NoError(t, err)
+
+	s := NewServer(initial, cfg)
+	require.NoError(t, s.RegisterTool("add", "", nil, addNumbers))
+
+	require.NoError(t, os.WriteFile(specPath, []byte(reloadSpecJSON("listItems", "listOrders")), 0644))
+	rebuilt, err := reloadSpec(s.tools, cfg, watcher)
+	require.NoError(t, err)
+	assert.True(t, rebuilt)
+	assert.ElementsMatch(t, []string{"listItems", "listOrders", "add"}, toolSetNames(s.tools.Load()))
+	assert.Contains(t, s.tools.Load().CustomHandlers, "add")
+
+	// A spec that now defines a registered tool's name is not swapped in
+	before := s.tools.Load()
+	require.NoError(t, os.WriteFile(specPath, []byte(reloadSpecJSON("listItems", "add")), 0644))
+	rebuilt, err = reloadSpec(s.tools, cfg, watcher)
+	assert.ErrorContains(t, err, "already defined by the OpenAPI spec")
+	assert.False(t, rebuilt)
+	assert.Same(t, before, s.tools.Load())
+}
+
+func TestReloadSpec_AppliesSpecTransforms(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.json")
+	require.NoError(t, os.WriteFile(specPath, []byte(reloadSpecJSON("listItems")), 0644))
+	cfg := &config.Config{SpecPath: specPath, SpecPollInterval: 1}
+	s := NewServer(&mcp.ToolSet{}, cfg).WithSpecTransform(func(doc interface{}) error {
+		doc.(*openapi3.T).Paths.Value("/listItems").Get.Tags = []string{"inventory"}
+		return nil
+	})
+
+	rebuilt, err := reloadSpec(s.tools, cfg, parser.NewSpecWatcher(cfg.SpecPath, s.specLoadOptions()))
+	require.NoError(t, err)
+	assert.True(t, rebuilt)
+	assert.Equal(t, []string{"inventory"}, s.tools.Load().Operations["listItems"].Tags)
+}
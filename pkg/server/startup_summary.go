@@ -0,0 +1,83 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// logStartupSummary logs the one-line "here's what I am" summary once the server listens, as
+// key=value pairs: the spec, how many of its operations became tools and through which filters,
+// where clients connect, whether connection state persists, and which upstream is called.
+func logStartupSummary(toolSet *mcp.ToolSet, cfg *config.Config, listenURL string) {
+	log.Printf("[Startup] %s", startupSummary(toolSet, cfg, listenURL))
+}
+
+// startupSummary builds the line logged by logStartupSummary.
+func startupSummary(toolSet *mcp.ToolSet, cfg *config.Config, listenURL string) string {
+	persistence := "off"
+	if cfg.StateFilePath != "" {
+		persistence = "on"
+	}
+	fields := []string{
+		fmt.Sprintf("spec=%q", cfg.SpecPath),
+		fmt.Sprintf("spec_version=%q", toolSet.SpecVersion),
+		fmt.Sprintf("operations=%d", toolSet.SpecOperations),
+		fmt.Sprintf("tools=%d", len(toolSet.Operations)),
+		fmt.Sprintf("filters=%q", strings.Join(summaryFilters(cfg), " ")),
+		"transports=streamable-http,websocket",
+		fmt.Sprintf("listen=%s", listenURL),
+		fmt.Sprintf("persistence=%s", persistence),
+	}
+	if cfg.StateFilePath != "" {
+		fields = append(fields, fmt.Sprintf("state_file=%q", cfg.StateFilePath))
+	}
+	fields = append(fields, fmt.Sprintf("upstream=%q", strings.Join(summaryUpstreams(toolSet, cfg), ",")))
+	return strings.Join(fields, " ")
+}
+
+// summaryFilters lists the operation filters in effect, in flag form.
+func summaryFilters(cfg *config.Config) []string {
+	var filters []string
+	for _, filter := range []struct {
+		flag   string
+		values []string
+	}{
+		{"include-tag", cfg.IncludeTags},
+		{"exclude-tag", cfg.ExcludeTags},
+		{"include-op", cfg.IncludeOperations},
+		{"exclude-op", cfg.ExcludeOperations},
+		{"path-prefix", cfg.PathPrefixes},
+		{"allow-method", cfg.AllowedMethods},
+	} {
+		if len(filter.values) > 0 {
+			filters = append(filters, filter.flag+"="+strings.Join(filter.values, ","))
+		}
+	}
+	if cfg.ReadOnly {
+		filters = append(filters, "read-only")
+	}
+	return filters
+}
+
+// summaryUpstreams lists the upstream base URLs: the --base-url override, or each distinct one
+// the operations use.
+func summaryUpstreams(toolSet *mcp.ToolSet, cfg *config.Config) []string {
+	if cfg.ServerBaseURL != "" {
+		return []string{cfg.ServerBaseURL}
+	}
+	seen := make(map[string]bool)
+	var upstreams []string
+	for _, operation := range toolSet.Operations {
+		if operation.BaseURL != "" && !seen[operation.BaseURL] {
+			seen[operation.BaseURL] = true
+			upstreams = append(upstreams, operation.BaseURL)
+		}
+	}
+	sort.Strings(upstreams)
+	return upstreams
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const startupSummarySpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Pet Store", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com/v1"}],
+  "paths": {
+    "/pets": {
+      "get": {"operationId": "listPets", "tags": ["pets"], "responses": {"200": {"description": "OK"}}},
+      "post": {"operationId": "createPet", "tags": ["pets"], "responses": {"201": {"description": "Created"}}}
+    },
+    "/pets/{id}": {
+      "get": {"operationId": "getPet", "tags": ["pets"], "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}], "responses": {"200": {"description": "OK"}}}
+    },
+    "/invoices": {
+      "get": {"operationId": "listInvoices", "tags": ["billing"], "responses": {"200": {"description": "OK"}}}
+    }
+  }
+}`
+
+func TestLogStartupSummary(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "petstore.json")
+	require.NoError(t, os.WriteFile(specPath, []byte(startupSummarySpec), 0644))
+	cfg := &config.Config{
+		SpecPath:      specPath,
+		IncludeTags:   []string{"pets"},
+		ReadOnly:      true,
+		StateFilePath: "/tmp/state.yaml",
+	}
+	doc, version, err := parser.LoadSwagger(specPath)
+	require.NoError(t, err)
+	toolSet, err := parser.GenerateToolSet(doc, version, cfg)
+	require.NoError(t, err)
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+	logStartupSummary(toolSet, cfg, "http://127.0.0.1:8080")
+
+	line := logged.String()
+	assert.Equal(t, 1, bytes.Count(logged.Bytes(), []byte("\n")), "the summary is a single line")
+	for _, field := range []string{
+		`[Startup] spec="` + specPath + `"`,
+		`spec_version="OpenAPI 3.0.3"`,
+		`operations=4`,
+		`tools=2`,
+		`filters="include-tag=pets read-only"`,
+		`transports=streamable-http,websocket`,
+		`listen=http://127.0.0.1:8080`,
+		`persistence=on`,
+		`state_file="/tmp/state.yaml"`,
+		`upstream="https://api.example.com/v1"`,
+	} {
+		assert.Contains(t, line, field)
+	}
+}
+
+func TestStartupSummary_Defaults(t *testing.T) {
+	cfg := &config.Config{SpecPath: "spec.yaml", ServerBaseURL: "http://localhost:9000"}
+	summary := startupSummary(createTestToolSetForCall(), cfg, "https://[::]:8443")
+	assert.Contains(t, summary, `filters=""`)
+	assert.Contains(t, summary, `persistence=off`)
+	assert.NotContains(t, summary, "state_file=")
+	assert.Contains(t, summary, `upstream="http://localhost:9000"`, "the --base-url override is reported")
+}
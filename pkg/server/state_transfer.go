@@ -0,0 +1,114 @@
+package server
+
+import (
+	"fmt"
+	"log"
+
+	"gopkg.in/yaml.v3"
+)
+
+// stateSnapshot is the portable form of the connection state. It has the layout of the state
+// file, so an export can also be checked with ValidateStateFile or used as a state file.
+type stateSnapshot struct {
+	Connection map[string]*Connection `yaml:"connection"`
+}
+
+// ExportState returns a snapshot of every connection's ID, state, negotiated protocol version,
+// client capabilities and timestamps, for handing sessions to another instance with ImportState.
+func (cm *ConnectionManager) ExportState() ([]byte, error) {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+
+	return yaml.Marshal(stateSnapshot{Connection: cm.connections})
+}
+
+// ImportState loads the connections of an ExportState snapshot into the manager. They get fresh
+// channels and wait, detached, for their client to reconnect (see ReattachConnection). IDs that
+// are already live here are kept as they are, as are shut down connections. Nothing is imported
+// if any entry is invalid, including IDs the connection ID policy rejects.
+func (cm *ConnectionManager) ImportState(data []byte) error {
+	var snapshot struct {
+		Connection map[string]interface{} `yaml:"connection"`
+	}
+	if err := yaml.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("invalid connection state: %w", err)
+	}
+	imported := make(map[string]*Connection, len(snapshot.Connection))
+	for key, entry := range snapshot.Connection {
+		conn, err := decodeStateEntry(entry)
+		if err != nil {
+			return fmt.Errorf("invalid connection state entry %q: %w", key, err)
+		}
+		imported[key] = conn
+	}
+
+	defer cm.flushState()
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	for key := range imported {
+		if err := cm.idPolicy.Validate(key); err != nil {
+			return fmt.Errorf("invalid connection state entry: %w", err)
+		}
+	}
+	added := 0
+	for key, conn := range imported {
+		id := cm.normalizeID(key)
+		if conn.State == StateShutdown {
+			continue
+		}
+		if _, ok := cm.connections[id]; ok {
+			log.Printf("[ConnectionManager] Not importing %s: already a live connection", id)
+			continue
+		}
+		conn.ID = id
+		conn.Channel = make(chan jsonRPCResponse, messageChannelBufferSize)
+		conn.sequencer = newResponseSequencer()
+		conn.detached = true
+		cm.addLocked(id, conn)
+		added++
+	}
+	log.Printf("[ConnectionManager] Imported %d of %d connection(s)", added, len(imported))
+	if added > 0 {
+		cm.persist()
+	}
+	return nil
+}
+
+// ReattachConnection hands a detached connection, imported or restored from the state file, to
+// the transport its client reconnected on, keeping its state so the client need not initialize
+// again. ok is false if no such connection exists or it is already attached; an ID the connection
+// ID policy rejects is an error wrapping ErrInvalidConnectionID.
+func (cm *ConnectionManager) ReattachConnection(id string) (conn *Connection, ok bool, err error) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	if err := cm.idPolicy.Validate(id); err != nil {
+		return nil, false, err
+	}
+	conn, ok = cm.connections[cm.normalizeID(id)]
+	if !ok || !conn.detached {
+		return nil, false, nil
+	}
+	conn.detached = false
+	log.Printf("[ConnectionManager] Reattached %s in state %s", conn.ID, conn.State)
+	return conn, true, nil
+}
+
+// detachConnection marks conn detached again, for a transport that claimed it but failed to serve.
+func (cm *ConnectionManager) detachConnection(conn *Connection) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	conn.detached = true
+}
+
+// ExportState returns a snapshot of the live connections, see ConnectionManager.ExportState.
+func (s *Server) ExportState() ([]byte, error) {
+	return mcpConnectionManager.ExportState()
+}
+
+// ImportState loads connections exported by another instance, see ConnectionManager.ImportState.
+func (s *Server) ImportState(data []byte) error {
+	return mcpConnectionManager.ImportState(data)
+}
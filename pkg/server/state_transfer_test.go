@@ -0,0 +1,61 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionManager_ExportImportState(t *testing.T) {
+	source := NewConnectionManager()
+	source.NewConnection("ready")
+	source.UpdateState("ready", StateReady)
+	source.SetProtocolVersion("ready", "2025-03-26")
+	source.SetClientCapabilities("ready", map[string]interface{}{"roots": map[string]interface{}{"listChanged": true}})
+	source.NewConnection("initializing")
+	source.UpdateState("initializing", StateInitializing)
+	source.NewConnection("shared")
+
+	data, err := source.ExportState()
+	require.NoError(t, err)
+
+	target := NewConnectionManager()
+	live, _ := target.NewConnection("shared")
+	require.NoError(t, target.ImportState(data))
+	assert.Equal(t, 3, target.GetConnectionCount())
+	assert.Same(t, live, target.GetConnection("shared"), "live connections are not overwritten")
+
+	original := source.GetConnection("ready")
+	imported := target.GetConnection("ready")
+	require.NotNil(t, imported)
+	assert.NotSame(t, original, imported)
+	assert.Equal(t, StateReady, imported.State)
+	assert.Equal(t, "2025-03-26", imported.ProtocolVersion)
+	assert.Equal(t, map[string]interface{}{"roots": map[string]interface{}{"listChanged": true}}, imported.ClientCapabilities)
+	assert.True(t, original.CreatedAt.Equal(imported.CreatedAt))
+	require.NotNil(t, imported.InitializedAt)
+	assert.True(t, original.InitializedAt.Equal(*imported.InitializedAt))
+	assert.Equal(t, 1, target.CountByState(StateReady), "imported connections are indexed by state")
+	assert.Equal(t, StateInitializing, target.GetConnection("initializing").State)
+
+	// The channel is new, and the connection is held until its client reconnects
+	require.NoError(t, imported.trySend(jsonRPCResponse{ID: 1}))
+	conn, ok, _ := target.ReattachConnection("ready")
+	require.True(t, ok)
+	assert.Same(t, imported, conn)
+	_, ok, _ = target.ReattachConnection("ready")
+	assert.False(t, ok, "a connection is reattached only once")
+	_, ok, _ = target.ReattachConnection("shared")
+	assert.False(t, ok, "live connections cannot be taken over")
+}
+
+func TestConnectionManager_ImportStateInvalid(t *testing.T) {
+	cm := NewConnectionManager()
+	assert.Error(t, cm.ImportState([]byte("connection: [")))
+	assert.Error(t, cm.ImportState([]byte("connection:\n  good:\n    state: 2\n  bad:\n    state: ready\n")))
+	assert.Zero(t, cm.GetConnectionCount(), "nothing is imported from an invalid snapshot")
+
+	require.NoError(t, cm.ImportState([]byte("connection:\n  gone:\n    state: 3\n")))
+	assert.Nil(t, cm.GetConnection("gone"), "shut down connections are skipped")
+}
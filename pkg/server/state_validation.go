@@ -0,0 +1,49 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/viper"
+)
+
+// InvalidStateEntry is a connection entry of the state file that cannot be loaded.
+type InvalidStateEntry struct {
+	Key string
+	Err error
+}
+
+// StateValidationResult reports which connection entries of a state file load cleanly.
+type StateValidationResult struct {
+	Valid   []string            // Keys of entries that load, sorted
+	Invalid []InvalidStateEntry // Entries NewConnectionManager would panic on, sorted by key
+}
+
+// ValidateStateFile reads a connection state file and checks every connection entry the way
+// NewConnectionManager loads it. The file is never written. An error is returned only when the
+// file itself cannot be read or parsed; bad entries are reported in the result.
+func ValidateStateFile(path string) (*StateValidationResult, error) {
+	// A private viper instance keeps the server's global state (and its WriteConfig) out of this
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("cannot read state file %s: %w", path, err)
+	}
+
+	entries := v.GetStringMap("connection")
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := &StateValidationResult{Valid: []string{}, Invalid: []InvalidStateEntry{}}
+	for _, key := range keys {
+		if _, err := decodeStateEntry(entries[key]); err != nil {
+			result.Invalid = append(result.Invalid, InvalidStateEntry{Key: key, Err: err})
+		} else {
+			result.Valid = append(result.Valid, key)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const stateFileWithCorruptEntry = `connection:
+  good-conn:
+    id: good-conn
+    state: 2
+    createdAt: 2025-01-02T03:04:05Z
+    lastActivity: 2025-01-02T03:04:05Z
+  bad-conn:
+    id: bad-conn
+    state: ready
+    createdAt: yesterday
+`
+
+func TestValidateStateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(stateFileWithCorruptEntry), 0o600))
+
+	result, err := ValidateStateFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"good-conn"}, result.Valid)
+	require.Len(t, result.Invalid, 1)
+	assert.Equal(t, "bad-conn", result.Invalid[0].Key)
+	assert.Contains(t, result.Invalid[0].Err.Error(), "cannot unmarshal")
+
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, stateFileWithCorruptEntry, string(after), "validation must not modify the file")
+}
+
+func TestValidateStateFile_Unreadable(t *testing.T) {
+	_, err := ValidateStateFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "broken.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("connection: [unterminated"), 0o600))
+	_, err = ValidateStateFile(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// defaultStreamMaxBytes caps the total size of a streamed upstream response when not configured.
+const defaultStreamMaxBytes = 10 << 20
+
+// isStreamingOperation reports whether a tool's upstream response should be forwarded incrementally,
+// either because the spec marks it (x-mcp-streaming or a streaming media type) or the config names it.
+func isStreamingOperation(toolName string, toolSet *mcp.ToolSet, cfg *config.Config) bool {
+	if operation, ok := toolSet.Operations[toolName]; ok && operation.Streaming {
+		return true
+	}
+	return cfg != nil && sliceContainsString(cfg.StreamingOperations, toolName)
+}
+
+// streamToolResponse reads the upstream body line by line (NDJSON records, SSE lines), queueing each
+// non-empty line on the connection's Channel as a notifications/progress message as it arrives.
+// The returned payload is the terminal result containing the complete body.
+func streamToolResponse(connID string, req *jsonRPCRequest, params *ToolCallParams, httpResp *http.Response, cfg *config.Config) ToolResultPayload {
+	maxBytes := int64(defaultStreamMaxBytes)
+	if cfg != nil && cfg.StreamMaxBytes > 0 {
+		maxBytes = cfg.StreamMaxBytes
+	}
+
+	// Clients correlate progress via the token they supplied, falling back to the request ID
+	var progressToken interface{} = req.ID
+	if token, ok := params.Meta["progressToken"]; ok && token != nil {
+		progressToken = token
+	}
+
+	conn := mcpConnectionManager.GetConnection(connID)
+	reader := bufio.NewReader(io.LimitReader(httpResp.Body, maxBytes+1))
+	var body strings.Builder
+	chunks := 0
+
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			body.WriteString(line)
+			if int64(body.Len()) > maxBytes {
+				log.Printf("[StreamToolCall] Stream for tool '%s' exceeded %d bytes, aborting", params.ToolName, maxBytes)
+				return streamFailure(req, fmt.Sprintf("Tool '%s' stream exceeded the maximum size of %d bytes", params.ToolName, maxBytes), failureCategory{ErrorCategoryClientError, false})
+			}
+			if chunk := strings.TrimRight(line, "\r\n"); chunk != "" {
+				chunks++
+				queueStreamChunk(conn, connID, progressToken, chunks, chunk)
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			log.Printf("[StreamToolCall] Error reading stream for tool '%s': %v", params.ToolName, err)
+			message := fmt.Sprintf("Failed to read stream from tool '%s': %v", params.ToolName, err)
+			failure := failureCategory{ErrorCategoryServerError, true}
+			if isTimeoutError(err) {
+				message = fmt.Sprintf("Failed to read stream from tool '%s': upstream stream did not complete in time", params.ToolName)
+				failure = failureCategory{ErrorCategoryTimeout, true}
+			}
+			return streamFailure(req, message, failure)
+		}
+	}
+
+	log.Printf("[StreamToolCall] Stream for tool '%s' completed with %d chunks (%d bytes)", params.ToolName, chunks, body.Len())
+	return ToolResultPayload{
+		Content:    []ToolResultContent{{Type: "text", Text: body.String()}},
+		IsError:    false,
+		ToolCallID: fmt.Sprintf("%v", req.ID),
+	}
+}
+
+// streamFailure is the tool error ending a stream that was cut short, with its failure category.
+func streamFailure(req *jsonRPCRequest, message string, failure failureCategory) ToolResultPayload {
+	return withFailureCategory(ToolResultPayload{
+		IsError:    true,
+		Content:    []ToolResultContent{{Type: "text", Text: message}},
+		Error:      &MCPError{Message: message},
+		ToolCallID: fmt.Sprintf("%v", req.ID),
+	}, failure)
+}
+
+// queueStreamChunk sends one chunk as a progress notification. It never blocks the stream: if the
+// channel is full (nobody draining it), the notification is dropped; the terminal result still has it.
+func queueStreamChunk(conn *Connection, connID string, progressToken interface{}, progress int, chunk string) {
+	if conn == nil {
+		return
+	}
+	notification := newJSONRPCNotification("notifications/progress", map[string]interface{}{
+		"progressToken": progressToken,
+		"progress":      progress,
+		"message":       chunk,
+	})
+	if err := conn.trySend(notification); err != nil {
+		log.Printf("[StreamToolCall] Dropped progress notification %d for %s: %v", progress, connID, err)
+	}
+}
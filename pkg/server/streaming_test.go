@@ -0,0 +1,119 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolCall_StreamsChunksBeforeCompletion(t *testing.T) {
+	// Each chunk is only written after the test has observed the previous one as a notification
+	proceed := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		flusher := w.(http.Flusher)
+		for i := 1; i <= 3; i++ {
+			fmt.Fprintf(w, "{\"n\":%d}\n", i)
+			flusher.Flush()
+			if i < 3 {
+				select {
+				case <-proceed:
+				case <-time.After(5 * time.Second):
+					return
+				}
+			}
+		}
+	}))
+	defer backend.Close()
+
+	connID := "stream-conn"
+	conn, channel := setupTestConnection(connID)
+	defer cleanupTestConnection(connID)
+	require.NotNil(t, conn)
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"tail_events": {Method: "GET", Path: "/events", BaseURL: backend.URL, Streaming: true},
+	}}
+	params, _ := json.Marshal(map[string]interface{}{
+		"name":      "tail_events",
+		"arguments": map[string]interface{}{},
+		"_meta":     map[string]interface{}{"progressToken": "tok-1"},
+	})
+	req := &jsonRPCRequest{Jsonrpc: "2.0", Method: "tools/call", Params: json.RawMessage(params), ID: "stream-1"}
+
+	done := make(chan jsonRPCResponse, 1)
+	go func() { done <- handleToolCallJSONRPC(connID, req, toolSet, &config.Config{}) }()
+
+	for i := 1; i <= 3; i++ {
+		select {
+		case notification := <-channel:
+			assert.Equal(t, "notifications/progress", notification.Method)
+			progress := notification.Params.(map[string]interface{})
+			assert.Equal(t, "tok-1", progress["progressToken"])
+			assert.Equal(t, i, progress["progress"])
+			assert.Equal(t, fmt.Sprintf("{\"n\":%d}", i), progress["message"])
+
+			encoded, err := json.Marshal(notification)
+			require.NoError(t, err)
+			assert.NotContains(t, string(encoded), `"id"`, "notifications carry no id")
+		case resp := <-done:
+			t.Fatalf("tool call completed before chunk %d was delivered: %+v", i, resp)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for chunk %d", i)
+		}
+		if i < 3 {
+			proceed <- struct{}{}
+		}
+	}
+
+	select {
+	case resp := <-done:
+		result, ok := resp.Result.(ToolResultPayload)
+		require.True(t, ok)
+		assert.False(t, result.IsError)
+		assert.Equal(t, "{\"n\":1}\n{\"n\":2}\n{\"n\":3}\n", result.Content[0].Text)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the terminal result")
+	}
+}
+
+func TestToolCall_StreamMaxBytes(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 10; i++ {
+			fmt.Fprintln(w, strings.Repeat("x", 20))
+		}
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"tail_events": {Method: "GET", Path: "/events", BaseURL: backend.URL},
+	}}
+	cfg := &config.Config{StreamingOperations: []string{"tail_events"}, StreamMaxBytes: 50}
+	params, _ := json.Marshal(ToolCallParams{ToolName: "tail_events", Input: map[string]interface{}{}})
+	req := &jsonRPCRequest{Jsonrpc: "2.0", Method: "tools/call", Params: json.RawMessage(params), ID: "stream-2"}
+
+	resp := handleToolCallJSONRPC("no-such-conn", req, toolSet, cfg)
+	result, ok := resp.Result.(ToolResultPayload)
+	require.True(t, ok)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "exceeded the maximum size of 50 bytes")
+}
+
+func TestJSONRPCResponse_MarshalJSON(t *testing.T) {
+	encoded, err := json.Marshal(jsonRPCResponse{Jsonrpc: "2.0", ID: 1, Result: "ok"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":"ok"}`, string(encoded))
+
+	encoded, err = json.Marshal(newJSONRPCNotification("notifications/progress", map[string]interface{}{"progress": 1}))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","method":"notifications/progress","params":{"progress":1}}`, string(encoded))
+}
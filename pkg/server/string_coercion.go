@@ -0,0 +1,82 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+var (
+	integerStringPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)$`)
+	numberStringPattern  = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+)
+
+// coerceStringValue converts a string holding a well-formed boolean, integer or number to that
+// type when the schema declares it: "true" becomes true and "42" becomes 42. Anything else,
+// including "yes", "1.5" for an integer or "0x1F", is returned unchanged for validation to reject.
+func coerceStringValue(schemaType, text string) (interface{}, bool) {
+	text = strings.TrimSpace(text)
+	switch schemaType {
+	case "boolean":
+		switch strings.ToLower(text) {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		}
+	case "integer":
+		if integerStringPattern.MatchString(text) {
+			return json.Number(text), true
+		}
+	case "number":
+		if numberStringPattern.MatchString(text) {
+			return json.Number(text), true
+		}
+	}
+	return nil, false
+}
+
+// coerceStrings applies coerceStringValue throughout value, guided by schema, and returns the
+// JSON pointers of the converted values. Maps and slices are modified in place.
+func coerceStrings(schema mcp.Schema, value interface{}, pointer string, coerced *[]string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, propSchema := range schema.Properties {
+			if propValue, ok := v[name]; ok {
+				v[name] = coerceStrings(propSchema, propValue, pointer+"/"+escapeJSONPointer(name), coerced)
+			}
+		}
+	case []interface{}:
+		if schema.Items != nil {
+			for i, element := range v {
+				v[i] = coerceStrings(*schema.Items, element, pointer+"/"+strconv.Itoa(i), coerced)
+			}
+		}
+	case string:
+		if converted, ok := coerceStringValue(schema.Type, v); ok {
+			*coerced = append(*coerced, pointer)
+			return converted
+		}
+	}
+	return value
+}
+
+// coerceStringArguments converts string arguments of a tool to the boolean, integer and number
+// types its input schema declares, before the arguments are validated, see CoerceStringArguments.
+func coerceStringArguments(toolName, connID string, toolSet *mcp.ToolSet, input map[string]interface{}) {
+	for _, tool := range toolSet.Tools {
+		if tool.Name != toolName {
+			continue
+		}
+		var coerced []string
+		coerceStrings(tool.InputSchema, input, "", &coerced)
+		if len(coerced) > 0 {
+			log.Printf("Coerced %d string argument(s) of tool '%s' for %s to their schema types: %s", len(coerced), toolName, connID, strings.Join(coerced, ", "))
+		}
+		return
+	}
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoerceStringValue(t *testing.T) {
+	tests := []struct {
+		name       string
+		schemaType string
+		text       string
+		expected   interface{}
+		ok         bool
+	}{
+		{name: "Boolean true", schemaType: "boolean", text: "true", expected: true, ok: true},
+		{name: "Boolean false", schemaType: "boolean", text: "false", expected: false, ok: true},
+		{name: "Boolean any case", schemaType: "boolean", text: "TRUE", expected: true, ok: true},
+		{name: "Boolean with whitespace", schemaType: "boolean", text: " false ", expected: false, ok: true},
+		{name: "Boolean yes is ambiguous", schemaType: "boolean", text: "yes", ok: false},
+		{name: "Boolean 1 is ambiguous", schemaType: "boolean", text: "1", ok: false},
+		{name: "Integer", schemaType: "integer", text: "42", expected: json.Number("42"), ok: true},
+		{name: "Negative integer", schemaType: "integer", text: "-7", expected: json.Number("-7"), ok: true},
+		{name: "Large integer stays exact", schemaType: "integer", text: "12345678901234567890", expected: json.Number("12345678901234567890"), ok: true},
+		{name: "Integer with fraction", schemaType: "integer", text: "1.5", ok: false},
+		{name: "Integer with leading zero", schemaType: "integer", text: "042", ok: false},
+		{name: "Integer in hex", schemaType: "integer", text: "0x1F", ok: false},
+		{name: "Integer empty", schemaType: "integer", text: "", ok: false},
+		{name: "Number", schemaType: "number", text: "3.14", expected: json.Number("3.14"), ok: true},
+		{name: "Number exponent", schemaType: "number", text: "-2.5e3", expected: json.Number("-2.5e3"), ok: true},
+		{name: "Number integral", schemaType: "number", text: "10", expected: json.Number("10"), ok: true},
+		{name: "Number trailing dot", schemaType: "number", text: "3.", ok: false},
+		{name: "Number NaN", schemaType: "number", text: "NaN", ok: false},
+		{name: "Number with unit", schemaType: "number", text: "3.14kg", ok: false},
+		{name: "String type untouched", schemaType: "string", text: "42", ok: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			value, ok := coerceStringValue(tc.schemaType, tc.text)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, value)
+		})
+	}
+}
+
+func TestCoerceStrings_Nested(t *testing.T) {
+	schema := mcp.Schema{Type: "object", Properties: map[string]mcp.Schema{
+		"limit":  {Type: "integer"},
+		"active": {Type: "boolean"},
+		"name":   {Type: "string"},
+		"filter": {Type: "object", Properties: map[string]mcp.Schema{"min": {Type: "number"}}},
+		"ids":    {Type: "array", Items: &mcp.Schema{Type: "integer"}},
+	}}
+	input := map[string]interface{}{
+		"limit":  "10",
+		"active": "true",
+		"name":   "42",
+		"filter": map[string]interface{}{"min": "0.5"},
+		"ids":    []interface{}{"1", "two", json.Number("3")},
+	}
+
+	var coerced []string
+	coerceStrings(schema, input, "", &coerced)
+	assert.Equal(t, map[string]interface{}{
+		"limit":  json.Number("10"),
+		"active": true,
+		"name":   "42",
+		"filter": map[string]interface{}{"min": json.Number("0.5")},
+		"ids":    []interface{}{json.Number("1"), "two", json.Number("3")},
+	}, input)
+	assert.ElementsMatch(t, []string{"/limit", "/active", "/filter/min", "/ids/0"}, coerced)
+}
+
+func TestHandleToolCall_CoerceStringArguments(t *testing.T) {
+	s := NewServer(&mcp.ToolSet{}, &config.Config{})
+	require.NoError(t, s.RegisterTool("echo_limit", "", json.RawMessage(`{"type": "object", "properties": {"limit": {"type": "integer"}, "verbose": {"type": "boolean"}}}`),
+		func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return args, nil
+		}))
+
+	call := map[string]interface{}{"name": "echo_limit", "arguments": map[string]interface{}{"limit": "25", "verbose": "false"}}
+	resp := dispatchToReadyConnection(t, s, "tools/call", call)
+	require.NotNil(t, resp.Error, "without coercion the strings fail validation")
+	assert.Equal(t, -32602, resp.Error.Code)
+
+	s.cfg.CoerceStringArguments = true
+	resp = dispatchToReadyConnection(t, s, "tools/call", call)
+	require.Nil(t, resp.Error)
+	result := resp.Result.(ToolResultPayload)
+	require.False(t, result.IsError)
+	assert.JSONEq(t, `{"limit": 25, "verbose": false}`, result.Content[0].Text)
+
+	call["arguments"] = map[string]interface{}{"limit": "a lot"}
+	resp = dispatchToReadyConnection(t, s, "tools/call", call)
+	require.NotNil(t, resp.Error, "unparseable strings keep the validation error")
+	assert.Equal(t, -32602, resp.Error.Code)
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+)
+
+// defaultStructuredResultHeaders are surfaced in structured results when no header set is configured.
+var defaultStructuredResultHeaders = []string{"Content-Type", "Location", "ETag", "Last-Modified"}
+
+// structuredResult is the structuredContent of a tool result when StructuredResults is enabled. It
+// keeps the HTTP metadata apart from the payload.
+type structuredResult struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    interface{}       `json:"body,omitempty"` // Parsed JSON, text, or omitted for binary bodies
+}
+
+// structuredResultHeaders resolves which upstream headers structured results include.
+func structuredResultHeaders(cfg *config.Config) []string {
+	if cfg == nil || cfg.StructuredResultHeaders == nil {
+		return defaultStructuredResultHeaders
+	}
+	return cfg.StructuredResultHeaders
+}
+
+// withStructuredResult adds a {status, headers, body} structuredContent object to an upstream
+// result. For clients without structured content support, the text content is replaced with the
+// same object serialized as JSON; binary content (images, audio, ...) is kept as is and the
+// structured object carries only the metadata. Results are returned unchanged when the option is
+// off.
+func withStructuredResult(toolName string, httpResp *http.Response, body []byte, cfg *config.Config, result ToolResultPayload) ToolResultPayload {
+	if cfg == nil || !cfg.StructuredResults || httpResp == nil {
+		return result
+	}
+
+	structured := structuredResult{
+		Status:  httpResp.StatusCode,
+		Headers: make(map[string]string),
+	}
+	for _, name := range structuredResultHeaders(cfg) {
+		if value := httpResp.Header.Get(name); value != "" {
+			structured.Headers[http.CanonicalHeaderKey(name)] = value
+		}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(httpResp.Header.Get("Content-Type"))
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(httpResp.Header.Get("Content-Type")))
+	}
+	textual := isTextualMediaType(mediaType)
+	if textual && len(body) > 0 {
+		var parsed interface{}
+		if strings.HasSuffix(mediaType, "json") && decodeJSON(body, &parsed) == nil {
+			structured.Body = parsed
+		} else {
+			structured.Body = string(body)
+		}
+	}
+
+	result.StructuredContent = structured
+	if textual {
+		serialized, err := json.Marshal(structured)
+		if err != nil {
+			log.Printf("[ExecuteToolCall] Error marshalling structured result for tool '%s': %v", toolName, err)
+			return result
+		}
+		result.Content = []ToolResultContent{{Type: "text", Text: string(serialized)}}
+	}
+	return result
+}
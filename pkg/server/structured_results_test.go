@@ -0,0 +1,108 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func structuredTestBackend(t *testing.T) *httptest.Server {
+	t.Helper()
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/items":
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Location", "/items/12345678901234567890")
+			w.Header().Set("X-Internal-Trace", "abc")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": 12345678901234567890}`))
+		case "/missing":
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("no such item"))
+		case "/logo":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte{0x89, 'P', 'N', 'G'})
+		}
+	}))
+	t.Cleanup(backend.Close)
+	return backend
+}
+
+func structuredTestToolSet(url string) *mcp.ToolSet {
+	return &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"create_item": {Method: "POST", Path: "/items", BaseURL: url},
+		"get_missing": {Method: "GET", Path: "/missing", BaseURL: url},
+		"get_logo":    {Method: "GET", Path: "/logo", BaseURL: url},
+	}}
+}
+
+func callStructuredTestTool(t *testing.T, toolName string, cfg *config.Config) ToolResultPayload {
+	t.Helper()
+	backend := structuredTestBackend(t)
+	params := &ToolCallParams{ToolName: toolName, Input: map[string]interface{}{}}
+	return callToolUpstream("conn", &jsonRPCRequest{ID: 1}, params, structuredTestToolSet(backend.URL), cfg)
+}
+
+func TestCallToolUpstream_StructuredResult(t *testing.T) {
+	result := callStructuredTestTool(t, "create_item", &config.Config{StructuredResults: true})
+	require.False(t, result.IsError)
+
+	structured, ok := result.StructuredContent.(structuredResult)
+	require.True(t, ok, "structuredContent is a structured result, got %T", result.StructuredContent)
+	assert.Equal(t, http.StatusCreated, structured.Status)
+	assert.Equal(t, map[string]string{
+		"Content-Type": "application/json",
+		"Location":     "/items/12345678901234567890",
+	}, structured.Headers, "only allow-listed headers are surfaced")
+	assert.Equal(t, map[string]interface{}{"id": json.Number("12345678901234567890")}, structured.Body)
+
+	// The text content carries the same object for clients without structured content support
+	require.Len(t, result.Content, 1)
+	assert.Equal(t, "text", result.Content[0].Type)
+	assert.JSONEq(t, `{"status": 201, "headers": {"Content-Type": "application/json", "Location": "/items/12345678901234567890"}, "body": {"id": 12345678901234567890}}`, result.Content[0].Text)
+}
+
+func TestCallToolUpstream_StructuredResultHeaders(t *testing.T) {
+	cfg := &config.Config{StructuredResults: true, StructuredResultHeaders: []string{"x-internal-trace"}}
+	result := callStructuredTestTool(t, "create_item", cfg)
+
+	structured, ok := result.StructuredContent.(structuredResult)
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{"X-Internal-Trace": "abc"}, structured.Headers, "a configured set replaces the default one")
+}
+
+func TestCallToolUpstream_StructuredResultAPIError(t *testing.T) {
+	result := callStructuredTestTool(t, "get_missing", &config.Config{StructuredResults: true})
+	assert.True(t, result.IsError)
+
+	structured, ok := result.StructuredContent.(structuredResult)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, structured.Status)
+	assert.Equal(t, "no such item", structured.Body, "non-JSON text bodies are kept as strings")
+}
+
+func TestCallToolUpstream_StructuredResultBinary(t *testing.T) {
+	result := callStructuredTestTool(t, "get_logo", &config.Config{StructuredResults: true})
+
+	structured, ok := result.StructuredContent.(structuredResult)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusOK, structured.Status)
+	assert.Nil(t, structured.Body, "binary bodies are left out of the structured object")
+	require.Len(t, result.Content, 1)
+	assert.Equal(t, "image", result.Content[0].Type, "binary content is kept")
+}
+
+func TestCallToolUpstream_PlainTextResult(t *testing.T) {
+	result := callStructuredTestTool(t, "create_item", &config.Config{})
+	assert.False(t, result.IsError)
+	assert.Nil(t, result.StructuredContent, "structured results are opt-in")
+	require.Len(t, result.Content, 1)
+	assert.Equal(t, `{"id": 12345678901234567890}`, result.Content[0].Text)
+}
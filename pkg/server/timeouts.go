@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// defaultUpstreamTimeout applies when neither the config nor the operation sets a timeout.
+const defaultUpstreamTimeout = 120 * time.Second
+
+// effectiveTimeout resolves the upstream timeout for a tool: a per-tool config override first,
+// then the operation's x-mcp-timeout, then the configured default.
+func effectiveTimeout(toolName string, operation mcp.OperationDetail, cfg *config.Config) time.Duration {
+	if cfg != nil {
+		if timeout, ok := cfg.OperationTimeouts[toolName]; ok && timeout > 0 {
+			return timeout
+		}
+	}
+	if operation.Timeout > 0 {
+		return operation.Timeout
+	}
+	if cfg != nil && cfg.UpstreamTimeout > 0 {
+		return cfg.UpstreamTimeout
+	}
+	return defaultUpstreamTimeout
+}
+
+// timeoutMeta is the tools/list _meta entry advertising a tool's timeout budget to the client.
+func timeoutMeta(timeout time.Duration) map[string]interface{} {
+	return map[string]interface{}{
+		"timeoutSeconds": timeout.Seconds(),
+	}
+}
+
+// errUpstreamTimeout reports that the upstream exceeded the tool's timeout budget.
+type errUpstreamTimeout struct {
+	budget time.Duration
+}
+
+func (e *errUpstreamTimeout) Error() string {
+	return fmt.Sprintf("upstream did not respond within %s", e.budget)
+}
+
+// isTimeoutError reports whether err came from a request deadline being exceeded.
+func isTimeoutError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveTimeout(t *testing.T) {
+	operation := mcp.OperationDetail{Timeout: 30 * time.Second}
+	tests := []struct {
+		name      string
+		operation mcp.OperationDetail
+		cfg       *config.Config
+		expected  time.Duration
+	}{
+		{name: "Built-in default", operation: mcp.OperationDetail{}, cfg: &config.Config{}, expected: defaultUpstreamTimeout},
+		{name: "Configured default", operation: mcp.OperationDetail{}, cfg: &config.Config{UpstreamTimeout: time.Minute}, expected: time.Minute},
+		{name: "Operation extension beats default", operation: operation, cfg: &config.Config{UpstreamTimeout: time.Minute}, expected: 30 * time.Second},
+		{name: "Per-tool override beats extension", operation: operation, cfg: &config.Config{OperationTimeouts: map[string]time.Duration{"get_report": 5 * time.Minute}}, expected: 5 * time.Minute},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, effectiveTimeout("get_report", tc.operation, tc.cfg))
+		})
+	}
+}
+
+func TestToolCall_TimeoutErrorStatesBudget(t *testing.T) {
+	release := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer backend.Close()
+	defer close(release)
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"slow_report": {Method: "GET", Path: "/report", BaseURL: backend.URL},
+	}}
+	cfg := &config.Config{OperationTimeouts: map[string]time.Duration{"slow_report": 50 * time.Millisecond}}
+
+	params, _ := json.Marshal(ToolCallParams{ToolName: "slow_report", Input: map[string]interface{}{}})
+	req := &jsonRPCRequest{Jsonrpc: "2.0", Method: "tools/call", Params: json.RawMessage(params), ID: "timeout-1"}
+	resp := handleToolCallJSONRPC("test-conn", req, toolSet, cfg)
+
+	result, ok := resp.Result.(ToolResultPayload)
+	require.True(t, ok)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "upstream did not respond within 50ms")
+}
+
+func TestListTools_AdvertisesTimeout(t *testing.T) {
+	toolSet := &mcp.ToolSet{
+		Tools: []mcp.Tool{{Name: "get_report"}, {Name: "list_items"}},
+		Operations: map[string]mcp.OperationDetail{
+			"get_report": {Method: "GET", Path: "/report", Timeout: 30 * time.Second},
+			"list_items": {Method: "GET", Path: "/items"},
+		},
+	}
+
+	tools := listTools(toolSet, &config.Config{UpstreamTimeout: 10 * time.Second})
+	assert.Equal(t, 30.0, tools[0].Meta["timeoutSeconds"])
+	assert.Equal(t, 10.0, tools[1].Meta["timeoutSeconds"])
+	assert.Nil(t, tools[2].Meta, "built-in tools make no upstream call")
+
+	// The generated tools themselves are not mutated
+	assert.Nil(t, toolSet.Tools[0].Meta)
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"sort"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// Orders of the tools in tools/list, see config.ToolOrder.
+const (
+	ToolOrderSpec = "spec" // As the operations are written in the spec; tools added later follow
+	ToolOrderName = "name" // By tool name
+	ToolOrderTag  = "tag"  // By the operation's first tag, then tool name; untagged tools last
+)
+
+// orderTools returns tools in the configured order, leaving the tool set's own order alone.
+// Every order is total, so repeated listings agree.
+func orderTools(tools []mcp.Tool, toolSet *mcp.ToolSet, cfg *config.Config) []mcp.Tool {
+	order := ToolOrderSpec
+	if cfg != nil && cfg.ToolOrder != "" {
+		order = cfg.ToolOrder
+	}
+	if order == ToolOrderSpec {
+		return tools
+	}
+
+	sorted := append([]mcp.Tool(nil), tools...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if order == ToolOrderTag {
+			tagI, tagJ := firstTag(sorted[i].Name, toolSet), firstTag(sorted[j].Name, toolSet)
+			if tagI != tagJ {
+				return tagJ == "" || (tagI != "" && tagI < tagJ)
+			}
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// firstTag returns the first spec tag of the operation behind a tool, or "".
+func firstTag(toolName string, toolSet *mcp.ToolSet) string {
+	if operation, ok := toolSet.Operations[toolName]; ok && len(operation.Tags) > 0 {
+		return operation.Tags[0]
+	}
+	return ""
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+// orderedToolSet lists its tools in spec order: neither by name nor by tag.
+func orderedToolSet() *mcp.ToolSet {
+	return &mcp.ToolSet{
+		Tools: []mcp.Tool{{Name: "listZoos"}, {Name: "createZoo"}, {Name: "getAnimal"}, {Name: "health"}, {Name: "adoptAnimal"}},
+		Operations: map[string]mcp.OperationDetail{
+			"listZoos":    {Method: "GET", Path: "/zoos", Tags: []string{"zoos"}},
+			"createZoo":   {Method: "POST", Path: "/zoos", Tags: []string{"zoos", "admin"}},
+			"getAnimal":   {Method: "GET", Path: "/animals/{id}", Tags: []string{"animals"}},
+			"health":      {Method: "GET", Path: "/health"},
+			"adoptAnimal": {Method: "POST", Path: "/animals/{id}/adopt", Tags: []string{"animals"}},
+		},
+	}
+}
+
+func listedToolNames(toolSet *mcp.ToolSet, cfg *config.Config) []string {
+	var names []string
+	for _, tool := range listTools(toolSet, cfg) {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+func TestListTools_Order(t *testing.T) {
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{"", []string{"listZoos", "createZoo", "getAnimal", "health", "adoptAnimal"}},
+		{ToolOrderSpec, []string{"listZoos", "createZoo", "getAnimal", "health", "adoptAnimal"}},
+		{ToolOrderName, []string{"adoptAnimal", "createZoo", "getAnimal", "health", "listZoos"}},
+		{ToolOrderTag, []string{"adoptAnimal", "getAnimal", "createZoo", "listZoos", "health"}},
+	}
+	for _, tc := range tests {
+		t.Run("order "+tc.order, func(t *testing.T) {
+			toolSet := orderedToolSet()
+			cfg := &config.Config{ToolOrder: tc.order}
+			want := append(tc.want, describeOperationToolName)
+
+			assert.Equal(t, want, listedToolNames(toolSet, cfg))
+			assert.Equal(t, want, listedToolNames(toolSet, cfg), "listings are stable")
+			assert.Equal(t, "listZoos", toolSet.Tools[0].Name, "the tool set keeps its order")
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// ToolKind says where a served tool comes from.
+type ToolKind string
+
+const (
+	ToolKindGenerated  ToolKind = "generated"  // Generated from an OpenAPI operation
+	ToolKindRegistered ToolKind = "registered" // Added with RegisterTool
+	ToolKindBuiltin    ToolKind = "builtin"    // Served by the server itself, e.g. __describe_operation
+)
+
+// ToolDefinition is a tool as currently served, with the operation behind it.
+type ToolDefinition struct {
+	Tool      mcp.Tool             // Exactly as listed by tools/list
+	Kind      ToolKind             // Where the tool comes from
+	Operation *mcp.OperationDetail // The upstream operation of a generated tool; nil otherwise
+}
+
+// Tools returns the tools currently served, in tools/list order. The list is a consistent
+// snapshot: a concurrent hot reload or registration shows up in full on a later call, never
+// partially. The returned definitions are copies, but the maps and slices inside them are shared
+// with the server and must not be modified.
+func (s *Server) Tools() []ToolDefinition {
+	return toolDefinitions(s.tools.Load(), s.cfg)
+}
+
+// Tool returns the currently served tool with the given name.
+func (s *Server) Tool(name string) (ToolDefinition, bool) {
+	for _, definition := range s.Tools() {
+		if definition.Tool.Name == name {
+			return definition, true
+		}
+	}
+	return ToolDefinition{}, false
+}
+
+// toolDefinitions describes every tool listed for toolSet.
+func toolDefinitions(toolSet *mcp.ToolSet, cfg *config.Config) []ToolDefinition {
+	if toolSet == nil {
+		return nil
+	}
+	tools := listTools(toolSet, cfg)
+	definitions := make([]ToolDefinition, 0, len(tools))
+	for _, tool := range tools {
+		definition := ToolDefinition{Tool: tool, Kind: ToolKindBuiltin}
+		if operation, ok := toolSet.Operations[tool.Name]; ok {
+			definition.Kind = ToolKindGenerated
+			definition.Operation = &operation
+		} else if _, ok := toolSet.CustomHandlers[tool.Name]; ok {
+			definition.Kind = ToolKindRegistered
+		}
+		definitions = append(definitions, definition)
+	}
+	return definitions
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func definitionNames(definitions []ToolDefinition) []string {
+	var names []string
+	for _, definition := range definitions {
+		names = append(names, definition.Tool.Name)
+	}
+	return names
+}
+
+func TestServer_Tools(t *testing.T) {
+	s := NewServer(createTestToolSetForCall(), &config.Config{})
+	require.NoError(t, s.RegisterTool("add", "Add two numbers", nil, addNumbers))
+
+	definitions := s.Tools()
+	assert.Equal(t, []string{"get_user", "post_data", "add", describeOperationToolName}, definitionNames(definitions))
+
+	getUser, ok := s.Tool("get_user")
+	require.True(t, ok)
+	assert.Equal(t, ToolKindGenerated, getUser.Kind)
+	require.NotNil(t, getUser.Operation)
+	assert.Equal(t, "GET", getUser.Operation.Method)
+	assert.Equal(t, "/users/{user_id}", getUser.Operation.Path)
+	assert.Equal(t, []string{"user_id"}, getUser.Tool.InputSchema.Required)
+
+	add, ok := s.Tool("add")
+	require.True(t, ok)
+	assert.Equal(t, ToolKindRegistered, add.Kind)
+	assert.Nil(t, add.Operation)
+	assert.Equal(t, "Add two numbers", add.Tool.Description)
+
+	describe, ok := s.Tool(describeOperationToolName)
+	require.True(t, ok)
+	assert.Equal(t, ToolKindBuiltin, describe.Kind)
+
+	_, ok = s.Tool("missing")
+	assert.False(t, ok)
+
+	// Hidden built-ins are not reported
+	hidden := NewServer(createTestToolSetForCall(), &config.Config{DisableDescribeTool: true})
+	assert.Equal(t, []string{"get_user", "post_data"}, definitionNames(hidden.Tools()))
+}
+
+func TestServer_ToolsAfterReload(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.json")
+	require.NoError(t, os.WriteFile(specPath, []byte(reloadSpecJSON("listItems")), 0644))
+	cfg := &config.Config{SpecPath: specPath, SpecPollInterval: 1, DisableDescribeTool: true}
+	watcher := parser.NewSpecWatcher(cfg.SpecPath, specLoadOptions(cfg))
+	doc, specVersion, _, err := watcher.Check()
+	require.NoError(t, err)
+	initial, err := parser.GenerateToolSet(doc, specVersion, cfg)
+	require.NoError(t, err)
+	s := NewServer(initial, cfg)
+
+	before := s.Tools()
+	assert.Equal(t, []string{"listItems"}, definitionNames(before))
+	_, ok := s.Tool("listOrders")
+	assert.False(t, ok)
+
+	require.NoError(t, os.WriteFile(specPath, []byte(reloadSpecJSON("listItems", "listOrders")), 0644))
+	rebuilt, err := reloadSpec(s.tools, cfg, watcher)
+	require.NoError(t, err)
+	require.True(t, rebuilt)
+
+	assert.ElementsMatch(t, []string{"listItems", "listOrders"}, definitionNames(s.Tools()), "the next call sees the reloaded tools")
+	listOrders, ok := s.Tool("listOrders")
+	require.True(t, ok)
+	assert.Equal(t, "/listOrders", listOrders.Operation.Path)
+	assert.Equal(t, []string{"listItems"}, definitionNames(before), "an earlier snapshot is unaffected")
+}
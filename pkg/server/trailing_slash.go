@@ -0,0 +1,48 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+)
+
+// Trailing slash modes for upstream request paths.
+const (
+	TrailingSlashAlways   = "always"   // Add a trailing slash when the path has none
+	TrailingSlashNever    = "never"    // Remove any trailing slash
+	TrailingSlashPreserve = "preserve" // Keep the path as the spec or path override produced it
+)
+
+// trailingSlashMode resolves the trailing slash mode for a tool: the --operation-trailing-slash
+// override first, then the one for the base URL it is sent to, then the global mode. The default
+// is to preserve the path.
+func trailingSlashMode(toolName, baseURL string, cfg *config.Config) string {
+	if cfg == nil {
+		return TrailingSlashPreserve
+	}
+	if mode, ok := cfg.OperationTrailingSlash[toolName]; ok && mode != "" {
+		return mode
+	}
+	for server, mode := range cfg.ServerTrailingSlash {
+		if mode != "" && strings.TrimRight(server, "/") == strings.TrimRight(baseURL, "/") {
+			return mode
+		}
+	}
+	if cfg.TrailingSlash != "" {
+		return cfg.TrailingSlash
+	}
+	return TrailingSlashPreserve
+}
+
+// normalizeTrailingSlash applies a trailing slash mode to a resolved request path.
+func normalizeTrailingSlash(path, mode string) string {
+	switch mode {
+	case TrailingSlashAlways:
+		if !strings.HasSuffix(path, "/") {
+			return path + "/"
+		}
+	case TrailingSlashNever:
+		return strings.TrimRight(path, "/")
+	}
+	return path
+}
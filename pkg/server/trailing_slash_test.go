@@ -0,0 +1,76 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// trailingSlashTestPath calls toolName and returns the path the upstream received.
+func trailingSlashTestPath(t *testing.T, toolName string, cfg func(baseURL string) *config.Config) string {
+	t.Helper()
+	var received string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.URL.Path
+		w.Write([]byte(`{}`))
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"get_user":   {Method: "GET", Path: "/users/{id}", BaseURL: backend.URL, Parameters: []mcp.ParameterDetail{{Name: "id", In: "path"}}},
+		"list_users": {Method: "GET", Path: "/users/", BaseURL: backend.URL},
+	}}
+	params := &ToolCallParams{ToolName: toolName, Input: map[string]interface{}{"id": "42"}}
+	result := callToolUpstream("conn", &jsonRPCRequest{ID: 1}, params, toolSet, cfg(backend.URL))
+	require.False(t, result.IsError, "%+v", result.Content)
+	return received
+}
+
+func TestTrailingSlash_Modes(t *testing.T) {
+	tests := []struct {
+		name     string
+		toolName string
+		cfg      func(baseURL string) *config.Config
+		expected string
+	}{
+		{name: "Preserve by default without slash", toolName: "get_user", cfg: func(string) *config.Config { return &config.Config{} }, expected: "/users/42"},
+		{name: "Preserve by default with slash", toolName: "list_users", cfg: func(string) *config.Config { return &config.Config{} }, expected: "/users/"},
+		{name: "Always adds one", toolName: "get_user", cfg: func(string) *config.Config { return &config.Config{TrailingSlash: "always"} }, expected: "/users/42/"},
+		{name: "Always keeps an existing one", toolName: "list_users", cfg: func(string) *config.Config { return &config.Config{TrailingSlash: "always"} }, expected: "/users/"},
+		{name: "Never removes it", toolName: "list_users", cfg: func(string) *config.Config { return &config.Config{TrailingSlash: "never"} }, expected: "/users"},
+		{
+			name: "Server mode overrides the global one", toolName: "get_user",
+			cfg: func(baseURL string) *config.Config {
+				return &config.Config{TrailingSlash: "never", ServerTrailingSlash: map[string]string{baseURL + "/": "always"}}
+			},
+			expected: "/users/42/",
+		},
+		{
+			name: "Operation mode overrides the server one", toolName: "list_users",
+			cfg: func(baseURL string) *config.Config {
+				return &config.Config{
+					ServerTrailingSlash:    map[string]string{baseURL: "always"},
+					OperationTrailingSlash: map[string]string{"list_users": "never"},
+				}
+			},
+			expected: "/users",
+		},
+		{
+			name: "Operation preserve overrides the global mode", toolName: "list_users",
+			cfg: func(string) *config.Config {
+				return &config.Config{TrailingSlash: "never", OperationTrailingSlash: map[string]string{"list_users": "preserve"}}
+			},
+			expected: "/users/",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, trailingSlashTestPath(t, tc.toolName, tc.cfg))
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+)
+
+// Upstream request phases that can time out on their own, see errUpstreamPhaseTimeout.
+const (
+	phaseConnect        = "connect"
+	phaseTLSHandshake   = "TLS handshake"
+	phaseResponseHeader = "response headers"
+)
+
+// dialUpstream opens upstream connections. Tests replace it to simulate unreachable hosts.
+var dialUpstream = (&net.Dialer{KeepAlive: 30 * time.Second}).DialContext
+
+// phaseTimeouts are the per-phase limits an upstream transport is built with.
+type phaseTimeouts struct {
+	connect, tlsHandshake, responseHeader time.Duration
+}
+
+// transportSettings key the shared upstream transports.
+type transportSettings struct {
+	phaseTimeouts
+	proxy upstreamProxySettings
+}
+
+// upstreamTransports shares one transport per set of phase limits and proxy settings, so
+// connections are pooled.
+var upstreamTransports = struct {
+	sync.Mutex
+	bySettings map[transportSettings]*http.Transport
+}{bySettings: make(map[transportSettings]*http.Transport)}
+
+// errUpstreamPhaseTimeout reports which phase of an upstream request ran out of time: "couldn't
+// reach the backend" (connect, TLS handshake) versus "backend is slow to answer" (response
+// headers). It is a timeout for isTimeoutError.
+type errUpstreamPhaseTimeout struct {
+	phase string
+	limit time.Duration
+}
+
+func (e *errUpstreamPhaseTimeout) Error() string {
+	switch e.phase {
+	case phaseConnect:
+		return fmt.Sprintf("could not connect to upstream within %s (connect timeout)", e.limit)
+	case phaseTLSHandshake:
+		return fmt.Sprintf("upstream TLS handshake did not complete within %s (TLS handshake timeout)", e.limit)
+	default:
+		return fmt.Sprintf("upstream accepted the request but sent no response headers within %s (response header timeout)", e.limit)
+	}
+}
+
+func (e *errUpstreamPhaseTimeout) Timeout() bool   { return true }
+func (e *errUpstreamPhaseTimeout) Temporary() bool { return true }
+
+// upstreamTransport returns the transport for upstream calls. Without phase limits or a proxy it
+// is the default transport, bounded only by the tool's overall timeout and proxied as the
+// environment says.
+func upstreamTransport(cfg *config.Config) http.RoundTripper {
+	if cfg == nil || (cfg.ConnectTimeout <= 0 && cfg.TLSHandshakeTimeout <= 0 && cfg.ResponseHeaderTimeout <= 0 && cfg.UpstreamProxyURL == "") {
+		return http.DefaultTransport
+	}
+	limits := phaseTimeouts{cfg.ConnectTimeout, cfg.TLSHandshakeTimeout, cfg.ResponseHeaderTimeout}
+	settings := transportSettings{phaseTimeouts: limits}
+	if cfg.UpstreamProxyURL != "" {
+		settings.proxy = upstreamProxySettings{proxyURL: cfg.UpstreamProxyURL, noProxy: strings.Join(cfg.UpstreamNoProxy, ",")}
+	}
+
+	upstreamTransports.Lock()
+	defer upstreamTransports.Unlock()
+	if transport, ok := upstreamTransports.bySettings[settings]; ok {
+		return transport
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if settings.proxy.proxyURL != "" {
+		proxy, err := upstreamProxyFunc(settings.proxy)
+		if err != nil {
+			// Checked at startup; fail the calls rather than bypass the proxy
+			proxy = func(*http.Request) (*url.URL, error) {
+				return nil, fmt.Errorf("invalid upstream proxy: %w", err)
+			}
+		}
+		transport.Proxy = proxy
+	}
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		if limits.connect <= 0 {
+			return dialUpstream(ctx, network, address)
+		}
+		dialCtx, cancel := context.WithTimeout(ctx, limits.connect)
+		defer cancel()
+		conn, err := dialUpstream(dialCtx, network, address)
+		// Only the connect limit's own deadline counts; the overall timeout is reported as usual
+		if err != nil && ctx.Err() == nil && errors.Is(dialCtx.Err(), context.DeadlineExceeded) {
+			return nil, &errUpstreamPhaseTimeout{phase: phaseConnect, limit: limits.connect}
+		}
+		return conn, err
+	}
+	if limits.tlsHandshake > 0 {
+		transport.TLSHandshakeTimeout = limits.tlsHandshake
+	}
+	transport.ResponseHeaderTimeout = limits.responseHeader
+	upstreamTransports.bySettings[settings] = transport
+	return transport
+}
+
+// upstreamPhaseTimeout identifies a per-phase timeout in a failed request's error, or returns nil.
+// net/http reports the TLS handshake and response header timeouts only through unexported errors,
+// so those are recognized by their text.
+func upstreamPhaseTimeout(err error, cfg *config.Config) *errUpstreamPhaseTimeout {
+	var phaseErr *errUpstreamPhaseTimeout
+	if errors.As(err, &phaseErr) {
+		return phaseErr
+	}
+	if cfg == nil {
+		return nil
+	}
+	message := err.Error()
+	switch {
+	case cfg.TLSHandshakeTimeout > 0 && strings.Contains(message, "TLS handshake timeout"):
+		return &errUpstreamPhaseTimeout{phase: phaseTLSHandshake, limit: cfg.TLSHandshakeTimeout}
+	case cfg.ResponseHeaderTimeout > 0 && strings.Contains(message, "timeout awaiting response headers"):
+		return &errUpstreamPhaseTimeout{phase: phaseResponseHeader, limit: cfg.ResponseHeaderTimeout}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func phaseTimeoutToolSet(baseURL string) *mcp.ToolSet {
+	return &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"get_report": {Method: "GET", Path: "/report", BaseURL: baseURL},
+	}}
+}
+
+func TestExecuteToolCall_ResponseHeaderTimeout(t *testing.T) {
+	// A listener that accepts connections but never answers
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	cfg := &config.Config{UpstreamTimeout: 5 * time.Second, ConnectTimeout: time.Second, ResponseHeaderTimeout: 50 * time.Millisecond}
+	params := &ToolCallParams{ToolName: "get_report", Input: map[string]interface{}{}}
+	_, err = executeToolCall(params, phaseTimeoutToolSet("http://"+listener.Addr().String()), cfg)
+	require.Error(t, err)
+
+	phaseErr, ok := err.(*errUpstreamPhaseTimeout)
+	require.True(t, ok, "got %T: %v", err, err)
+	assert.Equal(t, phaseResponseHeader, phaseErr.phase)
+	assert.Equal(t, "upstream accepted the request but sent no response headers within 50ms (response header timeout)", err.Error())
+	assert.True(t, isTimeoutError(err))
+}
+
+func TestExecuteToolCall_ConnectTimeout(t *testing.T) {
+	// Dialing an unroutable address hangs until the connect limit; whether it does in a sandbox
+	// depends on the network, so the dial is simulated
+	original := dialUpstream
+	dialUpstream = func(ctx context.Context, network, address string) (net.Conn, error) {
+		<-ctx.Done()
+		return nil, &net.OpError{Op: "dial", Net: network, Err: ctx.Err()}
+	}
+	defer func() { dialUpstream = original }()
+
+	cfg := &config.Config{UpstreamTimeout: 5 * time.Second, ConnectTimeout: 50 * time.Millisecond, ResponseHeaderTimeout: time.Second}
+	params := &ToolCallParams{ToolName: "get_report", Input: map[string]interface{}{}}
+	_, err := executeToolCall(params, phaseTimeoutToolSet("http://10.255.255.1:81"), cfg)
+	require.Error(t, err)
+
+	phaseErr, ok := err.(*errUpstreamPhaseTimeout)
+	require.True(t, ok, "got %T: %v", err, err)
+	assert.Equal(t, phaseConnect, phaseErr.phase)
+	assert.Equal(t, "could not connect to upstream within 50ms (connect timeout)", err.Error())
+}
+
+func TestUpstreamTransport(t *testing.T) {
+	assert.Same(t, http.DefaultTransport, upstreamTransport(&config.Config{}), "no phase limits keeps the default transport")
+	assert.Same(t, http.DefaultTransport, upstreamTransport(nil))
+
+	cfg := &config.Config{ConnectTimeout: time.Second, ResponseHeaderTimeout: 2 * time.Second}
+	transport, ok := upstreamTransport(cfg).(*http.Transport)
+	require.True(t, ok)
+	assert.Equal(t, 2*time.Second, transport.ResponseHeaderTimeout)
+	assert.Same(t, transport, upstreamTransport(&config.Config{ConnectTimeout: time.Second, ResponseHeaderTimeout: 2 * time.Second}), "transports are shared")
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// How the wait before an upstream retry is randomized, see config.UpstreamRetryJitter. Jitter keeps
+// the retries of many connections from arriving at a recovering upstream all at once.
+const (
+	RetryJitterFull  = "full"  // Anywhere between 0 and the backoff (the default)
+	RetryJitterEqual = "equal" // Half the backoff plus up to the other half
+	RetryJitterNone  = "none"  // Exactly the backoff
+)
+
+// Backoffs used when the config leaves them unset.
+const (
+	defaultUpstreamRetryBackoff    = 200 * time.Millisecond
+	defaultUpstreamRetryMaxBackoff = 10 * time.Second
+)
+
+// retryBackoff is the wait before retry number attempt (from 1) without jitter: the base backoff,
+// doubled for each attempt after the first, up to the maximum.
+func retryBackoff(attempt int, cfg *config.Config) time.Duration {
+	base, limit := cfg.UpstreamRetryBackoff, cfg.UpstreamRetryMaxBackoff
+	if base <= 0 {
+		base = defaultUpstreamRetryBackoff
+	}
+	if limit <= 0 {
+		limit = defaultUpstreamRetryMaxBackoff
+	}
+	backoff := base
+	for i := 1; i < attempt && backoff < limit; i++ {
+		backoff *= 2
+	}
+	if backoff > limit {
+		backoff = limit
+	}
+	return backoff
+}
+
+// jitteredBackoff randomizes a backoff according to the jitter strategy.
+func jitteredBackoff(backoff time.Duration, jitter string) time.Duration {
+	if backoff <= 0 {
+		return 0
+	}
+	switch jitter {
+	case RetryJitterNone:
+		return backoff
+	case RetryJitterEqual:
+		half := backoff / 2
+		return half + time.Duration(rand.Int63n(int64(backoff-half)+1))
+	default:
+		return time.Duration(rand.Int63n(int64(backoff) + 1))
+	}
+}
+
+// isIdempotentMethod reports whether repeating a request with the method has the same effect as
+// sending it once.
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retrySafe reports whether a failed call to a tool may be sent again: its method is idempotent,
+// or calls carry an idempotency key so the upstream can recognize the repeat.
+func retrySafe(toolName string, toolSet *mcp.ToolSet, cfg *config.Config) bool {
+	operation, ok := toolSet.Operations[toolName]
+	return ok && (isIdempotentMethod(operation.Method) || usesIdempotencyKey(toolName, toolSet, cfg))
+}
+
+// retryableAttempt reports whether an upstream attempt failed in a way a retry may fix: a transport
+// error, a timeout, or a retryable status such as 503. An open circuit is not retried, since it
+// fails fast until its cooldown ends.
+func retryableAttempt(resp *http.Response, err error) bool {
+	if err != nil {
+		var circuitErr *errCircuitOpen
+		if errors.As(err, &circuitErr) {
+			return false
+		}
+		return execFailureCategory(err).retryable
+	}
+	if resp.StatusCode < 300 {
+		return false
+	}
+	return statusFailureCategory(resp.StatusCode).retryable
+}
+
+// executeWithRetries is executeWithRelogin, retrying retryable failures up to UpstreamRetries times
+// with jittered exponential backoff. Calls that aren't retry-safe are sent once.
+func executeWithRetries(params *ToolCallParams, toolSet *mcp.ToolSet, cfg *config.Config) (*http.Response, error) {
+	resp, err := executeWithRelogin(params, toolSet, cfg)
+	if cfg == nil || cfg.UpstreamRetries <= 0 || !retryableAttempt(resp, err) {
+		return resp, err
+	}
+	if !retrySafe(params.ToolName, toolSet, cfg) {
+		log.Printf("[Retry] Not retrying failed call of tool '%s': its %s is not idempotent and has no idempotency key", params.ToolName, toolSet.Operations[params.ToolName].Method)
+		return resp, err
+	}
+	for attempt := 1; attempt <= cfg.UpstreamRetries && retryableAttempt(resp, err); attempt++ {
+		wait := jitteredBackoff(retryBackoff(attempt, cfg), cfg.UpstreamRetryJitter)
+		if err != nil {
+			log.Printf("[Retry] Call of tool '%s' failed (%v); retry %d of %d in %s", params.ToolName, err, attempt, cfg.UpstreamRetries, wait)
+		} else {
+			log.Printf("[Retry] Call of tool '%s' answered %s; retry %d of %d in %s", params.ToolName, resp.Status, attempt, cfg.UpstreamRetries, wait)
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // Lets the connection be reused
+			resp.Body.Close()
+		}
+		time.Sleep(wait)
+		resp, err = executeWithRelogin(params, toolSet, cfg)
+	}
+	return resp, err
+}
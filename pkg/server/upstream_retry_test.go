@@ -0,0 +1,111 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	cfg := &config.Config{UpstreamRetryBackoff: 100 * time.Millisecond, UpstreamRetryMaxBackoff: time.Second}
+	assert.Equal(t, 100*time.Millisecond, retryBackoff(1, cfg))
+	assert.Equal(t, 200*time.Millisecond, retryBackoff(2, cfg))
+	assert.Equal(t, 800*time.Millisecond, retryBackoff(4, cfg))
+	assert.Equal(t, time.Second, retryBackoff(5, cfg), "capped at the maximum")
+	assert.Equal(t, time.Second, retryBackoff(60, cfg))
+	assert.Equal(t, defaultUpstreamRetryBackoff, retryBackoff(1, &config.Config{}))
+}
+
+func TestJitteredBackoff_StaysWithinBounds(t *testing.T) {
+	backoff := 400 * time.Millisecond
+	for _, tc := range []struct {
+		jitter   string
+		min, max time.Duration
+	}{
+		{RetryJitterFull, 0, backoff},
+		{"", 0, backoff}, // Full jitter is the default
+		{RetryJitterEqual, backoff / 2, backoff},
+		{RetryJitterNone, backoff, backoff},
+	} {
+		var spread bool
+		for i := 0; i < 1000; i++ {
+			wait := jitteredBackoff(backoff, tc.jitter)
+			require.GreaterOrEqual(t, wait, tc.min, tc.jitter)
+			require.LessOrEqual(t, wait, tc.max, tc.jitter)
+			spread = spread || wait != jitteredBackoff(backoff, tc.jitter)
+		}
+		assert.Equal(t, tc.jitter != RetryJitterNone, spread, "jitter %q randomizes the wait", tc.jitter)
+	}
+	assert.Zero(t, jitteredBackoff(0, RetryJitterFull))
+}
+
+// flakyBackend answers 503 to the first failures requests, then 200.
+func flakyBackend(t *testing.T, failures int32) (*httptest.Server, *atomic.Int32) {
+	var hits atomic.Int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	t.Cleanup(backend.Close)
+	return backend, &hits
+}
+
+func TestExecuteWithRetries(t *testing.T) {
+	retryCfg := func() *config.Config {
+		return &config.Config{UpstreamRetries: 3, UpstreamRetryBackoff: time.Millisecond, UpstreamRetryMaxBackoff: 5 * time.Millisecond}
+	}
+	call := func(t *testing.T, method string, cfg *config.Config, failures int32) (*http.Response, int32) {
+		t.Helper()
+		backend, hits := flakyBackend(t, failures)
+		toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+			"orders": {Method: method, Path: "/orders", BaseURL: backend.URL},
+		}}
+		resp, err := executeWithRetries(&ToolCallParams{ToolName: "orders", Input: map[string]interface{}{}}, toolSet, cfg)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp, hits.Load()
+	}
+
+	t.Run("Idempotent methods are retried", func(t *testing.T) {
+		resp, hits := call(t, "GET", retryCfg(), 2)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(3), hits)
+	})
+
+	t.Run("Retries stop after the configured attempts", func(t *testing.T) {
+		resp, hits := call(t, "PUT", retryCfg(), 10)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, int32(4), hits)
+	})
+
+	t.Run("A POST without an idempotency key is not retried", func(t *testing.T) {
+		resp, hits := call(t, "POST", retryCfg(), 1)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, int32(1), hits)
+	})
+
+	t.Run("A POST with an idempotency key is retried", func(t *testing.T) {
+		cfg := retryCfg()
+		cfg.IdempotencyWindow = time.Minute
+		cfg.IdempotentOperations = []string{"orders"}
+		resp, hits := call(t, "POST", cfg, 1)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(2), hits)
+	})
+
+	t.Run("Retries are off by default", func(t *testing.T) {
+		resp, hits := call(t, "GET", &config.Config{}, 1)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, int32(1), hits)
+	})
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// errURLTooLong reports a request URL over config.MaxURLLength, usually because of large array
+// query parameters. It is returned before calling the upstream, which would answer 414 or cut the
+// URL off.
+type errURLTooLong struct {
+	toolName string
+	length   int
+	limit    int
+}
+
+func (e *errURLTooLong) Error() string {
+	return fmt.Sprintf("the request URL of tool '%s' would be %d bytes, over the limit of %d: its query parameters are too large. Send fewer or shorter values, or split the call into several", e.toolName, e.length, e.limit)
+}
+
+// checkURLLength returns an errURLTooLong if targetURL is over the configured limit.
+func checkURLLength(toolName, targetURL string, cfg *config.Config) error {
+	if cfg == nil || cfg.MaxURLLength <= 0 || len(targetURL) <= cfg.MaxURLLength {
+		return nil
+	}
+	return &errURLTooLong{toolName: toolName, length: len(targetURL), limit: cfg.MaxURLLength}
+}
+
+// longQueryFallback returns the tool configured to take over a call whose URL is too long, such
+// as a POST search operation accepting the same arguments in its body. A fallback never falls
+// back further.
+func longQueryFallback(params *ToolCallParams, toolSet *mcp.ToolSet, cfg *config.Config) (*ToolCallParams, bool) {
+	if cfg == nil || params.urlFallbackOf != "" {
+		return nil, false
+	}
+	fallbackName, ok := cfg.LongQueryFallbacks[params.ToolName]
+	if !ok || fallbackName == params.ToolName {
+		return nil, false
+	}
+	if _, ok := toolSet.Operations[fallbackName]; !ok {
+		log.Printf("[ExecuteToolCall] Warning: long query fallback '%s' of tool '%s' is not a tool", fallbackName, params.ToolName)
+		return nil, false
+	}
+	fallback := *params
+	fallback.ToolName = fallbackName
+	fallback.urlFallbackOf = params.ToolName
+	return &fallback, true
+}
+
+// callLongQueryFallback calls a long query fallback in place of the tool whose URL was too long.
+// Its response is handled as the fallback's own, with the fallback's size limit, pagination,
+// projection, transforms and schema, and the call is subject to the fallback's rate and
+// concurrency limits.
+func callLongQueryFallback(connID string, req *jsonRPCRequest, fallback *ToolCallParams, toolSet *mcp.ToolSet, cfg *config.Config) ToolResultPayload {
+	if limited, ok := checkMethodRateLimit(req, fallback.ToolName, toolSet, cfg); ok {
+		return limited
+	}
+	release, err := acquireToolSlot(context.Background(), fallback.ToolName, toolSet, cfg)
+	if err != nil {
+		return toolCallResult(fallback.ToolName, req, nil, err)
+	}
+	defer release()
+	return callToolUpstream(connID, req, fallback, toolSet, cfg)
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func longQueryToolSet(baseURL string) *mcp.ToolSet {
+	return &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"list_orders":   {Method: "GET", Path: "/orders", BaseURL: baseURL, Parameters: []mcp.ParameterDetail{{Name: "ids", In: "query"}}},
+		"search_orders": {Method: "POST", Path: "/orders/search", BaseURL: baseURL},
+	}}
+}
+
+func manyOrderIDs(n int) []interface{} {
+	ids := make([]interface{}, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("order-%05d", i)
+	}
+	return ids
+}
+
+func TestCallToolUpstream_URLTooLong(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		w.Write([]byte(`{"orders": []}`))
+	}))
+	defer backend.Close()
+
+	toolSet := longQueryToolSet(backend.URL)
+	cfg := &config.Config{MaxURLLength: 8192}
+	call := func(ids []interface{}) ToolResultPayload {
+		params := &ToolCallParams{ToolName: "list_orders", Input: map[string]interface{}{"ids": ids}}
+		return callToolUpstream("conn", &jsonRPCRequest{ID: 1}, params, toolSet, cfg)
+	}
+
+	assert.False(t, call(manyOrderIDs(10)).IsError, "short URLs go through")
+
+	result := call(manyOrderIDs(2000))
+	assert.True(t, result.IsError)
+	require.Len(t, result.Content, 1)
+	assert.Contains(t, result.Content[0].Text, "request URL of tool 'list_orders' would be")
+	assert.Contains(t, result.Content[0].Text, "over the limit of 8192")
+	assert.Contains(t, result.Content[0].Text, "query parameters are too large")
+	assert.Equal(t, ErrorCategoryClientError, result.Meta["category"])
+
+	mu.Lock()
+	assert.Equal(t, []string{"GET /orders"}, requests, "the long URL is never sent")
+	mu.Unlock()
+}
+
+func TestCallToolUpstream_LongQueryFallback(t *testing.T) {
+	var gotMethod, gotPath string
+	var gotBody map[string]interface{}
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath = r.Method, r.URL.Path
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"orders": []}`))
+	}))
+	defer backend.Close()
+
+	cfg := &config.Config{MaxURLLength: 8192, LongQueryFallbacks: map[string]string{"list_orders": "search_orders"}}
+	ids := manyOrderIDs(2000)
+	params := &ToolCallParams{ToolName: "list_orders", Input: map[string]interface{}{"ids": ids}}
+	result := callToolUpstream("conn", &jsonRPCRequest{ID: 1}, params, longQueryToolSet(backend.URL), cfg)
+
+	assert.False(t, result.IsError)
+	assert.Equal(t, "POST", gotMethod)
+	assert.Equal(t, "/orders/search", gotPath)
+	assert.Len(t, gotBody["ids"], len(ids), "the fallback gets the same arguments in its body")
+}
+
+func TestCallToolUpstream_LongQueryFallbackHandledAsItself(t *testing.T) {
+	thumbnail := base64.StdEncoding.EncodeToString(pngBytes)
+	backend := binaryFieldBackend(t, map[string]interface{}{
+		"match":  map[string]interface{}{"id": "order-1", "thumbnail": thumbnail},
+		"orders": []interface{}{"only the original tool projects this"},
+		"cursor": "c2",
+	})
+	toolSet := &mcp.ToolSet{Operations: map[string]mcp.OperationDetail{
+		"list_orders": {Method: "GET", Path: "/orders", BaseURL: backend.URL, Parameters: []mcp.ParameterDetail{{Name: "ids", In: "query"}}, Responses: map[string]mcp.Schema{
+			"200": {Type: "object", Properties: map[string]mcp.Schema{"orders": {Type: "array"}}},
+		}},
+		"search_orders": {Method: "POST", Path: "/orders/search", BaseURL: backend.URL, Responses: map[string]mcp.Schema{
+			"200": {Type: "object", Properties: map[string]mcp.Schema{
+				"match":  {Type: "object", Properties: map[string]mcp.Schema{"thumbnail": {Type: "string", Format: "byte"}}},
+				"cursor": {Type: "string"},
+			}},
+		}},
+	}}
+	cfg := &config.Config{
+		MaxURLLength:           8192,
+		LongQueryFallbacks:     map[string]string{"list_orders": "search_orders"},
+		ResponseProjections:    map[string][]string{"list_orders": {"orders"}, "search_orders": {"match"}},
+		BinaryFieldsFromSchema: true,
+	}
+	params := &ToolCallParams{ToolName: "list_orders", Input: map[string]interface{}{"ids": manyOrderIDs(2000)}}
+
+	result := callToolUpstream("conn", &jsonRPCRequest{ID: 1}, params, toolSet, cfg)
+	require.False(t, result.IsError)
+	require.Len(t, result.Content, 2, "the binary fields of the fallback's response schema are split out")
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &body))
+	assert.Contains(t, body, "match", "the fallback's projection applies")
+	assert.NotContains(t, body, "orders", "the original tool's projection does not")
+	assert.NotContains(t, body, "cursor")
+	assert.Equal(t, ToolResultContent{Type: "image", Data: thumbnail, MimeType: "image/png"}, result.Content[1])
+}
+
+func TestLongQueryFallback_DoesNotChain(t *testing.T) {
+	toolSet := longQueryToolSet("http://api.example.test")
+	cfg := &config.Config{LongQueryFallbacks: map[string]string{"list_orders": "search_orders", "search_orders": "list_orders"}}
+
+	fallback, ok := longQueryFallback(&ToolCallParams{ToolName: "list_orders"}, toolSet, cfg)
+	require.True(t, ok)
+	assert.Equal(t, "search_orders", fallback.ToolName)
+	_, ok = longQueryFallback(fallback, toolSet, cfg)
+	assert.False(t, ok, "a fallback's URL being too long is an error")
+
+	_, ok = longQueryFallback(&ToolCallParams{ToolName: "list_orders"}, toolSet, &config.Config{LongQueryFallbacks: map[string]string{"list_orders": "missing"}})
+	assert.False(t, ok)
+}
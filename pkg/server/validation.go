@@ -0,0 +1,196 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+)
+
+// ValidationError describes one way a tool call's arguments fail the tool's input schema.
+// A list of these is returned as the JSON-RPC error data for -32602 Invalid params.
+type ValidationError struct {
+	Path    string `json:"path"`    // JSON pointer (RFC 6901) to the offending value, e.g. "/address/zip"
+	Keyword string `json:"keyword"` // Schema keyword that failed: "required", "type", "enum", "additionalProperties" or "format"
+	Message string `json:"message"` // Human-readable explanation
+}
+
+// validateToolInput checks arguments against a tool's input schema. Errors are sorted by path,
+// then keyword, so the output is stable.
+func validateToolInput(schema mcp.Schema, input map[string]interface{}) []ValidationError {
+	var errs []ValidationError
+	// Arguments are always an object, even if the schema omits the type
+	validateObject(schema, input, "", &errs)
+	sortValidationErrors(errs)
+	return errs
+}
+
+// sortValidationErrors orders errors by path, then keyword.
+func sortValidationErrors(errs []ValidationError) {
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Path != errs[j].Path {
+			return errs[i].Path < errs[j].Path
+		}
+		return errs[i].Keyword < errs[j].Keyword
+	})
+}
+
+// validateValue validates a single value against a schema, appending any failures to errs.
+func validateValue(schema mcp.Schema, value interface{}, path string, errs *[]ValidationError) {
+	if schema.Type != "" && !matchesSchemaType(schema.Type, value) {
+		*errs = append(*errs, ValidationError{
+			Path:    path,
+			Keyword: "type",
+			Message: fmt.Sprintf("expected %s, got %s", schema.Type, describeJSONType(value)),
+		})
+		return
+	}
+
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		*errs = append(*errs, ValidationError{
+			Path:    path,
+			Keyword: "enum",
+			Message: fmt.Sprintf("value %v is not one of %v", value, schema.Enum),
+		})
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		validateObject(schema, v, path, errs)
+	case []interface{}:
+		if schema.Items != nil {
+			for i, item := range v {
+				validateValue(*schema.Items, item, path+"/"+strconv.Itoa(i), errs)
+			}
+		}
+	}
+}
+
+// validateObject checks required properties and validates each declared property present.
+// Undeclared properties are allowed unless the schema sets additionalProperties: false.
+func validateObject(schema mcp.Schema, obj map[string]interface{}, path string, errs *[]ValidationError) {
+	if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+		for name := range obj {
+			if _, declared := schema.Properties[name]; !declared {
+				*errs = append(*errs, ValidationError{
+					Path:    path + "/" + escapeJSONPointer(name),
+					Keyword: "additionalProperties",
+					Message: fmt.Sprintf("unknown property '%s'", name),
+				})
+			}
+		}
+	}
+	for _, name := range schema.Required {
+		if _, ok := obj[name]; !ok {
+			*errs = append(*errs, ValidationError{
+				Path:    path + "/" + escapeJSONPointer(name),
+				Keyword: "required",
+				Message: fmt.Sprintf("missing required property '%s'", name),
+			})
+		}
+	}
+	for name, propSchema := range schema.Properties {
+		if value, ok := obj[name]; ok {
+			validateValue(propSchema, value, path+"/"+escapeJSONPointer(name), errs)
+		}
+	}
+}
+
+// matchesSchemaType reports whether a decoded JSON value has the given JSON Schema type.
+func matchesSchemaType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := toFloat(value)
+		return ok
+	case "integer":
+		_, ok := integerText(value)
+		return ok
+	case "null":
+		return value == nil
+	default:
+		return true // Unknown types are not enforced
+	}
+}
+
+// describeJSONType names a decoded JSON value's type for error messages.
+func describeJSONType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	default:
+		if _, ok := toFloat(v); ok {
+			if _, integral := integerText(v); integral {
+				return "integer"
+			}
+			return "number"
+		}
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// toFloat converts any Go numeric value, including a json.Number, to float64.
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// enumContains compares numerically for numbers and structurally otherwise.
+func enumContains(enum []interface{}, value interface{}) bool {
+	valueNum, valueIsNum := toFloat(value)
+	for _, candidate := range enum {
+		if candidateNum, ok := toFloat(candidate); ok && valueIsNum {
+			if candidateNum == valueNum {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeJSONPointer escapes a property name for use as a JSON pointer segment.
+func escapeJSONPointer(segment string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(segment, "~", "~0"), "/", "~1")
+}
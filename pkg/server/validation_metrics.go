@@ -0,0 +1,80 @@
+package server
+
+import (
+	"log"
+	"strings"
+	"sync"
+)
+
+// validationViolations names each schema keyword in the validation failure counters.
+var validationViolations = map[string]string{
+	"required":             "missing-required",
+	"type":                 "type-mismatch",
+	"enum":                 "enum",
+	"additionalProperties": "unknown-property",
+	"format":               "format",
+}
+
+// validationFailureCounter counts rejected tool call arguments by tool name and violation type,
+// so descriptions of the tools clients most often get wrong can be improved.
+type validationFailureCounter struct {
+	mutex  sync.Mutex
+	counts map[string]map[string]int64 // Tool name, then violation type
+}
+
+var validationFailures = &validationFailureCounter{counts: make(map[string]map[string]int64)}
+
+// ValidationFailures returns how many argument violations tool calls were rejected for since
+// start, keyed by tool name and then violation type: "missing-required", "type-mismatch",
+// "enum", "unknown-property" or "format". A call with several violations counts each one.
+func (s *Server) ValidationFailures() map[string]map[string]int64 {
+	return validationFailures.snapshot()
+}
+
+// violationType is the counter label for a failed schema keyword.
+func violationType(keyword string) string {
+	if violation, ok := validationViolations[keyword]; ok {
+		return violation
+	}
+	return keyword
+}
+
+// record counts a rejected call's violations and logs where they are. Only paths and violation
+// types are logged, never the argument values.
+func (c *validationFailureCounter) record(toolName string, errs []ValidationError) {
+	locations := make([]string, 0, len(errs))
+	c.mutex.Lock()
+	counts, ok := c.counts[toolName]
+	if !ok {
+		counts = make(map[string]int64)
+		c.counts[toolName] = counts
+	}
+	for _, validationErr := range errs {
+		violation := violationType(validationErr.Keyword)
+		counts[violation]++
+		path := validationErr.Path
+		if path == "" {
+			path = "/"
+		}
+		locations = append(locations, violation+" at "+path)
+	}
+	c.mutex.Unlock()
+
+	log.Printf("DEBUG: Invalid arguments for tool '%s': %s", toolName, strings.Join(locations, ", "))
+}
+
+// snapshot returns a copy of the counters.
+func (c *validationFailureCounter) snapshot() map[string]map[string]int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	snapshot := make(map[string]map[string]int64, len(c.counts))
+	for toolName, counts := range c.counts {
+		copied := make(map[string]int64, len(counts))
+		for violation, count := range counts {
+			copied[violation] = count
+		}
+		snapshot[toolName] = copied
+	}
+	return snapshot
+}
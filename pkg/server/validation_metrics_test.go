@@ -0,0 +1,60 @@
+package server
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationFailures_CountedByToolAndViolation(t *testing.T) {
+	toolSet := &mcp.ToolSet{Tools: []mcp.Tool{{
+		Name: "create_secret_order",
+		InputSchema: mcp.Schema{
+			Type: "object",
+			Properties: map[string]mcp.Schema{
+				"quantity": {Type: "integer"},
+				"priority": {Type: "string", Enum: []interface{}{"low", "high"}},
+				"note":     {Type: "string"},
+			},
+			Required: []string{"quantity", "note"},
+		},
+	}}}
+	s := NewServer(toolSet, &config.Config{DisableDescribeTool: true})
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	resp := dispatchToReadyConnection(t, s, "tools/call", map[string]interface{}{
+		"name":      "create_secret_order",
+		"arguments": map[string]interface{}{"quantity": "hunter2-quantity", "priority": "hunter2-priority"},
+	})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, -32602, resp.Error.Code)
+
+	counts := s.ValidationFailures()["create_secret_order"]
+	assert.Equal(t, map[string]int64{"missing-required": 1, "type-mismatch": 1, "enum": 1}, counts)
+
+	var logged string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.Contains(line, "Invalid arguments for tool") {
+			logged = line
+		}
+	}
+	assert.Contains(t, logged, "Invalid arguments for tool 'create_secret_order': missing-required at /note, enum at /priority, type-mismatch at /quantity")
+	assert.NotContains(t, logged, "hunter2", "argument values are not logged")
+
+	// A second failure adds to the same labels
+	dispatchToReadyConnection(t, s, "tools/call", map[string]interface{}{
+		"name":      "create_secret_order",
+		"arguments": map[string]interface{}{"quantity": 1},
+	})
+	assert.Equal(t, int64(2), s.ValidationFailures()["create_secret_order"]["missing-required"])
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var orderInputSchema = mcp.Schema{
+	Type: "object",
+	Properties: map[string]mcp.Schema{
+		"quantity": {Type: "integer"},
+		"priority": {Type: "string", Enum: []interface{}{"low", "high"}},
+		"shipping": {
+			Type: "object",
+			Properties: map[string]mcp.Schema{
+				"zip/code": {Type: "string"},
+			},
+			Required: []string{"country"},
+		},
+		"tags": {Type: "array", Items: &mcp.Schema{Type: "string"}},
+	},
+	Required: []string{"quantity"},
+}
+
+func TestValidateToolInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []ValidationError
+	}{
+		{name: "Valid input", input: `{"quantity": 2, "priority": "high", "tags": ["a"]}`, expected: nil},
+		{name: "Missing required", input: `{}`, expected: []ValidationError{
+			{Path: "/quantity", Keyword: "required", Message: "missing required property 'quantity'"},
+		}},
+		{name: "Wrong type and bad enum", input: `{"quantity": 1.5, "priority": "urgent"}`, expected: []ValidationError{
+			{Path: "/priority", Keyword: "enum", Message: "value urgent is not one of [low high]"},
+			{Path: "/quantity", Keyword: "type", Message: "expected integer, got number"},
+		}},
+		{name: "Nested object and array items", input: `{"quantity": 1, "shipping": {"zip/code": 12345}, "tags": ["ok", 7]}`, expected: []ValidationError{
+			{Path: "/shipping/country", Keyword: "required", Message: "missing required property 'country'"},
+			{Path: "/shipping/zip~1code", Keyword: "type", Message: "expected string, got integer"},
+			{Path: "/tags/1", Keyword: "type", Message: "expected string, got integer"},
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var input map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(tc.input), &input))
+			assert.Equal(t, tc.expected, validateToolInput(orderInputSchema, input))
+		})
+	}
+}
+
+func TestToolCall_ValidationErrorData(t *testing.T) {
+	toolSet := &mcp.ToolSet{
+		Tools:      []mcp.Tool{{Name: "create_order", InputSchema: orderInputSchema}},
+		Operations: map[string]mcp.OperationDetail{"create_order": {Method: "POST", Path: "/orders", BaseURL: "http://127.0.0.1:1"}},
+	}
+	params := json.RawMessage(`{"name": "create_order", "arguments": {"quantity": "two", "priority": "urgent"}}`)
+	req := &jsonRPCRequest{Jsonrpc: "2.0", Method: "tools/call", Params: params, ID: "validate-1"}
+
+	resp := handleToolCallJSONRPC("test-conn", req, toolSet, &config.Config{})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, -32602, resp.Error.Code)
+
+	// The data field is a stable list of {path, keyword, message}
+	encoded, err := json.Marshal(resp.Error.Data)
+	require.NoError(t, err)
+	var entries []map[string]string
+	require.NoError(t, json.Unmarshal(encoded, &entries))
+	require.Len(t, entries, 2)
+	assert.Equal(t, map[string]string{"path": "/priority", "keyword": "enum", "message": "value urgent is not one of [low high]"}, entries[0])
+	assert.Equal(t, map[string]string{"path": "/quantity", "keyword": "type", "message": "expected integer, got string"}, entries[1])
+
+	// Validation can be turned off, in which case the call is attempted upstream
+	resp = handleToolCallJSONRPC("test-conn", req, toolSet, &config.Config{DisableInputValidation: true})
+	assert.Nil(t, resp.Error)
+}
+
+func TestValidateToolInput_AdditionalProperties(t *testing.T) {
+	disallowed, allowed := false, true
+	schema := mcp.Schema{
+		Type:                 "object",
+		AdditionalProperties: &disallowed,
+		Properties: map[string]mcp.Schema{
+			"name":     {Type: "string"},
+			"metadata": {Type: "object", AdditionalProperties: &allowed},
+			"address":  {Type: "object", AdditionalProperties: &disallowed, Properties: map[string]mcp.Schema{"city": {Type: "string"}}},
+		},
+	}
+
+	var input map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "Ada", "nickname": "A", "metadata": {"anything": 1}, "address": {"city": "London", "planet": "Earth"}}`), &input))
+	assert.Equal(t, []ValidationError{
+		{Path: "/address/planet", Keyword: "additionalProperties", Message: "unknown property 'planet'"},
+		{Path: "/nickname", Keyword: "additionalProperties", Message: "unknown property 'nickname'"},
+	}, validateToolInput(schema, input))
+
+	// Without additionalProperties: false, unknown properties are allowed
+	schema.AdditionalProperties = nil
+	schema.Properties["address"] = mcp.Schema{Type: "object", Properties: map[string]mcp.Schema{"city": {Type: "string"}}}
+	assert.Nil(t, validateToolInput(schema, input))
+}
+
+func TestToolCall_AdditionalProperties(t *testing.T) {
+	var receivedBody map[string]interface{}
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&receivedBody)
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer backend.Close()
+
+	disallowed := false
+	noteSchema := mcp.Schema{Type: "object", Properties: map[string]mcp.Schema{"text": {Type: "string"}}}
+	strictSchema := noteSchema
+	strictSchema.AdditionalProperties = &disallowed
+
+	toolSet := func(schema mcp.Schema) *mcp.ToolSet {
+		return &mcp.ToolSet{
+			Tools:      []mcp.Tool{{Name: "create_note", InputSchema: schema}},
+			Operations: map[string]mcp.OperationDetail{"create_note": {Method: "POST", Path: "/notes", BaseURL: backend.URL}},
+		}
+	}
+	params := json.RawMessage(`{"name": "create_note", "arguments": {"text": "hi", "color": "red"}}`)
+	req := &jsonRPCRequest{Jsonrpc: "2.0", Method: "tools/call", Params: params, ID: "extra-1"}
+
+	// Strict: the stray field is rejected before any upstream call
+	resp := handleToolCallJSONRPC("test-conn", req, toolSet(strictSchema), &config.Config{})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, -32602, resp.Error.Code)
+	assert.Equal(t, []ValidationError{{Path: "/color", Keyword: "additionalProperties", Message: "unknown property 'color'"}}, resp.Error.Data)
+	assert.Nil(t, receivedBody)
+
+	// Lenient: the stray field is passed through to the upstream
+	resp = handleToolCallJSONRPC("test-conn", req, toolSet(noteSchema), &config.Config{})
+	require.Nil(t, resp.Error)
+	assert.Equal(t, map[string]interface{}{"text": "hi", "color": "red"}, receivedBody)
+}
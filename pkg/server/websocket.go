@@ -0,0 +1,208 @@
+package server
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+)
+
+// WebSocket transport defaults, used when the corresponding config value is zero.
+const (
+	defaultWebSocketMaxMessageBytes = 1 << 20
+	defaultWebSocketReadTimeout     = 60 * time.Second
+	defaultWebSocketWriteTimeout    = 10 * time.Second
+)
+
+var webSocketUpgrader = websocket.Upgrader{
+	// Matches the permissive CORS policy of the HTTP transport
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// webSocketSettings resolves the WebSocket limits from config, applying defaults.
+func webSocketSettings(cfg *config.Config) (maxMessageBytes int64, readTimeout, writeTimeout time.Duration) {
+	maxMessageBytes, readTimeout, writeTimeout = defaultWebSocketMaxMessageBytes, defaultWebSocketReadTimeout, defaultWebSocketWriteTimeout
+	if cfg == nil {
+		return
+	}
+	if cfg.WebSocketMaxMessageBytes > 0 {
+		maxMessageBytes = cfg.WebSocketMaxMessageBytes
+	}
+	if cfg.WebSocketReadTimeout > 0 {
+		readTimeout = cfg.WebSocketReadTimeout
+	}
+	if cfg.WebSocketWriteTimeout > 0 {
+		writeTimeout = cfg.WebSocketWriteTimeout
+	}
+	return
+}
+
+// webSocketHandler upgrades the request and serves MCP over the socket. Each socket owns exactly
+// one Connection: inbound text frames are JSON-RPC requests, and everything queued on the
+// connection's Channel is written back as a frame.
+func webSocketHandler(w http.ResponseWriter, r *http.Request, tools *toolSetSource, cfg *config.Config) {
+	var conn *Connection
+	var reattached bool
+	connID := r.Header.Get(sessionIDHeader(cfg))
+	if connID == "" {
+		// Minted before the upgrade so the ID can go out in the handshake response
+		generated, err := mcpConnectionManager.NewGeneratedConnection(r)
+		if err != nil {
+			log.Printf("[WebSocket] Rejecting upgrade: %v", err)
+			http.Error(w, "Could not assign a connection ID", http.StatusInternalServerError)
+			return
+		}
+		conn = generated
+		connID = conn.ID
+	} else if err := mcpConnectionManager.validateID(connID); err != nil {
+		log.Printf("[WebSocket] Rejecting upgrade: %v", err)
+		http.Error(w, "Invalid "+sessionIDHeader(cfg)+" header", http.StatusBadRequest)
+		return
+	} else if mcpConnectionManager.GetConnection(connID) != nil {
+		// A session handed over from another instance (or restored at startup) resumes as it was
+		conn, reattached, _ = mcpConnectionManager.ReattachConnection(connID)
+		if !reattached {
+			log.Printf("[WebSocket] Rejecting upgrade: connection %s is already in use", connID)
+			http.Error(w, "Connection ID already in use", http.StatusConflict)
+			return
+		}
+	}
+
+	ws, err := webSocketUpgrader.Upgrade(w, r, http.Header{sessionIDHeader(cfg): []string{connID}})
+	if err != nil {
+		// Upgrade has already written an HTTP error response
+		log.Printf("[WebSocket] Upgrade failed for %s: %v", connID, err)
+		if reattached {
+			mcpConnectionManager.detachConnection(conn) // Kept for the client's next attempt
+		} else if conn != nil {
+			mcpConnectionManager.removeConnectionInstance(conn)
+		}
+		return
+	}
+
+	if conn == nil {
+		if conn, err = mcpConnectionManager.NewConnection(connID); err != nil {
+			// The ID was checked before the upgrade, so only a policy change gets here
+			log.Printf("[WebSocket] Closing %s: %v", connID, err)
+			ws.Close()
+			return
+		}
+	}
+	connID = conn.ID
+	log.Printf("[WebSocket] Connection %s opened from %s", connID, r.RemoteAddr)
+
+	maxMessageBytes, readTimeout, writeTimeout := webSocketSettings(cfg)
+	done := make(chan struct{})
+	writerDone := make(chan struct{})
+
+	go func() {
+		defer close(writerDone)
+		webSocketWriteLoop(ws, conn, done, readTimeout, writeTimeout)
+	}()
+
+	webSocketReadLoop(ws, conn, tools, cfg, writerDone, maxMessageBytes, readTimeout, writeTimeout)
+
+	// Socket closed (or failed): tear the connection down
+	close(done)
+	<-writerDone
+	mcpConnectionManager.removeConnectionInstance(conn)
+	ws.Close()
+	log.Printf("[WebSocket] Connection %s closed", connID)
+}
+
+// webSocketReadLoop reads JSON-RPC frames until the socket closes, goes idle past readTimeout, or
+// the writer stops (writerDone is closed).
+func webSocketReadLoop(ws *websocket.Conn, conn *Connection, tools *toolSetSource, cfg *config.Config, writerDone <-chan struct{}, maxMessageBytes int64, readTimeout, writeTimeout time.Duration) {
+	connID := conn.ID
+
+	ws.SetReadLimit(maxMessageBytes)
+	ws.SetReadDeadline(time.Now().Add(readTimeout))
+	ws.SetPongHandler(func(string) error {
+		mcpConnectionManager.Touch(connID)
+		return ws.SetReadDeadline(time.Now().Add(readTimeout))
+	})
+	ws.SetPingHandler(func(appData string) error {
+		mcpConnectionManager.Touch(connID)
+		if err := ws.SetReadDeadline(time.Now().Add(readTimeout)); err != nil {
+			return err
+		}
+		err := ws.WriteControl(websocket.PongMessage, []byte(appData), time.Now().Add(writeTimeout))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+		return err
+	})
+
+	for {
+		messageType, data, err := ws.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Printf("[WebSocket] Read error for %s: %v", connID, err)
+			}
+			return
+		}
+		ws.SetReadDeadline(time.Now().Add(readTimeout))
+		if messageType != websocket.TextMessage && messageType != websocket.BinaryMessage {
+			continue
+		}
+
+		log.Printf("[WebSocket] Received message for %s: %s", connID, string(data))
+
+		var respToSend jsonRPCResponse
+		var req jsonRPCRequest
+		if err := decodeJSON(data, &req); err != nil {
+			log.Printf("[WebSocket] Error decoding JSON-RPC request for %s: %v", connID, err)
+			respToSend = createJSONRPCError(nil, -32700, "Parse error decoding JSON request", err.Error())
+		} else {
+			req.size = int64(len(data))
+			// No frames (pongs included) are read while a call runs, so the idle deadline is
+			// suspended until it returns; a peer gone meanwhile fails the writer's pings instead
+			ws.SetReadDeadline(time.Time{})
+			var respond bool
+			respToSend, respond = dispatchJSONRPC(conn, connID, &req, tools.Load(), cfg)
+			ws.SetReadDeadline(time.Now().Add(readTimeout))
+			if !respond {
+				continue
+			}
+		}
+
+		// Block until the writer picks it up (the socket provides backpressure) or stops for good
+		if err := conn.sendOrDone(respToSend, writerDone); err != nil {
+			return
+		}
+		log.Printf("[WebSocket] Queued response (ID: %v) for %s", respToSend.ID, connID)
+	}
+}
+
+// webSocketWriteLoop writes queued responses and periodic pings until done is closed.
+func webSocketWriteLoop(ws *websocket.Conn, conn *Connection, done <-chan struct{}, readTimeout, writeTimeout time.Duration) {
+	// Ping well within the peer's read deadline so idle sockets stay alive
+	ticker := time.NewTicker(readTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case resp, ok := <-conn.Channel:
+			if !ok {
+				ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(writeTimeout))
+				return
+			}
+			ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := ws.WriteJSON(resp); err != nil {
+				log.Printf("[WebSocket] Write error for %s: %v", conn.ID, err)
+				ws.Close() // Unblocks the read loop
+				return
+			}
+		case <-ticker.C:
+			if err := ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				log.Printf("[WebSocket] Ping failed for %s: %v", conn.ID, err)
+				ws.Close()
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
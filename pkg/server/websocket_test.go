@@ -0,0 +1,211 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/litui/openapi-mcp-claude/pkg/config"
+	"github.com/litui/openapi-mcp-claude/pkg/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readWebSocketResponse reads the next JSON-RPC response frame, failing the test on timeout.
+func readWebSocketResponse(t *testing.T, ws *websocket.Conn) map[string]interface{} {
+	t.Helper()
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var resp map[string]interface{}
+	require.NoError(t, ws.ReadJSON(&resp))
+	return resp
+}
+
+func TestWebSocketTransport_FullFlow(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/users/42", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"42","name":"Ada"}`))
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{
+		Tools: []mcp.Tool{{Name: "get_user", InputSchema: mcp.Schema{Type: "object"}}},
+		Operations: map[string]mcp.OperationDetail{
+			"get_user": {Method: "GET", Path: "/users/{id}", BaseURL: backend.URL, Parameters: []mcp.ParameterDetail{{Name: "id", In: "path"}}},
+		},
+	}
+	srv := httptest.NewServer(newMCPMux(toolSet, &config.Config{}))
+	defer srv.Close()
+
+	const connID = "ws-full-flow"
+	header := http.Header{"Mcp-Session-Id": []string{connID}}
+	ws, httpResp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", header)
+	require.NoError(t, err)
+	assert.Equal(t, connID, httpResp.Header.Get("Mcp-Session-Id"))
+
+	// initialize -> notifications/initialized -> tools/call
+	require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","method":"initialize","id":1,"params":{"protocolVersion":"2024-11-05"}}`)))
+	initResp := readWebSocketResponse(t, ws)
+	assert.Equal(t, float64(1), initResp["id"])
+	assert.Nil(t, initResp["error"])
+	assert.Equal(t, StateInitializing, mcpConnectionManager.GetConnection(connID).State)
+
+	require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)))
+	require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","method":"tools/call","id":2,"params":{"name":"get_user","arguments":{"id":"42"}}}`)))
+	callResp := readWebSocketResponse(t, ws)
+	assert.Equal(t, float64(2), callResp["id"])
+	result, ok := callResp["result"].(map[string]interface{})
+	require.True(t, ok, "tools/call should return a result: %v", callResp)
+	assert.Equal(t, false, result["isError"])
+	content := result["content"].([]interface{})
+	assert.Equal(t, `{"id":"42","name":"Ada"}`, content[0].(map[string]interface{})["text"])
+
+	// A second socket cannot claim the same connection
+	_, conflictResp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", header)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusConflict, conflictResp.StatusCode)
+
+	// Closing the socket removes the connection
+	require.NoError(t, ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")))
+	ws.Close()
+	assert.Eventually(t, func() bool { return mcpConnectionManager.GetConnection(connID) == nil }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestWebSocketTransport_Keepalive(t *testing.T) {
+	srv := httptest.NewServer(newMCPMux(&mcp.ToolSet{}, &config.Config{WebSocketReadTimeout: 200 * time.Millisecond}))
+	defer srv.Close()
+
+	ws, httpResp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	defer ws.Close()
+	connID := httpResp.Header.Get("Mcp-Session-Id")
+	require.NotEmpty(t, connID, "server should assign a connection ID")
+
+	conn := mcpConnectionManager.GetConnection(connID)
+	require.NotNil(t, conn)
+	before := conn.LastActivity
+
+	// A client ping is answered with a pong and counts as activity
+	pong := make(chan struct{}, 1)
+	ws.SetPongHandler(func(string) error { pong <- struct{}{}; return nil })
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, ws.WriteControl(websocket.PingMessage, []byte("hi"), time.Now().Add(time.Second)))
+	go func() {
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	select {
+	case <-pong:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no pong received")
+	}
+	assert.True(t, mcpConnectionManager.GetConnection(connID).LastActivity.After(before))
+}
+
+func TestWebSocketTransport_MaxMessageSize(t *testing.T) {
+	srv := httptest.NewServer(newMCPMux(&mcp.ToolSet{}, &config.Config{WebSocketMaxMessageBytes: 64}))
+	defer srv.Close()
+
+	ws, httpResp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	defer ws.Close()
+	connID := httpResp.Header.Get("Mcp-Session-Id")
+
+	require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","method":"initialize","id":1,"params":{"padding":"`+strings.Repeat("x", 128)+`"}}`)))
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(2*time.Second)))
+	_, _, err = ws.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseMessageTooBig), "expected close 1009, got %v", err)
+	assert.Eventually(t, func() bool { return mcpConnectionManager.GetConnection(connID) == nil }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestWebSocket_SessionIDHeader(t *testing.T) {
+	srv := httptest.NewServer(newMCPMux(&mcp.ToolSet{}, &config.Config{SessionIDHeader: "X-Gateway-Session"}))
+	defer srv.Close()
+
+	const connID = "ws-gateway-session"
+	ws, httpResp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", http.Header{"X-Gateway-Session": []string{connID}})
+	require.NoError(t, err)
+	defer ws.Close()
+	assert.Equal(t, connID, httpResp.Header.Get("X-Gateway-Session"))
+
+	require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","method":"initialize","id":1,"params":{"protocolVersion":"2024-11-05"}}`)))
+	initResp := readWebSocketResponse(t, ws)
+	assert.Equal(t, connID, initResp["result"].(map[string]interface{})["connectionId"])
+	assert.NotNil(t, mcpConnectionManager.GetConnection(connID))
+}
+
+func TestWebSocketTransport_CallLongerThanReadTimeout(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(600 * time.Millisecond)
+		w.Write([]byte(`{"done":true}`))
+	}))
+	defer backend.Close()
+
+	toolSet := &mcp.ToolSet{
+		Tools:      []mcp.Tool{{Name: "slow_report", InputSchema: mcp.Schema{Type: "object"}}},
+		Operations: map[string]mcp.OperationDetail{"slow_report": {Method: "GET", Path: "/report", BaseURL: backend.URL}},
+	}
+	srv := httptest.NewServer(newMCPMux(toolSet, &config.Config{WebSocketReadTimeout: 200 * time.Millisecond}))
+	defer srv.Close()
+
+	ws, httpResp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	defer ws.Close()
+	connID := httpResp.Header.Get("Mcp-Session-Id")
+
+	require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","method":"initialize","id":1,"params":{"protocolVersion":"2024-11-05"}}`)))
+	readWebSocketResponse(t, ws)
+	require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)))
+	require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","method":"tools/call","id":2,"params":{"name":"slow_report","arguments":{}}}`)))
+	callResp := readWebSocketResponse(t, ws)
+	assert.Equal(t, float64(2), callResp["id"])
+	assert.NotNil(t, callResp["result"])
+
+	// The session outlives the call: a later ping is still answered
+	pong := make(chan struct{}, 1)
+	ws.SetPongHandler(func(string) error { pong <- struct{}{}; return nil })
+	go func() {
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)))
+	select {
+	case <-pong:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no pong received after the long call")
+	}
+	assert.NotNil(t, mcpConnectionManager.GetConnection(connID), "the connection is kept")
+}
+
+func TestWebSocketTransport_WriterFailureTearsDown(t *testing.T) {
+	// Big tools/list results to a client that never reads: the writer hits its deadline while
+	// the reader is waiting for room in the full channel
+	toolSet := &mcp.ToolSet{Tools: []mcp.Tool{{Name: "big", Description: strings.Repeat("x", 256<<10), InputSchema: mcp.Schema{Type: "object"}}}}
+	srv := httptest.NewServer(newMCPMux(toolSet, &config.Config{WebSocketWriteTimeout: 100 * time.Millisecond}))
+	defer srv.Close()
+
+	ws, httpResp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	defer ws.Close()
+	connID := httpResp.Header.Get("Mcp-Session-Id")
+
+	require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","method":"initialize","id":1,"params":{"protocolVersion":"2024-11-05"}}`)))
+	require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)))
+	for i := 0; i < 100; i++ {
+		if err := ws.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","method":"tools/list","id":2}`)); err != nil {
+			break
+		}
+	}
+	assert.Eventually(t, func() bool { return mcpConnectionManager.GetConnection(connID) == nil }, 5*time.Second, 20*time.Millisecond,
+		"the connection is removed once the writer gives up")
+}
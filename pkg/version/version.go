@@ -0,0 +1,12 @@
+package version
+
+// Build-time identification of the server binary.
+// These are overridden at link time, e.g.:
+//
+//	go build -ldflags "-X github.com/litui/openapi-mcp-claude/pkg/version.Version=1.2.3" ./cmd/openapi-mcp-claude
+var (
+	// Name is the server name reported to MCP clients in serverInfo.
+	Name = "openapi-mcp-claude"
+	// Version is the server version reported to MCP clients in serverInfo.
+	Version = "dev"
+)